@@ -0,0 +1,747 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/borankux/gops/internal/cli"
+	"github.com/borankux/gops/internal/config"
+	"github.com/borankux/gops/internal/port"
+	"github.com/borankux/gops/internal/process"
+	"github.com/borankux/gops/internal/service"
+	"github.com/borankux/gops/internal/utils"
+	"github.com/borankux/gops/internal/watch"
+	"github.com/borankux/gops/internal/webhook"
+	"github.com/borankux/gops/internal/window"
+)
+
+// runCommand dispatches a subcommand such as "gops kill 1234". file is
+// the configuration file given with -config, if any.
+func runCommand(ctx context.Context, args []string, file *config.File) {
+	switch args[0] {
+	case "kill":
+		runKill(ctx, args[1:])
+	case "signal":
+		runSignal(ctx, args[1:])
+	case "renice":
+		runRenice(ctx, args[1:])
+	case "find":
+		runFind(ctx, args[1:])
+	case "inspect":
+		runInspect(ctx, args[1:])
+	case "env":
+		runEnv(ctx, args[1:])
+	case "files":
+		runFiles(ctx, args[1:])
+	case "memory":
+		runMemory(ctx, args[1:])
+	case "threads":
+		runThreads(ctx, args[1:])
+	case "limits":
+		runLimits(ctx, args[1:])
+	case "icon":
+		runIcon(ctx, args[1:])
+	case "doctor":
+		runDoctor(ctx, args[1:])
+	case "focused":
+		runFocused(ctx, args[1:])
+	case "focus":
+		runFocus(ctx, args[1:])
+	case "close":
+		runClose(ctx, args[1:])
+	case "text":
+		runText(ctx, args[1:])
+	case "move":
+		runMove(ctx, args[1:])
+	case "arrange":
+		runArrange(ctx, args[1:])
+	case "minimize":
+		runMinimize(ctx, args[1:])
+	case "restore":
+		runRestore(ctx, args[1:])
+	case "hide":
+		runHide(ctx, args[1:])
+	case "diff":
+		runDiff(ctx, args[1:])
+	case "launch":
+		runLaunch(ctx, args[1:])
+	case "watch-ports":
+		runWatchPorts(ctx, args[1:])
+	case "service":
+		runService(ctx, args[1:])
+	case "watchdog":
+		runWatchdog(ctx, args[1:], file)
+	default:
+		fmt.Fprintf(os.Stderr, "❌ Error: unknown command %q\n", args[0])
+		os.Exit(2)
+	}
+}
+
+// runKill terminates a process: gops kill [-signal NAME] [-force] <pid>
+func runKill(ctx context.Context, args []string) {
+	fs := flag.NewFlagSet("kill", flag.ExitOnError)
+	sigName := fs.String("signal", "TERM", "Signal to send, by name or number")
+	force := fs.Bool("force", false, "Send SIGKILL, which the process cannot catch")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s kill [-signal NAME] [-force] <pid>\n\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	pid := parsePID(fs.Arg(0))
+
+	sig, err := process.ParseSignal(*sigName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Error: %v\n", err)
+		os.Exit(1)
+	}
+	if *force {
+		sig = syscall.SIGKILL
+	}
+
+	if err := cli.SendSignal(ctx, pid, sig); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// runSignal sends any supported signal: gops signal <signal> <pid>
+func runSignal(ctx context.Context, args []string) {
+	fs := flag.NewFlagSet("signal", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s signal <signal> <pid>\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Supported signals: %s\n", strings.Join(process.SignalNames(), ", "))
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 2 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	sig, err := process.ParseSignal(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Error: %v\n", err)
+		os.Exit(1)
+	}
+	pid := parsePID(fs.Arg(1))
+
+	if err := cli.SendSignal(ctx, pid, sig); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// runRenice changes a process priority: gops renice <priority> <pid>,
+// where priority is a nice value or, on Windows, a priority class
+func runRenice(ctx context.Context, args []string) {
+	fs := flag.NewFlagSet("renice", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s renice <priority> <pid>\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Priority is a nice value from %d to %d, or on Windows one of: %s\n",
+			process.MinNice, process.MaxNice, strings.Join(process.PriorityClasses, ", "))
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 2 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	pid := parsePID(fs.Arg(1))
+
+	if err := cli.SetPriority(ctx, pid, fs.Arg(0)); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// runService shows or controls a service: gops service [-user] <action> <name>,
+// where action is status, logs or one of service.Actions
+func runService(ctx context.Context, args []string) {
+	fs := flag.NewFlagSet("service", flag.ExitOnError)
+	user := fs.Bool("user", false, "Act on a systemd user unit (Linux)")
+	lines := fs.Int("lines", service.DefaultLogLines, "Log lines to show with logs")
+	since := fs.Duration("since", 0, "With logs, only show lines from this long ago, e.g. 30m")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s service [-user] [-lines N] [-since 30m] <status|logs|%s> <name>\n", os.Args[0], strings.Join(service.Actions, "|"))
+		fmt.Fprintf(os.Stderr, "       %s service [-lines N] failed\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Name is a launchd label, systemd unit or Windows service name.\n")
+		fmt.Fprintf(os.Stderr, "failed reports the services whose last run failed, with their last %d log lines.\n\n", service.DefaultFailedLogLines)
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	failed := fs.NArg() == 1 && fs.Arg(0) == "failed"
+	if fs.NArg() != 2 && !failed {
+		fs.Usage()
+		os.Exit(2)
+	}
+	opts := service.ControlOptions{User: *user}
+
+	var err error
+	switch fs.Arg(0) {
+	case "failed":
+		failedOpts := service.FailedOptions{LogLines: service.DefaultFailedLogLines}
+		fs.Visit(func(f *flag.Flag) {
+			if f.Name == "lines" {
+				failedOpts.LogLines = *lines
+			}
+		})
+		err = cli.DisplayFailedServices(ctx, failedOpts)
+	case "status":
+		err = cli.DisplayService(ctx, fs.Arg(1), opts)
+	case "logs":
+		logOpts := service.LogOptions{Lines: *lines, User: *user}
+		if *since > 0 {
+			logOpts.Since = time.Now().Add(-*since)
+		}
+		err = cli.DisplayServiceLogs(ctx, fs.Arg(1), logOpts)
+	default:
+		err = cli.ControlService(ctx, fs.Arg(1), fs.Arg(0), opts)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// runFind searches every process: gops find [-exact|-regex|-bundle] <pattern>
+func runFind(ctx context.Context, args []string) {
+	fs := flag.NewFlagSet("find", flag.ExitOnError)
+	exact := fs.Bool("exact", false, "Match the whole process name")
+	regex := fs.Bool("regex", false, "Treat the pattern as a regular expression")
+	bundle := fs.Bool("bundle", false, "Treat the pattern as a macOS bundle identifier")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s find [-exact|-regex|-bundle] <pattern>\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "By default, finds processes whose name contains the pattern.\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	var q process.Query
+	switch pattern := fs.Arg(0); {
+	case *regex:
+		q.Regex = pattern
+	case *bundle:
+		q.BundleID = pattern
+	default:
+		q.Name = pattern
+		q.Exact = *exact
+	}
+
+	if err := cli.DisplayFoundProcesses(ctx, q); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// runInspect prints the details of a process: gops inspect <pid>
+func runInspect(ctx context.Context, args []string) {
+	fs := flag.NewFlagSet("inspect", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s inspect <pid>\n", os.Args[0])
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	if err := cli.DisplayProcess(ctx, parsePID(fs.Arg(0))); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// runEnv prints a process environment: gops env <pid>
+func runEnv(ctx context.Context, args []string) {
+	fs := flag.NewFlagSet("env", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s env <pid>\n", os.Args[0])
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	if err := cli.DisplayEnvironment(ctx, parsePID(fs.Arg(0))); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// runFiles lists the files a process holds open: gops files <pid>
+func runFiles(ctx context.Context, args []string) {
+	fs := flag.NewFlagSet("files", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s files <pid>\n", os.Args[0])
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	if err := cli.DisplayOpenFiles(ctx, parsePID(fs.Arg(0))); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// runMemory shows the memory map of a process: gops memory [-regions] <pid>
+func runMemory(ctx context.Context, args []string) {
+	fs := flag.NewFlagSet("memory", flag.ExitOnError)
+	regions := fs.Bool("regions", false, "List every memory region, not just totals by type")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s memory [-regions] <pid>\n\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	if err := cli.DisplayMemoryMap(ctx, parsePID(fs.Arg(0)), *regions); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// runThreads lists the threads of a process: gops threads <pid>
+func runThreads(ctx context.Context, args []string) {
+	fs := flag.NewFlagSet("threads", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s threads <pid>\n", os.Args[0])
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	if err := cli.DisplayThreads(ctx, parsePID(fs.Arg(0))); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// runLimits shows the resource limits of a process: gops limits <pid>
+func runLimits(ctx context.Context, args []string) {
+	fs := flag.NewFlagSet("limits", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s limits <pid>\n", os.Args[0])
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	if err := cli.DisplayResourceLimits(ctx, parsePID(fs.Arg(0))); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// runIcon saves the application icon of a process as a PNG:
+// gops icon [-size 128] [-o file] <pid>
+func runIcon(ctx context.Context, args []string) {
+	fs := flag.NewFlagSet("icon", flag.ExitOnError)
+	size := fs.Int("size", process.DefaultIconSize, "Preferred icon width in pixels")
+	output := fs.String("o", "", "File to write (default: icon-<pid>.png)")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s icon [-size 128] [-o file] <pid>\n\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	pid := parsePID(fs.Arg(0))
+	if *output == "" {
+		*output = fmt.Sprintf("icon-%d.png", pid)
+	}
+	if err := cli.SaveIcon(ctx, pid, *size, *output); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// runLaunch starts an application: gops launch [-bundle] <app> [args...]
+func runLaunch(ctx context.Context, args []string) {
+	fs := flag.NewFlagSet("launch", flag.ExitOnError)
+	bundle := fs.Bool("bundle", false, "Treat app as a macOS bundle identifier")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s launch [-bundle] <app> [args...]\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "App is an executable or, on macOS, an .app bundle or bundle identifier.\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() < 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	req := process.LaunchRequest{Path: fs.Arg(0), Args: fs.Args()[1:]}
+	if *bundle {
+		req.BundleID, req.Path = req.Path, ""
+	}
+
+	if err := cli.LaunchApp(ctx, req); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// runDoctor checks permissions and helper commands: gops doctor
+func runDoctor(ctx context.Context, args []string) {
+	if len(args) != 0 {
+		fmt.Fprintf(os.Stderr, "Usage: %s doctor\n", os.Args[0])
+		os.Exit(2)
+	}
+	if err := cli.Doctor(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// runFocused shows the frontmost app and its focused window: gops focused
+func runFocused(ctx context.Context, args []string) {
+	if len(args) != 0 {
+		fmt.Fprintf(os.Stderr, "Usage: %s focused\n", os.Args[0])
+		os.Exit(2)
+	}
+	if err := cli.DisplayFocusedWindow(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// runFocus brings a window to the foreground:
+// gops focus [-id <id>] [-pid <pid>] [title]
+func runFocus(ctx context.Context, args []string) {
+	q := parseWindowQuery("focus", args)
+	if err := cli.FocusWindow(ctx, q); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// runClose closes a window: gops close [-dry-run] [-id <id>] [-pid <pid>] [title]
+func runClose(ctx context.Context, args []string) {
+	fs := flag.NewFlagSet("close", flag.ExitOnError)
+	id := fs.Uint("id", 0, "Window ID, as reported by the list_windows tool")
+	pid := fs.String("pid", "", "Process owning the window")
+	dryRun := fs.Bool("dry-run", false, "Only show which window would be closed")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s close [-dry-run] [-id <id>] [-pid <pid>] [title]\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Title matches windows whose title contains it, ignoring case.\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	q := window.Query{ID: uint32(*id), Title: strings.Join(fs.Args(), " ")}
+	if *pid != "" {
+		q.PID = parsePID(*pid)
+	}
+	if q.ID == 0 && q.PID == 0 && q.Title == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	if err := cli.CloseWindow(ctx, q, *dryRun); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// runMove moves and resizes a window:
+// gops move [-preset left_half] [-display n] [-x n] [-y n] [-width n] [-height n] [-id <id>] [-pid <pid>] [title]
+func runMove(ctx context.Context, args []string) {
+	fs := flag.NewFlagSet("move", flag.ExitOnError)
+	id := fs.Uint("id", 0, "Window ID, as reported by the list_windows tool")
+	pid := fs.String("pid", "", "Process owning the window")
+	preset := fs.String("preset", "", "Lay the window out on its display: "+strings.Join(window.Presets, ", "))
+	display := fs.Int("display", 0, "With -preset, lay the window out on this display (see -displays)")
+	x := fs.Int("x", 0, "Left edge")
+	y := fs.Int("y", 0, "Top edge")
+	width := fs.Int("width", 0, "Width")
+	height := fs.Int("height", 0, "Height")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s move [-preset <preset>] [-x n] [-y n] [-width n] [-height n] [-id <id>] [-pid <pid>] [title]\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Title matches windows whose title contains it, ignoring case. Values left out keep the window's current ones.\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	q := window.Query{ID: uint32(*id), Title: strings.Join(fs.Args(), " ")}
+	if *pid != "" {
+		q.PID = parsePID(*pid)
+	}
+	f := window.Frame{Preset: *preset, Display: *display}
+	fs.Visit(func(fl *flag.Flag) {
+		switch fl.Name {
+		case "x":
+			f.X = x
+		case "y":
+			f.Y = y
+		case "width":
+			f.Width = width
+		case "height":
+			f.Height = height
+		}
+	})
+	if (q.ID == 0 && q.PID == 0 && q.Title == "") ||
+		(f.Preset == "" && f.X == nil && f.Y == nil && f.Width == nil && f.Height == nil) {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	if err := cli.MoveWindow(ctx, q, f); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// runText prints the visible text of a window: gops text [-id <id>] [-pid <pid>] [title]
+func runText(ctx context.Context, args []string) {
+	q := parseWindowQuery("text", args)
+	if err := cli.DisplayWindowText(ctx, q); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// runArrange lays out several windows on a display:
+// gops arrange [-layout side_by_side] [-display n] <id> <id>...
+func runArrange(ctx context.Context, args []string) {
+	fs := flag.NewFlagSet("arrange", flag.ExitOnError)
+	layout := fs.String("layout", window.LayoutSideBySide, "Layout: "+strings.Join(window.Layouts, ", "))
+	display := fs.Int("display", 0, "Display to arrange the windows on (see -displays), default the first window's")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s arrange [-layout <layout>] [-display n] <id> <id>...\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Window IDs are those reported by the list_windows tool; the first gets the leftmost column, the top row or the top left cell.\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() == 0 || *display < 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	ids := make([]uint32, 0, fs.NArg())
+	for _, arg := range fs.Args() {
+		id, err := strconv.ParseUint(arg, 10, 32)
+		if err != nil || id == 0 {
+			fmt.Fprintf(os.Stderr, "❌ Error: invalid window ID: %s\n", arg)
+			os.Exit(1)
+		}
+		ids = append(ids, uint32(id))
+	}
+
+	if err := cli.ArrangeWindows(ctx, *layout, ids, *display); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// runMinimize minimizes a window: gops minimize [-id <id>] [-pid <pid>] [title]
+func runMinimize(ctx context.Context, args []string) {
+	q := parseWindowQuery("minimize", args)
+	if err := cli.MinimizeWindow(ctx, q); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// runRestore restores a minimized window: gops restore [-id <id>] [-pid <pid>] [title]
+func runRestore(ctx context.Context, args []string) {
+	q := parseWindowQuery("restore", args)
+	if err := cli.RestoreWindow(ctx, q); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// parseWindowQuery parses the window selection arguments of the command
+// name, exiting with its usage when none are given
+func parseWindowQuery(name string, args []string) window.Query {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	id := fs.Uint("id", 0, "Window ID, as reported by the list_windows tool")
+	pid := fs.String("pid", "", "Process owning the window")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s %s [-id <id>] [-pid <pid>] [title]\n\n", os.Args[0], name)
+		fmt.Fprintf(os.Stderr, "Title matches windows whose title contains it, ignoring case.\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	q := window.Query{ID: uint32(*id), Title: strings.Join(fs.Args(), " ")}
+	if *pid != "" {
+		q.PID = parsePID(*pid)
+	}
+	if q.ID == 0 && q.PID == 0 && q.Title == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+	return q
+}
+
+// runHide hides every window of an app: gops hide <pid>
+func runHide(ctx context.Context, args []string) {
+	if len(args) != 1 {
+		fmt.Fprintf(os.Stderr, "Usage: %s hide <pid>\n", os.Args[0])
+		os.Exit(2)
+	}
+	if err := cli.HideApp(ctx, parsePID(args[0])); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// runDiff shows process changes over an interval: gops diff [-interval 5s]
+func runDiff(ctx context.Context, args []string) {
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	interval := fs.Duration("interval", 5*time.Second, "Time between the two snapshots")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s diff [-interval 5s]\n\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 0 || *interval <= 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	if err := cli.DisplayProcessDiff(ctx, *interval); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// runWatchPorts reports ports as they open and close:
+// gops watch-ports [-interval 2s] [-ports 22,80] [-exposed] [-webhook URL]
+func runWatchPorts(ctx context.Context, args []string) {
+	fs := flag.NewFlagSet("watch-ports", flag.ExitOnError)
+	interval := fs.Duration("interval", watch.DefaultInterval, "Time between polls")
+	ports := fs.String("ports", "", "Comma-separated port numbers to watch (default: all)")
+	protocol := fs.String("protocol", "", "Only watch tcp or udp ports, or tcp4, tcp6, udp4 or udp6")
+	exposed := fs.Bool("exposed", false, "Only watch ports reachable from other machines")
+	hooks := fs.String("webhook", "", "Comma-separated URLs to POST each change to")
+	asJSON := fs.Bool("json", false, "Print each change as a JSON event")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s watch-ports [-interval 2s] [-ports 22,80] [-protocol tcp] [-exposed] [-webhook URL] [-json]\n\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 0 || *interval <= 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	var only []uint32
+	for _, p := range strings.Split(*ports, ",") {
+		if p = strings.TrimSpace(p); p == "" {
+			continue
+		}
+		n, err := strconv.ParseUint(p, 10, 16)
+		if err != nil || n == 0 {
+			fmt.Fprintf(os.Stderr, "❌ Error: invalid port: %s\n", p)
+			os.Exit(1)
+		}
+		only = append(only, uint32(n))
+	}
+
+	list := port.ListOptions{Protocol: *protocol, Exposed: *exposed}
+	if err := cli.WatchPorts(ctx, *interval, list, only, webhook.ParseURLs(*hooks), *asJSON); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// runWatchdog keeps the services and processes given as flags, and those
+// in the watchdog section of the configuration file, running
+func runWatchdog(ctx context.Context, args []string, file *config.File) {
+	var targets []watch.WatchdogTarget
+	var opts watch.WatchdogOptions
+	if file != nil {
+		targets = file.WatchdogTargets()
+		opts = file.WatchdogOptions()
+	}
+
+	fs := flag.NewFlagSet("watchdog", flag.ExitOnError)
+	services := fs.String("services", "", "Comma-separated services to keep running")
+	user := fs.Bool("user", false, "The services are systemd user units")
+	processes := fs.String("processes", "", "Comma-separated process names to keep running")
+	notifyOnly := fs.Bool("notify-only", false, "Only report targets going down, without restarting them")
+	fs.DurationVar(&opts.Interval, "interval", opts.Interval, "Time between checks (default 5s)")
+	fs.DurationVar(&opts.Backoff, "backoff", opts.Backoff, "Delay before the second restart, doubling after each (default 2s)")
+	fs.DurationVar(&opts.MaxBackoff, "max-backoff", opts.MaxBackoff, "Longest delay between restarts (default 5m)")
+	fs.IntVar(&opts.MaxRestarts, "max-restarts", opts.MaxRestarts, "Restarts within the window before giving up (default 5)")
+	fs.DurationVar(&opts.RestartWindow, "window", opts.RestartWindow, "Period restarts are counted over (default 1h)")
+	hooks := fs.String("webhook", "", "Comma-separated URLs to POST each event to")
+	asJSON := fs.Bool("json", false, "Print each event as JSON")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s watchdog [-services nginx,redis] [-processes myserver] [-max-restarts 5] [-backoff 2s] [-notify-only] [-webhook URL] [-json] [-- command args...]\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "A command after the flags starts the one process given with -processes;\nby default a process is started again with the command line it last ran with.\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	for _, name := range utils.SplitList(*services) {
+		targets = append(targets, watch.WatchdogTarget{Service: name, User: *user, NotifyOnly: *notifyOnly})
+	}
+	procs := utils.SplitList(*processes)
+	if fs.NArg() > 0 && len(procs) != 1 {
+		fmt.Fprintf(os.Stderr, "❌ Error: a command needs exactly one process name in -processes\n")
+		os.Exit(2)
+	}
+	for _, name := range procs {
+		targets = append(targets, watch.WatchdogTarget{Process: name, Command: fs.Args(), NotifyOnly: *notifyOnly})
+	}
+	if len(targets) == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	if err := cli.Watchdog(ctx, targets, opts, webhook.ParseURLs(*hooks), *asJSON); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// parsePID parses a PID argument, exiting on invalid input
+func parsePID(arg string) int32 {
+	pid, err := strconv.ParseInt(arg, 10, 32)
+	if err != nil || pid < 0 {
+		fmt.Fprintf(os.Stderr, "❌ Error: invalid PID: %s\n", arg)
+		os.Exit(1)
+	}
+	return int32(pid)
+}
@@ -8,41 +8,176 @@ import (
 	"os/signal"
 	"strconv"
 	"syscall"
+	"time"
 
 	"github.com/borankux/gops/internal/cli"
+	"github.com/borankux/gops/internal/config"
 	"github.com/borankux/gops/internal/mcp"
+	"github.com/borankux/gops/internal/network"
+	"github.com/borankux/gops/internal/port"
+	"github.com/borankux/gops/internal/process"
+	"github.com/borankux/gops/internal/service"
+	"github.com/borankux/gops/internal/utils"
+	"github.com/borankux/gops/internal/watch"
+	"github.com/borankux/gops/internal/webhook"
 )
 
 func main() {
 	var (
 		// CLI flags
 		processes  = flag.Bool("processes", false, "List user applications")
+		allProcs   = flag.Bool("all", false, "With -processes, include system processes")
+		cmdline    = flag.Bool("cmdline", false, "With -processes, show full command lines")
+		translated = flag.Bool("translated", false, "With -processes, only show processes running under Rosetta 2")
+		tree       = flag.Bool("tree", false, "Show processes as a parent/child tree")
+		zombies    = flag.Bool("zombies", false, "List zombie and orphaned processes")
 		windows    = flag.Bool("windows", false, "List open windows")
+		hidden     = flag.Bool("hidden", false, "With -windows, include minimized and hidden windows")
+		fullscreen = flag.Bool("fullscreen", false, "With -windows, only show full-screen windows")
+		displays   = flag.Bool("displays", false, "List connected displays")
+		space      = flag.String("space", "", "With -windows, only show windows on a Space or virtual desktop: current or its number")
 		ports      = flag.Bool("ports", false, "List open ports")
+		conns      = flag.Bool("connections", false, "List established connections")
+		unixSocks  = flag.Bool("unix", false, "List Unix domain sockets")
+		scan       = flag.String("scan", "", "Probe a range of local TCP ports, e.g. 3000-9000, for servers that answer")
+		interfaces = flag.Bool("interfaces", false, "List network interfaces")
+		routes     = flag.Bool("routes", false, "List the routing table")
+		vpnOnly    = flag.Bool("vpn", false, "With -routes, only show routes through VPN tunnels")
+		neighbors  = flag.Bool("neighbors", false, "List the ARP and NDP neighbor tables")
+		dns        = flag.Bool("dns", false, "Show the DNS resolvers and search domains")
+		dnsCache   = flag.Bool("cache", false, "With -dns, also list the DNS cache (Linux and Windows)")
+		nameFilter = flag.String("name", "", "With -dns -cache, only show records whose name contains this text; with -services, only services whose name matches this glob")
+		sockPath   = flag.String("path", "", "With -unix, only show sockets whose path contains this text")
 		resource   = flag.Bool("resource", false, "Show resource usage for a process")
 		services   = flag.Bool("services", false, "List system services")
+		svcStatus  = flag.String("status", "", "With -services, only show running, stopped or failed services")
+		svcDomain  = flag.String("domain", "", "With -services, only show system daemons (system) or per-user agents (user)")
+		scheduled  = flag.Bool("scheduled", false, "List cron entries, scheduled launchd jobs and systemd timers")
+		sysMetrics = flag.Bool("system", false, "Show total CPU, load averages, memory, swap, disk usage and uptime")
 		portFilter = flag.String("port", "", "Filter ports by port number")
+		protocol   = flag.String("protocol", "", "With -ports, only show tcp or udp ports, or tcp4, tcp6, udp4 or udp6 for one address family")
+		resolve    = flag.Bool("resolve", false, "With -ports, look up bind addresses with reverse DNS")
+		exposed    = flag.Bool("exposed", false, "With -ports, only show ports reachable from other machines")
+		firewall   = flag.Bool("firewall", false, "With -ports, show whether the firewall allows or blocks each port")
+		state      = flag.String("state", "", "With -ports, list sockets in a TCP state (e.g. established, time_wait) or all, instead of listeners")
 		pid        = flag.String("pid", "", "Filter ports by PID or show resource usage")
+		sortBy     = flag.String("sort", "", "Sort listings by key (e.g. pid, name, cpu, memory, uptime, port)")
+		order      = flag.String("order", "", "Sort order: asc or desc")
 
 		// MCP server flags
 		serverMode = flag.Bool("server", false, "Start MCP server")
+		configPath = flag.String("config", "", "Load server settings from a YAML file (flags take precedence)")
 		serverPort = flag.Int("server-port", 8080, "MCP server port (default: 8080)")
+		bindAddr   = flag.String("bind", mcp.DefaultBind, "Address to listen on; use 0.0.0.0 to accept connections on every interface")
+		stdioMode  = flag.Bool("stdio", false, "Serve MCP over stdin/stdout")
+		authToken  = flag.String("auth-token", os.Getenv("GOPS_AUTH_TOKEN"), "Require this bearer token on API routes (env: GOPS_AUTH_TOKEN)")
+		accessLog  = flag.String("access-log", "stdout", "JSON access log destination: stdout, stderr, off, or a file path")
+		rateLimit  = flag.Float64("rate-limit", 10, "Requests per second allowed per client (0 disables)")
+		rateBurst  = flag.Int("rate-burst", 20, "Burst size for the per-client rate limit")
+		collectTO  = flag.Duration("collector-timeout", 30*time.Second, "Maximum time a single collection may take (0 disables)")
+		cacheTTL   = flag.Duration("cache-ttl", mcp.DefaultCacheTTL, "How long identical tool calls reuse a cached result (0 disables)")
+		shutdownTO = flag.Duration("shutdown-timeout", 10*time.Second, "How long to wait for in-flight requests and streams on shutdown")
+		enableTool = flag.String("enable-tools", "", "Comma-separated tools or groups to allow, or opt-in tools to add (default: all but opt-in tools)")
+		disabTool  = flag.String("disable-tools", "", "Comma-separated tools or groups to turn off, e.g. services")
+		webhooks   = flag.String("webhook", "", "Comma-separated URLs to POST system events to")
+		cpuAlert   = flag.Float64("cpu-alert", 0, "Publish process.cpu_high events above this CPU percent (0 disables)")
+		geoipDBs   = flag.String("geoip", os.Getenv("GOPS_GEOIP"), "Comma-separated MaxMind Country, City or ASN databases to locate remote addresses with (env: GOPS_GEOIP)")
+		portHist   = flag.Bool("port-history", false, "Record listening ports over time for get_port_history")
+		histFile   = flag.String("history-file", "", "Where to save the port history (default: gops/port-history.json in the user config directory)")
+		histKeep   = flag.Duration("history-retention", watch.DefaultPortRetention, "How long the port history keeps closed listeners")
+		corsOrigin = flag.String("cors-origins", "*", "Comma-separated browser origins allowed by CORS (\"*\" lets any read, \"none\" disables)")
 	)
 
 	flag.Usage = func() {
 		fmt.Fprintf(os.Stderr, "🔧 gops - Process and System Information Tool\n\n")
-		fmt.Fprintf(os.Stderr, "Usage: %s [mode] [options]\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Usage: %s [mode] [options]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s <command> [options] [args]\n\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "Modes:\n")
 		fmt.Fprintf(os.Stderr, "  CLI Mode (default):\n")
 		fmt.Fprintf(os.Stderr, "    -processes              List all user applications\n")
+		fmt.Fprintf(os.Stderr, "    -processes -all          Include system processes\n")
+		fmt.Fprintf(os.Stderr, "    -processes -cmdline      Show full command lines\n")
+		fmt.Fprintf(os.Stderr, "    -processes -translated   Show processes running under Rosetta 2\n")
+		fmt.Fprintf(os.Stderr, "    -tree [-pid 1234]        Show the process tree\n")
+		fmt.Fprintf(os.Stderr, "    -zombies                 List zombie and orphaned processes\n")
 		fmt.Fprintf(os.Stderr, "    -windows                 List open windows\n")
+		fmt.Fprintf(os.Stderr, "    -windows -space current  Only windows on the current Space or desktop\n")
+		fmt.Fprintf(os.Stderr, "    -windows -hidden         Include minimized and hidden windows\n")
+		fmt.Fprintf(os.Stderr, "    -windows -fullscreen     Only full-screen windows\n")
+		fmt.Fprintf(os.Stderr, "    -displays                List connected displays\n")
 		fmt.Fprintf(os.Stderr, "    -ports                   List all open ports\n")
 		fmt.Fprintf(os.Stderr, "    -ports -port 8080        Show info for port 8080\n")
+		fmt.Fprintf(os.Stderr, "    -ports -protocol udp     Only show UDP (or TCP) ports\n")
+		fmt.Fprintf(os.Stderr, "    -ports -exposed          Only show ports reachable from other machines\n")
+		fmt.Fprintf(os.Stderr, "    -ports -firewall         Show the firewall state and each port's verdict\n")
+		fmt.Fprintf(os.Stderr, "    -ports -resolve          Show the host name of each bind address\n")
+		fmt.Fprintf(os.Stderr, "    -ports -state time_wait  List sockets in TIME_WAIT (or any TCP state, or all)\n")
+		fmt.Fprintf(os.Stderr, "    -connections             List established connections (-pid, -port)\n")
+		fmt.Fprintf(os.Stderr, "    -connections -geoip DBS  Show remote countries and ASNs from MaxMind databases\n")
+		fmt.Fprintf(os.Stderr, "    -unix -path docker       List Unix domain sockets (-pid, -path)\n")
+		fmt.Fprintf(os.Stderr, "    -scan 3000-9000          Show which local ports answer and which are only bound\n")
+		fmt.Fprintf(os.Stderr, "    -interfaces              List network interfaces with addresses and counters\n")
+		fmt.Fprintf(os.Stderr, "    -routes [-vpn]           List the routing table, default routes first\n")
+		fmt.Fprintf(os.Stderr, "    -neighbors               List hosts in the ARP and NDP neighbor tables\n")
+		fmt.Fprintf(os.Stderr, "    -dns [-cache -name x]    Show DNS resolvers, search domains and the cache\n")
 		fmt.Fprintf(os.Stderr, "    -resource -pid 1234      Show resource usage for PID 1234\n")
-		fmt.Fprintf(os.Stderr, "    -services                List system services\n\n")
+		fmt.Fprintf(os.Stderr, "    -services                List system services\n")
+		fmt.Fprintf(os.Stderr, "    -services -status failed Only failed (or running, stopped) services\n")
+		fmt.Fprintf(os.Stderr, "    -services -name 'ssh*'   Only services whose name matches a glob\n")
+		fmt.Fprintf(os.Stderr, "    -services -domain user   Only per-user agents (or system daemons)\n")
+		fmt.Fprintf(os.Stderr, "    -scheduled               List cron entries, launchd calendar jobs and systemd timers\n")
+		fmt.Fprintf(os.Stderr, "    -system                  Show CPU, load, memory, swap, disk usage and uptime\n")
+		fmt.Fprintf(os.Stderr, "    -sort cpu -order desc    Sort processes, ports or services\n\n")
+		fmt.Fprintf(os.Stderr, "  Commands:\n")
+		fmt.Fprintf(os.Stderr, "    find [-regex] <pattern>  Find processes by name, regex or bundle ID\n")
+		fmt.Fprintf(os.Stderr, "    inspect <pid>            Show process details with its parent and children\n")
+		fmt.Fprintf(os.Stderr, "    env <pid>                Show a process environment (secrets redacted)\n")
+		fmt.Fprintf(os.Stderr, "    files <pid>              List files a process holds open\n")
+		fmt.Fprintf(os.Stderr, "    memory [-regions] <pid>  Show a process memory map by region type\n")
+		fmt.Fprintf(os.Stderr, "    threads <pid>            List a process's threads, busiest first\n")
+		fmt.Fprintf(os.Stderr, "    limits <pid>             Show a process's resource limits and usage\n")
+		fmt.Fprintf(os.Stderr, "    icon [-o file] <pid>     Save the application icon of a process as PNG\n")
+		fmt.Fprintf(os.Stderr, "    diff [-interval 5s]      Show processes started, stopped and changed over an interval\n")
+		fmt.Fprintf(os.Stderr, "    watch-ports [-exposed]   Report ports as they open and close (-ports, -webhook, -json)\n")
+		fmt.Fprintf(os.Stderr, "    watchdog -services a,b   Restart services or -processes when they die, with backoff\n")
+		fmt.Fprintf(os.Stderr, "    launch <app> [args...]   Start an application (-bundle for a macOS bundle ID)\n")
+		fmt.Fprintf(os.Stderr, "    doctor                   Check permissions and helper commands\n")
+		fmt.Fprintf(os.Stderr, "    focused                  Show the frontmost app and its focused window\n")
+		fmt.Fprintf(os.Stderr, "    focus <title>            Bring a window to the foreground (or by -pid, -id)\n")
+		fmt.Fprintf(os.Stderr, "    close [-dry-run] <title> Close a window (or by -pid, -id)\n")
+		fmt.Fprintf(os.Stderr, "    text <title>             Print the visible text of a window (or by -pid, -id)\n")
+		fmt.Fprintf(os.Stderr, "    move <title>             Move and resize a window (-preset left_half, or -x -y -width -height)\n")
+		fmt.Fprintf(os.Stderr, "    arrange <id> <id>...     Lay out windows side by side (-layout stack, grid)\n")
+		fmt.Fprintf(os.Stderr, "    minimize <title>         Minimize a window (or by -pid, -id)\n")
+		fmt.Fprintf(os.Stderr, "    restore <title>          Restore a minimized window (or by -pid, -id)\n")
+		fmt.Fprintf(os.Stderr, "    hide <pid>               Hide an app's windows (minimizes them off macOS)\n")
+		fmt.Fprintf(os.Stderr, "    kill [-force] <pid>      Terminate a process (SIGTERM, or SIGKILL with -force)\n")
+		fmt.Fprintf(os.Stderr, "    signal <signal> <pid>    Send a signal such as HUP or USR1 to a process\n")
+		fmt.Fprintf(os.Stderr, "    renice <priority> <pid>  Change a process nice value or Windows priority class\n")
+		fmt.Fprintf(os.Stderr, "    service <action> <name>  Show (status, logs), start, stop, restart, reload, enable or disable a service (-user for systemd user units)\n")
+		fmt.Fprintf(os.Stderr, "    service failed           Report failed services with their restarts and last log lines\n\n")
 		fmt.Fprintf(os.Stderr, "  MCP Server Mode:\n")
 		fmt.Fprintf(os.Stderr, "    -server                  Start MCP server\n")
-		fmt.Fprintf(os.Stderr, "    -server-port 8080        MCP server port (default: 8080)\n\n")
+		fmt.Fprintf(os.Stderr, "    -server-port 8080        MCP server port (default: 8080)\n")
+		fmt.Fprintf(os.Stderr, "    -bind 127.0.0.1          Listen address (0.0.0.0 for all interfaces)\n")
+		fmt.Fprintf(os.Stderr, "    -config gops.yaml        Load settings from a YAML file\n")
+		fmt.Fprintf(os.Stderr, "    -auth-token TOKEN        Require bearer token (env: GOPS_AUTH_TOKEN)\n")
+		fmt.Fprintf(os.Stderr, "    -access-log PATH         Access log: stdout, stderr, off, or a file\n")
+		fmt.Fprintf(os.Stderr, "    -rate-limit 10           Requests per second per client (0 disables)\n")
+		fmt.Fprintf(os.Stderr, "    -rate-burst 20           Burst size for the rate limit\n")
+		fmt.Fprintf(os.Stderr, "    -collector-timeout 30s   Maximum time a single collection may take\n")
+		fmt.Fprintf(os.Stderr, "    -cache-ttl 1s            Reuse identical tool results for this long (0 disables)\n")
+		fmt.Fprintf(os.Stderr, "    -shutdown-timeout 10s    Time allowed to drain connections on shutdown\n")
+		fmt.Fprintf(os.Stderr, "    -enable-tools LIST       Only allow these tools or groups, or add opt-in tools\n")
+		fmt.Fprintf(os.Stderr, "    -disable-tools LIST      Turn off these tools or groups\n")
+		fmt.Fprintf(os.Stderr, "    -webhook URLS            POST system events to these URLs\n")
+		fmt.Fprintf(os.Stderr, "    -cpu-alert 90            Emit process.cpu_high above this CPU percent\n")
+		fmt.Fprintf(os.Stderr, "    -geoip DBS               MaxMind databases for list_connections geoip\n")
+		fmt.Fprintf(os.Stderr, "    -port-history            Record listening ports over time (get_port_history)\n")
+		fmt.Fprintf(os.Stderr, "    -history-file PATH       Where to save the port history\n")
+		fmt.Fprintf(os.Stderr, "    -history-retention 168h  How long to keep closed listeners\n")
+		fmt.Fprintf(os.Stderr, "    -cors-origins LIST       Allowed CORS origins (\"*\", \"none\", or a comma list)\n")
+		fmt.Fprintf(os.Stderr, "    -stdio                   Serve MCP over stdin/stdout\n\n")
 		fmt.Fprintf(os.Stderr, "Examples:\n")
 		fmt.Fprintf(os.Stderr, "  %s -processes              List all user applications\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "  %s -server                 Start MCP server on port 8080\n", os.Args[0])
@@ -51,35 +186,168 @@ func main() {
 
 	flag.Parse()
 
+	// Settings from the config file apply unless the flag was given
+	var file *config.File
+	if *configPath != "" {
+		var err error
+		file, err = config.Load(*configPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Error loading config: %v\n", err)
+			os.Exit(1)
+		}
+		explicit := make(map[string]bool)
+		flag.Visit(func(f *flag.Flag) {
+			explicit[f.Name] = true
+		})
+		for name, value := range file.Flags() {
+			if explicit[name] {
+				continue
+			}
+			if err := flag.Set(name, value); err != nil {
+				fmt.Fprintf(os.Stderr, "❌ Invalid %s in config: %v\n", name, err)
+				os.Exit(1)
+			}
+		}
+	}
+
+	if *order != "" && *order != "asc" && *order != "desc" {
+		fmt.Fprintf(os.Stderr, "❌ Invalid -order %q: use asc or desc\n\n", *order)
+		flag.Usage()
+		os.Exit(2)
+	}
+
 	ctx := context.Background()
+	descending := utils.Descending(*sortBy, *order)
+
+	serverConfig := mcp.Config{
+		Bind:      *bindAddr,
+		Port:      *serverPort,
+		AuthToken: *authToken,
+		AccessLog: *accessLog,
+		RateLimit: *rateLimit,
+		RateBurst: *rateBurst,
+
+		CORSOrigins:      mcp.ParseCORSOrigins(*corsOrigin),
+		CacheTTL:         *cacheTTL,
+		CollectorTimeout: *collectTO,
+		EnabledTools:     utils.SplitList(*enableTool),
+		DisabledTools:    utils.SplitList(*disabTool),
+		Webhooks:         webhook.ParseURLs(*webhooks),
+		CPUThreshold:     *cpuAlert,
+		ShutdownTimeout:  *shutdownTO,
+	}
+	var rules process.FilterRules
+	if file != nil {
+		rules = process.FilterRules{
+			Include: process.Rules(file.Processes.Include),
+			Exclude: process.Rules(file.Processes.Exclude),
+		}
+		serverConfig.ProcessFilter = rules
+		serverConfig.WatchProcesses = file.Watch.Processes
+		serverConfig.WatchPorts = file.Watch.Ports
+		serverConfig.Webhooks = append(serverConfig.Webhooks, file.WebhookList()...)
+		serverConfig.WatchdogTargets = file.WatchdogTargets()
+		serverConfig.Watchdog = file.WatchdogOptions()
+	}
+
+	var geo *port.GeoIP
+	if *geoipDBs != "" && (*conns || *serverMode || *stdioMode) {
+		var err error
+		if geo, err = port.OpenGeoIP(utils.SplitList(*geoipDBs)); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Error: %v\n", err)
+			os.Exit(1)
+		}
+		defer geo.Close()
+		serverConfig.GeoIP = geo
+	}
+
+	if flag.NArg() > 0 {
+		runCommand(ctx, flag.Args(), file)
+		return
+	}
+
+	if *portHist && (*serverMode || *stdioMode) {
+		path := *histFile
+		if path == "" {
+			var err error
+			if path, err = watch.DefaultPortHistoryFile(); err != nil {
+				fmt.Fprintf(os.Stderr, "❌ Error locating port history: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		history, err := watch.NewPortHistory(path, *histKeep)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Error loading port history: %v\n", err)
+			os.Exit(1)
+		}
+		serverConfig.PortHistory = history
+	}
+
+	// MCP stdio mode
+	if *stdioMode {
+		server := mcp.NewServer(serverConfig)
+		if err := server.ServeStdio(ctx, os.Stdin, os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Error serving MCP over stdio: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
 
 	// MCP Server Mode
 	if *serverMode {
-		server := mcp.NewServer(*serverPort)
+		server := mcp.NewServer(serverConfig)
 
 		// Handle graceful shutdown
 		sigChan := make(chan os.Signal, 1)
 		signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
 
+		errChan := make(chan error, 1)
 		go func() {
-			<-sigChan
-			fmt.Println("\n🛑 Shutting down MCP server...")
-			if err := server.Stop(ctx); err != nil {
-				fmt.Fprintf(os.Stderr, "❌ Error stopping server: %v\n", err)
-			}
-			os.Exit(0)
+			errChan <- server.Start()
 		}()
 
-		if err := server.Start(); err != nil {
+		select {
+		case err := <-errChan:
 			fmt.Fprintf(os.Stderr, "❌ Error starting MCP server: %v\n", err)
 			os.Exit(1)
+		case <-sigChan:
+			fmt.Println("\n🛑 Shutting down MCP server...")
+			if err := server.Stop(ctx); err != nil {
+				fmt.Fprintf(os.Stderr, "❌ Error stopping server: %v\n", err)
+				os.Exit(1)
+			}
 		}
 		return
 	}
 
 	// CLI Mode
 	if *processes {
-		if err := cli.DisplayProcesses(ctx); err != nil {
+		if err := cli.DisplayProcesses(ctx, process.ListOptions{SortBy: *sortBy, Descending: descending, Cmdline: *cmdline, Rules: rules, Translated: *translated}, *allProcs); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *zombies {
+		if err := cli.DisplayStrayProcesses(ctx); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *tree {
+		var root int64
+		if *pid != "" {
+			var err error
+			root, err = strconv.ParseInt(*pid, 10, 32)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "❌ Error: invalid PID: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		if err := cli.DisplayProcessTree(ctx, int32(root)); err != nil {
 			fmt.Fprintf(os.Stderr, "❌ Error: %v\n", err)
 			os.Exit(1)
 		}
@@ -87,7 +355,15 @@ func main() {
 	}
 
 	if *windows {
-		if err := cli.DisplayWindows(ctx); err != nil {
+		if err := cli.DisplayWindows(ctx, *space, *fullscreen, *hidden); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *displays {
+		if err := cli.DisplayScreens(ctx); err != nil {
 			fmt.Fprintf(os.Stderr, "❌ Error: %v\n", err)
 			os.Exit(1)
 		}
@@ -95,7 +371,63 @@ func main() {
 	}
 
 	if *ports {
-		if err := cli.DisplayPorts(ctx, *portFilter, *pid); err != nil {
+		if err := cli.DisplayPorts(ctx, *portFilter, *pid, port.ListOptions{SortBy: *sortBy, Descending: descending, Protocol: *protocol, State: *state, Exposed: *exposed, Resolve: *resolve}, *firewall); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *conns {
+		if err := cli.DisplayConnections(ctx, *portFilter, *pid, port.ConnectionOptions{SortBy: *sortBy, Descending: descending, GeoIP: geo}); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *unixSocks {
+		if err := cli.DisplayUnixSockets(ctx, *pid, port.UnixSocketOptions{SortBy: *sortBy, Descending: descending, Path: *sockPath}); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *scan != "" {
+		if err := cli.DisplayScan(ctx, *scan); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *interfaces {
+		if err := cli.DisplayInterfaces(ctx); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *routes {
+		if err := cli.DisplayRoutes(ctx, network.RouteOptions{VPN: *vpnOnly}); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *neighbors {
+		if err := cli.DisplayNeighbors(ctx, network.NeighborOptions{}); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *dns {
+		if err := cli.DisplayDNS(ctx, *dnsCache, *nameFilter); err != nil {
 			fmt.Fprintf(os.Stderr, "❌ Error: %v\n", err)
 			os.Exit(1)
 		}
@@ -120,7 +452,23 @@ func main() {
 	}
 
 	if *services {
-		if err := cli.DisplayServices(ctx); err != nil {
+		if err := cli.DisplayServices(ctx, service.ListOptions{SortBy: *sortBy, Descending: descending, Status: *svcStatus, Name: *nameFilter, Domain: *svcDomain}); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *scheduled {
+		if err := cli.DisplayScheduledJobs(ctx); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *sysMetrics {
+		if err := cli.DisplaySystemMetrics(ctx); err != nil {
 			fmt.Fprintf(os.Stderr, "❌ Error: %v\n", err)
 			os.Exit(1)
 		}
@@ -128,13 +476,53 @@ func main() {
 	}
 
 	// Default: show help
-	fmt.Println("🔧 gops - Process and System Information Tool\n")
+	fmt.Println("🔧 gops - Process and System Information Tool")
+	fmt.Println()
 	fmt.Println("Available commands:")
 	fmt.Println("  -processes    List user applications")
+	fmt.Println("  -tree         Show the process tree")
+	fmt.Println("  -zombies      List zombie and orphaned processes")
 	fmt.Println("  -windows      List open windows")
+	fmt.Println("  -displays     List connected displays")
 	fmt.Println("  -ports        List open ports")
+	fmt.Println("  -connections  List established connections")
+	fmt.Println("  -unix         List Unix domain sockets")
+	fmt.Println("  -scan         Probe a range of local ports")
+	fmt.Println("  -interfaces   List network interfaces")
+	fmt.Println("  -routes       List the routing table")
+	fmt.Println("  -neighbors    List the ARP and NDP neighbor tables")
+	fmt.Println("  -dns          Show DNS resolvers and search domains")
 	fmt.Println("  -resource     Show resource usage (requires -pid)")
 	fmt.Println("  -services     List system services")
+	fmt.Println("  -scheduled    List scheduled jobs")
+	fmt.Println("  -system       Show how the machine is doing overall")
+	fmt.Println("  find          Find processes by name")
+	fmt.Println("  inspect <pid> Show process details")
+	fmt.Println("  env <pid>     Show a process environment")
+	fmt.Println("  files <pid>   List files a process holds open")
+	fmt.Println("  memory <pid>  Show a process memory map")
+	fmt.Println("  threads <pid> List the threads of a process")
+	fmt.Println("  limits <pid>  Show the resource limits of a process")
+	fmt.Println("  icon <pid>    Save the icon of a process")
+	fmt.Println("  diff          Show process changes over an interval")
+	fmt.Println("  watch-ports   Report ports as they open and close")
+	fmt.Println("  watchdog      Restart services or processes when they die")
+	fmt.Println("  launch <app>  Start an application")
+	fmt.Println("  doctor        Check permissions and helper commands")
+	fmt.Println("  focused       Show the focused window")
+	fmt.Println("  focus         Bring a window to the foreground")
+	fmt.Println("  close         Close a window")
+	fmt.Println("  text          Print the text of a window")
+	fmt.Println("  move          Move and resize a window")
+	fmt.Println("  arrange       Lay out several windows")
+	fmt.Println("  minimize      Minimize a window")
+	fmt.Println("  restore       Restore a minimized window")
+	fmt.Println("  hide          Hide an app's windows")
+	fmt.Println("  kill <pid>    Terminate a process")
+	fmt.Println("  signal        Send a signal to a process")
+	fmt.Println("  renice        Change a process priority")
+	fmt.Println("  service       Show or control a service")
 	fmt.Println("  -server       Start MCP server")
+	fmt.Println("  -stdio        Serve MCP over stdin/stdout")
 	fmt.Println("\nUse -help for more information")
 }
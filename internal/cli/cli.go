@@ -2,80 +2,365 @@ package cli
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"net"
 	"os"
+	"os/exec"
+	"runtime"
+	"sort"
 	"strconv"
+	"strings"
+	"syscall"
+	"time"
 
+	"github.com/borankux/gops/internal/events"
+	"github.com/borankux/gops/internal/network"
+	"github.com/borankux/gops/internal/permission"
 	"github.com/borankux/gops/internal/port"
 	"github.com/borankux/gops/internal/process"
 	"github.com/borankux/gops/internal/resource"
 	"github.com/borankux/gops/internal/service"
+	"github.com/borankux/gops/internal/system"
+	"github.com/borankux/gops/internal/utils"
+	"github.com/borankux/gops/internal/watch"
+	"github.com/borankux/gops/internal/webhook"
 	"github.com/borankux/gops/internal/window"
 	"github.com/borankux/gops/pkg/types"
 	"github.com/jedib0t/go-pretty/v6/table"
 )
 
-// DisplayProcesses displays processes in a formatted table
-func DisplayProcesses(ctx context.Context) error {
-	procs, err := process.GetUserApplications(ctx)
+// DisplayProcesses displays processes in a formatted table. System
+// processes are only included when all is set.
+func DisplayProcesses(ctx context.Context, opts process.ListOptions, all bool) error {
+	title := "📱 User Applications"
+	list := process.GetUserApplications
+	if all {
+		title = "⚙️  All Processes"
+		list = process.GetAllProcesses
+	}
+
+	procs, err := list(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(title)
+	fmt.Println()
+	renderProcesses(procs, opts.Cmdline)
+
+	return nil
+}
+
+// DisplayFoundProcesses displays every process matched by q
+func DisplayFoundProcesses(ctx context.Context, q process.Query) error {
+	procs, err := process.Find(ctx, q)
 	if err != nil {
 		return err
 	}
 
-	fmt.Println("📱 User Applications")
+	if len(procs) == 0 {
+		fmt.Println("🔍 No matching processes")
+		return nil
+	}
+	fmt.Println("🔍 Matching Processes")
 	fmt.Println()
+	renderProcesses(procs, false)
 
+	return nil
+}
+
+// renderProcesses prints procs as a table, showing each command line
+// instead of the executable path if cmdline is set
+func renderProcesses(procs []types.ProcessInfo, cmdline bool) {
 	t := table.NewWriter()
 	t.SetOutputMirror(os.Stdout)
-	t.AppendHeader(table.Row{"🔢 PID", "📛 Name", "👤 User", "📍 Path"})
+	if cmdline {
+		t.AppendHeader(table.Row{"🔢 PID", "📛 Name", "👤 User", "⏱️ Uptime", "💻 Command"})
+	} else {
+		t.AppendHeader(table.Row{"🔢 PID", "📛 Name", "👤 User", "⏱️ Uptime", "📍 Path"})
+	}
 	t.Style().Options.SeparateRows = true
 
 	for _, p := range procs {
+		last := truncateString(p.Path, 50)
+		if cmdline {
+			last = truncateString(strings.Join(p.Cmdline, " "), 80)
+		}
 		t.AppendRow(table.Row{
 			fmt.Sprintf("%d", p.PID),
 			p.Name,
 			p.User,
-			truncateString(p.Path, 50),
+			p.Uptime,
+			last,
 		})
 	}
 
-	t.AppendFooter(table.Row{"Total", len(procs), "", ""})
+	t.AppendFooter(table.Row{"Total", len(procs), "", "", ""})
+	t.Render()
+}
+
+// DisplayProcessTree prints processes indented under their parents,
+// starting at root or at every top-level process if root is zero
+func DisplayProcessTree(ctx context.Context, root int32) error {
+	tree, err := process.GetProcessTree(ctx, root)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("🌳 Process Tree")
+	fmt.Println()
+	for _, node := range tree {
+		printProcessNode(node, "", "", "")
+	}
+	fmt.Printf("\nTotal: %d processes\n", process.CountNodes(tree))
+
+	return nil
+}
+
+// printProcessNode prints node and its descendants; prefix is the
+// indentation inherited from ancestors
+func printProcessNode(node types.ProcessNode, prefix, branch, childPrefix string) {
+	line := fmt.Sprintf("%s%s%d %s", prefix, branch, node.PID, node.Name)
+	if node.User != "" {
+		line += fmt.Sprintf(" (%s)", node.User)
+	}
+	fmt.Println(line)
+
+	for i, child := range node.Children {
+		if i == len(node.Children)-1 {
+			printProcessNode(child, prefix+childPrefix, "└─ ", "   ")
+		} else {
+			printProcessNode(child, prefix+childPrefix, "├─ ", "│  ")
+		}
+	}
+}
+
+// DisplayStrayProcesses displays zombie processes with the parents that
+// failed to reap them, followed by orphaned processes
+func DisplayStrayProcesses(ctx context.Context) error {
+	zombies, orphans, err := process.GetStrayProcesses(ctx)
+	if err != nil {
+		return err
+	}
+
+	render := func(title string, procs []types.StrayProcess) {
+		fmt.Println(title)
+		fmt.Println()
+		if len(procs) == 0 {
+			fmt.Println("  None found")
+			fmt.Println()
+			return
+		}
+
+		t := table.NewWriter()
+		t.SetOutputMirror(os.Stdout)
+		t.AppendHeader(table.Row{"🔢 PID", "📛 Name", "👤 User", "👪 Parent"})
+		for _, p := range procs {
+			parent := fmt.Sprintf("%d", p.PPID)
+			if p.ParentName != "" {
+				parent += " (" + p.ParentName + ")"
+			} else {
+				parent += " (gone)"
+			}
+			t.AppendRow(table.Row{p.PID, p.Name, p.User, parent})
+		}
+		t.AppendFooter(table.Row{"Total", len(procs), "", ""})
+		t.Render()
+		fmt.Println()
+	}
+
+	render("🧟 Zombie Processes", zombies)
+	render("🏚️  Orphaned Processes", orphans)
+
+	return nil
+}
+
+// DisplayProcessDiff snapshots the process table twice, interval apart,
+// and displays what started, stopped and changed in between
+func DisplayProcessDiff(ctx context.Context, interval time.Duration) error {
+	before, err := process.TakeSnapshot(ctx)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("📸 Comparing processes over %s...\n\n", interval)
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(interval):
+	}
+	after, err := process.TakeSnapshot(ctx)
+	if err != nil {
+		return err
+	}
+	diff := process.Diff(before, after)
+
+	render := func(title string, procs []types.ProcessInfo) {
+		fmt.Println(title)
+		fmt.Println()
+		if len(procs) == 0 {
+			fmt.Println("  None")
+			fmt.Println()
+			return
+		}
+
+		t := table.NewWriter()
+		t.SetOutputMirror(os.Stdout)
+		t.AppendHeader(table.Row{"🔢 PID", "📛 Name", "👤 User", "📍 Path"})
+		for _, p := range procs {
+			t.AppendRow(table.Row{p.PID, p.Name, p.User, truncateString(p.Path, 60)})
+		}
+		t.AppendFooter(table.Row{"Total", len(procs), "", ""})
+		t.Render()
+		fmt.Println()
+	}
+
+	render("🟢 Started", diff.Started)
+	render("🔴 Stopped", diff.Stopped)
+
+	fmt.Println("📈 Changed")
+	fmt.Println()
+	if len(diff.Changed) == 0 {
+		fmt.Println("  None")
+		return nil
+	}
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"🔢 PID", "📛 Name", "⚡ CPU", "💾 Memory", "± Memory"})
+	for _, c := range diff.Changed {
+		delta := "+" + utils.FormatBytes(uint64(c.MemoryDelta))
+		if c.MemoryDelta < 0 {
+			delta = "-" + utils.FormatBytes(uint64(-c.MemoryDelta))
+		}
+		t.AppendRow(table.Row{c.PID, c.Name, utils.FormatCPU(c.CPUPercent), utils.FormatBytes(c.MemoryRSS), delta})
+	}
+	t.AppendFooter(table.Row{"Total", len(diff.Changed), "", "", ""})
 	t.Render()
 
 	return nil
 }
 
-// DisplayWindows displays open windows in a formatted table
-func DisplayWindows(ctx context.Context) error {
+// DisplayWindows displays open windows in a formatted table, only those
+// on the given Space unless it is empty and only full-screen ones with
+// fullscreen set. Minimized and hidden windows are left out unless
+// includeHidden is set.
+func DisplayWindows(ctx context.Context, space string, fullscreen, includeHidden bool) error {
 	windows, err := window.GetOpenWindows(ctx)
 	if err != nil {
 		return err
 	}
+	if windows, err = window.FilterSpace(windows, space); err != nil {
+		return err
+	}
+	if fullscreen {
+		windows = window.FilterFullscreen(windows)
+	}
+	omitted := 0
+	if !includeHidden {
+		windows, omitted = window.FilterHidden(windows)
+	}
 
 	fmt.Println("🪟 Open Windows")
 	fmt.Println()
 
 	t := table.NewWriter()
 	t.SetOutputMirror(os.Stdout)
-	t.AppendHeader(table.Row{"🪟 Title", "🔢 PID", "📛 Process"})
+	t.AppendHeader(table.Row{"🪟 Title", "🔢 PID", "📛 Process", "📐 Geometry", "🖥️ Display", "🗂️ Space"})
 	t.Style().Options.SeparateRows = true
 
 	for _, w := range windows {
+		geometry := ""
+		if g := w.Geometry; g != nil {
+			geometry = fmt.Sprintf("%dx%d at %d,%d", g.Width, g.Height, g.X, g.Y)
+		}
+		if w.Fullscreen {
+			geometry += " (full screen)"
+		}
+		if w.Minimized {
+			geometry += " (minimized)"
+		}
+		if w.Hidden {
+			geometry += " (hidden)"
+		}
+		display := ""
+		if w.Display > 0 {
+			display = fmt.Sprintf("%d", w.Display)
+		}
+		space := ""
+		switch {
+		case w.Space < 0:
+			space = "all"
+		case w.CurrentSpace:
+			space = fmt.Sprintf("%d (current)", w.Space)
+		case w.Space > 0:
+			space = fmt.Sprintf("%d", w.Space)
+		}
 		t.AppendRow(table.Row{
 			truncateString(w.Title, 60),
 			fmt.Sprintf("%d", w.PID),
 			w.Process,
+			strings.TrimSpace(geometry),
+			display,
+			space,
 		})
 	}
 
-	t.AppendFooter(table.Row{"Total", len(windows), ""})
+	t.AppendFooter(table.Row{"Total", len(windows), "", "", "", ""})
+	t.Render()
+
+	if omitted > 0 {
+		fmt.Printf("\n%d minimized or hidden window(s) not shown; use -hidden to include them\n", omitted)
+	}
+	return nil
+}
+
+// DisplayScreens displays connected displays in a formatted table
+func DisplayScreens(ctx context.Context) error {
+	displays, err := window.GetDisplays(ctx)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("🖥️ Displays")
+	fmt.Println()
+
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"#", "📛 Name", "📐 Bounds", "🔍 Resolution", "🔄 Refresh", "🪟 Windows"})
+	t.Style().Options.SeparateRows = true
+
+	for _, d := range displays {
+		name := d.Name
+		if d.Primary {
+			name += " (primary)"
+		}
+		bounds := ""
+		if b := d.Bounds; b != nil {
+			bounds = fmt.Sprintf("%dx%d at %d,%d", b.Width, b.Height, b.X, b.Y)
+		}
+		resolution := ""
+		if d.PixelWidth > 0 {
+			resolution = fmt.Sprintf("%dx%d", d.PixelWidth, d.PixelHeight)
+			if d.Scale > 1 {
+				resolution += fmt.Sprintf(" @%gx", d.Scale)
+			}
+		}
+		refresh := ""
+		if d.RefreshRate > 0 {
+			refresh = fmt.Sprintf("%.0f Hz", d.RefreshRate)
+		}
+		t.AppendRow(table.Row{d.Index, name, bounds, resolution, refresh, d.Windows})
+	}
+
+	t.AppendFooter(table.Row{"Total", len(displays), "", "", "", ""})
 	t.Render()
 
 	return nil
 }
 
 // DisplayPorts displays open ports in a formatted table
-func DisplayPorts(ctx context.Context, portFilter string, pidFilter string) error {
+func DisplayPorts(ctx context.Context, portFilter string, pidFilter string, opts port.ListOptions, firewall bool) error {
 	var ports []types.PortInfo
 	var err error
 
@@ -84,124 +369,1482 @@ func DisplayPorts(ctx context.Context, portFilter string, pidFilter string) erro
 		if parseErr != nil {
 			return fmt.Errorf("invalid port number: %w", parseErr)
 		}
-		ports, err = port.GetPortInfoByPort(ctx, uint32(portNum))
+		ports, err = port.GetPortInfoByPort(ctx, uint32(portNum), opts)
 	} else if pidFilter != "" {
 		pid, parseErr := strconv.ParseInt(pidFilter, 10, 32)
 		if parseErr != nil {
 			return fmt.Errorf("invalid PID: %w", parseErr)
 		}
-		ports, err = port.GetPortsByPID(ctx, int32(pid))
+		ports, err = port.GetPortsByPID(ctx, int32(pid), opts)
 	} else {
-		ports, err = port.GetOpenPorts(ctx)
+		ports, err = port.GetOpenPorts(ctx, opts)
 	}
 
 	if err != nil {
 		return err
 	}
 
-	fmt.Println("🌐 Open Ports")
+	if opts.Exposed {
+		fmt.Println("🌐 Exposed Ports")
+	} else {
+		fmt.Println("🌐 Open Ports")
+	}
 	fmt.Println()
 
+	if firewall {
+		status := port.CheckFirewall(ctx, ports)
+		switch {
+		case status.Error != "":
+			fmt.Printf("🧱 Firewall: unknown (%s)\n\n", status.Error)
+		case !status.Enabled:
+			fmt.Printf("🧱 Firewall: %s disabled\n\n", status.Backend)
+		default:
+			fmt.Printf("🧱 Firewall: %s enabled, incoming %s by default\n\n", status.Backend, status.DefaultIncoming)
+		}
+	}
+
 	t := table.NewWriter()
 	t.SetOutputMirror(os.Stdout)
-	t.AppendHeader(table.Row{"🔌 Port", "📡 Protocol", "🔢 PID", "📛 Process", "📍 Path"})
+	// Sockets other than listeners have a state and usually a peer
+	connected := opts.State != "" && !strings.EqualFold(opts.State, port.StateListen)
+	header := table.Row{"🔌 Port", "📡 Protocol", "🌍 Family", "🛡️ Exposure"}
+	if connected {
+		header = append(header, "🔗 State", "🎯 Remote")
+	} else {
+		header = append(header, "🔗 Connections")
+	}
+	if firewall {
+		header = append(header, "🧱 Firewall")
+	}
+	if opts.Resolve {
+		header = append(header, "🏠 Host")
+	}
+	t.AppendHeader(append(header, "🔢 PID", "📛 Process", "📍 Path"))
 	t.Style().Options.SeparateRows = true
 
 	for _, p := range ports {
-		t.AppendRow(table.Row{
-			fmt.Sprintf("%d", p.Port),
-			p.Protocol,
+		portLabel := fmt.Sprintf("%d", p.Port)
+		if p.Service != "" {
+			portLabel += " " + p.Service
+		}
+		family := p.Family
+		if p.DualStack {
+			family += " (dual-stack)"
+		}
+		row := table.Row{portLabel, p.Protocol, family, p.Exposure}
+		if connected {
+			var remote string
+			if p.RemotePort != 0 {
+				remote = net.JoinHostPort(p.RemoteIP, strconv.FormatUint(uint64(p.RemotePort), 10))
+			}
+			row = append(row, p.State, remote)
+		} else {
+			row = append(row, formatConnections(p))
+		}
+		if firewall {
+			row = append(row, p.Firewall)
+		}
+		if opts.Resolve {
+			row = append(row, p.Hostname)
+		}
+		name := p.Name
+		if p.ContainerName != "" {
+			name = fmt.Sprintf("%s container %s (%s)", p.ContainerImage, p.ContainerName, p.Name)
+		}
+		t.AppendRow(append(row,
 			fmt.Sprintf("%d", p.PID),
-			p.Name,
+			name,
 			truncateString(p.Path, 50),
-		})
+		))
 	}
 
-	t.AppendFooter(table.Row{"Total", "", "", "", len(ports)})
+	footer := table.Row{"Total", "", "", "", "", ""}
+	if connected {
+		footer = append(footer, "", "")
+	} else {
+		footer = append(footer, "")
+	}
+	if firewall {
+		footer = append(footer, "")
+	}
+	if opts.Resolve {
+		footer = append(footer, "")
+	}
+	t.AppendFooter(append(footer, len(ports)))
 	t.Render()
 
 	return nil
 }
 
-// DisplayResourceUsage displays resource usage for a process
-func DisplayResourceUsage(ctx context.Context, pid int32) error {
-	usage, err := resource.GetProcessResourceUsage(ctx, pid)
+// formatConnections shows a listener's established connections and its
+// three busiest peers, one per line
+func formatConnections(p types.PortInfo) string {
+	if p.Connections == 0 {
+		return ""
+	}
+	lines := []string{fmt.Sprintf("%d", p.Connections)}
+	for i, peer := range p.TopPeers {
+		if i == 3 {
+			break
+		}
+		lines = append(lines, fmt.Sprintf("%s ×%d", peer.IP, peer.Connections))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// WatchPorts prints ports as they open and close until ctx is cancelled,
+// one line per change or, with asJSON, one JSON event per line. Changes
+// are also posted to hooks.
+func WatchPorts(ctx context.Context, interval time.Duration, list port.ListOptions, only []uint32, hooks []types.Webhook, asJSON bool) error {
+	bus := events.NewBus()
+	sub := bus.Subscribe(256)
+	defer sub.Close()
+
+	if len(hooks) > 0 {
+		manager := webhook.NewManager(bus)
+		for _, hook := range hooks {
+			if _, err := manager.Add(hook); err != nil {
+				return err
+			}
+		}
+		go manager.Run(ctx)
+	}
+
+	watcher := watch.NewPortWatcher(bus, interval, list, only)
+	done := make(chan error, 1)
+	go func() { done <- watcher.Run(ctx) }()
+
+	if !asJSON {
+		fmt.Printf("👀 Watching for ports opening and closing every %s (Ctrl-C to stop)\n\n", interval)
+	}
+	encoder := json.NewEncoder(os.Stdout)
+	for {
+		select {
+		case err := <-done:
+			return err
+		case event := <-sub.C:
+			if asJSON {
+				encoder.Encode(event)
+				continue
+			}
+			p, ok := event.Data.(types.PortInfo)
+			if !ok {
+				continue
+			}
+			mark := "🟢 opened"
+			if event.Type == events.PortClosed {
+				mark = "🔴 closed"
+			}
+			at, _ := time.Parse(time.RFC3339Nano, event.Time)
+			fmt.Printf("%s %s  %s %s (%s)  %s [%d]\n",
+				at.Format("15:04:05"), mark, p.Protocol,
+				net.JoinHostPort(p.LocalIP, strconv.FormatUint(uint64(p.Port), 10)),
+				p.Exposure, p.Name, p.PID)
+		}
+	}
+}
+
+// Watchdog keeps targets running until ctx is cancelled, printing a line
+// as each is found down, restarted, fails to restart or is given up on,
+// or with asJSON one JSON event per line. Events are also posted to hooks.
+func Watchdog(ctx context.Context, targets []watch.WatchdogTarget, opts watch.WatchdogOptions, hooks []types.Webhook, asJSON bool) error {
+	bus := events.NewBus()
+	watchdog, err := watch.NewWatchdog(bus, targets, opts)
 	if err != nil {
 		return err
 	}
+	sub := bus.Subscribe(256)
+	defer sub.Close()
 
-	fmt.Printf("📊 Resource Usage for Process %d (%s)\n", usage.PID, usage.Name)
+	if len(hooks) > 0 {
+		manager := webhook.NewManager(bus)
+		for _, hook := range hooks {
+			if _, err := manager.Add(hook); err != nil {
+				return err
+			}
+		}
+		go manager.Run(ctx)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		watchdog.Run(ctx)
+		close(done)
+	}()
+
+	if !asJSON {
+		opts = watchdog.Options()
+		names := make([]string, len(targets))
+		for i, t := range targets {
+			names[i] = t.Name()
+		}
+		fmt.Printf("🐕 Watching %s every %s, at most %d restarts per %s (Ctrl-C to stop)\n\n",
+			strings.Join(names, ", "), opts.Interval, opts.MaxRestarts, opts.RestartWindow)
+	}
+	encoder := json.NewEncoder(os.Stdout)
+	for {
+		select {
+		case <-done:
+			return nil
+		case event := <-sub.C:
+			if asJSON {
+				encoder.Encode(event)
+				continue
+			}
+			e, ok := event.Data.(types.WatchdogEvent)
+			if !ok {
+				continue
+			}
+			at, _ := time.Parse(time.RFC3339Nano, event.Time)
+			target := e.Kind + " " + e.Name
+			switch event.Type {
+			case events.WatchdogDown:
+				fmt.Printf("%s 🔴 down       %s\n", at.Format("15:04:05"), target)
+			case events.WatchdogRestarted:
+				if e.PID > 0 {
+					target += fmt.Sprintf(" [%d]", e.PID)
+				}
+				fmt.Printf("%s 🔄 restarted  %s (restart %d of %d)\n",
+					at.Format("15:04:05"), target, e.Restarts, e.MaxRestarts)
+			case events.WatchdogRestartFailed:
+				next, _ := time.Parse(time.RFC3339, e.NextAttempt)
+				fmt.Printf("%s ❌ failed     %s: %s (retrying at %s)\n",
+					at.Format("15:04:05"), target, e.Error, next.Local().Format("15:04:05"))
+			case events.WatchdogGaveUp:
+				fmt.Printf("%s 🛑 gave up    %s after %d restarts\n", at.Format("15:04:05"), target, e.Restarts)
+			}
+		}
+	}
+}
+
+// DisplayConnections displays established connections in a formatted
+// table
+func DisplayConnections(ctx context.Context, portFilter string, pidFilter string, opts port.ConnectionOptions) error {
+	if portFilter != "" {
+		portNum, err := strconv.ParseUint(portFilter, 10, 32)
+		if err != nil {
+			return fmt.Errorf("invalid port number: %w", err)
+		}
+		opts.Port = uint32(portNum)
+	}
+	if pidFilter != "" {
+		pid, err := strconv.ParseInt(pidFilter, 10, 32)
+		if err != nil {
+			return fmt.Errorf("invalid PID: %w", err)
+		}
+		opts.PID = int32(pid)
+	}
+
+	conns, err := port.GetConnections(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("🔗 Established Connections")
 	fmt.Println()
 
 	t := table.NewWriter()
 	t.SetOutputMirror(os.Stdout)
-	t.AppendHeader(table.Row{"Metric", "Value"})
+	header := table.Row{"🏠 Local", "🌐 Remote"}
+	if opts.GeoIP != nil {
+		header = append(header, "🗺️ Country", "🏢 ASN")
+	}
+	t.AppendHeader(append(header, "📡 Protocol", "🔢 PID", "📛 Process"))
 	t.Style().Options.SeparateRows = true
 
-	t.AppendRow(table.Row{"🔢 PID", fmt.Sprintf("%d", usage.PID)})
-	t.AppendRow(table.Row{"📛 Name", usage.Name})
-	t.AppendRow(table.Row{"💻 CPU Usage", usage.CPUHuman})
-	t.AppendRow(table.Row{"🧠 Memory Usage", usage.MemoryHuman})
-	t.AppendRow(table.Row{"📈 Memory %", fmt.Sprintf("%.2f%%", usage.MemoryPercent)})
-	t.AppendRow(table.Row{"🧵 Threads", fmt.Sprintf("%d", usage.Threads)})
-	t.AppendRow(table.Row{"📂 Open Files", fmt.Sprintf("%d", usage.OpenFiles)})
+	for _, c := range conns {
+		row := table.Row{
+			net.JoinHostPort(c.LocalIP, strconv.FormatUint(uint64(c.LocalPort), 10)),
+			net.JoinHostPort(c.RemoteIP, strconv.FormatUint(uint64(c.RemotePort), 10)),
+		}
+		if opts.GeoIP != nil {
+			asn := ""
+			if c.ASN != 0 {
+				asn = fmt.Sprintf("AS%d %s", c.ASN, c.ASOrg)
+			}
+			row = append(row, c.Country, asn)
+		}
+		t.AppendRow(append(row,
+			c.Protocol,
+			fmt.Sprintf("%d", c.PID),
+			c.Name,
+		))
+	}
 
+	footer := table.Row{"Total", "", "", ""}
+	if opts.GeoIP != nil {
+		footer = append(footer, "", "")
+	}
+	t.AppendFooter(append(footer, len(conns)))
 	t.Render()
 
 	return nil
 }
 
-// DisplayServices displays services in a formatted table
-func DisplayServices(ctx context.Context) error {
-	services, err := service.GetServices(ctx)
+// DisplayUnixSockets displays Unix domain sockets in a formatted table
+func DisplayUnixSockets(ctx context.Context, pidFilter string, opts port.UnixSocketOptions) error {
+	if pidFilter != "" {
+		pid, err := strconv.ParseInt(pidFilter, 10, 32)
+		if err != nil {
+			return fmt.Errorf("invalid PID: %w", err)
+		}
+		opts.PID = int32(pid)
+	}
+
+	sockets, err := port.GetUnixSockets(ctx, opts)
 	if err != nil {
 		return err
 	}
 
-	fmt.Println("⚙️  System Services")
+	fmt.Println("🧦 Unix Domain Sockets")
 	fmt.Println()
 
 	t := table.NewWriter()
 	t.SetOutputMirror(os.Stdout)
-	t.AppendHeader(table.Row{"📛 Name", "🟢 Status", "🔢 PID", "💻 CPU", "🧠 Memory"})
+	t.AppendHeader(table.Row{"📍 Path", "📡 Type", "🔢 PID", "📛 Process", "🔗 Sockets"})
 	t.Style().Options.SeparateRows = true
 
-	for _, s := range services {
-		pidStr := "-"
-		if s.PID > 0 {
-			pidStr = fmt.Sprintf("%d", s.PID)
-		}
+	for _, s := range sockets {
+		t.AppendRow(table.Row{
+			truncateString(s.Path, 60),
+			s.Type,
+			fmt.Sprintf("%d", s.PID),
+			s.Name,
+			s.Sockets,
+		})
+	}
 
-		cpuStr := "-"
-		memStr := "-"
-		if s.PID > 0 && s.CPUPercent > 0 {
-			cpuStr = s.CPUHuman
-			memStr = s.MemoryHuman
-		}
+	t.AppendFooter(table.Row{"Total", "", "", "", len(sockets)})
+	t.Render()
 
-		statusEmoji := "🟢"
-		if s.Status != "running" && s.Status != "active" {
-			statusEmoji = "🔴"
+	return nil
+}
+
+// DisplayScan probes the local TCP ports in portRange, written as
+// from-to, and displays those answering or bound
+func DisplayScan(ctx context.Context, portRange string) error {
+	low, high, isRange := strings.Cut(portRange, "-")
+	if !isRange {
+		high = low
+	}
+	from, err1 := strconv.ParseUint(strings.TrimSpace(low), 10, 16)
+	to, err2 := strconv.ParseUint(strings.TrimSpace(high), 10, 16)
+	if err1 != nil || err2 != nil {
+		return fmt.Errorf("invalid port range %q: expected from-to, e.g. 3000-9000", portRange)
+	}
+
+	results, err := port.ScanPorts(ctx, port.ScanOptions{From: uint32(from), To: uint32(to)})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("🔭 Port Scan %d-%d\n", from, to)
+	fmt.Println()
+
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"🔌 Port", "🚦 Status", "⏱️ Latency", "📍 Bound To", "🔢 PID", "📛 Process"})
+	t.Style().Options.SeparateRows = true
+
+	answering := 0
+	for _, r := range results {
+		status := "🟡 bound"
+		latency := ""
+		if r.Status == port.ScanAnswering {
+			answering++
+			status = "🟢 answering"
+			latency = fmt.Sprintf("%.2fms", r.LatencyMs)
+		}
+		portLabel := fmt.Sprintf("%d", r.Port)
+		if r.Service != "" {
+			portLabel += " " + r.Service
+		}
+		name := r.Name
+		if r.ContainerName != "" {
+			name = fmt.Sprintf("%s %s (%s)", r.ContainerImage, r.ContainerName, r.Name)
 		}
+		pid := ""
+		if r.PID != 0 {
+			pid = fmt.Sprintf("%d", r.PID)
+		}
+		t.AppendRow(table.Row{
+			portLabel,
+			status,
+			latency,
+			strings.Join(r.BindAddresses, "\n"),
+			pid,
+			name,
+		})
+	}
 
+	t.AppendFooter(table.Row{"Total", fmt.Sprintf("%d answering", answering), "", "", "", len(results)})
+	t.Render()
+
+	return nil
+}
+
+// DisplayInterfaces displays network interfaces in a formatted table
+func DisplayInterfaces(ctx context.Context) error {
+	ifaces, err := network.GetInterfaces(ctx)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("📶 Network Interfaces")
+	fmt.Println()
+
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"🏷️ Name", "🚦 State", "📍 Addresses", "🔖 MAC", "📏 MTU", "⬆️ Sent", "⬇️ Received"})
+	t.Style().Options.SeparateRows = true
+
+	for _, iface := range ifaces {
+		state := "🟢 up"
+		if iface.State != network.StateUp {
+			state = "🔴 down"
+		}
 		t.AppendRow(table.Row{
-			s.Name,
-			fmt.Sprintf("%s %s", statusEmoji, s.Status),
-			pidStr,
-			cpuStr,
-			memStr,
+			iface.Name,
+			state,
+			strings.Join(iface.Addresses, "\n"),
+			iface.MAC,
+			iface.MTU,
+			fmt.Sprintf("%s (%d pkts)", utils.FormatBytes(iface.BytesSent), iface.PacketsSent),
+			fmt.Sprintf("%s (%d pkts)", utils.FormatBytes(iface.BytesReceived), iface.PacketsReceived),
 		})
 	}
 
-	t.AppendFooter(table.Row{"Total", "", "", "", len(services)})
+	t.AppendFooter(table.Row{"Total", "", "", "", "", "", len(ifaces)})
 	t.Render()
 
 	return nil
 }
 
-func truncateString(s string, maxLen int) string {
-	if len(s) <= maxLen {
-		return s
+// DisplayRoutes displays the routing table in a formatted table
+func DisplayRoutes(ctx context.Context, opts network.RouteOptions) error {
+	routes, err := network.GetRoutes(ctx, opts)
+	if err != nil {
+		return err
 	}
-	return s[:maxLen-3] + "..."
+
+	fmt.Println("🧭 Routes")
+	fmt.Println()
+
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"🎯 Destination", "🚪 Gateway", "📶 Interface", "🌍 Family", "📏 Metric", "🏷️ Notes"})
+	t.Style().Options.SeparateRows = true
+
+	for _, r := range routes {
+		var notes []string
+		if r.Default {
+			notes = append(notes, "default")
+		}
+		if r.VPN {
+			notes = append(notes, "vpn")
+		}
+		metric := ""
+		if r.Metric != 0 {
+			metric = strconv.Itoa(r.Metric)
+		}
+		t.AppendRow(table.Row{
+			r.Destination,
+			r.Gateway,
+			r.Interface,
+			r.Family,
+			metric,
+			strings.Join(notes, ", "),
+		})
+	}
+
+	t.AppendFooter(table.Row{"Total", "", "", "", "", len(routes)})
+	t.Render()
+
+	return nil
+}
+
+// DisplayNeighbors displays the ARP and NDP neighbor tables in a
+// formatted table
+func DisplayNeighbors(ctx context.Context, opts network.NeighborOptions) error {
+	neighbors, err := network.GetNeighbors(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("🏘️  Neighbors")
+	fmt.Println()
+
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"📍 IP", "🔖 MAC", "📶 Interface", "🌍 Family", "🚦 State"})
+	t.Style().Options.SeparateRows = true
+
+	for _, n := range neighbors {
+		t.AppendRow(table.Row{n.IP, n.MAC, n.Interface, n.Family, n.State})
+	}
+
+	t.AppendFooter(table.Row{"Total", "", "", "", len(neighbors)})
+	t.Render()
+
+	return nil
+}
+
+// DisplayDNS displays the DNS resolvers and, when cache is set, the
+// records in the DNS cache whose name contains name
+func DisplayDNS(ctx context.Context, cache bool, name string) error {
+	source, resolvers, err := network.GetDNSConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("🔤 DNS Resolvers (from %s)\n", source)
+	fmt.Println()
+
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"🖥️ Nameservers", "🔎 Search Domains", "🏷️ Domain", "📶 Interface", "⚙️ Options"})
+	t.Style().Options.SeparateRows = true
+
+	for _, r := range resolvers {
+		iface := r.Interface
+		if r.Scoped && iface != "" {
+			iface += " (scoped)"
+		}
+		t.AppendRow(table.Row{
+			strings.Join(r.Nameservers, "\n"),
+			strings.Join(r.SearchDomains, "\n"),
+			r.Domain,
+			iface,
+			strings.Join(r.Options, " "),
+		})
+	}
+
+	t.AppendFooter(table.Row{"Total", "", "", "", len(resolvers)})
+	t.Render()
+
+	if !cache {
+		return nil
+	}
+	entries, err := network.GetDNSCache(ctx, name)
+	if err != nil {
+		fmt.Printf("\n🗂️  DNS Cache: unavailable (%v)\n", err)
+		return nil
+	}
+
+	fmt.Println()
+	fmt.Println("🗂️  DNS Cache")
+	fmt.Println()
+
+	c := table.NewWriter()
+	c.SetOutputMirror(os.Stdout)
+	c.AppendHeader(table.Row{"📛 Name", "🔖 Type", "📄 Data", "⏳ TTL"})
+	c.Style().Options.SeparateRows = true
+
+	for _, e := range entries {
+		ttl := ""
+		if e.TTL != 0 {
+			ttl = fmt.Sprintf("%ds", e.TTL)
+		}
+		c.AppendRow(table.Row{e.Name, e.Type, e.Data, ttl})
+	}
+
+	c.AppendFooter(table.Row{"Total", "", "", len(entries)})
+	c.Render()
+
+	return nil
+}
+
+// DisplayResourceUsage displays resource usage for a process
+func DisplayResourceUsage(ctx context.Context, pid int32) error {
+	usage, err := resource.GetProcessResourceUsage(ctx, pid)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("📊 Resource Usage for Process %d (%s)\n", usage.PID, usage.Name)
+	fmt.Println()
+
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"Metric", "Value"})
+	t.Style().Options.SeparateRows = true
+
+	t.AppendRow(table.Row{"🔢 PID", fmt.Sprintf("%d", usage.PID)})
+	t.AppendRow(table.Row{"📛 Name", usage.Name})
+	t.AppendRow(table.Row{"💻 CPU Usage", usage.CPUHuman})
+	t.AppendRow(table.Row{"🧠 Memory Usage", usage.MemoryHuman})
+	t.AppendRow(table.Row{"📈 Memory %", fmt.Sprintf("%.2f%%", usage.MemoryPercent)})
+	t.AppendRow(table.Row{"🧵 Threads", fmt.Sprintf("%d", usage.Threads)})
+	t.AppendRow(table.Row{"📂 Open Files", fmt.Sprintf("%d", usage.OpenFiles)})
+
+	t.Render()
+
+	return nil
+}
+
+// DisplaySystemMetrics displays CPU, load, memory, swap and uptime
+// followed by a table of disk usage per mount
+func DisplaySystemMetrics(ctx context.Context) error {
+	m, err := system.GetMetrics(ctx)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("🖥️  System Metrics for %s\n", m.Hostname)
+	fmt.Println()
+
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"Metric", "Value"})
+	t.Style().Options.SeparateRows = true
+
+	t.AppendRow(table.Row{"💻 CPU Usage", fmt.Sprintf("%s of %d CPUs", utils.FormatCPU(m.CPUPercent), m.CPUs)})
+	if m.Load != nil {
+		t.AppendRow(table.Row{"📈 Load Average", fmt.Sprintf("%.2f  %.2f  %.2f", m.Load.Load1, m.Load.Load5, m.Load.Load15)})
+	}
+	t.AppendRow(table.Row{"🧠 Memory", fmt.Sprintf("%s used of %s (%.1f%%), %s free, %s available, %s cached",
+		utils.FormatBytes(m.Memory.Used), utils.FormatBytes(m.Memory.Total), m.Memory.UsedPercent,
+		utils.FormatBytes(m.Memory.Free), utils.FormatBytes(m.Memory.Available), utils.FormatBytes(m.Memory.Cached))})
+	swap := "none"
+	if m.Swap.Total > 0 {
+		swap = fmt.Sprintf("%s used of %s (%.1f%%)", utils.FormatBytes(m.Swap.Used), utils.FormatBytes(m.Swap.Total), m.Swap.UsedPercent)
+	}
+	t.AppendRow(table.Row{"💾 Swap", swap})
+	t.AppendRow(table.Row{"⏱️  Uptime", m.UptimeHuman})
+	t.Render()
+	fmt.Println()
+
+	d := table.NewWriter()
+	d.SetOutputMirror(os.Stdout)
+	d.AppendHeader(table.Row{"📁 Mount", "💽 Device", "🗂️  Type", "📦 Size", "📊 Used", "🆓 Free", "📈 Use %"})
+	d.Style().Options.SeparateRows = true
+	for _, disk := range m.Disks {
+		d.AppendRow(table.Row{
+			disk.Mount,
+			truncateString(disk.Device, 30),
+			disk.FSType,
+			utils.FormatBytes(disk.Total),
+			utils.FormatBytes(disk.Used),
+			utils.FormatBytes(disk.Free),
+			fmt.Sprintf("%.1f%%", disk.UsedPercent),
+		})
+	}
+	d.AppendFooter(table.Row{"Total", "", "", "", "", "", len(m.Disks)})
+	d.Render()
+
+	return nil
+}
+
+// DisplayProcess displays the details of a process with its parent and
+// children
+func DisplayProcess(ctx context.Context, pid int32) error {
+	p, err := process.GetProcess(ctx, pid)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("🔎 Process %d (%s)\n", p.PID, p.Name)
+	fmt.Println()
+
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"Field", "Value"})
+	t.Style().Options.SeparateRows = true
+
+	parent := fmt.Sprintf("%d", p.PPID)
+	if p.ParentName != "" {
+		parent += " (" + p.ParentName + ")"
+	}
+	children := make([]string, 0, len(p.Children))
+	for _, c := range p.Children {
+		children = append(children, fmt.Sprintf("%d (%s)", c.PID, c.Name))
+	}
+
+	t.AppendRow(table.Row{"🔢 PID", fmt.Sprintf("%d", p.PID)})
+	t.AppendRow(table.Row{"📛 Name", p.Name})
+	t.AppendRow(table.Row{"👤 User", p.User})
+	t.AppendRow(table.Row{"📍 Path", p.Path})
+	if p.BundleID != "" {
+		t.AppendRow(table.Row{"📦 App", fmt.Sprintf("%s %s (%s)", p.AppName, p.AppVersion, p.BundleID)})
+	}
+	if p.Arch != "" {
+		arch := p.Arch
+		if p.Translated {
+			arch += " (Rosetta 2)"
+		}
+		t.AppendRow(table.Row{"🏗️ Arch", arch})
+	}
+	t.AppendRow(table.Row{"💻 Command", truncateString(strings.Join(p.Cmdline, " "), 80)})
+	t.AppendRow(table.Row{"📁 Working Dir", p.Cwd})
+	t.AppendRow(table.Row{"🚦 Status", p.Status})
+	t.AppendRow(table.Row{"🕐 Started", p.StartTime})
+	t.AppendRow(table.Row{"⏱️ Uptime", p.Uptime})
+	t.AppendRow(table.Row{"📈 CPU", utils.FormatCPU(p.CPUPercent)})
+	t.AppendRow(table.Row{"🧠 Memory", utils.FormatBytes(p.MemoryRSS)})
+	t.AppendRow(table.Row{"👪 Parent", parent})
+	t.AppendRow(table.Row{"👶 Children", strings.Join(children, "\n")})
+	if sec := p.Security; sec != nil {
+		signature := "unsigned"
+		if sec.Signed {
+			signature = "signed"
+			if sec.TeamID != "" {
+				signature += " (" + sec.TeamID + ")"
+			}
+		}
+		entitlements := make([]string, 0, len(sec.Entitlements))
+		for key := range sec.Entitlements {
+			entitlements = append(entitlements, key)
+		}
+		sort.Strings(entitlements)
+
+		t.AppendRow(table.Row{"🔏 Signature", signature})
+		t.AppendRow(table.Row{"📦 Sandboxed", yesNo(sec.Sandboxed)})
+		t.AppendRow(table.Row{"🛡️ Hardened Runtime", yesNo(sec.HardenedRuntime)})
+		t.AppendRow(table.Row{"🔑 Entitlements", strings.Join(entitlements, "\n")})
+	}
+
+	t.Render()
+
+	return nil
+}
+
+// DisplayEnvironment displays the environment variables of a process,
+// with secrets redacted
+func DisplayEnvironment(ctx context.Context, pid int32) error {
+	env, err := process.GetEnvironment(ctx, pid)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("🌱 Environment of Process %d (%s)\n", env.PID, env.Name)
+	fmt.Println()
+
+	keys := make([]string, 0, len(env.Env))
+	for key := range env.Env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"🔑 Key", "📝 Value"})
+	for _, key := range keys {
+		t.AppendRow(table.Row{key, truncateString(env.Env[key], 80)})
+	}
+	t.AppendFooter(table.Row{"Total", env.Count})
+	t.Render()
+
+	return nil
+}
+
+// DisplayOpenFiles displays the file descriptors a process holds open
+func DisplayOpenFiles(ctx context.Context, pid int32) error {
+	files, err := process.GetOpenFiles(ctx, pid)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("📂 Open Files of Process %d (%s)\n", files.PID, files.Name)
+	fmt.Println()
+
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"🔢 FD", "📄 Type", "🔐 Mode", "📍 Path"})
+	for _, f := range files.Files {
+		t.AppendRow(table.Row{f.FD, f.Type, f.Mode, truncateString(f.Path, 70)})
+	}
+	t.AppendFooter(table.Row{"Total", files.Count, "", ""})
+	t.Render()
+
+	return nil
+}
+
+// DisplayMemoryMap displays the memory of a process by region type and,
+// with regions set, every region
+func DisplayMemoryMap(ctx context.Context, pid int32, regions bool) error {
+	mmap, err := process.GetMemoryMap(ctx, pid, "")
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("🧠 Memory Map of Process %d (%s)\n", mmap.PID, mmap.Name)
+	fmt.Println()
+
+	var size, resident uint64
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"📄 Type", "🔢 Regions", "📐 Virtual", "💾 Resident"})
+	for _, sum := range mmap.Summary {
+		t.AppendRow(table.Row{sum.Type, sum.Regions, utils.FormatBytes(sum.Size), utils.FormatBytes(sum.Resident)})
+		size += sum.Size
+		resident += sum.Resident
+	}
+	t.AppendFooter(table.Row{"Total", mmap.Count, utils.FormatBytes(size), utils.FormatBytes(resident)})
+	t.Render()
+
+	if !regions {
+		return nil
+	}
+	fmt.Println()
+	t = table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"📍 Start", "📐 Virtual", "💾 Resident", "🔐 Perm", "📄 Type", "📝 Detail"})
+	for _, r := range mmap.Regions {
+		detail := r.Path
+		if detail == "" {
+			detail = r.Detail
+		}
+		t.AppendRow(table.Row{r.Start, utils.FormatBytes(r.Size), utils.FormatBytes(r.Resident), r.Permissions, r.Type, truncateString(detail, 60)})
+	}
+	t.Render()
+
+	return nil
+}
+
+// DisplayThreads displays the threads of a process, busiest first
+func DisplayThreads(ctx context.Context, pid int32) error {
+	threads, err := process.GetThreads(ctx, pid)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("🧵 Threads of Process %d (%s)\n", threads.PID, threads.Name)
+	fmt.Println()
+
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"🔢 ID", "📛 Name", "📊 State", "⚡ CPU", "👤 User Time", "⚙️  System Time"})
+	for _, th := range threads.Threads {
+		t.AppendRow(table.Row{
+			th.ID,
+			th.Name,
+			th.State,
+			utils.FormatCPU(th.CPUPercent),
+			fmt.Sprintf("%.2fs", th.UserTime),
+			fmt.Sprintf("%.2fs", th.SystemTime),
+		})
+	}
+	t.AppendFooter(table.Row{"Total", threads.Count, "", "", "", ""})
+	t.Render()
+
+	return nil
+}
+
+// DisplayResourceLimits displays the resource limits of a process
+func DisplayResourceLimits(ctx context.Context, pid int32) error {
+	limits, err := process.GetResourceLimits(ctx, pid)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("🚧 Resource Limits of Process %d (%s)\n", limits.PID, limits.Name)
+	if limits.Source == types.LimitSourceLaunchd {
+		fmt.Println("   launchd defaults; a shell's ulimit may have changed them")
+	}
+	fmt.Println()
+
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"📦 Resource", "📉 Soft", "📈 Hard", "📊 Used"})
+	for _, l := range limits.Limits {
+		used := ""
+		if l.Used != nil {
+			used = formatLimit(l.Used, l.Unit)
+		}
+		t.AppendRow(table.Row{l.Resource, formatLimit(l.Soft, l.Unit), formatLimit(l.Hard, l.Unit), used})
+	}
+	t.Render()
+
+	return nil
+}
+
+// formatLimit formats a resource limit value in its unit
+func formatLimit(v *uint64, unit string) string {
+	switch {
+	case v == nil:
+		return "unlimited"
+	case unit == types.LimitUnitBytes:
+		return utils.FormatBytes(*v)
+	case unit == types.LimitUnitSeconds:
+		return fmt.Sprintf("%ds", *v)
+	case unit == types.LimitUnitMicroseconds:
+		return fmt.Sprintf("%dµs", *v)
+	default:
+		return strconv.FormatUint(*v, 10)
+	}
+}
+
+// SendSignal sends sig to a process and reports the outcome
+func SendSignal(ctx context.Context, pid int32, sig syscall.Signal) error {
+	result, err := process.Signal(ctx, pid, sig)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ Sent %s to %s (PID %d)\n", result.Signal, result.Name, result.PID)
+	return nil
+}
+
+// LaunchApp starts an application and reports its PID
+func LaunchApp(ctx context.Context, req process.LaunchRequest) error {
+	result, err := process.Launch(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case result.AlreadyRunning:
+		fmt.Printf("✅ %s is already running (PID %d)\n", result.Name, result.PID)
+	case result.PID == 0:
+		fmt.Printf("✅ Launched %s\n", result.Name)
+	default:
+		fmt.Printf("✅ Launched %s (PID %d)\n", result.Name, result.PID)
+	}
+	return nil
+}
+
+// DisplayFocusedWindow shows the frontmost app and its focused window
+func DisplayFocusedWindow(ctx context.Context) error {
+	focused, err := window.GetFrontmost(ctx)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("🎯 App:      %s (PID %d)\n", focused.App.Name, focused.App.PID)
+	w := focused.Window
+	if w == nil {
+		fmt.Println("🪟 Window:   none")
+		return nil
+	}
+	fmt.Printf("🪟 Window:   %s\n", w.Title)
+	if w.ID != 0 {
+		fmt.Printf("🔢 ID:       %d\n", w.ID)
+	}
+	if g := w.Geometry; g != nil {
+		fmt.Printf("📐 Geometry: %dx%d at %d,%d\n", g.Width, g.Height, g.X, g.Y)
+	}
+	if w.Display > 0 {
+		fmt.Printf("🖥️ Display:  %d\n", w.Display)
+	}
+	return nil
+}
+
+// DisplayWindowText prints the visible text of the window matching q,
+// one element per line
+func DisplayWindowText(ctx context.Context, q window.Query) error {
+	w, elements, err := window.Text(ctx, q)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("🪟 %s (%s, PID %d)\n\n", w.Title, w.Process, w.PID)
+	if len(elements) == 0 {
+		fmt.Println("No text found")
+		return nil
+	}
+	for _, e := range elements {
+		fmt.Println(e.Text)
+	}
+	return nil
+}
+
+// FocusWindow brings the window matching q to the foreground
+func FocusWindow(ctx context.Context, q window.Query) error {
+	w, err := window.Focus(ctx, q)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("✅ Focused %q (%s, PID %d)\n", w.Title, w.Process, w.PID)
+	return nil
+}
+
+// CloseWindow closes the window matching q, or only reports it when
+// dryRun is set
+func CloseWindow(ctx context.Context, q window.Query, dryRun bool) error {
+	w, err := window.Close(ctx, q, dryRun)
+	if err != nil {
+		return err
+	}
+	if dryRun {
+		fmt.Printf("🔍 Would close %q (%s, PID %d)\n", w.Title, w.Process, w.PID)
+		return nil
+	}
+	fmt.Printf("✅ Closed %q (%s, PID %d)\n", w.Title, w.Process, w.PID)
+	return nil
+}
+
+// helperCommands are the external commands window support relies on, by
+// platform, with what each is needed for
+var helperCommands = map[string][]struct{ name, purpose string }{
+	"linux": {
+		{"wmctrl", "listing, focusing and closing windows"},
+		{"xprop", "window stacking order and minimized state"},
+		{"xrandr", "displays"},
+		{"xdotool", "the focused window and minimizing windows"},
+		{"import", "window screenshots (ImageMagick)"},
+	},
+	"windows": {
+		{"powershell", "windows and displays"},
+	},
+}
+
+// Doctor checks the permissions and helper commands gops needs, showing
+// how to fix each problem. It fails when any problem is found.
+func Doctor(ctx context.Context) error {
+	problems := 0
+	fmt.Println("🩺 Permissions")
+	for _, p := range permission.Check(ctx) {
+		label := permission.Label(p.Name)
+		switch p.Status {
+		case permission.StatusGranted:
+			fmt.Printf("  ✅ %-17s granted\n", label)
+		case permission.StatusNotRequired:
+			fmt.Printf("  ➖ %-17s not required\n", label)
+		case permission.StatusDenied:
+			problems++
+			fmt.Printf("  ❌ %-17s denied: %s\n", label, p.Detail)
+			fmt.Printf("     Grant it to gops (or your terminal) in %s\n", p.Settings)
+			fmt.Printf("     open %q\n", permission.SettingsURL(p.Name))
+		default:
+			fmt.Printf("  ❔ %-17s unknown: %s\n", label, p.Detail)
+		}
+	}
+
+	if commands := helperCommands[runtime.GOOS]; len(commands) > 0 {
+		fmt.Println()
+		fmt.Println("🧰 Helper commands")
+		for _, c := range commands {
+			if path, err := exec.LookPath(c.name); err == nil {
+				fmt.Printf("  ✅ %-17s %s\n", c.name, path)
+			} else {
+				problems++
+				fmt.Printf("  ❌ %-17s not found; needed for %s\n", c.name, c.purpose)
+			}
+		}
+	}
+
+	fmt.Println()
+	if problems > 0 {
+		return fmt.Errorf("%d problem(s) found", problems)
+	}
+	fmt.Println("✅ No problems found")
+	return nil
+}
+
+// MoveWindow moves and resizes the window matching q
+func MoveWindow(ctx context.Context, q window.Query, f window.Frame) error {
+	w, err := window.Move(ctx, q, f)
+	if err != nil {
+		return err
+	}
+	g := w.Geometry
+	fmt.Printf("✅ Moved %q (%s, PID %d) to %dx%d at %d,%d\n", w.Title, w.Process, w.PID, g.Width, g.Height, g.X, g.Y)
+	return nil
+}
+
+// ArrangeWindows lays out the windows with the given IDs on a display
+func ArrangeWindows(ctx context.Context, layout string, ids []uint32, display int) error {
+	windows, n, err := window.Arrange(ctx, layout, ids, display)
+	for _, w := range windows {
+		g := w.Geometry
+		fmt.Printf("✅ Moved %q (%s, PID %d) to %dx%d at %d,%d\n", w.Title, w.Process, w.PID, g.Width, g.Height, g.X, g.Y)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Printf("🪟 Arranged %d window(s) %s on display %d\n", len(windows), strings.ReplaceAll(layout, "_", " "), n)
+	return nil
+}
+
+// MinimizeWindow minimizes the window matching q
+func MinimizeWindow(ctx context.Context, q window.Query) error {
+	w, err := window.Minimize(ctx, q)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("✅ Minimized %q (%s, PID %d)\n", w.Title, w.Process, w.PID)
+	return nil
+}
+
+// RestoreWindow restores the window matching q
+func RestoreWindow(ctx context.Context, q window.Query) error {
+	w, err := window.Restore(ctx, q)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("✅ Restored %q (%s, PID %d)\n", w.Title, w.Process, w.PID)
+	return nil
+}
+
+// HideApp hides the windows of a process, minimizing them where apps
+// can't be hidden
+func HideApp(ctx context.Context, pid int32) error {
+	windows, err := window.HideApp(ctx, pid)
+	if err != nil {
+		return err
+	}
+	verb := "Minimized"
+	if runtime.GOOS == "darwin" {
+		verb = "Hid"
+	}
+	fmt.Printf("✅ %s %d window(s) of %s (PID %d)\n", verb, len(windows), windows[0].Process, pid)
+	return nil
+}
+
+// SetPriority changes a process priority and reports the old and new
+// values. priority is a nice value, or a priority class on Windows.
+func SetPriority(ctx context.Context, pid int32, priority string) error {
+	var result types.PriorityResponse
+	var err error
+	if runtime.GOOS == "windows" {
+		result, err = process.SetPriorityClass(ctx, pid, priority)
+	} else {
+		nice, convErr := strconv.Atoi(priority)
+		if convErr != nil {
+			return fmt.Errorf("invalid nice value: %s", priority)
+		}
+		result, err = process.SetNice(ctx, pid, nice)
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ Changed priority of %s (PID %d) from %s to %s\n", result.Name, result.PID, result.OldPriority, result.NewPriority)
+	return nil
+}
+
+// DisplayServices displays services in a formatted table
+func DisplayServices(ctx context.Context, opts service.ListOptions) error {
+	services, err := service.GetServices(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("⚙️  System Services")
+	fmt.Println()
+
+	// Windows services have no domain, but a start type
+	withDomain := len(services) > 0 && services[0].Domain != ""
+	withEnablement := withDomain || len(services) > 0 && services[0].Enablement != ""
+
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	header := table.Row{"📛 Name", "🟢 Status", "🔢 PID", "💻 CPU", "🧠 Memory"}
+	if withDomain {
+		header = append(header, "🏠 Domain")
+	}
+	if withEnablement {
+		header = append(header, "🔌 Enabled")
+	}
+	t.AppendHeader(header)
+	t.Style().Options.SeparateRows = true
+
+	for _, s := range services {
+		pidStr := "-"
+		if s.PID > 0 {
+			pidStr = fmt.Sprintf("%d", s.PID)
+		}
+
+		cpuStr := "-"
+		memStr := "-"
+		if s.PID > 0 && s.CPUPercent > 0 {
+			cpuStr = s.CPUHuman
+			memStr = s.MemoryHuman
+		}
+
+		statusEmoji := "🟢"
+		if s.Status != "running" && s.Status != "active" {
+			statusEmoji = "🔴"
+		}
+
+		row := table.Row{
+			s.Name,
+			fmt.Sprintf("%s %s", statusEmoji, s.Status),
+			pidStr,
+			cpuStr,
+			memStr,
+		}
+		if withDomain {
+			row = append(row, fmt.Sprintf("%s (%s)", s.Domain, s.Scope))
+		}
+		if withEnablement {
+			enablement := s.Enablement
+			if enablement == "" {
+				enablement = "-"
+			}
+			row = append(row, enablement)
+		}
+		t.AppendRow(row)
+	}
+
+	footer := table.Row{"Total", "", "", "", len(services)}
+	if withDomain {
+		footer = append(footer, "")
+	}
+	if withEnablement {
+		footer = append(footer, "")
+	}
+	t.AppendFooter(footer)
+	t.Render()
+
+	return nil
+}
+
+// DisplayService shows how a service is configured and how it last ran
+func DisplayService(ctx context.Context, name string, opts service.ControlOptions) error {
+	d, err := service.GetService(ctx, name, opts)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("⚙️  Service %s (%s)\n", d.Name, d.Manager)
+	fmt.Println()
+
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"Field", "Value"})
+	t.Style().Options.SeparateRows = true
+
+	state := d.State
+	if d.Throttled {
+		state += " (throttled)"
+	}
+	t.AppendRow(table.Row{"🟢 State", state})
+	if d.PID > 0 {
+		t.AppendRow(table.Row{"🔢 PID", fmt.Sprintf("%d", d.PID)})
+	}
+	if d.Domain != "" {
+		t.AppendRow(table.Row{"🏠 Domain", d.Domain})
+	}
+	if d.Enablement != "" {
+		t.AppendRow(table.Row{"🔌 Enabled", d.Enablement})
+	}
+	t.AppendRow(table.Row{"📄 File", d.File})
+	t.AppendRow(table.Row{"💻 Program", truncateString(strings.Join(d.Program, " "), 80)})
+	if d.Manager == service.ManagerLaunchd {
+		keepAlive := "false"
+		if d.KeepAlive != nil {
+			keepAlive = fmt.Sprint(d.KeepAlive)
+		}
+		t.AppendRow(table.Row{"🚀 Run At Load", fmt.Sprint(d.RunAtLoad)})
+		t.AppendRow(table.Row{"♻️ Keep Alive", keepAlive})
+		t.AppendRow(table.Row{"🔁 Runs", fmt.Sprintf("%d", d.Runs)})
+	}
+	if d.Restart != "" {
+		t.AppendRow(table.Row{"♻️ Restart", d.Restart})
+		t.AppendRow(table.Row{"🔁 Restarts", fmt.Sprintf("%d", d.Restarts)})
+	}
+	if d.ThrottleInterval > 0 {
+		t.AppendRow(table.Row{"⏳ Throttle Interval", fmt.Sprintf("%gs", d.ThrottleInterval)})
+	}
+	lastExit := "-"
+	switch {
+	case d.LastSignal != "":
+		lastExit = d.LastSignal
+	case d.LastExitStatus != nil:
+		lastExit = fmt.Sprintf("%d", *d.LastExitStatus)
+	}
+	t.AppendRow(table.Row{"🏁 Last Exit", lastExit})
+	t.Render()
+
+	return nil
+}
+
+// DisplayServiceLogs prints the most recent log lines of a service
+func DisplayServiceLogs(ctx context.Context, name string, opts service.LogOptions) error {
+	entries, err := service.GetLogs(ctx, name, opts)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		fmt.Printf("📜 No log lines for %s\n", name)
+		return nil
+	}
+
+	source := ""
+	for _, e := range entries {
+		if e.Source != source {
+			source = e.Source
+			fmt.Printf("\n📄 %s\n", source)
+		}
+		if e.Source != "" {
+			fmt.Println(e.Message)
+			continue
+		}
+		process := e.Process
+		if e.PID > 0 {
+			process += fmt.Sprintf("[%d]", e.PID)
+		}
+		fmt.Printf("%s %s: %s\n", e.Time, process, e.Message)
+	}
+	return nil
+}
+
+// DisplayFailedServices reports the services whose last run failed,
+// followed by their last log lines
+func DisplayFailedServices(ctx context.Context, opts service.FailedOptions) error {
+	failed, err := service.GetFailedServices(ctx, opts)
+	if err != nil {
+		return err
+	}
+	if len(failed) == 0 {
+		fmt.Println("✅ No failed services")
+		return nil
+	}
+
+	fmt.Println("💥 Failed Services")
+	fmt.Println()
+
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"📛 Name", "🟢 State", "🔢 PID", "💥 Exit", "🔁 Restarts", "🏠 Domain"})
+	t.Style().Options.SeparateRows = true
+
+	for _, f := range failed {
+		pid := "-"
+		if f.PID > 0 {
+			pid = fmt.Sprintf("%d", f.PID)
+		}
+		exit := "-"
+		switch {
+		case f.LastSignal != "":
+			exit = f.LastSignal
+		case f.LastExitStatus != nil:
+			exit = fmt.Sprintf("%d", *f.LastExitStatus)
+		}
+		// launchd counts every start, systemd only restarts
+		restarts := fmt.Sprintf("%d", f.Restarts)
+		if f.Runs > 0 {
+			restarts = fmt.Sprintf("%d runs", f.Runs)
+		}
+		if f.Throttled {
+			restarts += " (throttled)"
+		}
+		domain := "-"
+		if f.Domain != "" {
+			domain = f.Domain
+		}
+		t.AppendRow(table.Row{f.Name, f.State, pid, exit, restarts, domain})
+	}
+
+	t.AppendFooter(table.Row{"Total", "", "", "", "", len(failed)})
+	t.Render()
+
+	for _, f := range failed {
+		if len(f.Logs) == 0 && f.Error == "" {
+			continue
+		}
+		fmt.Printf("\n📜 %s\n", f.Name)
+		if f.Error != "" {
+			fmt.Printf("   ⚠️  %s\n", f.Error)
+		}
+		for _, e := range f.Logs {
+			if e.Source != "" {
+				fmt.Printf("   %s: %s\n", e.Source, e.Message)
+				continue
+			}
+			fmt.Printf("   %s %s\n", e.Time, e.Message)
+		}
+	}
+	return nil
+}
+
+// DisplayScheduledJobs lists cron entries, scheduled launchd jobs and
+// systemd timers, the next to run first
+func DisplayScheduledJobs(ctx context.Context) error {
+	jobs, err := service.GetScheduledJobs(ctx)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("⏰ Scheduled Jobs")
+	fmt.Println()
+
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"📦 Source", "📛 Name", "🗓️  Schedule", "⏭️  Next Run", "👤 User", "💻 Command"})
+	t.Style().Options.SeparateRows = true
+
+	for _, j := range jobs {
+		next := "-"
+		if at, err := time.Parse(time.RFC3339, j.NextRun); err == nil {
+			next = at.Local().Format("2006-01-02 15:04")
+		}
+		user := j.User
+		if user == "" {
+			user = "-"
+		}
+		t.AppendRow(table.Row{
+			j.Source,
+			j.Name,
+			truncateString(j.Schedule, 40),
+			next,
+			user,
+			truncateString(j.Command, 60),
+		})
+	}
+
+	t.AppendFooter(table.Row{"Total", "", "", "", "", len(jobs)})
+	t.Render()
+
+	return nil
+}
+
+// ControlService acts on a service and reports whether it runs
+// afterwards, or for enable and disable its new enablement
+func ControlService(ctx context.Context, name, action string, opts service.ControlOptions) error {
+	result, err := service.Control(ctx, name, action, opts)
+	if err != nil {
+		return err
+	}
+
+	done := map[string]string{
+		service.ActionStart:   "Started",
+		service.ActionStop:    "Stopped",
+		service.ActionRestart: "Restarted",
+		service.ActionReload:  "Reloaded",
+		service.ActionEnable:  "Enabled",
+		service.ActionDisable: "Disabled",
+	}[action]
+	switch {
+	case action == service.ActionEnable || action == service.ActionDisable:
+		fmt.Printf("✅ %s %s (now %s)\n", done, result.Name, result.Enablement)
+		if action == service.ActionDisable && result.Running {
+			fmt.Printf("   It keeps running until stopped (PID %d)\n", result.PID)
+		}
+	case result.PID > 0:
+		fmt.Printf("✅ %s %s (PID %d)\n", done, result.Name, result.PID)
+	case result.Running:
+		fmt.Printf("✅ %s %s\n", done, result.Name)
+	default:
+		fmt.Printf("✅ %s %s, which is not running\n", done, result.Name)
+	}
+	return nil
+}
+
+func truncateString(s string, maxLen int) string {
+	if len(s) <= maxLen {
+		return s
+	}
+	return s[:maxLen-3] + "..."
+}
+
+// SaveIcon writes the application icon of a process to path as a PNG
+func SaveIcon(ctx context.Context, pid int32, size int, path string) error {
+	icon, err := process.GetIcon(ctx, pid, size)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, icon.Data, 0o644); err != nil {
+		return err
+	}
+	fmt.Printf("✅ Saved %dx%d icon of %s to %s\n", icon.Width, icon.Height, icon.Name, path)
+	fmt.Printf("   Source: %s\n", icon.Source)
+	return nil
+}
+
+// yesNo formats a flag for display
+func yesNo(b bool) string {
+	if b {
+		return "✅ yes"
+	}
+	return "❌ no"
 }
@@ -0,0 +1,244 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/borankux/gops/internal/watch"
+	"github.com/borankux/gops/pkg/types"
+	"gopkg.in/yaml.v3"
+)
+
+// File is the on-disk server configuration. Every setting is optional and
+// command-line flags take precedence over values from the file.
+type File struct {
+	Server struct {
+		Bind            string         `yaml:"bind"`
+		Port            int            `yaml:"port"`
+		AccessLog       string         `yaml:"access_log"`
+		ShutdownTimeout *time.Duration `yaml:"shutdown_timeout"`
+	} `yaml:"server"`
+
+	Auth struct {
+		Token string `yaml:"token"`
+	} `yaml:"auth"`
+
+	CORS struct {
+		// Origins may be ["*"], ["none"] or a list of allowed origins
+		Origins []string `yaml:"origins"`
+	} `yaml:"cors"`
+
+	RateLimit struct {
+		Rate  *float64 `yaml:"rate"`
+		Burst int      `yaml:"burst"`
+	} `yaml:"rate_limit"`
+
+	Cache struct {
+		TTL *time.Duration `yaml:"ttl"`
+	} `yaml:"cache"`
+
+	Collectors struct {
+		Timeout *time.Duration `yaml:"timeout"`
+	} `yaml:"collectors"`
+
+	Tools struct {
+		// Enabled and Disabled hold tool or group names
+		Enabled  []string `yaml:"enabled"`
+		Disabled []string `yaml:"disabled"`
+	} `yaml:"tools"`
+
+	Processes struct {
+		// Include and Exclude adjust which processes count as user
+		// applications; Exclude wins when both match
+		Include ProcessRules `yaml:"include"`
+		Exclude ProcessRules `yaml:"exclude"`
+	} `yaml:"processes"`
+
+	Webhooks []Webhook `yaml:"webhooks"`
+
+	Watch struct {
+		CPUAlert float64 `yaml:"cpu_alert"`
+		// Processes and Ports limit process and port events to these
+		// process names and port numbers
+		Processes []string `yaml:"processes"`
+		Ports     []uint32 `yaml:"ports"`
+	} `yaml:"watch"`
+
+	Watchdog struct {
+		Interval    *time.Duration `yaml:"interval"`
+		Backoff     *time.Duration `yaml:"backoff"`
+		MaxBackoff  *time.Duration `yaml:"max_backoff"`
+		MaxRestarts int            `yaml:"max_restarts"`
+		Window      *time.Duration `yaml:"window"`
+		// Targets are the services and processes kept running
+		Targets []WatchdogTarget `yaml:"targets"`
+	} `yaml:"watchdog"`
+
+	GeoIP struct {
+		// Databases are MaxMind Country, City or ASN database files
+		Databases []string `yaml:"databases"`
+	} `yaml:"geoip"`
+
+	History struct {
+		// Ports records listening ports over time
+		Ports     bool           `yaml:"ports"`
+		File      string         `yaml:"file"`
+		Retention *time.Duration `yaml:"retention"`
+	} `yaml:"history"`
+}
+
+// Webhook is a webhook registered from the configuration file
+type Webhook struct {
+	URL    string   `yaml:"url"`
+	Events []string `yaml:"events"`
+	Secret string   `yaml:"secret"`
+}
+
+// WatchdogTarget is a service or process the watchdog keeps running
+type WatchdogTarget struct {
+	Service string `yaml:"service"`
+	User    bool   `yaml:"user"`
+	Process string `yaml:"process"`
+	// Command starts the process; by default the command line it was
+	// last seen running with
+	Command []string `yaml:"command"`
+	// NotifyOnly reports the target down without restarting it
+	NotifyOnly bool `yaml:"notify_only"`
+}
+
+// ProcessRules match processes by glob patterns of their name, owning
+// user or executable path
+type ProcessRules struct {
+	Names []string `yaml:"names"`
+	Users []string `yaml:"users"`
+	Paths []string `yaml:"paths"`
+}
+
+// Load reads a YAML configuration file. Unknown keys are rejected so that
+// typos don't silently fall back to defaults.
+func Load(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var f File
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&f); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return &f, nil
+}
+
+// Flags returns the file's settings keyed by the command-line flag that
+// overrides them, formatted as flag values
+func (f *File) Flags() map[string]string {
+	flags := make(map[string]string)
+	if f.Server.Bind != "" {
+		flags["bind"] = f.Server.Bind
+	}
+	if f.Server.Port != 0 {
+		flags["server-port"] = strconv.Itoa(f.Server.Port)
+	}
+	if f.Server.AccessLog != "" {
+		flags["access-log"] = f.Server.AccessLog
+	}
+	if f.Server.ShutdownTimeout != nil {
+		flags["shutdown-timeout"] = f.Server.ShutdownTimeout.String()
+	}
+	if f.Auth.Token != "" {
+		flags["auth-token"] = f.Auth.Token
+	}
+	if len(f.CORS.Origins) > 0 {
+		flags["cors-origins"] = strings.Join(f.CORS.Origins, ",")
+	}
+	if f.RateLimit.Rate != nil {
+		flags["rate-limit"] = strconv.FormatFloat(*f.RateLimit.Rate, 'f', -1, 64)
+	}
+	if f.RateLimit.Burst != 0 {
+		flags["rate-burst"] = strconv.Itoa(f.RateLimit.Burst)
+	}
+	if f.Cache.TTL != nil {
+		flags["cache-ttl"] = f.Cache.TTL.String()
+	}
+	if f.Collectors.Timeout != nil {
+		flags["collector-timeout"] = f.Collectors.Timeout.String()
+	}
+	if len(f.Tools.Enabled) > 0 {
+		flags["enable-tools"] = strings.Join(f.Tools.Enabled, ",")
+	}
+	if len(f.Tools.Disabled) > 0 {
+		flags["disable-tools"] = strings.Join(f.Tools.Disabled, ",")
+	}
+	if f.Watch.CPUAlert != 0 {
+		flags["cpu-alert"] = strconv.FormatFloat(f.Watch.CPUAlert, 'f', -1, 64)
+	}
+	if len(f.GeoIP.Databases) > 0 {
+		flags["geoip"] = strings.Join(f.GeoIP.Databases, ",")
+	}
+	if f.History.Ports {
+		flags["port-history"] = "true"
+	}
+	if f.History.File != "" {
+		flags["history-file"] = f.History.File
+	}
+	if f.History.Retention != nil {
+		flags["history-retention"] = f.History.Retention.String()
+	}
+	return flags
+}
+
+// WebhookList converts the configured webhooks to their API form
+func (f *File) WebhookList() []types.Webhook {
+	var hooks []types.Webhook
+	for _, hook := range f.Webhooks {
+		hooks = append(hooks, types.Webhook{
+			URL:    hook.URL,
+			Events: hook.Events,
+			Secret: hook.Secret,
+		})
+	}
+	return hooks
+}
+
+// WatchdogTargets converts the configured watchdog targets to their
+// watch form
+func (f *File) WatchdogTargets() []watch.WatchdogTarget {
+	var targets []watch.WatchdogTarget
+	for _, t := range f.Watchdog.Targets {
+		targets = append(targets, watch.WatchdogTarget{
+			Service:    t.Service,
+			User:       t.User,
+			Process:    t.Process,
+			Command:    t.Command,
+			NotifyOnly: t.NotifyOnly,
+		})
+	}
+	return targets
+}
+
+// WatchdogOptions returns the configured watchdog timing and restart
+// limits; unset values are zero, taking the watchdog defaults
+func (f *File) WatchdogOptions() watch.WatchdogOptions {
+	opts := watch.WatchdogOptions{MaxRestarts: f.Watchdog.MaxRestarts}
+	if f.Watchdog.Interval != nil {
+		opts.Interval = *f.Watchdog.Interval
+	}
+	if f.Watchdog.Backoff != nil {
+		opts.Backoff = *f.Watchdog.Backoff
+	}
+	if f.Watchdog.MaxBackoff != nil {
+		opts.MaxBackoff = *f.Watchdog.MaxBackoff
+	}
+	if f.Watchdog.Window != nil {
+		opts.RestartWindow = *f.Watchdog.Window
+	}
+	return opts
+}
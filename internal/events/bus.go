@@ -0,0 +1,128 @@
+package events
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/borankux/gops/pkg/types"
+)
+
+// Event types published on the bus
+const (
+	ProcessStarted = "process.started"
+	ProcessExited  = "process.exited"
+	PortOpened     = "port.opened"
+	PortClosed     = "port.closed"
+	WindowFocused  = "window.focused"
+	WindowOpened   = "window.opened"
+	WindowClosed   = "window.closed"
+	WindowRetitled = "window.title_changed"
+	ProcessCPUHigh = "process.cpu_high"
+	ServiceCrashed = "service.crashed"
+
+	WatchdogDown          = "watchdog.down"
+	WatchdogRestarted     = "watchdog.restarted"
+	WatchdogRestartFailed = "watchdog.restart_failed"
+	WatchdogGaveUp        = "watchdog.gave_up"
+)
+
+// Bus fans out published events to all current subscribers
+type Bus struct {
+	mu   sync.Mutex
+	subs map[*Subscription]struct{}
+}
+
+// Subscription receives events published after it was created
+type Subscription struct {
+	C <-chan types.Event
+
+	ch   chan types.Event
+	bus  *Bus
+	once sync.Once
+}
+
+// NewBus creates an event bus with no subscribers
+func NewBus() *Bus {
+	return &Bus{
+		subs: make(map[*Subscription]struct{}),
+	}
+}
+
+// Subscribe registers a new subscriber with the given channel buffer
+func (b *Bus) Subscribe(buffer int) *Subscription {
+	ch := make(chan types.Event, buffer)
+	sub := &Subscription{C: ch, ch: ch, bus: b}
+
+	b.mu.Lock()
+	b.subs[sub] = struct{}{}
+	b.mu.Unlock()
+
+	return sub
+}
+
+// Subscribers returns the number of active subscriptions
+func (b *Bus) Subscribers() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.subs)
+}
+
+// Publish delivers an event to every subscriber. Subscribers whose buffer
+// is full miss the event rather than blocking the publisher.
+func (b *Bus) Publish(eventType string, data interface{}) {
+	event := types.Event{
+		Type: eventType,
+		Time: time.Now().Format(time.RFC3339Nano),
+		Data: data,
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for sub := range b.subs {
+		select {
+		case sub.ch <- event:
+		default:
+		}
+	}
+}
+
+// Close unregisters the subscription and closes its channel
+func (s *Subscription) Close() {
+	s.once.Do(func() {
+		s.bus.mu.Lock()
+		delete(s.bus.subs, s)
+		s.bus.mu.Unlock()
+		close(s.ch)
+	})
+}
+
+// Matches reports whether eventType is selected by filters. A filter matches
+// either the full type ("port.opened") or its category ("port"). An empty
+// filter list matches everything.
+func Matches(filters []string, eventType string) bool {
+	if len(filters) == 0 {
+		return true
+	}
+	category := eventType
+	if i := strings.IndexByte(eventType, '.'); i >= 0 {
+		category = eventType[:i]
+	}
+	for _, f := range filters {
+		if f == eventType || f == category {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseFilter splits a comma-separated list of event types or categories
+func ParseFilter(value string) []string {
+	var filters []string
+	for _, f := range strings.Split(value, ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			filters = append(filters, f)
+		}
+	}
+	return filters
+}
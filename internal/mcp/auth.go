@@ -0,0 +1,48 @@
+package mcp
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/borankux/gops/pkg/types"
+)
+
+// authMiddleware rejects requests without the configured bearer token.
+// It is a no-op when no token is configured.
+func (s *Server) authMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	if s.config.AuthToken == "" {
+		return next
+	}
+
+	// Compare fixed-length digests so the check leaks neither content
+	// nor length of the token through timing
+	want := sha256.Sum256([]byte(s.config.AuthToken))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, ok := bearerToken(r)
+		got := sha256.Sum256([]byte(token))
+		if !ok || subtle.ConstantTimeCompare(got[:], want[:]) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="gops"`)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(types.ErrorResponse{Error: "unauthorized"})
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+// bearerToken extracts the token from an "Authorization: Bearer" header
+func bearerToken(r *http.Request) (string, bool) {
+	header := r.Header.Get("Authorization")
+	scheme, token, found := strings.Cut(header, " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
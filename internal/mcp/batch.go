@@ -0,0 +1,89 @@
+package mcp
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+
+	"github.com/borankux/gops/pkg/types"
+)
+
+const (
+	// maxBatchCalls bounds the number of sub-requests in one batch
+	maxBatchCalls = 100
+
+	// batchConcurrency is how many sub-requests run at once
+	batchConcurrency = 8
+)
+
+// handleBatch runs several tool calls in one round trip. Sub-requests run
+// concurrently and each reports its own status, so one failing call does
+// not fail the batch.
+func (s *Server) handleBatch(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		json.NewEncoder(w).Encode(types.ErrorResponse{Error: "method not allowed"})
+		return
+	}
+	if !requireJSON(w, r) {
+		return
+	}
+
+	var req types.BatchRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxMessageSize)).Decode(&req); err != nil {
+		s.sendError(w, r, argumentErrorf("invalid batch request: %v", err))
+		return
+	}
+	if len(req.Requests) == 0 {
+		s.sendError(w, r, argumentErrorf("requests must contain at least one call"))
+		return
+	}
+	if len(req.Requests) > maxBatchCalls {
+		s.sendError(w, r, argumentErrorf("too many requests in batch: %d (max %d)", len(req.Requests), maxBatchCalls))
+		return
+	}
+
+	results := make([]types.BatchResult, len(req.Requests))
+	sem := make(chan struct{}, batchConcurrency)
+	var wg sync.WaitGroup
+	for i, call := range req.Requests {
+		wg.Add(1)
+		go func(i int, call types.BatchCall) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			results[i] = s.runBatchCall(r, call)
+		}(i, call)
+	}
+	wg.Wait()
+
+	s.sendJSON(w, withSchemaVersion(types.BatchResponse{
+		Results: results,
+		Count:   len(results),
+	}, apiVersion(r)))
+}
+
+// runBatchCall executes one sub-request of a batch
+func (s *Server) runBatchCall(r *http.Request, call types.BatchCall) types.BatchResult {
+	res := types.BatchResult{ID: call.ID, Tool: call.Tool}
+	if _, exists := s.registry.Get(call.Tool); !exists {
+		res.Status = http.StatusNotFound
+		res.Error = "unknown tool: " + call.Tool
+		return res
+	}
+
+	result, err := s.callTool(r.Context(), call.Tool, call.Arguments)
+	if err != nil {
+		res.Status = errorStatus(err)
+		res.Error = err.Error()
+		res.PermissionRequired = permissionRequired(err)
+		return res
+	}
+
+	res.Status = http.StatusOK
+	res.Result = result
+	return res
+}
@@ -0,0 +1,130 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// DefaultCacheTTL is how long tool results are reused by default
+const DefaultCacheTTL = time.Second
+
+// cacheEntry is a cached tool result
+type cacheEntry struct {
+	result  interface{}
+	expires time.Time
+}
+
+// resultCache keeps recent tool results keyed by tool name and arguments so
+// that bursts of identical calls don't re-run expensive collectors
+type resultCache struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+func newResultCache(ttl time.Duration) *resultCache {
+	return &resultCache{
+		ttl:     ttl,
+		entries: make(map[string]cacheEntry),
+	}
+}
+
+// cacheKey identifies a call; map keys are marshalled in sorted order so
+// equal arguments produce equal keys
+func cacheKey(name string, args Arguments) (string, bool) {
+	data, err := json.Marshal(args)
+	if err != nil {
+		return "", false
+	}
+	return name + "?" + string(data), true
+}
+
+func (c *resultCache) get(key string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, exists := c.entries[key]
+	if !exists || time.Now().After(entry.expires) {
+		return nil, false
+	}
+	return copyResult(entry.result), true
+}
+
+// set stores a result and drops expired entries
+func (c *resultCache) set(key string, result interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	for k, entry := range c.entries {
+		if now.After(entry.expires) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = cacheEntry{result: copyResult(result), expires: now.Add(c.ttl)}
+}
+
+// copyResult deep-copies the maps and slices of a projected result, so
+// each caller of a cached tool gets its own to stamp or adjust. Response
+// structs are already copied when returned by value, and the handlers
+// never change the slices they hold after returning them.
+func copyResult(result interface{}) interface{} {
+	switch v := result.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			m[k] = copyResult(item)
+		}
+		return m
+	case []interface{}:
+		items := make([]interface{}, len(v))
+		for i, item := range v {
+			items[i] = copyResult(item)
+		}
+		return items
+	}
+	return result
+}
+
+// callTool runs a tool through the result cache. Errors are never cached,
+// and every call gets a result of its own, cached or not.
+func (s *Server) callTool(ctx context.Context, name string, args Arguments) (interface{}, error) {
+	t, exists := s.registry.Get(name)
+	if exists && t.OptIn && fromBrowser(ctx) {
+		return nil, fmt.Errorf("%s can't be called from a web page: %w", name, os.ErrPermission)
+	}
+	if s.cache == nil || !exists || t.NoCache {
+		return s.runTool(ctx, t, name, args)
+	}
+
+	key, ok := cacheKey(name, args)
+	if !ok {
+		return s.runTool(ctx, t, name, args)
+	}
+	if result, hit := s.cache.get(key); hit {
+		return result, nil
+	}
+
+	result, err := s.runTool(ctx, t, name, args)
+	if err != nil {
+		return nil, err
+	}
+	s.cache.set(key, result)
+	return result, nil
+}
+
+// runTool calls the registry within the collector timeout and records the
+// outcome for health reporting
+func (s *Server) runTool(ctx context.Context, t Tool, name string, args Arguments) (interface{}, error) {
+	if s.config.CollectorTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.config.CollectorTimeout)
+		defer cancel()
+	}
+	result, err := s.registry.Call(ctx, name, args)
+	s.recordCollection(t, err)
+	return result, err
+}
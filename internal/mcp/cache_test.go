@@ -0,0 +1,91 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type cacheTestItem struct {
+	PID  int32  `json:"pid"`
+	Name string `json:"name"`
+}
+
+type cacheTestResponse struct {
+	SchemaVersion int             `json:"schema_version,omitempty"`
+	Items         []cacheTestItem `json:"items"`
+}
+
+func newCacheTestServer() *Server {
+	s := NewServer(Config{CacheTTL: time.Minute})
+	s.registry.Register(Tool{
+		Name:        "cache_test",
+		InputSchema: objectSchema(withFields(nil)),
+		Handler: func(ctx context.Context, args Arguments) (interface{}, error) {
+			return cacheTestResponse{Items: []cacheTestItem{{PID: 1, Name: "init"}, {PID: 2, Name: "kthreadd"}}}, nil
+		},
+	})
+	return s
+}
+
+// TestCachedProjectionConcurrent runs projected calls that hit the cache
+// in parallel and stamps each with a schema version, as the v2 routes do.
+// Run with -race.
+func TestCachedProjectionConcurrent(t *testing.T) {
+	s := newCacheTestServer()
+	args := Arguments{"fields": []interface{}{"pid"}}
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 32)
+	for i := 0; i < 32; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, err := s.callTool(ctx, "cache_test", args)
+			if err != nil {
+				errs <- err
+				return
+			}
+			if _, err := json.Marshal(withSchemaVersion(result, 2)); err != nil {
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+}
+
+// TestCachedProjectionVersionLeak checks that stamping a v2 response
+// doesn't leave schema_version in the cached result served to v1
+func TestCachedProjectionVersionLeak(t *testing.T) {
+	s := newCacheTestServer()
+	args := Arguments{"fields": []interface{}{"name"}}
+	ctx := context.Background()
+
+	v2, err := s.callTool(ctx, "cache_test", args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := json.Marshal(withSchemaVersion(v2, 2)); !strings.Contains(string(data), `"schema_version":2`) {
+		t.Fatalf("v2 response lacks schema_version: %s", data)
+	}
+
+	v1, err := s.callTool(ctx, "cache_test", args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := json.Marshal(withSchemaVersion(v1, 0))
+	if strings.Contains(string(data), "schema_version") {
+		t.Fatalf("v1 response carries schema_version: %s", data)
+	}
+	if !strings.Contains(string(data), `"name":"init"`) || strings.Contains(string(data), `"pid"`) {
+		t.Fatalf("unexpected projection: %s", data)
+	}
+}
@@ -0,0 +1,122 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/borankux/gops/pkg/types"
+)
+
+// ParseCORSOrigins parses the -cors-origins flag value. "none" or an empty
+// value disables CORS; otherwise origins are comma-separated.
+func ParseCORSOrigins(value string) []string {
+	value = strings.TrimSpace(value)
+	if value == "" || strings.EqualFold(value, "none") {
+		return nil
+	}
+	var origins []string
+	for _, origin := range strings.Split(value, ",") {
+		if origin = strings.TrimRight(strings.TrimSpace(origin), "/"); origin != "" {
+			origins = append(origins, origin)
+		}
+	}
+	return origins
+}
+
+// allowedOrigin returns the Access-Control-Allow-Origin value for a request
+// origin using method, or "" if the origin is not allowed. "*" only covers
+// reads: requests that may change state, such as POST and DELETE, need the
+// origin listed by name.
+func (s *Server) allowedOrigin(origin, method string) string {
+	for _, allowed := range s.config.CORSOrigins {
+		if allowed == "*" && safeMethod(method) {
+			return "*"
+		}
+		if origin != "" && strings.EqualFold(allowed, origin) {
+			return origin
+		}
+	}
+	return ""
+}
+
+// safeMethod reports whether method only reads
+func safeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead
+}
+
+// checkOrigin reports whether a browser request from r's Origin may use the
+// server. Requests without an Origin header come from non-browser clients
+// and are always allowed.
+func (s *Server) checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	return origin == "" || s.allowedOrigin(origin, r.Method) != ""
+}
+
+// corsMiddleware adds CORS headers for allowed origins and rejects
+// requests from other origins with 403, so that a web page can't use the
+// server from the user's browser, including through DNS rebinding
+func (s *Server) corsMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		method := r.Method
+		if method == http.MethodOptions {
+			method = r.Header.Get("Access-Control-Request-Method")
+		}
+		allowOrigin := s.allowedOrigin(origin, method)
+		if allowOrigin != "" {
+			methods := "GET, POST, DELETE, OPTIONS"
+			if allowOrigin == "*" {
+				methods = "GET, OPTIONS"
+			} else {
+				w.Header().Add("Vary", "Origin")
+			}
+			w.Header().Set("Access-Control-Allow-Origin", allowOrigin)
+			w.Header().Set("Access-Control-Allow-Methods", methods)
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Accept, Authorization, Mcp-Session-Id, Mcp-Protocol-Version")
+			w.Header().Set("Access-Control-Expose-Headers", "Mcp-Session-Id, X-Request-ID, Retry-After")
+		}
+
+		if origin != "" && allowOrigin == "" {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusForbidden)
+			json.NewEncoder(w).Encode(types.ErrorResponse{Error: "origin not allowed: " + origin})
+			return
+		}
+		if r.Method == http.MethodOptions {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		if origin != "" {
+			r = r.WithContext(context.WithValue(r.Context(), browserKey{}, origin))
+		}
+		next(w, r)
+	}
+}
+
+// browserKey is the context key under which corsMiddleware records the
+// Origin of a request made by a web page
+type browserKey struct{}
+
+// fromBrowser reports whether ctx belongs to a request made by a web page
+func fromBrowser(ctx context.Context) bool {
+	origin, _ := ctx.Value(browserKey{}).(string)
+	return origin != ""
+}
+
+// requireJSON answers 415 unless r carries a JSON body, so that a web page
+// can't reach a POST endpoint with a form or text/plain request that
+// browsers send without a preflight
+func requireJSON(w http.ResponseWriter, r *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err == nil && mediaType == "application/json" {
+		return true
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusUnsupportedMediaType)
+	json.NewEncoder(w).Encode(withSchemaVersion(types.ErrorResponse{Error: "Content-Type must be application/json"}, apiVersion(r)))
+	return false
+}
@@ -0,0 +1,96 @@
+package mcp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestStreamableOrigin(t *testing.T) {
+	tests := []struct {
+		name    string
+		origins []string
+		origin  string
+		want    int
+	}{
+		{"no origin", []string{"*"}, "", http.StatusOK},
+		{"wildcard refuses post", []string{"*"}, "http://evil.example", http.StatusForbidden},
+		{"named origin", []string{"http://app.example"}, "http://app.example", http.StatusOK},
+		{"other origin", []string{"http://app.example"}, "http://evil.example", http.StatusForbidden},
+		{"cors disabled", nil, "http://app.example", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewServer(Config{CORSOrigins: tt.origins})
+			body := `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`
+			r := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(body))
+			r.Header.Set("Content-Type", "application/json")
+			if tt.origin != "" {
+				r.Header.Set("Origin", tt.origin)
+			}
+			w := httptest.NewRecorder()
+			s.handleStreamable(w, r)
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+		})
+	}
+}
+
+func TestRequireJSON(t *testing.T) {
+	tests := []struct {
+		contentType string
+		want        bool
+	}{
+		{"application/json", true},
+		{"application/json; charset=utf-8", true},
+		{"text/plain", false},
+		{"application/x-www-form-urlencoded", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodPost, "/mcp/v2/batch", strings.NewReader("{}"))
+		if tt.contentType != "" {
+			r.Header.Set("Content-Type", tt.contentType)
+		}
+		w := httptest.NewRecorder()
+		if got := requireJSON(w, r); got != tt.want {
+			t.Errorf("requireJSON(%q) = %v, want %v", tt.contentType, got, tt.want)
+		}
+		if !tt.want && w.Code != http.StatusUnsupportedMediaType {
+			t.Errorf("requireJSON(%q) status = %d, want 415", tt.contentType, w.Code)
+		}
+	}
+}
+
+func TestOptInToolFromBrowser(t *testing.T) {
+	s := NewServer(Config{CORSOrigins: []string{"http://app.example"}})
+	tool := Tool{
+		Name:    "opt_in_test",
+		Method:  http.MethodPost,
+		NoCache: true,
+		OptIn:   true,
+		Handler: func(ctx context.Context, args Arguments) (interface{}, error) {
+			return map[string]bool{"ok": true}, nil
+		},
+	}
+	s.registry.Register(tool)
+	handler := s.corsMiddleware(s.handleTool(tool))
+
+	for _, origin := range []string{"", "http://app.example"} {
+		r := httptest.NewRequest(http.MethodPost, "/mcp/v2/opt-in", strings.NewReader("{}"))
+		r.Header.Set("Content-Type", "application/json")
+		want := http.StatusOK
+		if origin != "" {
+			r.Header.Set("Origin", origin)
+			want = http.StatusForbidden
+		}
+		w := httptest.NewRecorder()
+		handler(w, r)
+		if w.Code != want {
+			t.Errorf("origin %q: status = %d, want %d: %s", origin, w.Code, want, w.Body)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/borankux/gops/internal/events"
+	"github.com/borankux/gops/internal/watch"
+	"github.com/borankux/gops/pkg/types"
+)
+
+// sseKeepAlive is how often an idle event stream sends a comment so
+// proxies don't close it
+const sseKeepAlive = 30 * time.Second
+
+// handleEvents streams system events over Server-Sent Events, for clients
+// that can't use the WebSocket. Each event is named after its type, and
+// the optional types query parameter filters them as on /ws.
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Allow", "GET")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		json.NewEncoder(w).Encode(types.ErrorResponse{Error: "method not allowed"})
+		return
+	}
+	filters := events.ParseFilter(r.URL.Query().Get("types"))
+
+	s.streams.Add(1)
+	defer s.streams.Done()
+
+	s.startWatcher()
+	sub := s.bus.Subscribe(64)
+	defer sub.Close()
+	startSSE(w)
+
+	keepAlive := time.NewTicker(sseKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-s.lifetime.Done():
+			writeSSE(w, "close", []byte(`{"reason":"server shutting down"}`))
+			return
+		case event, ok := <-sub.C:
+			if !ok {
+				return
+			}
+			if !events.Matches(filters, event.Type) {
+				continue
+			}
+			data, err := json.Marshal(event)
+			if err != nil {
+				continue
+			}
+			if err := writeSSE(w, event.Type, data); err != nil {
+				return
+			}
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			flush(w)
+		}
+	}
+}
+
+// registerWatcherTools registers the tools reading what the watcher has
+// recorded
+func (s *Server) registerWatcherTools() {
+	s.registry.Register(Tool{
+		Name:        "get_window_title_history",
+		Group:       "windows",
+		Description: "Get the titles windows have had over time, with when each appeared, to follow long-running jobs that show their progress in the title bar. Titles are recorded while gops watches for events; the first call starts watching, so only later changes are recorded.",
+		InputSchema: objectSchema(map[string]*Schema{
+			"id":    integerProperty("Only the window with this ID, from list_windows", 1, 4294967295),
+			"pid":   pidProperty("Only windows of this process"),
+			"title": {Type: "string", Description: "Only windows that had a title containing this text, ignoring case"},
+			"since": {Type: "string", Description: "Only titles recorded since this RFC 3339 time, or this long ago, e.g. 30m"},
+		}),
+		Path:    "/mcp/v2/windows/titles",
+		NoCache: true,
+		Output:  types.WindowTitleHistoryResponse{},
+		Handler: s.windowTitleHistory,
+	})
+
+	if s.config.PortHistory == nil {
+		return
+	}
+	s.registry.Register(Tool{
+		Name:        "get_port_history",
+		Group:       "ports",
+		Description: "Look up which processes listened on which ports in the past, e.g. what was listening on 8080 yesterday at 3pm. Listeners are recorded while the server runs with port history enabled, and kept for the configured retention.",
+		InputSchema: objectSchema(map[string]*Schema{
+			"port":  portProperty("Only listeners on this port"),
+			"pid":   pidProperty("Only listeners held by this process"),
+			"name":  {Type: "string", Description: "Only listeners whose process name contains this text, ignoring case"},
+			"at":    {Type: "string", Description: "Only listeners open at this RFC 3339 time, or this long ago, e.g. 24h"},
+			"since": {Type: "string", Description: "Only listeners open at some point since this RFC 3339 time, or this long ago"},
+			"until": {Type: "string", Description: "Only listeners open at some point before this RFC 3339 time, or this long ago"},
+		}),
+		Path:    "/mcp/v2/ports/history",
+		NoCache: true,
+		Output:  types.PortHistoryResponse{},
+		Handler: s.portHistory,
+	})
+}
+
+func (s *Server) portHistory(ctx context.Context, args Arguments) (interface{}, error) {
+	portNum, _, err := args.Port("port")
+	if err != nil {
+		return nil, err
+	}
+	pid, _, err := args.PID("pid")
+	if err != nil {
+		return nil, err
+	}
+	q := watch.PortHistoryQuery{Port: portNum, PID: pid, Name: args.String("name")}
+	for key, t := range map[string]*time.Time{"at": &q.At, "since": &q.Since, "until": &q.Until} {
+		if *t, err = parseTimeArg(args, key); err != nil {
+			return nil, err
+		}
+	}
+
+	history := s.config.PortHistory
+	ports := history.Query(q)
+	resp := types.PortHistoryResponse{
+		Ports:     ports,
+		Count:     len(ports),
+		Retention: history.Retention().String(),
+	}
+	if started := history.Started(); !started.IsZero() {
+		resp.Since = started.Format(time.RFC3339)
+	}
+	return resp, nil
+}
+
+// parseTimeArg reads a time given as RFC 3339 or as a duration ago, the
+// zero time when the argument is absent
+func parseTimeArg(args Arguments, key string) (time.Time, error) {
+	v := args.String(key)
+	if v == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(v); err == nil {
+		return time.Now().Add(-d), nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return time.Time{}, argumentErrorf("invalid %s %q: expected a duration such as 30m or an RFC 3339 time", key, v)
+	}
+	return t, nil
+}
+
+func (s *Server) windowTitleHistory(ctx context.Context, args Arguments) (interface{}, error) {
+	id, _, err := args.Int("id")
+	if err != nil {
+		return nil, err
+	}
+	if id < 0 || id > math.MaxUint32 {
+		return nil, argumentErrorf("invalid id: %d", id)
+	}
+	pid, _, err := args.PID("pid")
+	if err != nil {
+		return nil, err
+	}
+	q := watch.TitleQuery{ID: uint32(id), PID: pid, Title: args.String("title")}
+	if q.Since, err = parseTimeArg(args, "since"); err != nil {
+		return nil, err
+	}
+
+	s.startWatcher()
+	windows := s.titles.Query(q)
+	resp := types.WindowTitleHistoryResponse{
+		Windows: windows,
+		Count:   len(windows),
+	}
+	if started := s.titles.Started(); !started.IsZero() {
+		resp.Since = started.Format(time.RFC3339)
+	}
+	return resp, nil
+}
@@ -0,0 +1,216 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/borankux/gops/internal/permission"
+	"github.com/borankux/gops/internal/process"
+	"github.com/borankux/gops/internal/service"
+	"github.com/borankux/gops/internal/utils"
+	"github.com/borankux/gops/internal/window"
+	"github.com/borankux/gops/pkg/types"
+)
+
+const (
+	// permissionCheckInterval is how long permission results are reused;
+	// checking runs osascript, which is too slow for every probe
+	permissionCheckInterval = 30 * time.Second
+
+	// readinessTimeout bounds how long the readiness checks may take
+	readinessTimeout = 5 * time.Second
+)
+
+// readinessChecks verify that the tools each collector shells out to work
+var readinessChecks = map[string]func(context.Context) error{
+	"processes": process.Ready,
+	"windows":   window.Ready,
+	"services":  service.Ready,
+}
+
+// healthTracker records collector outcomes and caches permission checks
+type healthTracker struct {
+	started time.Time
+
+	mu          sync.Mutex
+	collectors  map[string]types.CollectorStatus
+	permissions []types.PermissionStatus
+	checkedAt   time.Time
+	refreshing  bool
+}
+
+func newHealthTracker() *healthTracker {
+	return &healthTracker{
+		started:    time.Now(),
+		collectors: make(map[string]types.CollectorStatus),
+	}
+}
+
+// record notes the outcome of a collection
+func (h *healthTracker) record(collector string, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	status := h.collectors[collector]
+	now := time.Now().Format(time.RFC3339)
+	if err != nil {
+		status.Status = "failing"
+		status.LastError = err.Error()
+		status.LastErrorTime = now
+	} else {
+		status.Status = "ok"
+		status.LastSuccess = now
+	}
+	h.collectors[collector] = status
+}
+
+// collectorStatus returns the recorded status of a collector
+func (h *healthTracker) collectorStatus(collector string) types.CollectorStatus {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	status, exists := h.collectors[collector]
+	if !exists {
+		return types.CollectorStatus{Status: "unknown"}
+	}
+	return status
+}
+
+// permissionStatus returns the permission checks, refreshing them when
+// stale. The checks run without holding the lock, so a slow osascript
+// doesn't stall collector updates; while one caller refreshes, others get
+// the previous results.
+func (h *healthTracker) permissionStatus(ctx context.Context) []types.PermissionStatus {
+	h.mu.Lock()
+	if h.permissions != nil && (h.refreshing || time.Since(h.checkedAt) <= permissionCheckInterval) {
+		defer h.mu.Unlock()
+		return h.permissions
+	}
+	h.refreshing = true
+	h.mu.Unlock()
+
+	permissions := permission.Check(ctx)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.refreshing = false
+	h.permissions = permissions
+	h.checkedAt = time.Now()
+	return permissions
+}
+
+// recordCollection updates collector health after a tool call. Invalid
+// arguments say nothing about the collector and are ignored.
+func (s *Server) recordCollection(t Tool, err error) {
+	if t.Collector == "" || (err != nil && isArgumentError(err)) {
+		return
+	}
+	s.health.record(t.Collector, err)
+}
+
+// Health reports collector and permission status and server uptime
+func (s *Server) Health(ctx context.Context) types.HealthResponse {
+	resp := types.HealthResponse{
+		Status:      "healthy",
+		StartedAt:   s.health.started.Format(time.RFC3339),
+		Collectors:  make(map[string]types.CollectorStatus),
+		Permissions: s.health.permissionStatus(ctx),
+	}
+	uptime := uint64(time.Since(s.health.started).Seconds())
+	resp.Uptime = uptime
+	resp.UptimeHuman = utils.FormatDuration(uptime)
+
+	for _, t := range s.registry.List() {
+		if t.Collector == "" {
+			continue
+		}
+		status := s.health.collectorStatus(t.Collector)
+		if status.Status == "failing" {
+			resp.Status = "degraded"
+		}
+		resp.Collectors[t.Collector] = status
+	}
+	for _, p := range resp.Permissions {
+		if p.Status == permission.StatusDenied {
+			resp.Status = "degraded"
+		}
+	}
+	sort.Slice(resp.Permissions, func(i, j int) bool {
+		return resp.Permissions[i].Name < resp.Permissions[j].Name
+	})
+	return resp
+}
+
+// handleHealth reports server health. Partial failures are described in
+// the body; the status code stays 200 while the server can respond.
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	s.sendJSON(w, s.Health(r.Context()))
+}
+
+// Ready runs the readiness checks concurrently
+func (s *Server) Ready(ctx context.Context) types.ReadinessResponse {
+	ctx, cancel := context.WithTimeout(ctx, readinessTimeout)
+	defer cancel()
+
+	resp := types.ReadinessResponse{
+		Status: "ready",
+		Checks: make(map[string]string),
+	}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, check := range readinessChecks {
+		wg.Add(1)
+		go func(name string, check func(context.Context) error) {
+			defer wg.Done()
+			err := check(ctx)
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err == nil:
+				resp.Checks[name] = "ok"
+			case optionalCheck(err):
+				resp.Checks[name] = "unavailable: " + err.Error()
+				if resp.Status == "ready" {
+					resp.Status = "degraded"
+				}
+			default:
+				resp.Checks[name] = err.Error()
+				resp.Status = "not_ready"
+			}
+		}(name, check)
+	}
+	wg.Wait()
+	return resp
+}
+
+// optionalCheck reports whether a readiness check failed because its
+// collector has nothing to collect on this host, such as windows on a
+// headless server, rather than because its tools are broken
+func optionalCheck(err error) bool {
+	return errors.Is(err, window.ErrNoSession)
+}
+
+// handleLiveness answers as long as the server can serve requests
+func (s *Server) handleLiveness(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	io.WriteString(w, `{"status":"alive"}`)
+}
+
+// handleReadiness answers 503 unless every collector dependency works or
+// is unavailable on this host
+func (s *Server) handleReadiness(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	resp := s.Ready(r.Context())
+	if resp.Status == "not_ready" {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(resp)
+		return
+	}
+	s.sendJSON(w, resp)
+}
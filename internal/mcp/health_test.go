@@ -0,0 +1,48 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/borankux/gops/internal/window"
+)
+
+func TestReadiness(t *testing.T) {
+	saved := readinessChecks
+	t.Cleanup(func() { readinessChecks = saved })
+
+	pass := func(context.Context) error { return nil }
+	tests := []struct {
+		name   string
+		checks map[string]func(context.Context) error
+		status string
+		code   int
+	}{
+		{"all ok", map[string]func(context.Context) error{"processes": pass, "windows": pass}, "ready", http.StatusOK},
+		{"headless", map[string]func(context.Context) error{
+			"processes": pass,
+			"windows":   func(context.Context) error { return window.ErrNoSession },
+		}, "degraded", http.StatusOK},
+		{"failing", map[string]func(context.Context) error{
+			"processes": func(context.Context) error { return errors.New("exit status 1") },
+			"windows":   func(context.Context) error { return window.ErrNoSession },
+		}, "not_ready", http.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			readinessChecks = tt.checks
+			s := NewServer(Config{})
+			if got := s.Ready(context.Background()).Status; got != tt.status {
+				t.Errorf("status = %q, want %q", got, tt.status)
+			}
+			w := httptest.NewRecorder()
+			s.handleReadiness(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+			if w.Code != tt.code {
+				t.Errorf("code = %d, want %d: %s", w.Code, tt.code, w.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,418 @@
+package mcp
+
+import (
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/borankux/gops/pkg/types"
+)
+
+// openAPIDocument is the root of an OpenAPI 3 document
+type openAPIDocument struct {
+	OpenAPI    string                          `json:"openapi"`
+	Info       map[string]string               `json:"info"`
+	Paths      map[string]map[string]operation `json:"paths"`
+	Components components                      `json:"components"`
+	Security   []map[string][]string           `json:"security,omitempty"`
+}
+
+type components struct {
+	Schemas         map[string]*Schema                `json:"schemas"`
+	SecuritySchemes map[string]map[string]interface{} `json:"securitySchemes,omitempty"`
+}
+
+type operation struct {
+	OperationID string              `json:"operationId"`
+	Summary     string              `json:"summary"`
+	Parameters  []parameter         `json:"parameters,omitempty"`
+	RequestBody *requestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]response `json:"responses"`
+	Deprecated  bool                `json:"deprecated,omitempty"`
+}
+
+type parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required"`
+	Schema      *Schema `json:"schema"`
+}
+
+type requestBody struct {
+	Required bool                          `json:"required"`
+	Content  map[string]map[string]*Schema `json:"content"`
+}
+
+type response struct {
+	Description string                        `json:"description"`
+	Content     map[string]map[string]*Schema `json:"content,omitempty"`
+}
+
+// openAPIBuilder accumulates component schemas while paths are described
+type openAPIBuilder struct {
+	schemas map[string]*Schema
+}
+
+// OpenAPI builds an OpenAPI 3 document describing the REST endpoints
+func (s *Server) OpenAPI() interface{} {
+	b := &openAPIBuilder{schemas: make(map[string]*Schema)}
+	errorRef := b.schemaFor(reflect.TypeOf(types.ErrorResponse{}))
+
+	doc := openAPIDocument{
+		OpenAPI: "3.0.3",
+		Info: map[string]string{
+			"title":   "gops API",
+			"version": serverVersion,
+		},
+		Paths: make(map[string]map[string]operation),
+	}
+
+	errorResponses := func(responses map[string]response) map[string]response {
+		responses["400"] = jsonResponse("Invalid parameters", errorRef)
+		responses["500"] = jsonResponse("Collection failed", errorRef)
+		if s.config.AuthToken != "" {
+			responses["401"] = jsonResponse("Missing or invalid bearer token", errorRef)
+		}
+		return responses
+	}
+
+	for _, t := range s.registry.List() {
+		if t.Path == "" {
+			continue
+		}
+		result := &Schema{Type: "object"}
+		if t.Output != nil {
+			result = b.schemaFor(reflect.TypeOf(t.Output))
+		}
+		op := operation{
+			OperationID: t.Name,
+			Summary:     t.Description,
+			Responses: errorResponses(map[string]response{
+				"200": jsonResponse("Successful response", result),
+			}),
+		}
+		if t.MediaType != "" {
+			op.Responses["200"].Content[t.MediaType] = map[string]*Schema{
+				"schema": {Type: "string", Format: "binary"},
+			}
+			op.Responses["404"] = jsonResponse("Target does not exist", errorRef)
+		}
+		if t.Group == "windows" {
+			// Window listing needs privacy permissions on macOS
+			op.Responses["403"] = jsonResponse("A macOS permission is missing; see permission_required", errorRef)
+		}
+		if t.Method == http.MethodPost {
+			body := bodySchema(t.Path, t.InputSchema)
+			op.RequestBody = &requestBody{
+				Required: len(body.Required) > 0,
+				Content: map[string]map[string]*Schema{
+					"application/json": {"schema": body},
+				},
+			}
+			op.Responses["403"] = jsonResponse("Target is protected or access was denied", errorRef)
+			op.Responses["404"] = jsonResponse("Target does not exist", errorRef)
+			for _, param := range toolParameters(t.Path, t.InputSchema) {
+				if param.In == "path" {
+					op.Parameters = append(op.Parameters, param)
+				}
+			}
+			doc.Paths[t.Path] = map[string]operation{"post": op}
+			continue
+		}
+		op.Parameters = toolParameters(t.Path, t.InputSchema)
+		if t.MediaType != "" {
+			op.Parameters = append(op.Parameters, parameter{
+				Name:        "format",
+				In:          "query",
+				Description: "json returns the JSON result instead of the " + t.MediaType + " body",
+				Schema:      &Schema{Type: "string", Enum: []string{"json"}},
+			})
+		}
+		doc.Paths[t.Path] = map[string]operation{"get": op}
+	}
+
+	doc.Paths[apiV2Prefix+"tools"] = map[string]operation{
+		"get": {
+			OperationID: "list_tools",
+			Summary:     "List the available tools with their input schemas and endpoints",
+			Responses: errorResponses(map[string]response{
+				"200": jsonResponse("Tool manifest", &Schema{Type: "object"}),
+			}),
+		},
+	}
+
+	if _, enabled := s.registry.Get("get_resource_usage"); enabled {
+		usageRef := b.schemaFor(reflect.TypeOf(types.ResourceUsage{}))
+		doc.Paths[apiV2Prefix+"resource/stream"] = map[string]operation{
+			"get": {
+				OperationID: "stream_resource_usage",
+				Summary:     "Stream resource usage samples for a process over Server-Sent Events",
+				Parameters: []parameter{
+					{Name: "pid", In: "query", Required: true, Description: "Process ID to sample", Schema: pidProperty("")},
+					{Name: "interval", In: "query", Description: "Sampling interval as a Go duration, e.g. 500ms", Schema: &Schema{Type: "string"}},
+				},
+				Responses: errorResponses(map[string]response{
+					"200": {
+						Description: "Stream of usage events, each carrying a ResourceUsage object",
+						Content: map[string]map[string]*Schema{
+							"text/event-stream": {"schema": usageRef},
+						},
+					},
+				}),
+			},
+		}
+	}
+
+	doc.Paths[apiV2Prefix+"events"] = map[string]operation{
+		"get": {
+			OperationID: "stream_events",
+			Summary:     "Stream system events such as process starts and exits over Server-Sent Events",
+			Parameters: []parameter{
+				{Name: "types", In: "query", Description: "Comma-separated event types or categories, e.g. process,port.opened", Schema: &Schema{Type: "string"}},
+			},
+			Responses: errorResponses(map[string]response{
+				"200": {
+					Description: "Stream of events named after their type",
+					Content: map[string]map[string]*Schema{
+						"text/event-stream": {"schema": b.schemaFor(reflect.TypeOf(types.Event{}))},
+					},
+				},
+			}),
+		},
+	}
+
+	doc.Paths[apiV2Prefix+"batch"] = map[string]operation{
+		"post": {
+			OperationID: "batch",
+			Summary:     "Run several tool calls in one round trip",
+			RequestBody: &requestBody{
+				Required: true,
+				Content: map[string]map[string]*Schema{
+					"application/json": {"schema": b.schemaFor(reflect.TypeOf(types.BatchRequest{}))},
+				},
+			},
+			Responses: errorResponses(map[string]response{
+				"200": jsonResponse("Per-call results in request order", b.schemaFor(reflect.TypeOf(types.BatchResponse{}))),
+			}),
+		},
+	}
+
+	webhookRef := b.schemaFor(reflect.TypeOf(types.Webhook{}))
+	doc.Paths[apiV2Prefix+"webhooks"] = map[string]operation{
+		"get": {
+			OperationID: "list_webhooks",
+			Summary:     "List registered webhooks",
+			Responses: errorResponses(map[string]response{
+				"200": jsonResponse("Registered webhooks", b.schemaFor(reflect.TypeOf(types.WebhooksResponse{}))),
+			}),
+		},
+		"post": {
+			OperationID: "create_webhook",
+			Summary:     "Register a URL to receive system events",
+			RequestBody: &requestBody{
+				Required: true,
+				Content: map[string]map[string]*Schema{
+					"application/json": {"schema": webhookRef},
+				},
+			},
+			Responses: errorResponses(map[string]response{
+				"201": jsonResponse("Webhook created", webhookRef),
+			}),
+		},
+	}
+	doc.Paths[apiV2Prefix+"webhooks/{id}"] = map[string]operation{
+		"delete": {
+			OperationID: "delete_webhook",
+			Summary:     "Remove a webhook",
+			Parameters: []parameter{
+				{Name: "id", In: "path", Required: true, Schema: &Schema{Type: "string"}},
+			},
+			Responses: errorResponses(map[string]response{
+				"204": {Description: "Webhook removed"},
+				"404": jsonResponse("Unknown webhook", errorRef),
+			}),
+		},
+	}
+
+	// v1 routes serve the same operations without schema_version
+	legacy := make(map[string]map[string]operation, len(doc.Paths))
+	for path, ops := range doc.Paths {
+		legacy[v1Path(path)] = make(map[string]operation, len(ops))
+		for method, op := range ops {
+			op.OperationID += "_v1"
+			op.Deprecated = true
+			legacy[v1Path(path)][method] = op
+		}
+	}
+	for path, ops := range legacy {
+		doc.Paths[path] = ops
+	}
+
+	doc.Components.Schemas = b.schemas
+	if s.config.AuthToken != "" {
+		doc.Components.SecuritySchemes = map[string]map[string]interface{}{
+			"bearerAuth": {"type": "http", "scheme": "bearer"},
+		}
+		doc.Security = []map[string][]string{{"bearerAuth": {}}}
+	}
+
+	return doc
+}
+
+func (s *Server) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	s.sendJSON(w, s.OpenAPI())
+}
+
+func jsonResponse(description string, schema *Schema) response {
+	return response{
+		Description: description,
+		Content: map[string]map[string]*Schema{
+			"application/json": {"schema": schema},
+		},
+	}
+}
+
+// toolParameters turns a tool input schema into parameters, taken from
+// the path for {name} segments of path and from the query otherwise
+func toolParameters(path string, input *Schema) []parameter {
+	if input == nil {
+		return nil
+	}
+
+	required := make(map[string]bool)
+	for _, name := range input.Required {
+		required[name] = true
+	}
+	inPath := make(map[string]bool)
+	for _, name := range pathParameters(path) {
+		inPath[name] = true
+	}
+
+	var params []parameter
+	for name, prop := range input.Properties {
+		schema := *prop
+		schema.Description = ""
+		in := "query"
+		if inPath[name] {
+			in = "path"
+		}
+		params = append(params, parameter{
+			Name:        name,
+			In:          in,
+			Description: prop.Description,
+			Required:    required[name] || inPath[name],
+			Schema:      &schema,
+		})
+	}
+	sort.Slice(params, func(i, j int) bool {
+		return params[i].Name < params[j].Name
+	})
+	return params
+}
+
+// bodySchema returns the input schema of a POST tool without the
+// parameters taken from its path
+func bodySchema(path string, input *Schema) *Schema {
+	inPath := pathParameters(path)
+	if len(inPath) == 0 {
+		return input
+	}
+	body := *input
+	body.Properties = make(map[string]*Schema, len(input.Properties))
+	for name, prop := range input.Properties {
+		body.Properties[name] = prop
+	}
+	for _, name := range inPath {
+		delete(body.Properties, name)
+	}
+	body.Required = nil
+	for _, name := range input.Required {
+		if _, ok := body.Properties[name]; ok {
+			body.Required = append(body.Required, name)
+		}
+	}
+	return &body
+}
+
+// schemaFor returns the schema for t, registering named structs as
+// components and referencing them
+func (b *openAPIBuilder) schemaFor(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: b.schemaFor(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: b.schemaFor(t.Elem())}
+	case reflect.Struct:
+		return b.structSchema(t)
+	default:
+		// interface{} and anything else accepts any JSON value
+		return &Schema{}
+	}
+}
+
+func (b *openAPIBuilder) structSchema(t reflect.Type) *Schema {
+	name := t.Name()
+	ref := &Schema{Ref: "#/components/schemas/" + name}
+	if name != "" {
+		if _, exists := b.schemas[name]; exists {
+			return ref
+		}
+		// Reserve the name first so recursive types terminate
+		b.schemas[name] = &Schema{Type: "object"}
+	}
+
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	b.addFields(schema, t)
+
+	if name == "" {
+		return schema
+	}
+	b.schemas[name] = schema
+	return ref
+}
+
+// addFields adds the JSON fields of struct t to schema. Embedded structs
+// without a tag are flattened, as encoding/json does.
+func (b *openAPIBuilder) addFields(schema *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		if field.Anonymous && tag == "" && field.Type.Kind() == reflect.Struct {
+			b.addFields(schema, field.Type)
+			continue
+		}
+		fieldName, opts, _ := strings.Cut(tag, ",")
+		if fieldName == "" {
+			fieldName = field.Name
+		}
+		schema.Properties[fieldName] = b.schemaFor(field.Type)
+		if !strings.Contains(opts, "omitempty") {
+			schema.Required = append(schema.Required, fieldName)
+		}
+	}
+}
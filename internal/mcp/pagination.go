@@ -0,0 +1,84 @@
+package mcp
+
+import (
+	"encoding/base64"
+	"strconv"
+	"strings"
+)
+
+// cursorPrefix marks the opaque pagination cursors handed to clients
+const cursorPrefix = "offset:"
+
+// page is the window of a list selected by limit/offset/cursor arguments
+type page struct {
+	start, end int
+	total      int
+	nextCursor string
+}
+
+// withPagination adds the limit, offset and cursor inputs to properties
+func withPagination(properties map[string]*Schema) map[string]*Schema {
+	if properties == nil {
+		properties = make(map[string]*Schema)
+	}
+	properties["limit"] = integerProperty("Maximum number of items to return", 1, 10000)
+	properties["offset"] = integerProperty("Number of items to skip", 0, 1e9)
+	properties["cursor"] = &Schema{
+		Type:        "string",
+		Description: "Opaque cursor from a previous response's next_cursor",
+	}
+	return properties
+}
+
+// paginate selects the page of a list with total items described by args
+func paginate(args Arguments, total int) (page, error) {
+	offset := 0
+	if cursor := args.String("cursor"); cursor != "" {
+		n, err := decodeCursor(cursor)
+		if err != nil {
+			return page{}, err
+		}
+		offset = n
+	} else if n, ok, err := args.Int("offset"); err != nil {
+		return page{}, err
+	} else if ok {
+		if n < 0 {
+			return page{}, argumentErrorf("invalid offset: %d", n)
+		}
+		offset = int(n)
+	}
+
+	limit, hasLimit, err := args.Int("limit")
+	if err != nil {
+		return page{}, err
+	}
+	if hasLimit && limit < 1 {
+		return page{}, argumentErrorf("invalid limit: %d", limit)
+	}
+
+	p := page{start: offset, end: total, total: total}
+	if p.start > total {
+		p.start = total
+	}
+	if hasLimit && int64(p.start)+limit < int64(total) {
+		p.end = p.start + int(limit)
+		p.nextCursor = encodeCursor(p.end)
+	}
+	return p, nil
+}
+
+func encodeCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(cursorPrefix + strconv.Itoa(offset)))
+}
+
+func decodeCursor(cursor string) (int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || !strings.HasPrefix(string(raw), cursorPrefix) {
+		return 0, argumentErrorf("invalid cursor")
+	}
+	n, err := strconv.Atoi(strings.TrimPrefix(string(raw), cursorPrefix))
+	if err != nil || n < 0 {
+		return 0, argumentErrorf("invalid cursor")
+	}
+	return n, nil
+}
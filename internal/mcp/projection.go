@@ -0,0 +1,123 @@
+package mcp
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// withFields adds the fields projection input to properties
+func withFields(properties map[string]*Schema) map[string]*Schema {
+	if properties == nil {
+		properties = make(map[string]*Schema)
+	}
+	properties["fields"] = &Schema{
+		Type:        "array",
+		Description: "Only return these fields of each list item, e.g. pid,name",
+		Items:       &Schema{Type: "string"},
+	}
+	return properties
+}
+
+// project reduces every list item in result to the given JSON fields.
+// Fields are validated against the item struct type; non-list members of
+// the response (counts, cursors) are kept unchanged.
+func project(result interface{}, fields []string) (interface{}, error) {
+	v := reflect.Indirect(reflect.ValueOf(result))
+	if v.Kind() != reflect.Struct {
+		return result, nil
+	}
+
+	// Collect list members and the field names their items allow
+	lists := make(map[string]map[string]bool)
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		name := jsonName(field)
+		if name == "" || field.Type.Kind() != reflect.Slice {
+			continue
+		}
+		elem := field.Type.Elem()
+		for elem.Kind() == reflect.Ptr {
+			elem = elem.Elem()
+		}
+		if elem.Kind() != reflect.Struct {
+			continue
+		}
+		allowed := make(map[string]bool)
+		for j := 0; j < elem.NumField(); j++ {
+			if n := jsonName(elem.Field(j)); n != "" {
+				allowed[n] = true
+			}
+		}
+		lists[name] = allowed
+	}
+	if len(lists) == 0 {
+		return result, nil
+	}
+
+	for _, allowed := range lists {
+		for _, f := range fields {
+			if !allowed[f] {
+				return nil, argumentErrorf("unknown field %q (valid fields: %s)", f, strings.Join(sortedKeys(allowed), ","))
+			}
+		}
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+	var out map[string]interface{}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, err
+	}
+
+	for name := range lists {
+		items, ok := out[name].([]interface{})
+		if !ok {
+			continue
+		}
+		for i, item := range items {
+			obj, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			projected := make(map[string]interface{}, len(fields))
+			for _, f := range fields {
+				if val, present := obj[f]; present {
+					projected[f] = val
+				}
+			}
+			items[i] = projected
+		}
+	}
+
+	return out, nil
+}
+
+// jsonName returns the JSON member name of a struct field, or "" if the
+// field is not serialized
+func jsonName(field reflect.StructField) string {
+	if !field.IsExported() {
+		return ""
+	}
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return ""
+	}
+	name, _, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = field.Name
+	}
+	return name
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,236 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+)
+
+const (
+	// ProtocolVersion is the MCP protocol revision implemented by the server
+	ProtocolVersion = "2025-06-18"
+
+	serverName    = "gops"
+	serverVersion = "1.0.0"
+)
+
+// supportedProtocolVersions lists the revisions the server can speak
+var supportedProtocolVersions = []string{ProtocolVersion, "2025-03-26", "2024-11-05"}
+
+// JSON-RPC error codes
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+	codeInternalError  = -32603
+)
+
+// rpcRequest is a JSON-RPC 2.0 request or notification
+type rpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// isNotification reports whether the request expects no response
+func (r *rpcRequest) isNotification() bool {
+	return len(r.ID) == 0
+}
+
+// rpcResponse is a JSON-RPC 2.0 response
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+// rpcError is a JSON-RPC 2.0 error object
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// contentBlock is a piece of tool output: text, or an image with its
+// base64 data
+type contentBlock struct {
+	Type     string `json:"type"`
+	Text     string `json:"text,omitempty"`
+	Data     []byte `json:"data,omitempty"`
+	MimeType string `json:"mimeType,omitempty"`
+}
+
+// toolResult is the result of tools/call
+type toolResult struct {
+	Content           []contentBlock `json:"content"`
+	StructuredContent interface{}    `json:"structuredContent,omitempty"`
+	IsError           bool           `json:"isError,omitempty"`
+}
+
+// handleMessage decodes and dispatches a single JSON-RPC message, returning
+// the encoded response or nil for notifications
+func (s *Server) handleMessage(ctx context.Context, data []byte) []byte {
+	var req rpcRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		return encodeResponse(errorResponse(nil, codeParseError, "parse error: "+err.Error()))
+	}
+
+	resp := s.dispatch(ctx, &req)
+	if resp == nil {
+		return nil
+	}
+	return encodeResponse(resp)
+}
+
+// dispatch routes a request to the matching MCP method
+func (s *Server) dispatch(ctx context.Context, req *rpcRequest) *rpcResponse {
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		if req.isNotification() {
+			return nil
+		}
+		return errorResponse(req.ID, codeInvalidRequest, "invalid request")
+	}
+
+	var result interface{}
+	var rpcErr *rpcError
+
+	switch req.Method {
+	case "initialize":
+		result, rpcErr = s.rpcInitialize(req.Params)
+	case "ping":
+		result = struct{}{}
+	case "tools/list":
+		result = s.rpcToolsList()
+	case "tools/call":
+		result, rpcErr = s.rpcToolsCall(ctx, req.Params)
+	case "resources/list":
+		result = s.rpcResourcesList()
+	case "resources/read":
+		result, rpcErr = s.rpcResourcesRead(ctx, req.Params)
+	default:
+		if req.isNotification() {
+			// notifications/initialized and friends need no reply
+			return nil
+		}
+		rpcErr = &rpcError{Code: codeMethodNotFound, Message: "method not found: " + req.Method}
+	}
+
+	if req.isNotification() {
+		return nil
+	}
+	if rpcErr != nil {
+		return &rpcResponse{JSONRPC: "2.0", ID: req.ID, Error: rpcErr}
+	}
+	return &rpcResponse{JSONRPC: "2.0", ID: req.ID, Result: result}
+}
+
+func (s *Server) rpcInitialize(params json.RawMessage) (interface{}, *rpcError) {
+	var p struct {
+		ProtocolVersion string `json:"protocolVersion"`
+	}
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, &rpcError{Code: codeInvalidParams, Message: err.Error()}
+		}
+	}
+
+	version := ProtocolVersion
+	for _, v := range supportedProtocolVersions {
+		if v == p.ProtocolVersion {
+			version = v
+			break
+		}
+	}
+
+	return map[string]interface{}{
+		"protocolVersion": version,
+		"capabilities": map[string]interface{}{
+			"tools":     map[string]interface{}{"listChanged": false},
+			"resources": map[string]interface{}{"subscribe": false, "listChanged": false},
+		},
+		"serverInfo": map[string]string{
+			"name":    serverName,
+			"version": serverVersion,
+		},
+	}, nil
+}
+
+func (s *Server) rpcToolsList() interface{} {
+	var tools []ToolDescriptor
+	for _, t := range s.registry.List() {
+		d := t.Descriptor()
+		d.Endpoint = ""
+		d.Method = ""
+		d.Group = ""
+		tools = append(tools, d)
+	}
+	return map[string]interface{}{
+		"tools": tools,
+	}
+}
+
+func (s *Server) rpcToolsCall(ctx context.Context, params json.RawMessage) (interface{}, *rpcError) {
+	var p struct {
+		Name      string    `json:"name"`
+		Arguments Arguments `json:"arguments"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, &rpcError{Code: codeInvalidParams, Message: err.Error()}
+	}
+	t, exists := s.registry.Get(p.Name)
+	if !exists {
+		return nil, &rpcError{Code: codeInvalidParams, Message: "unknown tool: " + p.Name}
+	}
+
+	result, err := s.callTool(ctx, p.Name, p.Arguments)
+	if err != nil {
+		return toolResult{
+			Content: []contentBlock{{Type: "text", Text: err.Error()}},
+			IsError: true,
+		}, nil
+	}
+
+	if t.Media != nil {
+		if data := t.Media(result); data != nil {
+			return toolResult{
+				Content: []contentBlock{{Type: "image", Data: data, MimeType: t.MediaType}},
+			}, nil
+		}
+	}
+
+	text, err := json.Marshal(result)
+	if err != nil {
+		return nil, &rpcError{Code: codeInternalError, Message: err.Error()}
+	}
+	return toolResult{
+		Content:           []contentBlock{{Type: "text", Text: string(text)}},
+		StructuredContent: result,
+	}, nil
+}
+
+func errorResponse(id json.RawMessage, code int, message string) *rpcResponse {
+	return &rpcResponse{
+		JSONRPC: "2.0",
+		ID:      id,
+		Error:   &rpcError{Code: code, Message: message},
+	}
+}
+
+func encodeResponse(resp *rpcResponse) []byte {
+	if resp.ID == nil {
+		resp.ID = json.RawMessage("null")
+	}
+	data, err := json.Marshal(resp)
+	if err != nil {
+		data, _ = json.Marshal(errorResponse(resp.ID, codeInternalError, err.Error()))
+	}
+	return data
+}
+
+// isArgumentError reports whether err was caused by invalid tool arguments
+func isArgumentError(err error) bool {
+	var argErr *ArgumentError
+	return errors.As(err, &argErr)
+}
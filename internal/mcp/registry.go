@@ -0,0 +1,451 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ToolHandler executes a tool with the given arguments
+type ToolHandler func(ctx context.Context, args Arguments) (interface{}, error)
+
+// Tool describes a capability exposed over MCP and the REST API
+type Tool struct {
+	Name        string
+	Description string
+	InputSchema *Schema
+	// Path is the REST route serving the tool, empty if it is MCP-only
+	Path string
+	// Method is the HTTP method of the REST route, GET if empty. POST
+	// tools read their arguments from a JSON request body.
+	Method string
+	// Output is a zero value of the result type, used to document responses
+	Output interface{}
+	// MediaType, when set, is the content type of the body Media extracts
+	// from a result. REST clients receive that body instead of JSON unless
+	// they ask for format=json, and MCP clients receive it as an image.
+	// Results for which Media returns nil are sent as JSON.
+	MediaType string
+	Media     func(result interface{}) []byte
+	// NoCache disables result caching, for tools with side effects
+	NoCache bool
+	// Destructive marks tools that change system state, advertised to MCP
+	// clients so they can ask for confirmation
+	Destructive bool
+	// OptIn marks tools that stay off unless enabled by name and that web
+	// pages can't call, whatever the allowed CORS origins, such as those
+	// that start programs or read what is on screen
+	OptIn bool
+	// Collector names the data source whose health the tool reports
+	Collector string
+	// Group is the capability group the tool belongs to, used with the
+	// tool name to enable or disable it
+	Group   string
+	Handler ToolHandler
+}
+
+// ToolDescriptor is the discoverable manifest entry for a tool
+type ToolDescriptor struct {
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	InputSchema *Schema `json:"inputSchema"`
+	Endpoint    string  `json:"endpoint,omitempty"`
+	Method      string  `json:"method,omitempty"`
+	Group       string  `json:"group,omitempty"`
+	// Annotations carries MCP behaviour hints
+	Annotations *ToolAnnotations `json:"annotations,omitempty"`
+}
+
+// ToolAnnotations are the MCP hints describing a tool's side effects
+type ToolAnnotations struct {
+	ReadOnlyHint    bool `json:"readOnlyHint"`
+	DestructiveHint bool `json:"destructiveHint"`
+}
+
+// Descriptor returns the manifest entry for the tool
+func (t Tool) Descriptor() ToolDescriptor {
+	d := ToolDescriptor{
+		Name:        t.Name,
+		Description: t.Description,
+		InputSchema: t.InputSchema,
+		Endpoint:    t.Path,
+		Group:       t.Group,
+	}
+	if t.Path != "" && t.Method != "" {
+		d.Method = t.Method
+	}
+	if t.Destructive {
+		d.Annotations = &ToolAnnotations{DestructiveHint: true}
+	}
+	return d
+}
+
+// Registry holds the tools and resources known to the server
+type Registry struct {
+	tools     []Tool
+	index     map[string]int
+	resources []Resource
+}
+
+// NewRegistry creates an empty tool registry
+func NewRegistry() *Registry {
+	return &Registry{
+		index: make(map[string]int),
+	}
+}
+
+// Register adds a tool to the registry, replacing any tool with the same name
+func (r *Registry) Register(t Tool) {
+	if t.InputSchema == nil {
+		t.InputSchema = objectSchema(nil)
+	}
+	if i, exists := r.index[t.Name]; exists {
+		r.tools[i] = t
+		return
+	}
+	r.index[t.Name] = len(r.tools)
+	r.tools = append(r.tools, t)
+}
+
+// Unregister removes a tool and any resources backed by it, reporting
+// whether the tool existed
+func (r *Registry) Unregister(name string) bool {
+	i, exists := r.index[name]
+	if !exists {
+		return false
+	}
+	r.tools = append(r.tools[:i], r.tools[i+1:]...)
+	r.index = make(map[string]int, len(r.tools))
+	for j, t := range r.tools {
+		r.index[t.Name] = j
+	}
+
+	resources := r.resources[:0]
+	for _, res := range r.resources {
+		if res.Tool != name {
+			resources = append(resources, res)
+		}
+	}
+	r.resources = resources
+	return true
+}
+
+// Restrict keeps only the tools matched by enabled (all tools if it is
+// empty) and then removes those matched by disabled. Selectors are tool
+// or group names; it returns the selectors that matched nothing.
+//
+// Opt-in tools are only kept when enabled names them, not their group.
+// Naming one turns it on without restricting the other tools, so
+// enabled holding nothing but opt-in tools keeps every tool as well.
+func (r *Registry) Restrict(enabled, disabled []string) []string {
+	used := make(map[string]bool)
+	matches := func(t Tool, selectors []string) bool {
+		found := false
+		for _, sel := range selectors {
+			if sel == t.Name || sel == t.Group {
+				used[sel] = true
+				found = true
+			}
+		}
+		return found
+	}
+
+	restricted := false
+	for _, sel := range enabled {
+		if t, exists := r.Get(sel); !exists || !t.OptIn {
+			restricted = true
+		}
+	}
+
+	for _, t := range r.List() {
+		var keep bool
+		if t.OptIn {
+			keep = matches(t, filterOut(enabled, t.Group))
+		} else {
+			keep = !restricted || matches(t, enabled)
+		}
+		if matches(t, disabled) {
+			keep = false
+		}
+		if !keep {
+			r.Unregister(t.Name)
+		}
+	}
+
+	var unknown []string
+	for _, sel := range append(append([]string{}, enabled...), disabled...) {
+		if !used[sel] {
+			unknown = append(unknown, sel)
+		}
+	}
+	return unknown
+}
+
+// filterOut returns list without the entries equal to s
+func filterOut(list []string, s string) []string {
+	var out []string
+	for _, v := range list {
+		if v != s {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// Get returns the tool with the given name
+func (r *Registry) Get(name string) (Tool, bool) {
+	i, exists := r.index[name]
+	if !exists {
+		return Tool{}, false
+	}
+	return r.tools[i], true
+}
+
+// List returns all registered tools in registration order
+func (r *Registry) List() []Tool {
+	tools := make([]Tool, len(r.tools))
+	copy(tools, r.tools)
+	return tools
+}
+
+// Call validates the arguments and runs the named tool
+func (r *Registry) Call(ctx context.Context, name string, args Arguments) (interface{}, error) {
+	t, exists := r.Get(name)
+	if !exists {
+		return nil, fmt.Errorf("unknown tool: %s", name)
+	}
+	if args == nil {
+		args = Arguments{}
+	}
+	for _, req := range t.InputSchema.Required {
+		if _, present := args[req]; !present {
+			return nil, argumentErrorf("%s parameter is required", req)
+		}
+	}
+	for name, prop := range t.InputSchema.Properties {
+		if err := checkEnum(name, prop, args); err != nil {
+			return nil, err
+		}
+	}
+
+	result, err := t.Handler(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+
+	fields, err := args.StringList("fields")
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) > 0 {
+		return project(result, fields)
+	}
+	return result, nil
+}
+
+// checkEnum verifies that a string argument is one of the allowed values
+func checkEnum(name string, prop *Schema, args Arguments) error {
+	if len(prop.Enum) == 0 {
+		return nil
+	}
+	value := args.String(name)
+	if value == "" {
+		return nil
+	}
+	for _, allowed := range prop.Enum {
+		if value == allowed {
+			return nil
+		}
+	}
+	return argumentErrorf("invalid %s %q (valid values: %s)", name, value, strings.Join(prop.Enum, ","))
+}
+
+// ArgumentError reports invalid or missing tool arguments
+type ArgumentError struct {
+	msg string
+}
+
+func (e *ArgumentError) Error() string {
+	return e.msg
+}
+
+func argumentErrorf(format string, a ...interface{}) error {
+	return &ArgumentError{msg: fmt.Sprintf(format, a...)}
+}
+
+// Arguments holds the decoded arguments of a tool call
+type Arguments map[string]interface{}
+
+// String returns the string argument named key, or "" if absent
+func (a Arguments) String(key string) string {
+	switch v := a[key].(type) {
+	case string:
+		return v
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// StringList returns the list argument named key. Both JSON arrays and
+// comma-separated strings are accepted.
+func (a Arguments) StringList(key string) ([]string, error) {
+	var items []string
+	switch v := a[key].(type) {
+	case nil:
+		return nil, nil
+	case []string:
+		items = v
+	case []interface{}:
+		for _, item := range v {
+			str, ok := item.(string)
+			if !ok {
+				return nil, argumentErrorf("invalid %s: expected a list of strings", key)
+			}
+			items = append(items, str)
+		}
+	case string:
+		items = strings.Split(v, ",")
+	default:
+		return nil, argumentErrorf("invalid %s: expected a list of strings", key)
+	}
+
+	var list []string
+	for _, item := range items {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list, nil
+}
+
+// Int returns the integer argument named key; ok is false if it is absent
+func (a Arguments) Int(key string) (n int64, ok bool, err error) {
+	v, present := a[key]
+	if !present || v == nil {
+		return 0, false, nil
+	}
+	switch val := v.(type) {
+	case int64:
+		return val, true, nil
+	case int:
+		return int64(val), true, nil
+	case float64:
+		if val != math.Trunc(val) {
+			return 0, true, argumentErrorf("invalid %s: %v is not an integer", key, val)
+		}
+		return int64(val), true, nil
+	case string:
+		n, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return 0, true, argumentErrorf("invalid %s: %v", key, err)
+		}
+		return n, true, nil
+	default:
+		return 0, true, argumentErrorf("invalid %s: expected integer", key)
+	}
+}
+
+// Bool returns the boolean argument named key, false if absent
+func (a Arguments) Bool(key string) (bool, error) {
+	switch v := a[key].(type) {
+	case nil:
+		return false, nil
+	case bool:
+		return v, nil
+	case string:
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return false, argumentErrorf("invalid %s: %v", key, err)
+		}
+		return b, nil
+	default:
+		return false, argumentErrorf("invalid %s: expected boolean", key)
+	}
+}
+
+// PID returns the process ID argument named key
+func (a Arguments) PID(key string) (int32, bool, error) {
+	n, ok, err := a.Int(key)
+	if err != nil || !ok {
+		return 0, ok, err
+	}
+	if n < 0 || n > math.MaxInt32 {
+		return 0, true, argumentErrorf("invalid PID: %d", n)
+	}
+	return int32(n), true, nil
+}
+
+// Port returns the port number argument named key
+func (a Arguments) Port(key string) (uint32, bool, error) {
+	n, ok, err := a.Int(key)
+	if err != nil || !ok {
+		return 0, ok, err
+	}
+	if n < 1 || n > 65535 {
+		return 0, true, argumentErrorf("invalid port number: %d", n)
+	}
+	return uint32(n), true, nil
+}
+
+// argumentsFromBody decodes a JSON object request body into tool
+// arguments; an empty body yields no arguments
+func argumentsFromBody(body io.Reader) (Arguments, error) {
+	args := Arguments{}
+	if err := json.NewDecoder(body).Decode(&args); err != nil && err != io.EOF {
+		return nil, argumentErrorf("invalid request body: %v", err)
+	}
+	return args, nil
+}
+
+// argumentsFromQuery converts URL query parameters into tool arguments
+// using the property types declared in the schema
+func argumentsFromQuery(query url.Values, schema *Schema) (Arguments, error) {
+	args := Arguments{}
+	if schema == nil {
+		return args, nil
+	}
+	for name, prop := range schema.Properties {
+		value := query.Get(name)
+		if value == "" {
+			continue
+		}
+		switch prop.Type {
+		case "integer":
+			n, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil, argumentErrorf("invalid %s: %v", name, err)
+			}
+			args[name] = n
+		case "number":
+			f, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, argumentErrorf("invalid %s: %v", name, err)
+			}
+			args[name] = f
+		case "boolean":
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, argumentErrorf("invalid %s: %v", name, err)
+			}
+			args[name] = b
+		case "array":
+			var items []interface{}
+			for _, item := range strings.Split(value, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					items = append(items, item)
+				}
+			}
+			args[name] = items
+		default:
+			args[name] = value
+		}
+	}
+	return args, nil
+}
@@ -0,0 +1,38 @@
+package mcp
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRestrictOptIn(t *testing.T) {
+	tests := []struct {
+		name              string
+		enabled, disabled []string
+		want              []string
+	}{
+		{"default", nil, nil, []string{"list", "kill"}},
+		{"opt-in by name", []string{"launch"}, nil, []string{"list", "kill", "launch"}},
+		{"opt-in with group", []string{"control", "launch"}, nil, []string{"kill", "launch"}},
+		{"group alone", []string{"control"}, nil, []string{"kill"}},
+		{"opt-in disabled", []string{"launch"}, []string{"control"}, []string{"list"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRegistry()
+			r.Register(Tool{Name: "list", Group: "processes"})
+			r.Register(Tool{Name: "kill", Group: "control"})
+			r.Register(Tool{Name: "launch", Group: "control", OptIn: true})
+			if unknown := r.Restrict(tt.enabled, tt.disabled); len(unknown) > 0 {
+				t.Fatalf("unknown selectors: %v", unknown)
+			}
+			var got []string
+			for _, tool := range r.List() {
+				got = append(got, tool.Name)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("tools = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
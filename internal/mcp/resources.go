@@ -0,0 +1,148 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/borankux/gops/internal/system"
+)
+
+// codeResourceNotFound is the MCP error code for an unknown resource URI
+const codeResourceNotFound = -32002
+
+// ResourceReader produces the current contents of a resource
+type ResourceReader func(ctx context.Context) (interface{}, error)
+
+// Resource is a read-only JSON snapshot exposed via resources/read
+type Resource struct {
+	URI         string `json:"uri"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType"`
+
+	// Tool names the tool the resource is read through, if any
+	Tool string         `json:"-"`
+	Read ResourceReader `json:"-"`
+}
+
+// resourceContents is a single entry of a resources/read result
+type resourceContents struct {
+	URI      string `json:"uri"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+// RegisterResource adds a resource to the registry
+func (r *Registry) RegisterResource(res Resource) {
+	if res.MimeType == "" {
+		res.MimeType = "application/json"
+	}
+	for i, existing := range r.resources {
+		if existing.URI == res.URI {
+			r.resources[i] = res
+			return
+		}
+	}
+	r.resources = append(r.resources, res)
+}
+
+// Resources returns all registered resources
+func (r *Registry) Resources() []Resource {
+	resources := make([]Resource, len(r.resources))
+	copy(resources, r.resources)
+	return resources
+}
+
+// Resource returns the resource with the given URI
+func (r *Registry) Resource(uri string) (Resource, bool) {
+	for _, res := range r.resources {
+		if res.URI == uri {
+			return res, true
+		}
+	}
+	return Resource{}, false
+}
+
+// registerSnapshotResources exposes system snapshots as MCP resources
+func registerSnapshotResources(r *Registry) {
+	fromTool := func(name string) ResourceReader {
+		return func(ctx context.Context) (interface{}, error) {
+			return r.Call(ctx, name, nil)
+		}
+	}
+
+	r.RegisterResource(Resource{
+		URI:         "gops://processes",
+		Name:        "processes",
+		Description: "Running user applications",
+		Tool:        "list_processes",
+		Read:        fromTool("list_processes"),
+	})
+	r.RegisterResource(Resource{
+		URI:         "gops://windows",
+		Name:        "windows",
+		Description: "Open windows and their owning processes",
+		Tool:        "list_windows",
+		Read:        fromTool("list_windows"),
+	})
+	r.RegisterResource(Resource{
+		URI:         "gops://ports",
+		Name:        "ports",
+		Description: "Listening ports and their owning processes",
+		Tool:        "list_ports",
+		Read:        fromTool("list_ports"),
+	})
+	r.RegisterResource(Resource{
+		URI:         "gops://services",
+		Name:        "services",
+		Description: "System services with status and resource usage",
+		Tool:        "list_services",
+		Read:        fromTool("list_services"),
+	})
+	r.RegisterResource(Resource{
+		URI:         "gops://system",
+		Name:        "system",
+		Description: "Host identity, platform and uptime",
+		Read: func(ctx context.Context) (interface{}, error) {
+			return system.GetSystemInfo(ctx)
+		},
+	})
+}
+
+func (s *Server) rpcResourcesList() interface{} {
+	return map[string]interface{}{
+		"resources": s.registry.Resources(),
+	}
+}
+
+func (s *Server) rpcResourcesRead(ctx context.Context, params json.RawMessage) (interface{}, *rpcError) {
+	var p struct {
+		URI string `json:"uri"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, &rpcError{Code: codeInvalidParams, Message: err.Error()}
+	}
+
+	res, exists := s.registry.Resource(p.URI)
+	if !exists {
+		return nil, &rpcError{Code: codeResourceNotFound, Message: "resource not found: " + p.URI}
+	}
+
+	snapshot, err := res.Read(ctx)
+	if err != nil {
+		return nil, &rpcError{Code: codeInternalError, Message: err.Error()}
+	}
+
+	text, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, &rpcError{Code: codeInternalError, Message: err.Error()}
+	}
+
+	return map[string]interface{}{
+		"contents": []resourceContents{{
+			URI:      res.URI,
+			MimeType: res.MimeType,
+			Text:     string(text),
+		}},
+	}, nil
+}
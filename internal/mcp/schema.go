@@ -0,0 +1,77 @@
+package mcp
+
+// Schema is the subset of JSON Schema used to describe tool inputs and
+// API responses
+type Schema struct {
+	Ref         string             `json:"$ref,omitempty"`
+	Type        string             `json:"type,omitempty"`
+	Format      string             `json:"format,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Properties  map[string]*Schema `json:"properties,omitempty"`
+	Required    []string           `json:"required,omitempty"`
+	Items       *Schema            `json:"items,omitempty"`
+	Enum        []string           `json:"enum,omitempty"`
+	Minimum     *float64           `json:"minimum,omitempty"`
+	Maximum     *float64           `json:"maximum,omitempty"`
+
+	AdditionalProperties *Schema `json:"additionalProperties,omitempty"`
+}
+
+// objectSchema builds an object schema from its properties
+func objectSchema(properties map[string]*Schema, required ...string) *Schema {
+	return &Schema{
+		Type:       "object",
+		Properties: properties,
+		Required:   required,
+	}
+}
+
+// integerProperty describes an integer input bounded by min and max
+func integerProperty(description string, min, max float64) *Schema {
+	return &Schema{
+		Type:        "integer",
+		Description: description,
+		Minimum:     &min,
+		Maximum:     &max,
+	}
+}
+
+// pidProperty describes a process ID input
+func pidProperty(description string) *Schema {
+	return integerProperty(description, 0, 2147483647)
+}
+
+// portProperty describes a TCP/UDP port number input
+func portProperty(description string) *Schema {
+	return integerProperty(description, 1, 65535)
+}
+
+// withSorting adds the sort and order inputs to properties
+func withSorting(properties map[string]*Schema, keys []string) map[string]*Schema {
+	if properties == nil {
+		properties = make(map[string]*Schema)
+	}
+	properties["sort"] = &Schema{
+		Type:        "string",
+		Description: "Sort key",
+		Enum:        keys,
+	}
+	properties["order"] = &Schema{
+		Type:        "string",
+		Description: "Sort order; cpu, memory and uptime default to desc, other keys to asc",
+		Enum:        []string{"asc", "desc"},
+	}
+	return properties
+}
+
+// withWindowQuery adds the id, pid and title inputs selecting a window to
+// properties
+func withWindowQuery(properties map[string]*Schema) map[string]*Schema {
+	if properties == nil {
+		properties = make(map[string]*Schema)
+	}
+	properties["id"] = integerProperty("Window ID from list_windows", 1, 4294967295)
+	properties["pid"] = pidProperty("Process owning the window")
+	properties["title"] = &Schema{Type: "string", Description: "Text the window title contains, ignoring case"}
+	return properties
+}
@@ -3,190 +3,382 @@ package mcp
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
+	"log/slog"
+	"net"
 	"net/http"
+	"net/url"
+	"os"
 	"strconv"
+	"sync"
+	"time"
 
+	"github.com/borankux/gops/internal/events"
+	"github.com/borankux/gops/internal/permission"
 	"github.com/borankux/gops/internal/port"
 	"github.com/borankux/gops/internal/process"
-	"github.com/borankux/gops/internal/resource"
 	"github.com/borankux/gops/internal/service"
+	"github.com/borankux/gops/internal/watch"
+	"github.com/borankux/gops/internal/webhook"
 	"github.com/borankux/gops/internal/window"
 	"github.com/borankux/gops/pkg/types"
 )
 
+// DefaultBind keeps the server reachable only from the local machine
+const DefaultBind = "127.0.0.1"
+
+// Config holds the MCP server settings
+type Config struct {
+	// Bind is the address the HTTP server listens on; use "0.0.0.0" or
+	// "::" to accept connections on every interface
+	Bind string
+	// Port is the TCP port the HTTP server listens on
+	Port int
+	// AuthToken, when set, is required as a bearer token on API routes
+	AuthToken string
+	// AccessLog is where JSON access logs go: "stdout" (default),
+	// "stderr", "off", or a file path
+	AccessLog string
+	// RateLimit is the sustained requests per second allowed per client
+	// address; zero disables rate limiting
+	RateLimit float64
+	// RateBurst is the number of requests a client may make at once
+	RateBurst int
+	// CORSOrigins lists the browser origins allowed to call the API. "*"
+	// allows any origin; an empty list disables CORS headers entirely.
+	CORSOrigins []string
+	// CacheTTL is how long identical tool calls reuse a result; zero
+	// disables caching
+	CacheTTL time.Duration
+	// CollectorTimeout bounds how long a single tool call may run; zero
+	// means no limit
+	CollectorTimeout time.Duration
+	// EnabledTools, when set, limits the server to these tools or tool
+	// groups; DisabledTools then removes tools or groups. Restricted tools
+	// are absent from MCP and the REST API alike.
+	EnabledTools  []string
+	DisabledTools []string
+	// WatchProcesses and WatchPorts limit process and port events to
+	// these process names and port numbers
+	WatchProcesses []string
+	WatchPorts     []uint32
+	// ProcessFilter adjusts which processes list_processes reports as
+	// user applications; calls may override each list
+	ProcessFilter process.FilterRules
+	// Webhooks are registered at startup in addition to those added
+	// through the API
+	Webhooks []types.Webhook
+	// CPUThreshold publishes process.cpu_high events when a process rises
+	// above this CPU percentage; zero disables CPU monitoring
+	CPUThreshold float64
+	// ShutdownTimeout bounds how long Stop waits for in-flight requests
+	// and streams to drain; zero waits as long as the Stop context allows
+	ShutdownTimeout time.Duration
+	// PortHistory, when set, records listening ports over time from the
+	// moment the server starts
+	PortHistory *watch.PortHistory
+	// GeoIP, when set, lets list_connections locate remote addresses
+	GeoIP *port.GeoIP
+	// WatchdogTargets, when set, are kept running from the moment the
+	// server starts, with their watchdog events sent to clients and
+	// webhooks
+	WatchdogTargets []watch.WatchdogTarget
+	Watchdog        watch.WatchdogOptions
+}
+
 // Server represents the MCP server
 type Server struct {
-	port   int
-	server *http.Server
+	config   Config
+	server   *http.Server
+	registry *Registry
+	sessions *sessionStore
+
+	bus       *events.Bus
+	watchOnce sync.Once
+	webhooks  *webhook.Manager
+	titles    *watch.TitleHistory
+
+	accessLog       *slog.Logger
+	accessLogCloser io.Closer
+	limiter         *rateLimiter
+	cache           *resultCache
+	health          *healthTracker
+
+	// lifetime is cancelled when the server stops, ending background work
+	// and telling open streams to close
+	lifetime context.Context
+	shutdown context.CancelFunc
+	// streams tracks SSE and WebSocket connections so Stop can wait for
+	// them to say goodbye; hijacked connections are invisible to Shutdown
+	streams sync.WaitGroup
 }
 
 // NewServer creates a new MCP server
-func NewServer(port int) *Server {
-	return &Server{
-		port: port,
+func NewServer(config Config) *Server {
+	lifetime, shutdown := context.WithCancel(context.Background())
+	var limiter *rateLimiter
+	if config.RateLimit > 0 {
+		limiter = newRateLimiter(config.RateLimit, config.RateBurst)
+	}
+	var cache *resultCache
+	if config.CacheTTL > 0 {
+		cache = newResultCache(config.CacheTTL)
+	}
+	bus := events.NewBus()
+	hooks := webhook.NewManager(bus)
+	for _, hook := range config.Webhooks {
+		if _, err := hooks.Add(hook); err != nil {
+			log.Printf("⚠️  Skipping webhook: %v", err)
+		}
+	}
+	s := &Server{
+		config:   config,
+		registry: DefaultRegistry(config),
+		sessions: newSessionStore(),
+		bus:      bus,
+		webhooks: hooks,
+		titles:   watch.NewTitleHistory(0),
+		limiter:  limiter,
+		cache:    cache,
+		health:   newHealthTracker(),
+		lifetime: lifetime,
+		shutdown: shutdown,
 	}
+	s.registerWatcherTools()
+	for _, sel := range s.registry.Restrict(config.EnabledTools, config.DisabledTools) {
+		log.Printf("⚠️  Unknown tool or group %q", sel)
+	}
+	return s
 }
 
 // Start starts the MCP server
 func (s *Server) Start() error {
 	mux := http.NewServeMux()
 
-	// MCP protocol endpoints with CORS support
-	mux.HandleFunc("/mcp/v1/processes", s.corsMiddleware(s.handleProcesses))
-	mux.HandleFunc("/mcp/v1/windows", s.corsMiddleware(s.handleWindows))
-	mux.HandleFunc("/mcp/v1/ports", s.corsMiddleware(s.handlePorts))
-	mux.HandleFunc("/mcp/v1/resource", s.corsMiddleware(s.handleResource))
-	mux.HandleFunc("/mcp/v1/services", s.corsMiddleware(s.handleServices))
+	// MCP protocol endpoints with CORS support, optional authentication
+	// and response compression. Every v2 route is also served under v1.
+	routes := map[string]http.HandlerFunc{
+		apiV2Prefix + "tools":     s.handleTools,
+		apiV2Prefix + "events":    s.handleEvents,
+		apiV2Prefix + "batch":     s.handleBatch,
+		apiV2Prefix + "webhooks":  s.handleWebhooks,
+		apiV2Prefix + "webhooks/": s.handleWebhook,
+	}
+	if _, enabled := s.registry.Get("get_resource_usage"); enabled {
+		routes[apiV2Prefix+"resource/stream"] = s.handleResourceStream
+	}
+	templates := make(map[string][]pathTemplate)
+	for _, t := range s.registry.List() {
+		switch {
+		case t.Path == "":
+		case isTemplate(t.Path):
+			prefix := templatePrefix(t.Path)
+			templates[prefix] = append(templates[prefix], newPathTemplate(t))
+		default:
+			routes[t.Path] = s.handleTool(t)
+		}
+	}
+	for prefix, tmpls := range templates {
+		routes[prefix] = s.handleTemplates(tmpls)
+	}
+	for path, handler := range routes {
+		mux.HandleFunc(path, s.api(handler))
+		mux.HandleFunc(v1Path(path), s.api(handler))
+	}
+	mux.HandleFunc("/mcp", s.api(s.handleStreamable))
+	mux.HandleFunc("/ws", s.rateLimitMiddleware(s.authMiddleware(s.handleWebSocket)))
 	mux.HandleFunc("/health", s.corsMiddleware(s.handleHealth))
+	mux.HandleFunc("/healthz", s.handleLiveness)
+	mux.HandleFunc("/readyz", s.handleReadiness)
+	mux.HandleFunc("/openapi.json", s.corsMiddleware(s.compressMiddleware(s.handleOpenAPI)))
+
+	out, closer, err := openAccessLog(s.config.AccessLog)
+	if err != nil {
+		return fmt.Errorf("opening access log: %w", err)
+	}
+	if out != nil {
+		s.accessLog = slog.New(slog.NewJSONHandler(out, nil))
+		s.accessLogCloser = closer
+	}
+
+	go s.webhooks.Run(s.lifetime)
+	if s.webhooks.Len() > 0 || s.config.PortHistory != nil {
+		s.startWatcher()
+	}
+	if len(s.config.WatchdogTargets) > 0 {
+		watchdog, err := watch.NewWatchdog(s.bus, s.config.WatchdogTargets, s.config.Watchdog)
+		if err != nil {
+			return err
+		}
+		go watchdog.Run(s.lifetime)
+		log.Printf("🐕 Watchdog keeping %d services and processes running", len(s.config.WatchdogTargets))
+	}
 
 	s.server = &http.Server{
-		Addr:    fmt.Sprintf(":%d", s.port),
-		Handler: mux,
+		Addr:    s.Addr(),
+		Handler: s.loggingMiddleware(mux),
 	}
 
-	log.Printf("🚀 MCP Server starting on port %d", s.port)
+	if s.config.AuthToken != "" {
+		log.Printf("🔒 Bearer token authentication enabled")
+	} else if !isLoopback(s.config.Bind) {
+		log.Printf("⚠️  Listening on %s without authentication; anyone on the network can query this host", s.config.Bind)
+	}
+	log.Printf("🚀 MCP Server starting on %s", s.Addr())
 	return s.server.ListenAndServe()
 }
 
-// Stop stops the MCP server
-func (s *Server) Stop(ctx context.Context) error {
-	if s.server != nil {
-		return s.server.Shutdown(ctx)
+// Addr returns the address the server listens on
+func (s *Server) Addr() string {
+	bind := s.config.Bind
+	if bind == "" {
+		bind = DefaultBind
 	}
-	return nil
+	return net.JoinHostPort(bind, strconv.Itoa(s.config.Port))
 }
 
-func (s *Server) handleProcesses(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
-	w.Header().Set("Content-Type", "application/json")
-
-	procs, err := process.GetUserApplications(ctx)
-	if err != nil {
-		s.sendError(w, err)
-		return
+// isLoopback reports whether a bind address only accepts local connections
+func isLoopback(bind string) bool {
+	if bind == "" || bind == "localhost" {
+		return true
 	}
+	ip := net.ParseIP(bind)
+	return ip != nil && ip.IsLoopback()
+}
 
-	response := types.ProcessesResponse{
-		Processes: procs,
-		Count:     len(procs),
+// Stop gracefully stops the MCP server. Streaming clients are sent a
+// close event, new connections are refused, and in-flight requests are
+// allowed to finish until ShutdownTimeout elapses, after which remaining
+// connections are closed.
+func (s *Server) Stop(ctx context.Context) error {
+	if s.config.ShutdownTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.config.ShutdownTimeout)
+		defer cancel()
 	}
 
-	s.sendJSON(w, response)
-}
-
-func (s *Server) handleWindows(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
-	w.Header().Set("Content-Type", "application/json")
+	s.shutdown()
+	var err error
+	if s.server != nil {
+		err = s.server.Shutdown(ctx)
+	}
 
-	windows, err := window.GetOpenWindows(ctx)
-	if err != nil {
-		s.sendError(w, err)
-		return
+	drained := make(chan struct{})
+	go func() {
+		s.streams.Wait()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		if err == nil {
+			err = ctx.Err()
+		}
+	}
+	if err != nil && s.server != nil {
+		s.server.Close()
 	}
 
-	response := types.WindowsResponse{
-		Windows: windows,
-		Count:   len(windows),
+	if s.accessLogCloser != nil {
+		s.accessLogCloser.Close()
 	}
+	if s.config.PortHistory != nil {
+		if saveErr := s.config.PortHistory.Save(); saveErr != nil {
+			log.Printf("⚠️  Saving port history: %v", saveErr)
+		}
+	}
+	return err
+}
 
-	s.sendJSON(w, response)
+// startWatcher starts polling for system events the first time a client
+// or webhook subscribes to them
+func (s *Server) startWatcher() {
+	s.watchOnce.Do(func() {
+		w := watch.New(s.bus, watch.Options{
+			CPUThreshold: s.config.CPUThreshold,
+			Processes:    s.config.WatchProcesses,
+			Ports:        s.config.WatchPorts,
+			Titles:       s.titles,
+			PortHistory:  s.config.PortHistory,
+		})
+		go w.Run(s.lifetime)
+	})
 }
 
-func (s *Server) handlePorts(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
-	w.Header().Set("Content-Type", "application/json")
+// handleTool serves a registered tool over REST, reading its arguments
+// from the query string
+func (s *Server) handleTool(t Tool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.serveTool(w, r, t, r.URL.Query())
+	}
+}
 
-	portParam := r.URL.Query().Get("port")
-	pidParam := r.URL.Query().Get("pid")
+// serveTool runs t for a REST request. GET tools take their arguments
+// from query; POST tools from the JSON request body.
+func (s *Server) serveTool(w http.ResponseWriter, r *http.Request, t Tool, query url.Values) {
+	w.Header().Set("Content-Type", "application/json")
 
-	var ports []types.PortInfo
+	var args Arguments
 	var err error
-
-	if portParam != "" {
-		portNum, parseErr := strconv.ParseUint(portParam, 10, 32)
-		if parseErr != nil {
-			s.sendError(w, fmt.Errorf("invalid port number: %w", parseErr))
+	if t.Method == http.MethodPost {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", "POST")
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			json.NewEncoder(w).Encode(types.ErrorResponse{Error: "method not allowed"})
 			return
 		}
-		ports, err = port.GetPortInfoByPort(ctx, uint32(portNum))
-	} else if pidParam != "" {
-		pid, parseErr := strconv.ParseInt(pidParam, 10, 32)
-		if parseErr != nil {
-			s.sendError(w, fmt.Errorf("invalid PID: %w", parseErr))
+		if !requireJSON(w, r) {
 			return
 		}
-		ports, err = port.GetPortsByPID(ctx, int32(pid))
+		args, err = argumentsFromBody(http.MaxBytesReader(w, r.Body, maxMessageSize))
+		if err == nil && isTemplate(t.Path) {
+			err = setPathArguments(args, query, t)
+		}
 	} else {
-		ports, err = port.GetOpenPorts(ctx)
-	}
-
-	if err != nil {
-		s.sendError(w, err)
-		return
+		args, err = argumentsFromQuery(query, t.InputSchema)
 	}
-
-	response := types.PortsResponse{
-		Ports: ports,
-		Count: len(ports),
-	}
-
-	s.sendJSON(w, response)
-}
-
-func (s *Server) handleResource(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
-	w.Header().Set("Content-Type", "application/json")
-
-	pidParam := r.URL.Query().Get("pid")
-	if pidParam == "" {
-		s.sendError(w, fmt.Errorf("pid parameter is required"))
-		return
-	}
-
-	pid, err := strconv.ParseInt(pidParam, 10, 32)
 	if err != nil {
-		s.sendError(w, fmt.Errorf("invalid PID: %w", err))
+		s.sendError(w, r, err)
 		return
 	}
 
-	usage, err := resource.GetProcessResourceUsage(ctx, int32(pid))
+	result, err := s.callTool(r.Context(), t.Name, args)
 	if err != nil {
-		s.sendError(w, err)
+		s.sendError(w, r, err)
 		return
 	}
 
-	response := types.ResourceResponse{
-		Usage: *usage,
+	if t.Media != nil && query.Get("format") != "json" {
+		if data := t.Media(result); data != nil {
+			w.Header().Set("Content-Type", t.MediaType)
+			w.WriteHeader(http.StatusOK)
+			w.Write(data)
+			return
+		}
 	}
-
-	s.sendJSON(w, response)
+	s.sendJSON(w, withSchemaVersion(result, apiVersion(r)))
 }
 
-func (s *Server) handleServices(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
+func (s *Server) handleTools(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
-	services, err := service.GetServices(ctx)
-	if err != nil {
-		s.sendError(w, err)
-		return
-	}
-
-	response := types.ServicesResponse{
-		Services: services,
-		Count:    len(services),
+	version := apiVersion(r)
+	var tools []ToolDescriptor
+	for _, t := range s.registry.List() {
+		d := t.Descriptor()
+		if version == 0 && d.Endpoint != "" {
+			d.Endpoint = v1Path(d.Endpoint)
+		}
+		tools = append(tools, d)
 	}
 
-	s.sendJSON(w, response)
-}
-
-func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	io.WriteString(w, `{"status":"healthy"}`)
+	s.sendJSON(w, withSchemaVersion(map[string]interface{}{
+		"tools": tools,
+		"count": len(tools),
+	}, version))
 }
 
 func (s *Server) sendJSON(w http.ResponseWriter, data interface{}) {
@@ -196,26 +388,43 @@ func (s *Server) sendJSON(w http.ResponseWriter, data interface{}) {
 	}
 }
 
-func (s *Server) sendError(w http.ResponseWriter, err error) {
-	w.WriteHeader(http.StatusInternalServerError)
+func (s *Server) sendError(w http.ResponseWriter, r *http.Request, err error) {
+	w.WriteHeader(errorStatus(err))
 	response := types.ErrorResponse{
-		Error: err.Error(),
+		Error:              err.Error(),
+		PermissionRequired: permissionRequired(err),
 	}
-	json.NewEncoder(w).Encode(response)
+	json.NewEncoder(w).Encode(withSchemaVersion(response, apiVersion(r)))
 }
 
-// corsMiddleware adds CORS headers to responses
-func (s *Server) corsMiddleware(next http.HandlerFunc) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
-
-		if r.Method == "OPTIONS" {
-			w.WriteHeader(http.StatusOK)
-			return
-		}
+// errorStatus maps a tool error to its HTTP status code
+func errorStatus(err error) int {
+	switch {
+	case isArgumentError(err):
+		return http.StatusBadRequest
+	case errors.Is(err, process.ErrProtected), errors.Is(err, os.ErrPermission), permissionRequired(err) != "":
+		return http.StatusForbidden
+	case errors.Is(err, process.ErrNotFound), errors.Is(err, process.ErrSnapshotNotFound), errors.Is(err, process.ErrNoIcon),
+		errors.Is(err, window.ErrNotFound), errors.Is(err, window.ErrNoDisplay), errors.Is(err, service.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, window.ErrUnsupported):
+		return http.StatusNotImplemented
+	default:
+		return http.StatusInternalServerError
+	}
+}
 
-		next(w, r)
+// permissionRequired returns the macOS permission whose absence caused
+// err, if any
+func permissionRequired(err error) string {
+	var permErr *permission.Error
+	if errors.As(err, &permErr) {
+		return permErr.Permission
 	}
+	return ""
+}
+
+// api wraps an API handler with the standard middleware chain
+func (s *Server) api(next http.HandlerFunc) http.HandlerFunc {
+	return s.corsMiddleware(s.rateLimitMiddleware(s.authMiddleware(s.compressMiddleware(next))))
 }
@@ -0,0 +1,47 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+)
+
+// maxMessageSize bounds a single newline-delimited JSON-RPC message
+const maxMessageSize = 4 << 20
+
+// ServeStdio serves MCP over newline-delimited JSON-RPC on in/out until
+// in is closed or ctx is cancelled
+func (s *Server) ServeStdio(ctx context.Context, in io.Reader, out io.Writer) error {
+	if s.config.PortHistory != nil {
+		s.startWatcher()
+		defer s.config.PortHistory.Save()
+	}
+
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 64*1024), maxMessageSize)
+	writer := bufio.NewWriter(out)
+
+	for scanner.Scan() {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		resp := s.handleMessage(ctx, line)
+		if resp == nil {
+			continue
+		}
+		writer.Write(resp)
+		writer.WriteByte('\n')
+		if err := writer.Flush(); err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
@@ -0,0 +1,87 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/borankux/gops/internal/resource"
+	"github.com/borankux/gops/pkg/types"
+)
+
+const (
+	defaultStreamInterval = time.Second
+	minStreamInterval     = 100 * time.Millisecond
+)
+
+// handleResourceStream pushes ResourceUsage samples for a process over SSE
+// every interval until the client disconnects or the process exits
+func (s *Server) handleResourceStream(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	pidParam := query.Get("pid")
+	if pidParam == "" {
+		w.Header().Set("Content-Type", "application/json")
+		s.sendError(w, r, argumentErrorf("pid parameter is required"))
+		return
+	}
+	pid, err := strconv.ParseInt(pidParam, 10, 32)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		s.sendError(w, r, argumentErrorf("invalid PID: %v", err))
+		return
+	}
+
+	interval := defaultStreamInterval
+	if v := query.Get("interval"); v != "" {
+		interval, err = time.ParseDuration(v)
+		if err != nil || interval < minStreamInterval {
+			w.Header().Set("Content-Type", "application/json")
+			s.sendError(w, r, argumentErrorf("invalid interval: must be a duration of at least %s", minStreamInterval))
+			return
+		}
+	}
+
+	ctx := r.Context()
+	sampler, err := resource.NewSampler(ctx, int32(pid))
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		s.sendError(w, r, err)
+		return
+	}
+
+	s.streams.Add(1)
+	defer s.streams.Done()
+	startSSE(w)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.lifetime.Done():
+			writeSSE(w, "close", []byte(`{"reason":"server shutting down"}`))
+			return
+		case <-ticker.C:
+			usage, err := sampler.Sample(ctx)
+			if err != nil {
+				data, _ := json.Marshal(types.ErrorResponse{
+					Error: fmt.Sprintf("process %d: %v", pid, err),
+				})
+				writeSSE(w, "error", data)
+				return
+			}
+			data, err := json.Marshal(usage)
+			if err != nil {
+				return
+			}
+			if err := writeSSE(w, "usage", data); err != nil {
+				return
+			}
+		}
+	}
+}
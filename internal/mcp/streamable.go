@@ -0,0 +1,195 @@
+package mcp
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// sessionHeader carries the Streamable HTTP session ID
+	sessionHeader = "Mcp-Session-Id"
+
+	// sessionIdleTimeout is how long an unused session is kept around
+	sessionIdleTimeout = time.Hour
+)
+
+// sessionStore tracks Streamable HTTP sessions created by initialize
+type sessionStore struct {
+	mu       sync.Mutex
+	sessions map[string]time.Time
+}
+
+func newSessionStore() *sessionStore {
+	return &sessionStore{
+		sessions: make(map[string]time.Time),
+	}
+}
+
+// create starts a new session and prunes idle ones
+func (st *sessionStore) create() string {
+	buf := make([]byte, 16)
+	rand.Read(buf)
+	id := hex.EncodeToString(buf)
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	now := time.Now()
+	for sid, lastSeen := range st.sessions {
+		if now.Sub(lastSeen) > sessionIdleTimeout {
+			delete(st.sessions, sid)
+		}
+	}
+	st.sessions[id] = now
+	return id
+}
+
+// touch marks a session as used, reporting whether it exists
+func (st *sessionStore) touch(id string) bool {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if _, exists := st.sessions[id]; !exists {
+		return false
+	}
+	st.sessions[id] = time.Now()
+	return true
+}
+
+// remove terminates a session, reporting whether it existed
+func (st *sessionStore) remove(id string) bool {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if _, exists := st.sessions[id]; !exists {
+		return false
+	}
+	delete(st.sessions, id)
+	return true
+}
+
+// handleStreamable implements the MCP Streamable HTTP transport. The
+// transport requires servers to validate Origin themselves, so it is
+// checked here as well as in corsMiddleware.
+func (s *Server) handleStreamable(w http.ResponseWriter, r *http.Request) {
+	if !s.checkOrigin(r) {
+		http.Error(w, "origin not allowed: "+r.Header.Get("Origin"), http.StatusForbidden)
+		return
+	}
+	switch r.Method {
+	case http.MethodPost:
+		s.handleStreamablePost(w, r)
+	case http.MethodDelete:
+		id := r.Header.Get(sessionHeader)
+		if id == "" {
+			http.Error(w, "missing "+sessionHeader+" header", http.StatusBadRequest)
+			return
+		}
+		if !s.sessions.remove(id) {
+			http.Error(w, "unknown session", http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		// No server-initiated stream is offered over GET
+		w.Header().Set("Allow", "POST, DELETE")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) handleStreamablePost(w http.ResponseWriter, r *http.Request) {
+	if !requireJSON(w, r) {
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxMessageSize))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var messages []json.RawMessage
+	body = bytes.TrimSpace(body)
+	batch := len(body) > 0 && body[0] == '['
+	if batch {
+		if err := json.Unmarshal(body, &messages); err != nil {
+			writeRPCError(w, codeParseError, "parse error: "+err.Error())
+			return
+		}
+	} else {
+		messages = []json.RawMessage{body}
+	}
+
+	requests := make([]*rpcRequest, 0, len(messages))
+	initializing := false
+	for _, msg := range messages {
+		var req rpcRequest
+		if err := json.Unmarshal(msg, &req); err != nil {
+			writeRPCError(w, codeParseError, "parse error: "+err.Error())
+			return
+		}
+		if req.Method == "initialize" {
+			initializing = true
+		}
+		requests = append(requests, &req)
+	}
+
+	sessionID := r.Header.Get(sessionHeader)
+	if initializing {
+		sessionID = s.sessions.create()
+		w.Header().Set(sessionHeader, sessionID)
+	} else if sessionID == "" {
+		http.Error(w, "missing "+sessionHeader+" header", http.StatusBadRequest)
+		return
+	} else if !s.sessions.touch(sessionID) {
+		http.Error(w, "unknown session", http.StatusNotFound)
+		return
+	}
+
+	var responses []*rpcResponse
+	for _, req := range requests {
+		if resp := s.dispatch(r.Context(), req); resp != nil {
+			responses = append(responses, resp)
+		}
+	}
+
+	// Notifications and client responses are acknowledged without a body
+	if len(responses) == 0 {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	if acceptsEventStream(r) {
+		startSSE(w)
+		for _, resp := range responses {
+			writeSSE(w, "message", encodeResponse(resp))
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if !batch {
+		w.Write(encodeResponse(responses[0]))
+		return
+	}
+	encoded := make([]json.RawMessage, len(responses))
+	for i, resp := range responses {
+		encoded[i] = encodeResponse(resp)
+	}
+	json.NewEncoder(w).Encode(encoded)
+}
+
+// acceptsEventStream reports whether the client accepts an SSE response
+func acceptsEventStream(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), "text/event-stream")
+}
+
+func writeRPCError(w http.ResponseWriter, code int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	w.Write(encodeResponse(errorResponse(nil, code, message)))
+}
@@ -0,0 +1,113 @@
+package mcp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/borankux/gops/pkg/types"
+)
+
+// pathTemplate is a tool path with {name} segments, such as
+// /mcp/v2/process/{pid}/files, whose values become tool arguments
+type pathTemplate struct {
+	tool     Tool
+	segments []string
+}
+
+// isTemplate reports whether path has {name} segments
+func isTemplate(path string) bool {
+	return strings.Contains(path, "{")
+}
+
+// templatePrefix returns the fixed part of a template path up to its
+// first parameter, which is registered with the mux as a subtree
+func templatePrefix(path string) string {
+	return path[:strings.LastIndex(path[:strings.Index(path, "{")], "/")+1]
+}
+
+// pathParameters returns the names of the {name} segments in path
+func pathParameters(path string) []string {
+	var names []string
+	for _, seg := range strings.Split(path, "/") {
+		if strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}") {
+			names = append(names, seg[1:len(seg)-1])
+		}
+	}
+	return names
+}
+
+// match returns the parameters captured from the request path rest,
+// relative to the API version prefix, or false if it does not match
+func (t pathTemplate) match(rest string) (map[string]string, bool) {
+	parts := strings.Split(strings.Trim(rest, "/"), "/")
+	if len(parts) != len(t.segments) {
+		return nil, false
+	}
+	params := make(map[string]string)
+	for i, seg := range t.segments {
+		if strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}") {
+			if parts[i] == "" {
+				return nil, false
+			}
+			params[seg[1:len(seg)-1]] = parts[i]
+		} else if seg != parts[i] {
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+// handleTemplates serves the tools whose template paths share a prefix,
+// passing path parameters to the tool as arguments
+func (s *Server) handleTemplates(templates []pathTemplate) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		rest := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, apiV2Prefix), apiV1Prefix)
+
+		for _, tmpl := range templates {
+			params, ok := tmpl.match(rest)
+			if !ok {
+				continue
+			}
+			query := url.Values{}
+			for key, values := range r.URL.Query() {
+				query[key] = values
+			}
+			for name, value := range params {
+				query.Set(name, value)
+			}
+			s.serveTool(w, r, tmpl.tool, query)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(withSchemaVersion(types.ErrorResponse{Error: "not found"}, apiVersion(r)))
+	}
+}
+
+// setPathArguments sets the path parameters of a POST tool, which
+// handleTemplates passes in query, over those in its request body
+func setPathArguments(args Arguments, query url.Values, t Tool) error {
+	params := url.Values{}
+	for _, name := range pathParameters(t.Path) {
+		params.Set(name, query.Get(name))
+	}
+	fromPath, err := argumentsFromQuery(params, t.InputSchema)
+	if err != nil {
+		return err
+	}
+	for name, value := range fromPath {
+		args[name] = value
+	}
+	return nil
+}
+
+// newPathTemplate parses a tool's template path
+func newPathTemplate(t Tool) pathTemplate {
+	return pathTemplate{
+		tool:     t,
+		segments: strings.Split(strings.Trim(strings.TrimPrefix(t.Path, apiV2Prefix), "/"), "/"),
+	}
+}
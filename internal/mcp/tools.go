@@ -0,0 +1,1852 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image/png"
+	"math"
+	"net/http"
+	"path"
+	"runtime"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/borankux/gops/internal/network"
+	"github.com/borankux/gops/internal/port"
+	"github.com/borankux/gops/internal/process"
+	"github.com/borankux/gops/internal/resource"
+	"github.com/borankux/gops/internal/service"
+	"github.com/borankux/gops/internal/system"
+	"github.com/borankux/gops/internal/utils"
+	"github.com/borankux/gops/internal/window"
+	"github.com/borankux/gops/pkg/types"
+)
+
+// DefaultRegistry returns a registry populated with the built-in tools
+// and resources, using config for tool defaults
+func DefaultRegistry(config Config) *Registry {
+	r := NewRegistry()
+
+	r.Register(Tool{
+		Name:        "list_processes",
+		Group:       "processes",
+		Description: "List running user applications (non-system processes), or every process with all=true",
+		InputSchema: objectSchema(withSorting(withFields(withPagination(withFilterRules(map[string]*Schema{
+			"all":        {Type: "boolean", Description: "Include system daemons, kernel threads and processes owned by system users"},
+			"name":       {Type: "string", Description: "Only return processes whose name contains this text, ignoring case"},
+			"exact":      {Type: "boolean", Description: "Require name to match the whole process name"},
+			"match":      {Type: "string", Description: "Only return processes whose name matches this regular expression"},
+			"bundle_id":  {Type: "string", Description: "Only return processes of the macOS app with this bundle identifier"},
+			"status":     {Type: "string", Description: "Only return processes in this state, searching all processes", Enum: process.Statuses},
+			"cmdline":    {Type: "boolean", Description: "Include each process's full command line arguments (implied when fields contains cmdline)"},
+			"translated": {Type: "boolean", Description: "Only return x86_64 processes running under Rosetta 2 emulation on Apple Silicon"},
+		}))), process.SortKeys)),
+		Path:      "/mcp/v2/processes",
+		Collector: "processes",
+		Output:    types.ProcessesResponse{},
+		Handler:   processLister(config.ProcessFilter),
+	})
+
+	r.Register(Tool{
+		Name:        "get_process_tree",
+		Group:       "processes",
+		Description: "Get every process arranged by parent, showing which app spawned which helper",
+		InputSchema: objectSchema(map[string]*Schema{
+			"pid": pidProperty("Only return the subtree rooted at this process"),
+		}),
+		Path:      "/mcp/v2/processes/tree",
+		Collector: "processes",
+		Output:    types.ProcessTreeResponse{},
+		Handler:   getProcessTree,
+	})
+
+	r.Register(Tool{
+		Name:        "get_process_env",
+		Group:       "processes",
+		Description: "Get the environment variables of a process, with values of keys containing TOKEN, SECRET, PASSWORD or API_KEY redacted. Usually only permitted for processes of the same user.",
+		InputSchema: objectSchema(map[string]*Schema{
+			"pid": pidProperty("Process ID to inspect"),
+		}, "pid"),
+		Path:    "/mcp/v2/process/env",
+		Output:  types.EnvironmentResponse{},
+		Handler: getProcessEnv,
+	})
+
+	r.Register(Tool{
+		Name:        "list_open_files",
+		Group:       "processes",
+		Description: "List the file descriptors a process holds open, with their path, type (file, dir, socket, pipe, device) and access mode",
+		InputSchema: objectSchema(map[string]*Schema{
+			"pid": pidProperty("Process ID to inspect"),
+		}, "pid"),
+		Path:    "/mcp/v2/process/{pid}/files",
+		Output:  types.OpenFilesResponse{},
+		Handler: listOpenFiles,
+	})
+
+	r.Register(Tool{
+		Name:        "get_memory_map",
+		Group:       "processes",
+		Description: "Get the memory regions of a process (executable, shared libraries, heap, stacks, anonymous and file mappings) with their size, resident memory and permissions, plus totals per region type. Uses vmmap on macOS and /proc on Linux.",
+		InputSchema: objectSchema(map[string]*Schema{
+			"pid":  pidProperty("Process ID to inspect"),
+			"type": {Type: "string", Description: "Only list regions of this type; the summary still covers every region", Enum: process.RegionTypes},
+		}, "pid"),
+		Path:    "/mcp/v2/process/{pid}/memory",
+		Output:  types.MemoryMapResponse{},
+		Handler: getMemoryMap,
+	})
+
+	r.Register(Tool{
+		Name:        "list_threads",
+		Group:       "processes",
+		Description: "List the threads of a process with their state, current CPU percentage and user/system CPU time, busiest first, to spot a spinning thread",
+		InputSchema: objectSchema(map[string]*Schema{
+			"pid": pidProperty("Process ID to inspect"),
+		}, "pid"),
+		Path:    "/mcp/v2/process/{pid}/threads",
+		Output:  types.ThreadsResponse{},
+		Handler: listThreads,
+	})
+
+	r.Register(Tool{
+		Name:        "get_resource_limits",
+		Group:       "processes",
+		Description: "Get the soft and hard resource limits (open files, processes, stack, memory, CPU time) of a process with current usage where known, e.g. to debug \"too many open files\". On macOS these are the launchd defaults, as other processes' limits aren't exposed.",
+		InputSchema: objectSchema(map[string]*Schema{
+			"pid": pidProperty("Process ID to inspect"),
+		}, "pid"),
+		Path:    "/mcp/v2/process/{pid}/limits",
+		Output:  types.ResourceLimitsResponse{},
+		Handler: getResourceLimits,
+	})
+
+	r.Register(Tool{
+		Name:        "get_process_icon",
+		Group:       "processes",
+		Description: "Get the icon of the application a process belongs to as a PNG image, from its .app bundle on macOS, its .desktop entry on Linux or an .ico file next to the executable on Windows",
+		InputSchema: objectSchema(map[string]*Schema{
+			"pid":  pidProperty("Process ID whose icon to return"),
+			"size": integerProperty("Preferred icon width in pixels; the closest available size is returned (default 128)", 16, 1024),
+		}, "pid"),
+		Path:      "/mcp/v2/process/{pid}/icon",
+		Output:    types.ProcessIconResponse{},
+		MediaType: "image/png",
+		Media: func(result interface{}) []byte {
+			return result.(types.ProcessIconResponse).Data
+		},
+		Handler: getProcessIcon,
+	})
+
+	r.Register(Tool{
+		Name:        "kill_process",
+		Group:       "control",
+		Description: "Terminate a process with SIGTERM, or SIGKILL with force=true. PID 1, gops itself and critical system processes are refused.",
+		InputSchema: objectSchema(map[string]*Schema{
+			"pid":    pidProperty("Process ID to terminate"),
+			"signal": {Type: "string", Description: "Signal to send instead of SIGTERM, by name (TERM, SIGHUP) or number"},
+			"force":  {Type: "boolean", Description: "Send SIGKILL, which the process cannot catch"},
+		}, "pid"),
+		Path:        "/mcp/v2/process/kill",
+		Method:      http.MethodPost,
+		NoCache:     true,
+		Destructive: true,
+		Output:      types.SignalResponse{},
+		Handler:     killProcess,
+	})
+
+	r.Register(Tool{
+		Name:        "signal_process",
+		Group:       "control",
+		Description: "Send a signal to a process, e.g. SIGHUP to make a daemon reload its configuration. PID 1, gops itself and critical system processes are refused.",
+		InputSchema: objectSchema(map[string]*Schema{
+			"pid":    pidProperty("Process ID to signal"),
+			"signal": {Type: "string", Description: "Signal name or number; supported names: " + strings.Join(process.SignalNames(), ", ")},
+		}, "pid", "signal"),
+		Path:        "/mcp/v2/process/signal",
+		Method:      http.MethodPost,
+		NoCache:     true,
+		Destructive: true,
+		Output:      types.SignalResponse{},
+		Handler:     signalProcess,
+	})
+
+	r.Register(Tool{
+		Name:        "set_priority",
+		Group:       "control",
+		Description: "Change the scheduling priority of a process: its nice value on macOS and Linux, or its priority class on Windows. Reports the old and new priority.",
+		InputSchema: objectSchema(map[string]*Schema{
+			"pid":   pidProperty("Process ID to change"),
+			"nice":  integerProperty("Nice value from -20 (highest priority) to 19 (lowest); raising priority usually requires root", process.MinNice, process.MaxNice),
+			"class": {Type: "string", Description: "Windows priority class", Enum: process.PriorityClasses},
+		}, "pid"),
+		Path:        "/mcp/v2/process/priority",
+		Method:      http.MethodPost,
+		NoCache:     true,
+		Destructive: true,
+		Output:      types.PriorityResponse{},
+		Handler:     setPriority,
+	})
+
+	r.Register(Tool{
+		Name:        "launch_app",
+		Group:       "control",
+		Description: "Launch a macOS application by bundle ID or .app bundle path, returning its PID. The app is opened as if the user had started it; an app that is already running is brought to the front. Off unless the server is started with -enable-tools launch_app.",
+		InputSchema: objectSchema(map[string]*Schema{
+			"bundle_id": {Type: "string", Description: "macOS bundle identifier, e.g. com.apple.Safari"},
+			"path":      {Type: "string", Description: "Path to a macOS .app bundle, e.g. /Applications/Safari.app"},
+		}),
+		Path:        "/mcp/v2/process/launch",
+		Method:      http.MethodPost,
+		NoCache:     true,
+		Destructive: true,
+		OptIn:       true,
+		Output:      types.LaunchResponse{},
+		Handler:     launchApp,
+	})
+
+	r.Register(Tool{
+		Name:        "focus_window",
+		Group:       "control",
+		Description: "Bring a window to the foreground, restoring it if minimized. Select it by id from list_windows, or by pid and/or a title substring (case-insensitive); the frontmost match wins.",
+		InputSchema: objectSchema(withWindowQuery(nil)),
+		Path:        "/mcp/v2/window/focus",
+		Method:      http.MethodPost,
+		NoCache:     true,
+		Output:      types.FocusWindowResponse{},
+		Handler:     focusWindow,
+	})
+
+	r.Register(Tool{
+		Name:        "close_window",
+		Group:       "control",
+		Description: "Close a window as if its close button was clicked; the app may still ask to save changes. Select it like focus_window. Use dry_run to check which window would be closed.",
+		InputSchema: objectSchema(withWindowQuery(map[string]*Schema{
+			"dry_run": {Type: "boolean", Description: "Only report the window that would be closed"},
+		})),
+		Path:        "/mcp/v2/window/close",
+		Method:      http.MethodPost,
+		NoCache:     true,
+		Destructive: true,
+		Output:      types.CloseWindowResponse{},
+		Handler:     closeWindow,
+	})
+
+	r.Register(Tool{
+		Name:        "minimize_window",
+		Group:       "control",
+		Description: "Minimize a window to the Dock or taskbar. Select it like focus_window.",
+		InputSchema: objectSchema(withWindowQuery(nil)),
+		Path:        "/mcp/v2/window/minimize",
+		Method:      http.MethodPost,
+		NoCache:     true,
+		Output:      types.WindowStateResponse{},
+		Handler:     minimizeWindow,
+	})
+
+	r.Register(Tool{
+		Name:        "restore_window",
+		Group:       "control",
+		Description: "Restore a minimized window and, on macOS, unhide its app. Select it like focus_window.",
+		InputSchema: objectSchema(withWindowQuery(nil)),
+		Path:        "/mcp/v2/window/restore",
+		Method:      http.MethodPost,
+		NoCache:     true,
+		Output:      types.WindowStateResponse{},
+		Handler:     restoreWindow,
+	})
+
+	r.Register(Tool{
+		Name:        "move_window",
+		Group:       "control",
+		Description: "Move and resize a window, to a preset such as left_half or maximize on its display (or another one), or to an explicit position and size in screen coordinates. Omitted values keep the current ones. Select the window like focus_window.",
+		InputSchema: objectSchema(withWindowQuery(map[string]*Schema{
+			"preset":  {Type: "string", Description: "Lay the window out on a display", Enum: window.Presets},
+			"display": integerProperty("Display to lay the preset out on, from list_displays (default: the window's)", 1, 64),
+			"x":       integerProperty("Left edge", -100000, 100000),
+			"y":       integerProperty("Top edge", -100000, 100000),
+			"width":   integerProperty("Width", 1, 100000),
+			"height":  integerProperty("Height", 1, 100000),
+		})),
+		Path:    "/mcp/v2/window/move",
+		Method:  http.MethodPost,
+		NoCache: true,
+		Output:  types.MoveWindowResponse{},
+		Handler: moveWindow,
+	})
+
+	r.Register(Tool{
+		Name:        "arrange_windows",
+		Group:       "control",
+		Description: "Lay out several windows on a display, in the order given: side_by_side gives each an equal column from left to right, stack an equal row from top to bottom, and grid fills rows of equal cells. Use it to set up a workspace, e.g. an editor on the left and a terminal on the right.",
+		InputSchema: objectSchema(map[string]*Schema{
+			"ids": {
+				Type:        "array",
+				Description: "IDs of the windows to arrange, from list_windows",
+				Items:       integerProperty("Window ID", 1, 4294967295),
+			},
+			"layout":  {Type: "string", Description: "How to lay the windows out", Enum: window.Layouts},
+			"display": integerProperty("Display to arrange the windows on, from list_displays (default: the first window's)", 1, 64),
+		}, "ids", "layout"),
+		Path:    "/mcp/v2/window/arrange",
+		Method:  http.MethodPost,
+		NoCache: true,
+		Output:  types.ArrangeWindowsResponse{},
+		Handler: arrangeWindows,
+	})
+
+	r.Register(Tool{
+		Name:        "hide_app",
+		Group:       "control",
+		Description: "Hide every window of an app, like Command-H on macOS. Linux and Windows can't hide apps, so their windows are minimized instead.",
+		InputSchema: objectSchema(map[string]*Schema{
+			"pid": pidProperty("Process owning the windows"),
+		}, "pid"),
+		Path:    "/mcp/v2/window/hide",
+		Method:  http.MethodPost,
+		NoCache: true,
+		Output:  types.HideAppResponse{},
+		Handler: hideApp,
+	})
+
+	r.Register(Tool{
+		Name:        "list_stray_processes",
+		Group:       "processes",
+		Description: "List zombie processes with the parent that failed to reap them, and orphaned processes whose parent has gone away",
+		Path:        "/mcp/v2/processes/zombies",
+		Collector:   "processes",
+		Output:      types.StrayProcessesResponse{},
+		Handler:     listStrayProcesses,
+	})
+
+	snapshots := process.NewSnapshotStore(0)
+	r.Register(Tool{
+		Name:        "snapshot_processes",
+		Group:       "processes",
+		Description: "Capture the process table with memory and CPU time, returning a snapshot ID to pass to diff_processes later",
+		Path:        "/mcp/v2/processes/snapshot",
+		Method:      http.MethodPost,
+		NoCache:     true,
+		Collector:   "processes",
+		Output:      types.ProcessSnapshotResponse{},
+		Handler:     snapshotProcesses(snapshots),
+	})
+
+	r.Register(Tool{
+		Name:        "diff_processes",
+		Group:       "processes",
+		Description: fmt.Sprintf("Compare the process table now with an earlier snapshot: processes started and stopped since, and CPU and memory changes. The current table is stored as a new snapshot; the last %d are kept.", process.DefaultSnapshotLimit),
+		InputSchema: objectSchema(map[string]*Schema{
+			"since": {Type: "string", Description: "Snapshot ID to compare against; defaults to the most recent snapshot"},
+		}),
+		Path:      "/mcp/v2/processes/diff",
+		NoCache:   true,
+		Collector: "processes",
+		Output:    types.ProcessDiffResponse{},
+		Handler:   diffProcesses(snapshots),
+	})
+
+	r.Register(Tool{
+		Name:        "get_process",
+		Group:       "processes",
+		Description: "Get the details of a process: its path, user, command line, working directory, usage, parent and children, to trace helper processes back to the app that owns them",
+		InputSchema: objectSchema(map[string]*Schema{
+			"pid": pidProperty("Process ID to inspect"),
+		}, "pid"),
+		Path:    "/mcp/v2/process/{pid}",
+		Output:  types.ProcessResponse{},
+		Handler: getProcess,
+	})
+
+	r.Register(Tool{
+		Name:        "list_windows",
+		Group:       "windows",
+		Description: "List open windows with their owning processes, whether each is minimized, hidden or on screen, and the Space or virtual desktop each is on. Minimized and hidden windows are left out unless include_hidden is set.",
+		InputSchema: objectSchema(withFields(map[string]*Schema{
+			"space":          {Type: "string", Description: "Only windows on this Space or virtual desktop: current, or its 1-based number"},
+			"include_hidden": {Type: "boolean", Description: "Also list minimized windows and windows of hidden apps"},
+			"fullscreen":     {Type: "boolean", Description: "Only list full-screen windows"},
+			"pid":            pidProperty("Only list windows of this process"),
+			"app":            {Type: "string", Description: "Only list windows of apps whose name contains this text, ignoring case"},
+		})),
+		Path:      "/mcp/v2/windows",
+		Collector: "windows",
+		Output:    types.WindowsResponse{},
+		Handler:   listWindows,
+	})
+
+	r.Register(Tool{
+		Name:        "get_window_text",
+		Group:       "windows",
+		Description: "Read the visible text of a window, such as the message and buttons of an error dialog, from its accessibility tree instead of a screenshot. Select the window like focus_window. Needs Accessibility access on macOS; not supported on Linux. Off unless the server is started with -enable-tools get_window_text.",
+		InputSchema: objectSchema(withWindowQuery(nil)),
+		Path:        "/mcp/v2/window/text",
+		Method:      http.MethodPost,
+		OptIn:       true,
+		Collector:   "windows",
+		Output:      types.WindowTextResponse{},
+		Handler:     getWindowText,
+	})
+
+	r.Register(Tool{
+		Name:        "capture_window",
+		Group:       "windows",
+		Description: "Take a PNG screenshot of a window. With ocr=true, also recognize the text in it with bounding boxes (macOS only, through the Vision framework), for windows whose text get_window_text can't read; the result is then JSON with the image base64-encoded. Select the window like focus_window. Needs Screen Recording access on macOS, and ImageMagick on X11 or grim on Wayland. Off unless the server is started with -enable-tools capture_window.",
+		InputSchema: objectSchema(withWindowQuery(map[string]*Schema{
+			"ocr": {Type: "boolean", Description: "Recognize the text in the screenshot"},
+		})),
+		Path:      "/mcp/v2/window/capture",
+		Method:    http.MethodPost,
+		NoCache:   true,
+		OptIn:     true,
+		Collector: "windows",
+		Output:    types.WindowCaptureResponse{},
+		MediaType: "image/png",
+		Media: func(result interface{}) []byte {
+			capture := result.(types.WindowCaptureResponse)
+			if capture.TextRegions != nil {
+				return nil
+			}
+			return capture.Data
+		},
+		Handler: captureWindow,
+	})
+
+	r.Register(Tool{
+		Name:        "list_displays",
+		Group:       "windows",
+		Description: "List connected displays with their bounds, resolution, scale factor, refresh rate and window count. Window display numbers refer to these.",
+		InputSchema: objectSchema(withFields(nil)),
+		Path:        "/mcp/v2/displays",
+		Output:      types.DisplaysResponse{},
+		Handler:     listDisplays,
+	})
+
+	r.Register(Tool{
+		Name:        "get_focused_window",
+		Group:       "windows",
+		Description: "Get the frontmost app and the window that has keyboard focus, i.e. what the user is looking at right now",
+		InputSchema: objectSchema(nil),
+		Path:        "/mcp/v2/windows/focused",
+		NoCache:     true,
+		Output:      types.FocusedWindowResponse{},
+		Handler:     getFocusedWindow,
+	})
+
+	r.Register(Tool{
+		Name:        "list_ports",
+		Group:       "ports",
+		Description: "List listening TCP ports and bound UDP sockets with their well-known service names and owning processes, optionally filtered by port, PID, protocol or TCP state",
+		InputSchema: objectSchema(withSorting(withFields(withPagination(map[string]*Schema{
+			"port":     portProperty("Only return listeners on this port"),
+			"pid":      pidProperty("Only return ports opened by this process"),
+			"protocol": {Type: "string", Description: "Only return TCP or UDP ports, over either family or only IPv4 (tcp4, udp4) or IPv6 (tcp6, udp6)", Enum: port.Protocols},
+			"state":    {Type: "string", Description: "Select sockets by TCP state instead of listeners, e.g. TIME_WAIT, or ALL for every socket (default LISTEN)", Enum: port.States},
+			"exposed":  {Type: "boolean", Description: "Only return ports reachable from other machines, leaving out those bound to loopback"},
+			"firewall": {Type: "boolean", Description: "Read the host firewall (Application Firewall on macOS, ufw or firewalld on Linux) and report whether it allows or blocks each port"},
+			"resolve":  {Type: "boolean", Description: "Look up the host name of each bind address with reverse DNS"},
+		})), port.SortKeys)),
+		Path:      "/mcp/v2/ports",
+		Collector: "ports",
+		Output:    types.PortsResponse{},
+		Handler:   listPorts,
+	})
+
+	r.Register(Tool{
+		Name:        "list_connections",
+		Group:       "ports",
+		Description: "List established TCP connections with their local and remote addresses and owning processes, to see what the machine is talking to",
+		InputSchema: objectSchema(withSorting(withFields(withPagination(map[string]*Schema{
+			"pid":   pidProperty("Only return connections of this process"),
+			"port":  portProperty("Only return connections whose local or remote port this is"),
+			"geoip": {Type: "boolean", Description: "Annotate remote addresses with their country and autonomous system from the server's MaxMind databases"},
+		})), port.ConnectionSortKeys)),
+		Path:      "/mcp/v2/connections",
+		Collector: "ports",
+		Output:    types.ConnectionsResponse{},
+		Handler:   connectionLister(config.GeoIP),
+	})
+
+	r.Register(Tool{
+		Name:        "list_unix_sockets",
+		Group:       "ports",
+		Description: "List Unix domain sockets bound to a path, such as Docker's, ssh-agent's or a database's, with their type and owning processes. Not supported on Windows.",
+		InputSchema: objectSchema(withSorting(withFields(withPagination(map[string]*Schema{
+			"pid":  pidProperty("Only return sockets of this process"),
+			"path": {Type: "string", Description: "Only return sockets whose path contains this text, ignoring case"},
+		})), port.UnixSocketSortKeys)),
+		Path:      "/mcp/v2/ports/unix",
+		Collector: "ports",
+		Output:    types.UnixSocketsResponse{},
+		Handler:   listUnixSockets,
+	})
+
+	r.Register(Tool{
+		Name:        "scan_ports",
+		Group:       "ports",
+		Description: "Probe a range of local TCP ports by connecting to each on 127.0.0.1 and ::1, merged with the listening sockets and their processes, to tell dev servers that actually answer from ports that are merely bound. Connections are closed as soon as they open; nothing is sent. Ports neither answering nor bound are left out.",
+		InputSchema: objectSchema(map[string]*Schema{
+			"from":    portProperty("First port of the range, e.g. 3000"),
+			"to":      portProperty(fmt.Sprintf("Last port of the range, e.g. 9000 (at most %d ports)", port.MaxScanPorts)),
+			"timeout": {Type: "string", Description: "How long each connection attempt waits, e.g. 500ms (default 300ms, at most 5s)"},
+		}, "from", "to"),
+		Path:      "/mcp/v2/ports/scan",
+		Collector: "ports",
+		Output:    types.ScanResponse{},
+		NoCache:   true,
+		Handler:   scanPorts,
+	})
+
+	r.Register(Tool{
+		Name:        "get_network_top",
+		Group:       "network",
+		Description: "Measure how many bytes per second each process sends and receives over an interval and list the busiest first. macOS reads traffic with nettop; Linux reads TCP socket counters with ss, which only sees other users' processes when gops runs as root.",
+		InputSchema: objectSchema(map[string]*Schema{
+			"interval": {Type: "string", Description: "How long to measure, e.g. 2s (default 1s, at most 10s)"},
+			"limit":    integerProperty("Maximum number of processes to return (default 10, 0 for all)", 0, 10000),
+		}),
+		Path:      "/mcp/v2/network/top",
+		Collector: "network",
+		Output:    types.NetworkTopResponse{},
+		Handler:   getNetworkTop,
+	})
+
+	r.Register(Tool{
+		Name:        "list_interfaces",
+		Group:       "network",
+		Description: "List network interfaces with their IP addresses, MAC address, MTU, link state and byte, packet, error and drop counters since boot",
+		InputSchema: objectSchema(withFields(map[string]*Schema{
+			"up": {Type: "boolean", Description: "Only return interfaces whose link is up"},
+		})),
+		Path:      "/mcp/v2/interfaces",
+		Collector: "network",
+		Output:    types.InterfacesResponse{},
+		Handler:   listInterfaces,
+	})
+
+	r.Register(Tool{
+		Name:        "list_routes",
+		Group:       "network",
+		Description: "List the routing table with destinations, gateways and interfaces, default routes first, flagging routes through VPN tunnels, to see where traffic goes",
+		InputSchema: objectSchema(withFields(map[string]*Schema{
+			"family":    {Type: "string", Description: "Only return IPv4 or IPv6 routes", Enum: network.Families},
+			"interface": {Type: "string", Description: "Only return routes through this interface, e.g. en0"},
+			"vpn":       {Type: "boolean", Description: "Only return routes through VPN tunnels"},
+		})),
+		Path:      "/mcp/v2/routes",
+		Collector: "network",
+		Output:    types.RoutesResponse{},
+		Handler:   listRoutes,
+	})
+
+	r.Register(Tool{
+		Name:        "list_neighbors",
+		Group:       "network",
+		Description: "List the ARP and NDP neighbor tables: hosts on the local networks this machine recently exchanged packets with, with their MAC address, interface and state",
+		InputSchema: objectSchema(withFields(map[string]*Schema{
+			"family":    {Type: "string", Description: "Only return IPv4 (ARP) or IPv6 (NDP) neighbors", Enum: network.Families},
+			"interface": {Type: "string", Description: "Only return neighbors reached through this interface, e.g. en0"},
+		})),
+		Path:      "/mcp/v2/neighbors",
+		Collector: "network",
+		Output:    types.NeighborsResponse{},
+		Handler:   listNeighbors,
+	})
+
+	r.Register(Tool{
+		Name:        "get_dns_config",
+		Group:       "network",
+		Description: "Report the DNS resolvers the system queries, with their search domains and the interfaces they are scoped to, and optionally the DNS cache, to debug names resolving differently than expected. macOS reads scutil --dns, Linux resolv.conf or systemd-resolved. The cache can be read on Linux with systemd-resolved (as root) and on Windows, not on macOS.",
+		InputSchema: objectSchema(map[string]*Schema{
+			"cache": {Type: "boolean", Description: "Also return the records in the DNS cache"},
+			"name":  {Type: "string", Description: "Only return cached records whose name contains this, e.g. example.com"},
+		}),
+		Path:      "/mcp/v2/dns",
+		Collector: "network",
+		Output:    types.DNSResponse{},
+		Handler:   getDNSConfig,
+	})
+
+	r.Register(Tool{
+		Name:        "get_resource_usage",
+		Group:       "processes",
+		Description: "Get CPU, memory, thread and open file usage for a process",
+		InputSchema: objectSchema(map[string]*Schema{
+			"pid": pidProperty("Process ID to inspect"),
+		}, "pid"),
+		Path:    "/mcp/v2/resource",
+		Output:  types.ResourceResponse{},
+		Handler: getResourceUsage,
+	})
+
+	r.Register(Tool{
+		Name:        "get_system_metrics",
+		Group:       "system",
+		Description: "Report how the machine is doing overall: total CPU percentage over half a second, 1, 5 and 15 minute load averages (not on Windows), memory used, free, available and cached, swap, disk usage per mounted file system and uptime",
+		InputSchema: objectSchema(nil),
+		Path:        "/mcp/v2/system",
+		Output:      types.SystemMetricsResponse{},
+		Handler:     getSystemMetrics,
+	})
+
+	r.Register(Tool{
+		Name:        "list_services",
+		Group:       "services",
+		Description: "List system services with their status, resource usage, domain (launchd system, user/<uid> or gui/<uid>; systemd system or user), scope (daemon or agent) and whether each is enabled (on Windows, its start type: automatic, manual or disabled). macOS has hundreds of launchd jobs; filter by status, name or domain to narrow them down.",
+		InputSchema: objectSchema(withSorting(withFields(map[string]*Schema{
+			"status": {Type: "string", Description: "Only list running services, stopped ones, or failed ones: systemd units in the failed state and launchd jobs whose last exit status isn't zero", Enum: service.Statuses},
+			"name":   {Type: "string", Description: "Only list services whose name matches this glob, ignoring case, e.g. com.apple.* or *docker*; without wildcards, names containing it"},
+			"domain": {Type: "string", Description: "Only list system-wide daemons (system) or per-user agents and systemd user units (user)", Enum: service.Domains},
+		}), service.SortKeys)),
+		Path:      "/mcp/v2/services",
+		Collector: "services",
+		Output:    types.ServicesResponse{},
+		Handler:   listServices,
+	})
+
+	r.Register(Tool{
+		Name:        "get_service",
+		Group:       "services",
+		Description: "Get how a service is configured and how it last ran, to understand why it keeps restarting: its launchd plist with ProgramArguments, KeepAlive, RunAtLoad and ThrottleInterval on macOS, or its systemd unit properties with the Restart policy and restart count on Linux, plus its state, last exit status and whether it is being throttled",
+		InputSchema: objectSchema(map[string]*Schema{
+			"name": {Type: "string", Description: "Service name as listed by list_services: a launchd label, systemd unit or Windows service name"},
+			"user": {Type: "boolean", Description: "Linux: read a systemd user unit of the user gops runs as"},
+		}, "name"),
+		Path:    "/mcp/v2/services/{name}",
+		Output:  types.ServiceDetailResponse{},
+		Handler: getService,
+	})
+
+	r.Register(Tool{
+		Name:        "get_service_logs",
+		Group:       "services",
+		Description: "Get the most recent log lines of a service, oldest first, e.g. to find out why it died: its systemd journal on Linux (journalctl -u), or on macOS the unified log entries of its process and launchd's messages about it (log show, the last hour unless since is given) followed by the tail of its StandardOutPath and StandardErrorPath files. Reading system logs may require root.",
+		InputSchema: objectSchema(map[string]*Schema{
+			"name":  {Type: "string", Description: "Service name as listed by list_services: a launchd label or systemd unit"},
+			"lines": integerProperty(fmt.Sprintf("How many of the most recent lines to return (default %d)", service.DefaultLogLines), 1, service.MaxLogLines),
+			"since": {Type: "string", Description: "Leave out older lines: a duration ago such as 30m, or an RFC 3339 time"},
+			"user":  {Type: "boolean", Description: "Linux: read the journal of a systemd user unit"},
+		}, "name"),
+		Path:    "/mcp/v2/services/{name}/logs",
+		NoCache: true,
+		Output:  types.ServiceLogsResponse{},
+		Handler: getServiceLogs,
+	})
+
+	r.Register(Tool{
+		Name:        "list_failed_services",
+		Group:       "services",
+		Description: "Triage failed services in one call: services whose last run ended with a non-zero exit status or a signal, those restarted most often first, with their state, last exit status or signal, how often launchd started the job (runs) or systemd restarted the unit (restarts), whether they are running again or being throttled, and their last log lines. Covers launchd jobs on macOS, including those KeepAlive restarted, and failed or auto-restarting systemd units on Linux.",
+		InputSchema: objectSchema(withFields(map[string]*Schema{
+			"lines": integerProperty(fmt.Sprintf("How many of the last log lines to include per service (default %d; 0 leaves logs out, which is much faster on macOS)", service.DefaultFailedLogLines), 0, service.MaxFailedLogLines),
+		})),
+		Path:    "/mcp/v2/services/failed",
+		NoCache: true,
+		Output:  types.FailedServicesResponse{},
+		Handler: listFailedServices,
+	})
+
+	r.Register(Tool{
+		Name:        "list_scheduled_jobs",
+		Group:       "services",
+		Description: "List what runs automatically on a schedule, the next to run first: cron entries (macOS and Linux; every user's crontab as root), launchd jobs with StartCalendarInterval or StartInterval (macOS) and systemd timers (Linux), with their schedule, command, user and next run time",
+		InputSchema: objectSchema(withFields(nil)),
+		Path:        "/mcp/v2/scheduled",
+		Output:      types.ScheduledJobsResponse{},
+		Handler:     listScheduledJobs,
+	})
+
+	r.Register(Tool{
+		Name:        "control_service",
+		Group:       "control",
+		Description: "Start, stop, restart or reload a service: a launchd job on macOS (bootstrap, bootout, kickstart, or SIGHUP to reload), a systemd unit on Linux or a Windows service. Stopping a launchd job unloads it so KeepAlive doesn't restart it. enable and disable decide whether it starts at boot or login without starting or stopping it now (launchctl and systemctl enable/disable; on Windows, Set-Service sets the start type to Automatic or Disabled). System services usually need gops to run as root or administrator; a missing privilege is reported as 403.",
+		InputSchema: objectSchema(map[string]*Schema{
+			"name":   {Type: "string", Description: "Service name as listed by list_services: a launchd label, systemd unit or Windows service name"},
+			"action": {Type: "string", Description: "What to do with the service; Windows services can't be reloaded", Enum: service.Actions},
+			"user":   {Type: "boolean", Description: "Linux: act on a systemd user unit of the user gops runs as (systemctl --user)"},
+		}, "name", "action"),
+		Path:        "/mcp/v2/services/{name}/action",
+		Method:      http.MethodPost,
+		NoCache:     true,
+		Destructive: true,
+		Output:      types.ServiceActionResponse{},
+		Handler:     controlService,
+	})
+
+	registerSnapshotResources(r)
+
+	return r
+}
+
+// processLister returns the list_processes handler, applying rules to the
+// user application filter unless a call overrides them
+func processLister(rules process.FilterRules) ToolHandler {
+	return func(ctx context.Context, args Arguments) (interface{}, error) {
+		return listProcesses(ctx, args, rules)
+	}
+}
+
+func listProcesses(ctx context.Context, args Arguments, rules process.FilterRules) (interface{}, error) {
+	all, err := args.Bool("all")
+	if err != nil {
+		return nil, err
+	}
+	status := args.String("status")
+	list := process.GetUserApplications
+	if all || status != "" {
+		// Zombies and stopped processes are rarely user applications
+		list = process.GetAllProcesses
+	}
+
+	cmdline, err := args.Bool("cmdline")
+	if err != nil {
+		return nil, err
+	}
+	fields, err := args.StringList("fields")
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range fields {
+		if f == "cmdline" {
+			cmdline = true
+		}
+	}
+
+	rules, err = filterRulesArgs(args, rules)
+	if err != nil {
+		return nil, err
+	}
+	translated, err := args.Bool("translated")
+	if err != nil {
+		return nil, err
+	}
+
+	sortBy, descending := sortArgs(args)
+	procs, err := list(ctx, process.ListOptions{
+		SortBy:     sortBy,
+		Descending: descending,
+		Cmdline:    cmdline,
+		Rules:      rules,
+		Translated: translated,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	exact, err := args.Bool("exact")
+	if err != nil {
+		return nil, err
+	}
+	procs, err = process.Filter(procs, process.Query{
+		Name:     args.String("name"),
+		Exact:    exact,
+		Regex:    args.String("match"),
+		BundleID: args.String("bundle_id"),
+	})
+	if err != nil {
+		return nil, argumentErrorf("%v", err)
+	}
+	if status != "" {
+		if procs, err = process.FilterStatus(procs, status); err != nil {
+			return nil, argumentErrorf("%v", err)
+		}
+	}
+
+	pg, err := paginate(args, len(procs))
+	if err != nil {
+		return nil, err
+	}
+	procs = procs[pg.start:pg.end]
+
+	return types.ProcessesResponse{
+		Processes:  procs,
+		Count:      len(procs),
+		Total:      pg.total,
+		NextCursor: pg.nextCursor,
+	}, nil
+}
+
+// withFilterRules adds the inputs overriding the configured process
+// filter rules to properties
+func withFilterRules(properties map[string]*Schema) map[string]*Schema {
+	counted := map[string]string{"include": "always", "exclude": "never"}
+	subjects := map[string]string{"names": "process names", "users": "owning users", "paths": "executable paths"}
+	for kind, when := range counted {
+		for field, subject := range subjects {
+			properties[kind+"_"+field] = &Schema{
+				Type:        "array",
+				Description: fmt.Sprintf("Glob patterns of %s %s counted as user applications, replacing the configured list", subject, when),
+				Items:       &Schema{Type: "string"},
+			}
+		}
+	}
+	return properties
+}
+
+// filterRulesArgs applies the filter rule arguments to rules; each given
+// list replaces the matching configured one
+func filterRulesArgs(args Arguments, rules process.FilterRules) (process.FilterRules, error) {
+	lists := map[string]*[]string{
+		"include_names": &rules.Include.Names,
+		"include_users": &rules.Include.Users,
+		"include_paths": &rules.Include.Paths,
+		"exclude_names": &rules.Exclude.Names,
+		"exclude_users": &rules.Exclude.Users,
+		"exclude_paths": &rules.Exclude.Paths,
+	}
+	for key, list := range lists {
+		if _, present := args[key]; !present {
+			continue
+		}
+		patterns, err := args.StringList(key)
+		if err != nil {
+			return rules, err
+		}
+		*list = patterns
+	}
+	return rules, nil
+}
+
+func listStrayProcesses(ctx context.Context, args Arguments) (interface{}, error) {
+	zombies, orphans, err := process.GetStrayProcesses(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return types.StrayProcessesResponse{
+		Zombies: zombies,
+		Orphans: orphans,
+		Count:   len(zombies) + len(orphans),
+	}, nil
+}
+
+func snapshotProcesses(snapshots *process.SnapshotStore) ToolHandler {
+	return func(ctx context.Context, args Arguments) (interface{}, error) {
+		snap, err := snapshots.Take(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		return types.ProcessSnapshotResponse{
+			ID:    snap.ID,
+			Time:  snap.Time.Format(time.RFC3339),
+			Count: snap.Len(),
+		}, nil
+	}
+}
+
+func diffProcesses(snapshots *process.SnapshotStore) ToolHandler {
+	return func(ctx context.Context, args Arguments) (interface{}, error) {
+		since, err := snapshots.Get(args.String("since"))
+		if err != nil {
+			return nil, err
+		}
+		now, err := snapshots.Take(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		return process.Diff(since, now), nil
+	}
+}
+
+func getProcess(ctx context.Context, args Arguments) (interface{}, error) {
+	pid, _, err := args.PID("pid")
+	if err != nil {
+		return nil, err
+	}
+
+	detail, err := process.GetProcess(ctx, pid)
+	if err != nil {
+		return nil, err
+	}
+
+	return types.ProcessResponse{
+		Process: detail,
+	}, nil
+}
+
+func getProcessTree(ctx context.Context, args Arguments) (interface{}, error) {
+	pid, _, err := args.PID("pid")
+	if err != nil {
+		return nil, err
+	}
+
+	tree, err := process.GetProcessTree(ctx, pid)
+	if err != nil {
+		return nil, err
+	}
+
+	return types.ProcessTreeResponse{
+		Roots: tree,
+		Count: process.CountNodes(tree),
+	}, nil
+}
+
+func getProcessEnv(ctx context.Context, args Arguments) (interface{}, error) {
+	pid, _, err := args.PID("pid")
+	if err != nil {
+		return nil, err
+	}
+	return process.GetEnvironment(ctx, pid)
+}
+
+func listOpenFiles(ctx context.Context, args Arguments) (interface{}, error) {
+	pid, _, err := args.PID("pid")
+	if err != nil {
+		return nil, err
+	}
+	return process.GetOpenFiles(ctx, pid)
+}
+
+func getMemoryMap(ctx context.Context, args Arguments) (interface{}, error) {
+	pid, _, err := args.PID("pid")
+	if err != nil {
+		return nil, err
+	}
+	return process.GetMemoryMap(ctx, pid, args.String("type"))
+}
+
+func listThreads(ctx context.Context, args Arguments) (interface{}, error) {
+	pid, _, err := args.PID("pid")
+	if err != nil {
+		return nil, err
+	}
+	return process.GetThreads(ctx, pid)
+}
+
+func getResourceLimits(ctx context.Context, args Arguments) (interface{}, error) {
+	pid, _, err := args.PID("pid")
+	if err != nil {
+		return nil, err
+	}
+	return process.GetResourceLimits(ctx, pid)
+}
+
+func getProcessIcon(ctx context.Context, args Arguments) (interface{}, error) {
+	pid, _, err := args.PID("pid")
+	if err != nil {
+		return nil, err
+	}
+	size, hasSize, err := args.Int("size")
+	if err != nil {
+		return nil, err
+	}
+	if hasSize && (size < 16 || size > 1024) {
+		return nil, argumentErrorf("invalid size: %d (must be between 16 and 1024)", size)
+	}
+	return process.GetIcon(ctx, pid, int(size))
+}
+
+func killProcess(ctx context.Context, args Arguments) (interface{}, error) {
+	pid, _, err := args.PID("pid")
+	if err != nil {
+		return nil, err
+	}
+	force, err := args.Bool("force")
+	if err != nil {
+		return nil, err
+	}
+
+	sig := syscall.SIGTERM
+	if name := args.String("signal"); name != "" {
+		if force {
+			return nil, argumentErrorf("signal and force cannot be combined")
+		}
+		if sig, err = process.ParseSignal(name); err != nil {
+			return nil, argumentErrorf("%v", err)
+		}
+	} else if force {
+		sig = syscall.SIGKILL
+	}
+
+	return process.Signal(ctx, pid, sig)
+}
+
+func signalProcess(ctx context.Context, args Arguments) (interface{}, error) {
+	pid, _, err := args.PID("pid")
+	if err != nil {
+		return nil, err
+	}
+	sig, err := process.ParseSignal(args.String("signal"))
+	if err != nil {
+		return nil, argumentErrorf("%v", err)
+	}
+
+	return process.Signal(ctx, pid, sig)
+}
+
+func setPriority(ctx context.Context, args Arguments) (interface{}, error) {
+	pid, _, err := args.PID("pid")
+	if err != nil {
+		return nil, err
+	}
+	nice, hasNice, err := args.Int("nice")
+	if err != nil {
+		return nil, err
+	}
+	class := args.String("class")
+
+	if runtime.GOOS == "windows" {
+		if class == "" {
+			return nil, argumentErrorf("class parameter is required on windows")
+		}
+		return process.SetPriorityClass(ctx, pid, class)
+	}
+
+	if !hasNice {
+		return nil, argumentErrorf("nice parameter is required")
+	}
+	if nice < process.MinNice || nice > process.MaxNice {
+		return nil, argumentErrorf("invalid nice: %d (must be between %d and %d)", nice, process.MinNice, process.MaxNice)
+	}
+	return process.SetNice(ctx, pid, int(nice))
+}
+
+func launchApp(ctx context.Context, args Arguments) (interface{}, error) {
+	bundleID, path := args.String("bundle_id"), args.String("path")
+	if (bundleID == "") == (path == "") {
+		return nil, argumentErrorf("exactly one of bundle_id and path is required")
+	}
+	if runtime.GOOS != "darwin" {
+		return nil, argumentErrorf("launching apps is only supported on macOS")
+	}
+	if path != "" && !strings.HasSuffix(strings.TrimSuffix(path, "/"), ".app") {
+		return nil, argumentErrorf("path must be an .app bundle: %s", path)
+	}
+
+	result, err := process.Launch(ctx, process.LaunchRequest{
+		BundleID: bundleID,
+		Path:     path,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func focusWindow(ctx context.Context, args Arguments) (interface{}, error) {
+	q, err := windowQuery(args)
+	if err != nil {
+		return nil, err
+	}
+
+	w, err := window.Focus(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	return types.FocusWindowResponse{
+		ID:      w.ID,
+		PID:     w.PID,
+		Process: w.Process,
+		Title:   w.Title,
+	}, nil
+}
+
+func closeWindow(ctx context.Context, args Arguments) (interface{}, error) {
+	q, err := windowQuery(args)
+	if err != nil {
+		return nil, err
+	}
+	dryRun, err := args.Bool("dry_run")
+	if err != nil {
+		return nil, err
+	}
+
+	w, err := window.Close(ctx, q, dryRun)
+	if err != nil {
+		return nil, err
+	}
+	return types.CloseWindowResponse{
+		ID:      w.ID,
+		PID:     w.PID,
+		Process: w.Process,
+		Title:   w.Title,
+		DryRun:  dryRun,
+	}, nil
+}
+
+func minimizeWindow(ctx context.Context, args Arguments) (interface{}, error) {
+	q, err := windowQuery(args)
+	if err != nil {
+		return nil, err
+	}
+	w, err := window.Minimize(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	return windowStateResponse(w), nil
+}
+
+func restoreWindow(ctx context.Context, args Arguments) (interface{}, error) {
+	q, err := windowQuery(args)
+	if err != nil {
+		return nil, err
+	}
+	w, err := window.Restore(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	return windowStateResponse(w), nil
+}
+
+func moveWindow(ctx context.Context, args Arguments) (interface{}, error) {
+	q, err := windowQuery(args)
+	if err != nil {
+		return nil, err
+	}
+	f := window.Frame{Preset: args.String("preset")}
+	display, hasDisplay, err := args.Int("display")
+	if err != nil {
+		return nil, err
+	}
+	if hasDisplay && f.Preset == "" {
+		return nil, argumentErrorf("display requires a preset")
+	}
+	if hasDisplay && display < 1 {
+		return nil, argumentErrorf("invalid display: %d", display)
+	}
+	f.Display = int(display)
+
+	if f.X, err = optionalInt(args, "x"); err != nil {
+		return nil, err
+	}
+	if f.Y, err = optionalInt(args, "y"); err != nil {
+		return nil, err
+	}
+	if f.Width, err = optionalInt(args, "width"); err != nil {
+		return nil, err
+	}
+	if f.Height, err = optionalInt(args, "height"); err != nil {
+		return nil, err
+	}
+	if (f.Width != nil && *f.Width < 1) || (f.Height != nil && *f.Height < 1) {
+		return nil, argumentErrorf("width and height must be at least 1")
+	}
+	if f.Preset == "" && f.X == nil && f.Y == nil && f.Width == nil && f.Height == nil {
+		return nil, argumentErrorf("preset or at least one of x, y, width and height is required")
+	}
+
+	w, err := window.Move(ctx, q, f)
+	if err != nil {
+		return nil, err
+	}
+	return types.MoveWindowResponse{
+		ID:       w.ID,
+		PID:      w.PID,
+		Process:  w.Process,
+		Title:    w.Title,
+		Geometry: *w.Geometry,
+		Display:  w.Display,
+	}, nil
+}
+
+func arrangeWindows(ctx context.Context, args Arguments) (interface{}, error) {
+	ids, err := windowIDs(args, "ids")
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, argumentErrorf("ids must list at least one window")
+	}
+	layout := args.String("layout")
+	display, _, err := args.Int("display")
+	if err != nil {
+		return nil, err
+	}
+	if display < 0 {
+		return nil, argumentErrorf("invalid display: %d", display)
+	}
+
+	windows, n, err := window.Arrange(ctx, layout, ids, int(display))
+	if err != nil {
+		return nil, err
+	}
+	resp := types.ArrangeWindowsResponse{Layout: layout, Display: n}
+	for _, w := range windows {
+		resp.Windows = append(resp.Windows, types.MoveWindowResponse{
+			ID:       w.ID,
+			PID:      w.PID,
+			Process:  w.Process,
+			Title:    w.Title,
+			Geometry: *w.Geometry,
+			Display:  w.Display,
+		})
+	}
+	return resp, nil
+}
+
+// windowIDs returns the list of window IDs named key, accepting a JSON
+// array or a comma-separated string
+func windowIDs(args Arguments, key string) ([]uint32, error) {
+	var items []interface{}
+	switch v := args[key].(type) {
+	case nil:
+		return nil, nil
+	case []interface{}:
+		items = v
+	case string:
+		for _, s := range strings.Split(v, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				items = append(items, s)
+			}
+		}
+	default:
+		return nil, argumentErrorf("invalid %s: expected a list of window IDs", key)
+	}
+
+	ids := make([]uint32, 0, len(items))
+	for _, item := range items {
+		id, _, err := Arguments{key: item}.Int(key)
+		if err != nil {
+			return nil, err
+		}
+		if id < 1 || id > math.MaxUint32 {
+			return nil, argumentErrorf("invalid window id: %d", id)
+		}
+		ids = append(ids, uint32(id))
+	}
+	return ids, nil
+}
+
+// optionalInt returns the integer argument named key, nil if it is absent
+func optionalInt(args Arguments, key string) (*int, error) {
+	n, ok, err := args.Int(key)
+	if err != nil || !ok {
+		return nil, err
+	}
+	v := int(n)
+	return &v, nil
+}
+
+// windowStateResponse reports the state of w after an action
+func windowStateResponse(w types.WindowInfo) types.WindowStateResponse {
+	return types.WindowStateResponse{
+		ID:        w.ID,
+		PID:       w.PID,
+		Process:   w.Process,
+		Title:     w.Title,
+		Minimized: w.Minimized,
+		Hidden:    w.Hidden,
+	}
+}
+
+func hideApp(ctx context.Context, args Arguments) (interface{}, error) {
+	pid, _, err := args.PID("pid")
+	if err != nil {
+		return nil, err
+	}
+	windows, err := window.HideApp(ctx, pid)
+	if err != nil {
+		return nil, err
+	}
+	action := "minimized"
+	if runtime.GOOS == "darwin" {
+		action = "hidden"
+	}
+	return types.HideAppResponse{
+		PID:     pid,
+		Process: windows[0].Process,
+		Action:  action,
+		Windows: len(windows),
+	}, nil
+}
+
+// windowQuery reads the id, pid and title arguments selecting a window
+func windowQuery(args Arguments) (window.Query, error) {
+	id, _, err := args.Int("id")
+	if err != nil {
+		return window.Query{}, err
+	}
+	if id < 0 || id > math.MaxUint32 {
+		return window.Query{}, argumentErrorf("invalid id: %d", id)
+	}
+	pid, _, err := args.PID("pid")
+	if err != nil {
+		return window.Query{}, err
+	}
+	q := window.Query{ID: uint32(id), PID: pid, Title: args.String("title")}
+	if q.ID == 0 && q.PID == 0 && q.Title == "" {
+		return window.Query{}, argumentErrorf("one of id, pid or title is required")
+	}
+	return q, nil
+}
+
+func listWindows(ctx context.Context, args Arguments) (interface{}, error) {
+	pid, hasPID, err := args.PID("pid")
+	if err != nil {
+		return nil, err
+	}
+
+	var windows []types.WindowInfo
+	if hasPID {
+		windows, err = window.GetWindowsForPID(ctx, pid)
+	} else {
+		windows, err = window.GetOpenWindows(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+	windows = window.FilterApp(windows, args.String("app"))
+	if windows, err = window.FilterSpace(windows, args.String("space")); err != nil {
+		return nil, argumentErrorf("%v", err)
+	}
+	includeHidden, err := args.Bool("include_hidden")
+	if err != nil {
+		return nil, err
+	}
+	fullscreen, err := args.Bool("fullscreen")
+	if err != nil {
+		return nil, err
+	}
+	if fullscreen {
+		windows = window.FilterFullscreen(windows)
+	}
+	omitted := 0
+	if !includeHidden {
+		windows, omitted = window.FilterHidden(windows)
+	}
+
+	return types.WindowsResponse{
+		Windows: windows,
+		Count:   len(windows),
+		Omitted: omitted,
+		Backend: window.Backend(),
+	}, nil
+}
+
+func getWindowText(ctx context.Context, args Arguments) (interface{}, error) {
+	q, err := windowQuery(args)
+	if err != nil {
+		return nil, err
+	}
+	w, elements, err := window.Text(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	lines := make([]string, len(elements))
+	for i, e := range elements {
+		lines[i] = e.Text
+	}
+	return types.WindowTextResponse{
+		ID:       w.ID,
+		PID:      w.PID,
+		Process:  w.Process,
+		Title:    w.Title,
+		Text:     strings.Join(lines, "\n"),
+		Elements: elements,
+	}, nil
+}
+
+func captureWindow(ctx context.Context, args Arguments) (interface{}, error) {
+	q, err := windowQuery(args)
+	if err != nil {
+		return nil, err
+	}
+	ocr, err := args.Bool("ocr")
+	if err != nil {
+		return nil, err
+	}
+
+	w, data, err := window.Capture(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	config, err := png.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("decoding screenshot: %w", err)
+	}
+	resp := types.WindowCaptureResponse{
+		ID:       w.ID,
+		PID:      w.PID,
+		Process:  w.Process,
+		Title:    w.Title,
+		MimeType: "image/png",
+		Width:    config.Width,
+		Height:   config.Height,
+		Data:     data,
+	}
+	if ocr {
+		regions, err := window.RecognizeText(ctx, data)
+		if err != nil {
+			return nil, err
+		}
+		lines := make([]string, len(regions))
+		for i, r := range regions {
+			lines[i] = r.Text
+		}
+		resp.Text = strings.Join(lines, "\n")
+		resp.TextRegions = regions
+	}
+	return resp, nil
+}
+
+func listDisplays(ctx context.Context, args Arguments) (interface{}, error) {
+	displays, err := window.GetDisplays(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return types.DisplaysResponse{
+		Displays: displays,
+		Count:    len(displays),
+	}, nil
+}
+
+func getFocusedWindow(ctx context.Context, args Arguments) (interface{}, error) {
+	return window.GetFrontmost(ctx)
+}
+
+func listPorts(ctx context.Context, args Arguments) (interface{}, error) {
+	portNum, hasPort, err := args.Port("port")
+	if err != nil {
+		return nil, err
+	}
+	pid, hasPID, err := args.PID("pid")
+	if err != nil {
+		return nil, err
+	}
+
+	resolve, err := args.Bool("resolve")
+	if err != nil {
+		return nil, err
+	}
+	exposed, err := args.Bool("exposed")
+	if err != nil {
+		return nil, err
+	}
+	firewall, err := args.Bool("firewall")
+	if err != nil {
+		return nil, err
+	}
+
+	sortBy, descending := sortArgs(args)
+	opts := port.ListOptions{
+		SortBy:     sortBy,
+		Descending: descending,
+		Protocol:   args.String("protocol"),
+		State:      args.String("state"),
+		Exposed:    exposed,
+		Resolve:    resolve,
+	}
+
+	var ports []types.PortInfo
+	if hasPort {
+		ports, err = port.GetPortInfoByPort(ctx, portNum, opts)
+	} else if hasPID {
+		ports, err = port.GetPortsByPID(ctx, pid, opts)
+	} else {
+		ports, err = port.GetOpenPorts(ctx, opts)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	pg, err := paginate(args, len(ports))
+	if err != nil {
+		return nil, err
+	}
+	ports = ports[pg.start:pg.end]
+
+	var status *types.FirewallStatus
+	if firewall {
+		status = port.CheckFirewall(ctx, ports)
+	}
+
+	return types.PortsResponse{
+		Ports:      ports,
+		Count:      len(ports),
+		Total:      pg.total,
+		NextCursor: pg.nextCursor,
+		Firewall:   status,
+	}, nil
+}
+
+func connectionLister(geo *port.GeoIP) ToolHandler {
+	return func(ctx context.Context, args Arguments) (interface{}, error) {
+		return listConnections(ctx, args, geo)
+	}
+}
+
+func listConnections(ctx context.Context, args Arguments, geo *port.GeoIP) (interface{}, error) {
+	portNum, _, err := args.Port("port")
+	if err != nil {
+		return nil, err
+	}
+	pid, _, err := args.PID("pid")
+	if err != nil {
+		return nil, err
+	}
+	locate, err := args.Bool("geoip")
+	if err != nil {
+		return nil, err
+	}
+	if locate && geo == nil {
+		return nil, argumentErrorf("geoip needs the server started with -geoip and a MaxMind database")
+	}
+	sortBy, descending := sortArgs(args)
+
+	opts := port.ConnectionOptions{
+		SortBy:     sortBy,
+		Descending: descending,
+		PID:        pid,
+		Port:       portNum,
+	}
+	if locate {
+		opts.GeoIP = geo
+	}
+	conns, err := port.GetConnections(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	pg, err := paginate(args, len(conns))
+	if err != nil {
+		return nil, err
+	}
+	conns = conns[pg.start:pg.end]
+
+	return types.ConnectionsResponse{
+		Connections: conns,
+		Count:       len(conns),
+		Total:       pg.total,
+		NextCursor:  pg.nextCursor,
+	}, nil
+}
+
+func scanPorts(ctx context.Context, args Arguments) (interface{}, error) {
+	from, _, err := args.Port("from")
+	if err != nil {
+		return nil, err
+	}
+	to, _, err := args.Port("to")
+	if err != nil {
+		return nil, err
+	}
+	if from > to {
+		return nil, argumentErrorf("invalid range: from %d is after to %d", from, to)
+	}
+	if n := to - from + 1; n > port.MaxScanPorts {
+		return nil, argumentErrorf("range too large: %d ports (at most %d)", n, port.MaxScanPorts)
+	}
+	var timeout time.Duration
+	if v := args.String("timeout"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 || d > port.MaxScanTimeout {
+			return nil, argumentErrorf("invalid timeout %q: must be a duration up to %s", v, port.MaxScanTimeout)
+		}
+		timeout = d
+	}
+
+	results, err := port.ScanPorts(ctx, port.ScanOptions{From: from, To: to, Timeout: timeout})
+	if err != nil {
+		return nil, err
+	}
+	resp := types.ScanResponse{
+		Ports: results,
+		Count: len(results),
+		From:  from,
+		To:    to,
+	}
+	for _, r := range results {
+		if r.Status == port.ScanAnswering {
+			resp.Answering++
+		} else {
+			resp.Bound++
+		}
+	}
+	return resp, nil
+}
+
+func listUnixSockets(ctx context.Context, args Arguments) (interface{}, error) {
+	pid, _, err := args.PID("pid")
+	if err != nil {
+		return nil, err
+	}
+	sortBy, descending := sortArgs(args)
+
+	sockets, err := port.GetUnixSockets(ctx, port.UnixSocketOptions{
+		SortBy:     sortBy,
+		Descending: descending,
+		PID:        pid,
+		Path:       args.String("path"),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	pg, err := paginate(args, len(sockets))
+	if err != nil {
+		return nil, err
+	}
+	sockets = sockets[pg.start:pg.end]
+
+	return types.UnixSocketsResponse{
+		Sockets:    sockets,
+		Count:      len(sockets),
+		Total:      pg.total,
+		NextCursor: pg.nextCursor,
+	}, nil
+}
+
+func listInterfaces(ctx context.Context, args Arguments) (interface{}, error) {
+	up, err := args.Bool("up")
+	if err != nil {
+		return nil, err
+	}
+	ifaces, err := network.GetInterfaces(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if up {
+		var upIfaces []types.InterfaceInfo
+		for _, iface := range ifaces {
+			if iface.State == network.StateUp {
+				upIfaces = append(upIfaces, iface)
+			}
+		}
+		ifaces = upIfaces
+	}
+
+	return types.InterfacesResponse{
+		Interfaces: ifaces,
+		Count:      len(ifaces),
+	}, nil
+}
+
+func listRoutes(ctx context.Context, args Arguments) (interface{}, error) {
+	vpn, err := args.Bool("vpn")
+	if err != nil {
+		return nil, err
+	}
+	routes, err := network.GetRoutes(ctx, network.RouteOptions{
+		Family:    args.String("family"),
+		Interface: args.String("interface"),
+		VPN:       vpn,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return types.RoutesResponse{
+		Routes: routes,
+		Count:  len(routes),
+	}, nil
+}
+
+func listNeighbors(ctx context.Context, args Arguments) (interface{}, error) {
+	neighbors, err := network.GetNeighbors(ctx, network.NeighborOptions{
+		Family:    args.String("family"),
+		Interface: args.String("interface"),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return types.NeighborsResponse{
+		Neighbors: neighbors,
+		Count:     len(neighbors),
+	}, nil
+}
+
+func getDNSConfig(ctx context.Context, args Arguments) (interface{}, error) {
+	cache, err := args.Bool("cache")
+	if err != nil {
+		return nil, err
+	}
+	source, resolvers, err := network.GetDNSConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := types.DNSResponse{
+		Source:    source,
+		Resolvers: resolvers,
+	}
+	if cache {
+		entries, err := network.GetDNSCache(ctx, args.String("name"))
+		if err != nil {
+			resp.CacheError = err.Error()
+		} else {
+			resp.Cache = entries
+		}
+	}
+	return resp, nil
+}
+
+func getNetworkTop(ctx context.Context, args Arguments) (interface{}, error) {
+	interval := time.Second
+	if v := args.String("interval"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 100*time.Millisecond || d > 10*time.Second {
+			return nil, argumentErrorf("invalid interval %q: must be a duration between 100ms and 10s", v)
+		}
+		interval = d
+	}
+	limit, hasLimit, err := args.Int("limit")
+	if err != nil {
+		return nil, err
+	}
+	if !hasLimit {
+		limit = 10
+	}
+	if limit < 0 {
+		return nil, argumentErrorf("invalid limit: %d", limit)
+	}
+
+	usages, err := resource.GetNetworkTop(ctx, interval, int(limit))
+	if err != nil {
+		return nil, err
+	}
+	return types.NetworkTopResponse{
+		Processes: usages,
+		Count:     len(usages),
+		Interval:  interval.String(),
+	}, nil
+}
+
+func getResourceUsage(ctx context.Context, args Arguments) (interface{}, error) {
+	pid, _, err := args.PID("pid")
+	if err != nil {
+		return nil, err
+	}
+
+	usage, err := resource.GetProcessResourceUsage(ctx, pid)
+	if err != nil {
+		return nil, err
+	}
+
+	return types.ResourceResponse{
+		Usage: *usage,
+	}, nil
+}
+
+func getSystemMetrics(ctx context.Context, args Arguments) (interface{}, error) {
+	metrics, err := system.GetMetrics(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return *metrics, nil
+}
+
+func listServices(ctx context.Context, args Arguments) (interface{}, error) {
+	name := args.String("name")
+	if _, err := path.Match(name, ""); err != nil {
+		return nil, argumentErrorf("invalid name: %q is not a valid glob", name)
+	}
+	sortBy, descending := sortArgs(args)
+	services, err := service.GetServices(ctx, service.ListOptions{
+		SortBy:     sortBy,
+		Descending: descending,
+		Status:     args.String("status"),
+		Name:       name,
+		Domain:     args.String("domain"),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return types.ServicesResponse{
+		Services: services,
+		Count:    len(services),
+	}, nil
+}
+
+func listFailedServices(ctx context.Context, args Arguments) (interface{}, error) {
+	lines, hasLines, err := args.Int("lines")
+	if err != nil {
+		return nil, err
+	}
+	if !hasLines {
+		lines = service.DefaultFailedLogLines
+	}
+	if lines < 0 || lines > service.MaxFailedLogLines {
+		return nil, argumentErrorf("invalid lines: %d (must be between 0 and %d)", lines, service.MaxFailedLogLines)
+	}
+
+	failed, err := service.GetFailedServices(ctx, service.FailedOptions{LogLines: int(lines)})
+	if err != nil {
+		return nil, err
+	}
+	return types.FailedServicesResponse{
+		Services: failed,
+		Count:    len(failed),
+	}, nil
+}
+
+func listScheduledJobs(ctx context.Context, args Arguments) (interface{}, error) {
+	jobs, err := service.GetScheduledJobs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return types.ScheduledJobsResponse{
+		Jobs:  jobs,
+		Count: len(jobs),
+	}, nil
+}
+
+func getService(ctx context.Context, args Arguments) (interface{}, error) {
+	name := args.String("name")
+	if err := service.ValidName(name); err != nil {
+		return nil, argumentErrorf("%v", err)
+	}
+	user, err := args.Bool("user")
+	if err != nil {
+		return nil, err
+	}
+	return service.GetService(ctx, name, service.ControlOptions{User: user})
+}
+
+func getServiceLogs(ctx context.Context, args Arguments) (interface{}, error) {
+	name := args.String("name")
+	if name == "" {
+		return nil, argumentErrorf("invalid name: must not be empty")
+	}
+	lines, hasLines, err := args.Int("lines")
+	if err != nil {
+		return nil, err
+	}
+	if hasLines && (lines < 1 || lines > service.MaxLogLines) {
+		return nil, argumentErrorf("invalid lines: %d (must be between 1 and %d)", lines, service.MaxLogLines)
+	}
+	since, err := parseTimeArg(args, "since")
+	if err != nil {
+		return nil, err
+	}
+	user, err := args.Bool("user")
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := service.GetLogs(ctx, name, service.LogOptions{Lines: int(lines), Since: since, User: user})
+	if err != nil {
+		return nil, err
+	}
+	return types.ServiceLogsResponse{
+		Name:    name,
+		Entries: entries,
+		Count:   len(entries),
+	}, nil
+}
+
+func controlService(ctx context.Context, args Arguments) (interface{}, error) {
+	name := args.String("name")
+	if name == "" {
+		return nil, argumentErrorf("invalid name: must not be empty")
+	}
+	if err := service.ValidName(name); err != nil {
+		return nil, argumentErrorf("%v", err)
+	}
+	user, err := args.Bool("user")
+	if err != nil {
+		return nil, err
+	}
+	return service.Control(ctx, name, args.String("action"), service.ControlOptions{User: user})
+}
+
+// sortArgs returns the sort key and direction requested in args
+func sortArgs(args Arguments) (string, bool) {
+	sortBy := args.String("sort")
+	return sortBy, utils.Descending(sortBy, args.String("order"))
+}
@@ -0,0 +1,60 @@
+package mcp
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/borankux/gops/pkg/types"
+)
+
+// API path prefixes. v2 is the current API; v1 routes are thin adapters
+// over the same handlers, kept for existing clients.
+const (
+	apiV1Prefix = "/mcp/v1/"
+	apiV2Prefix = "/mcp/v2/"
+)
+
+// v1Path returns the legacy v1 route for a v2 path
+func v1Path(path string) string {
+	return apiV1Prefix + strings.TrimPrefix(path, apiV2Prefix)
+}
+
+// apiVersion returns the response schema version served at the request
+// path, or 0 for v1 routes, whose responses predate schema_version
+func apiVersion(r *http.Request) int {
+	if strings.HasPrefix(r.URL.Path, apiV2Prefix) {
+		return types.SchemaVersion
+	}
+	return 0
+}
+
+// withSchemaVersion stamps a response with its schema version. Results
+// are either response structs with a SchemaVersion field or projected maps;
+// either way the stamp goes on a copy, leaving result as it was.
+func withSchemaVersion(result interface{}, version int) interface{} {
+	if version == 0 {
+		return result
+	}
+	if m, ok := result.(map[string]interface{}); ok {
+		stamped := make(map[string]interface{}, len(m)+1)
+		for k, v := range m {
+			stamped[k] = v
+		}
+		stamped["schema_version"] = version
+		return stamped
+	}
+
+	v := reflect.ValueOf(result)
+	if v.Kind() != reflect.Struct {
+		return result
+	}
+	stamped := reflect.New(v.Type()).Elem()
+	stamped.Set(v)
+	field := stamped.FieldByName("SchemaVersion")
+	if !field.IsValid() || field.Kind() != reflect.Int {
+		return result
+	}
+	field.SetInt(int64(version))
+	return stamped.Interface()
+}
@@ -0,0 +1,78 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/borankux/gops/pkg/types"
+)
+
+// errWebhooksNeedAuth refuses changes to the webhooks of a server without
+// an auth token, as anyone who can reach it could have events sent
+// wherever they like
+var errWebhooksNeedAuth = fmt.Errorf("registering and removing webhooks requires the server to run with -auth-token: %w", os.ErrPermission)
+
+// handleWebhooks lists (GET) and registers (POST) webhooks
+func (s *Server) handleWebhooks(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	switch r.Method {
+	case http.MethodGet:
+		hooks := s.webhooks.List()
+		s.sendJSON(w, withSchemaVersion(types.WebhooksResponse{
+			Webhooks: hooks,
+			Count:    len(hooks),
+		}, apiVersion(r)))
+	case http.MethodPost:
+		if s.config.AuthToken == "" {
+			s.sendError(w, r, errWebhooksNeedAuth)
+			return
+		}
+		if !requireJSON(w, r) {
+			return
+		}
+		var hook types.Webhook
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxMessageSize)).Decode(&hook); err != nil {
+			s.sendError(w, r, argumentErrorf("invalid webhook: %v", err))
+			return
+		}
+		created, err := s.webhooks.Add(hook)
+		if err != nil {
+			s.sendError(w, r, argumentErrorf("%v", err))
+			return
+		}
+		s.startWatcher()
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(created)
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		json.NewEncoder(w).Encode(types.ErrorResponse{Error: "method not allowed"})
+	}
+}
+
+// handleWebhook removes the webhook named by the last path segment
+func (s *Server) handleWebhook(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if r.Method != http.MethodDelete {
+		w.Header().Set("Allow", "DELETE")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		json.NewEncoder(w).Encode(types.ErrorResponse{Error: "method not allowed"})
+		return
+	}
+
+	if s.config.AuthToken == "" {
+		s.sendError(w, r, errWebhooksNeedAuth)
+		return
+	}
+	id := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+	if !s.webhooks.Remove(id) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(withSchemaVersion(types.ErrorResponse{Error: "unknown webhook: " + id}, apiVersion(r)))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,84 @@
+package mcp
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/borankux/gops/internal/events"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// wsWriteTimeout bounds how long a single event write may block
+	wsWriteTimeout = 10 * time.Second
+
+	// wsPingInterval keeps idle connections alive through proxies
+	wsPingInterval = 30 * time.Second
+)
+
+// handleWebSocket streams system events to the client as JSON messages.
+// The optional types query parameter restricts the stream to the given
+// event types or categories, e.g. ?types=process,port.opened
+func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
+	filters := events.ParseFilter(r.URL.Query().Get("types"))
+
+	upgrader := websocket.Upgrader{
+		ReadBufferSize:  1024,
+		WriteBufferSize: 4096,
+		CheckOrigin:     s.checkOrigin,
+	}
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrade has already replied with an HTTP error
+		return
+	}
+	defer conn.Close()
+	s.streams.Add(1)
+	defer s.streams.Done()
+
+	s.startWatcher()
+	sub := s.bus.Subscribe(64)
+	defer sub.Close()
+
+	// Drain client messages so control frames are processed and a
+	// disconnect is noticed promptly
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ping := time.NewTicker(wsPingInterval)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-closed:
+			return
+		case <-s.lifetime.Done():
+			msg := websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down")
+			conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(wsWriteTimeout))
+			return
+		case event, ok := <-sub.C:
+			if !ok {
+				return
+			}
+			if !events.Matches(filters, event.Type) {
+				continue
+			}
+			conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+			if err := conn.WriteJSON(event); err != nil {
+				return
+			}
+		case <-ping.C:
+			deadline := time.Now().Add(wsWriteTimeout)
+			if err := conn.WriteControl(websocket.PingMessage, nil, deadline); err != nil {
+				return
+			}
+		}
+	}
+}
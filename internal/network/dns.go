@@ -0,0 +1,413 @@
+package network
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/borankux/gops/pkg/types"
+)
+
+// Sources of the DNS configuration reported in DNSResponse.Source
+const (
+	DNSSourceScutil          = "scutil"
+	DNSSourceResolvConf      = "resolv.conf"
+	DNSSourceSystemdResolved = "systemd-resolved"
+	DNSSourceDNSClient       = "dnsclient"
+)
+
+const resolvConf = "/etc/resolv.conf"
+
+// stubResolver is the address systemd-resolved listens on and writes to
+// resolv.conf in place of the real nameservers
+const stubResolver = "127.0.0.53"
+
+// dnsRecordTypes names the numeric record types Get-DnsClientCache
+// reports
+var dnsRecordTypes = map[int]string{
+	1:  "A",
+	2:  "NS",
+	5:  "CNAME",
+	6:  "SOA",
+	12: "PTR",
+	15: "MX",
+	16: "TXT",
+	28: "AAAA",
+	33: "SRV",
+	65: "HTTPS",
+}
+
+// GetDNSConfig returns the resolvers the system sends queries to, with
+// their search domains, and the name of what it read them from. macOS
+// reads them with scutil, Linux from resolv.conf or, when that points at
+// systemd-resolved's stub, from resolvectl, and Windows with
+// Get-DnsClientServerAddress.
+func GetDNSConfig(ctx context.Context) (string, []types.DNSResolver, error) {
+	switch runtime.GOOS {
+	case "darwin":
+		resolvers, err := scutilResolvers(ctx)
+		return DNSSourceScutil, resolvers, err
+	case "linux":
+		resolvers, err := resolvConfResolvers(resolvConf)
+		if err != nil || !usesStubResolver(resolvers) {
+			return DNSSourceResolvConf, resolvers, err
+		}
+		if resolved, err := resolvectlResolvers(ctx); err == nil && len(resolved) > 0 {
+			return DNSSourceSystemdResolved, resolved, nil
+		}
+		return DNSSourceResolvConf, resolvers, nil
+	case "windows":
+		resolvers, err := windowsResolvers(ctx)
+		return DNSSourceDNSClient, resolvers, err
+	default:
+		return "", nil, errors.New("reading the DNS configuration is not supported on " + runtime.GOOS)
+	}
+}
+
+// GetDNSCache returns the records in the system's DNS cache whose name
+// contains name, or all of them when name is empty. Linux reads the cache
+// of systemd-resolved, which needs root, and Windows that of the DNS
+// Client service; macOS's mDNSResponder offers no way to list its cache.
+func GetDNSCache(ctx context.Context, name string) ([]types.DNSCacheEntry, error) {
+	var entries []types.DNSCacheEntry
+	var err error
+	switch runtime.GOOS {
+	case "linux":
+		entries, err = resolvedCache(ctx)
+	case "windows":
+		entries, err = windowsCache(ctx)
+	default:
+		err = errors.New("reading the DNS cache is not supported on " + runtime.GOOS)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	name = strings.ToLower(strings.TrimSuffix(name, "."))
+	result := make([]types.DNSCacheEntry, 0, len(entries))
+	for _, e := range entries {
+		e.Name = strings.TrimSuffix(e.Name, ".")
+		if name != "" && !strings.Contains(strings.ToLower(e.Name), name) {
+			continue
+		}
+		result = append(result, e)
+	}
+	return result, nil
+}
+
+// scutilResolver matches the heading of a resolver in scutil --dns
+var scutilResolver = regexp.MustCompile(`^resolver #\d+`)
+
+// scutilResolvers parses scutil --dns, which lists the resolvers used for
+// all queries and then, under "DNS configuration (for scoped queries)",
+// those bound to an interface:
+//
+//	resolver #1
+//	  search domain[0] : corp.example.com
+//	  nameserver[0] : 192.168.1.1
+//	  if_index : 6 (en0)
+//	  flags    : Request A records
+//	  reach    : 0x00020002 (Reachable,Directly Reachable Address)
+//
+//	resolver #2
+//	  domain   : local
+//	  options  : mdns
+func scutilResolvers(ctx context.Context) ([]types.DNSResolver, error) {
+	output, err := exec.CommandContext(ctx, "scutil", "--dns").Output()
+	if err != nil {
+		return nil, fmt.Errorf("scutil: %w", err)
+	}
+
+	var resolvers []types.DNSResolver
+	scoped := false
+	scanner := bufio.NewScanner(strings.NewReader(string(output)))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case strings.HasPrefix(line, "DNS configuration (for scoped queries)"):
+			scoped = true
+			continue
+		case scutilResolver.MatchString(line):
+			resolvers = append(resolvers, types.DNSResolver{Scoped: scoped})
+			continue
+		case len(resolvers) == 0:
+			continue
+		}
+		current := &resolvers[len(resolvers)-1]
+
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		switch {
+		case strings.HasPrefix(key, "nameserver["):
+			current.Nameservers = append(current.Nameservers, value)
+		case strings.HasPrefix(key, "search domain["):
+			current.SearchDomains = append(current.SearchDomains, value)
+		case key == "domain":
+			current.Domain = value
+		case key == "options":
+			current.Options = strings.Fields(value)
+		case key == "if_index":
+			// if_index : 6 (en0)
+			if _, iface, found := strings.Cut(value, "("); found {
+				current.Interface = strings.TrimSuffix(iface, ")")
+			}
+		}
+	}
+
+	// Supplemental resolvers with neither a nameserver nor a domain,
+	// which scutil lists for some VPN configurations, answer nothing
+	result := resolvers[:0]
+	for _, r := range resolvers {
+		if len(r.Nameservers) > 0 || r.Domain != "" {
+			result = append(result, r)
+		}
+	}
+	return result, nil
+}
+
+// resolvConfResolvers parses a resolv.conf, which configures a single
+// resolver; of search and domain, the last one given wins
+func resolvConfResolvers(path string) ([]types.DNSResolver, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var r types.DNSResolver
+	scanner := bufio.NewScanner(strings.NewReader(string(data)))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || strings.HasPrefix(fields[0], "#") || strings.HasPrefix(fields[0], ";") {
+			continue
+		}
+		switch fields[0] {
+		case "nameserver":
+			r.Nameservers = append(r.Nameservers, fields[1])
+		case "search":
+			r.SearchDomains, r.Domain = fields[1:], ""
+		case "domain":
+			r.Domain, r.SearchDomains = fields[1], nil
+		case "options":
+			r.Options = append(r.Options, fields[1:]...)
+		}
+	}
+	return []types.DNSResolver{r}, nil
+}
+
+// usesStubResolver reports whether resolv.conf sends every query to
+// systemd-resolved
+func usesStubResolver(resolvers []types.DNSResolver) bool {
+	return len(resolvers) == 1 && len(resolvers[0].Nameservers) == 1 &&
+		resolvers[0].Nameservers[0] == stubResolver
+}
+
+// resolvectlLink matches the heading of a link in resolvectl status
+var resolvectlLink = regexp.MustCompile(`^Link \d+ \((.+)\)$`)
+
+// resolvectlResolvers parses resolvectl status, which has a global
+// section and one per link, a value continuing on the lines below its
+// key:
+//
+//	Global
+//	       Protocols: +LLMNR +mDNS -DNSOverTLS DNSSEC=no/unsupported
+//
+//	Link 2 (eth0)
+//	     Current Scopes: DNS
+//	Current DNS Server: 192.168.1.1
+//	       DNS Servers: 192.168.1.1
+//	                    192.168.1.2
+//	        DNS Domain: corp.example.com
+//
+// Sections without DNS servers are left out.
+func resolvectlResolvers(ctx context.Context) ([]types.DNSResolver, error) {
+	output, err := exec.CommandContext(ctx, "resolvectl", "status", "--no-pager").Output()
+	if err != nil {
+		return nil, fmt.Errorf("resolvectl: %w", err)
+	}
+
+	var resolvers []types.DNSResolver
+	var key string
+	scanner := bufio.NewScanner(strings.NewReader(string(output)))
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case line == "Global":
+			resolvers = append(resolvers, types.DNSResolver{})
+			key = ""
+			continue
+		case resolvectlLink.MatchString(line):
+			m := resolvectlLink.FindStringSubmatch(line)
+			resolvers = append(resolvers, types.DNSResolver{Interface: m[1], Scoped: true})
+			key = ""
+			continue
+		case len(resolvers) == 0 || trimmed == "":
+			continue
+		}
+		current := &resolvers[len(resolvers)-1]
+
+		// The padding lets a key with an empty value match
+		value := trimmed
+		if k, v, found := strings.Cut(trimmed+" ", ": "); found {
+			key, value = k, v
+		}
+		switch key {
+		case "DNS Servers":
+			current.Nameservers = append(current.Nameservers, strings.Fields(value)...)
+		case "DNS Domain":
+			current.SearchDomains = append(current.SearchDomains, strings.Fields(value)...)
+		}
+	}
+
+	result := resolvers[:0]
+	for _, r := range resolvers {
+		if len(r.Nameservers) > 0 {
+			result = append(result, r)
+		}
+	}
+	return result, nil
+}
+
+// windowsResolvers reads the nameservers of each interface with
+// Get-DnsClientServerAddress and the global suffix search list
+func windowsResolvers(ctx context.Context) ([]types.DNSResolver, error) {
+	psScript := `
+		$suffixes = @((Get-DnsClientGlobalSetting).SuffixSearchList)
+		@(Get-DnsClientServerAddress | Where-Object { $_.ServerAddresses.Count -gt 0 } | ForEach-Object {
+			$client = Get-DnsClient -InterfaceIndex $_.InterfaceIndex -ErrorAction SilentlyContinue
+			[pscustomobject]@{
+				dev = $_.InterfaceAlias
+				servers = @($_.ServerAddresses)
+				suffix = [string]$client.ConnectionSpecificSuffix
+				search = $suffixes
+			}
+		}) | ConvertTo-Json -Compress -Depth 3
+	`
+	output, err := exec.CommandContext(ctx, "powershell", "-Command", psScript).Output()
+	if err != nil {
+		return nil, errors.New("failed to read DNS servers with Get-DnsClientServerAddress")
+	}
+	output = []byte(strings.TrimSpace(string(output)))
+	if len(output) == 0 {
+		return nil, nil
+	}
+	// ConvertTo-Json writes a lone interface as an object
+	if output[0] == '{' {
+		output = append(append([]byte{'['}, output...), ']')
+	}
+	var parsed []struct {
+		Dev     string   `json:"dev"`
+		Servers []string `json:"servers"`
+		Suffix  string   `json:"suffix"`
+		Search  []string `json:"search"`
+	}
+	if err := json.Unmarshal(output, &parsed); err != nil {
+		return nil, fmt.Errorf("parsing Get-DnsClientServerAddress output: %w", err)
+	}
+
+	resolvers := make([]types.DNSResolver, 0, len(parsed))
+	for _, p := range parsed {
+		resolvers = append(resolvers, types.DNSResolver{
+			Nameservers:   p.Servers,
+			SearchDomains: p.Search,
+			Domain:        p.Suffix,
+			Interface:     p.Dev,
+			Scoped:        true,
+		})
+	}
+	return resolvers, nil
+}
+
+// resolvedCache parses resolvectl show-cache, which lists the records
+// cached per scope:
+//
+//	Scope protocol=dns interface=eth0:
+//	example.com IN A 93.184.216.34
+//	www.example.com IN CNAME example.com
+func resolvedCache(ctx context.Context) ([]types.DNSCacheEntry, error) {
+	output, err := exec.CommandContext(ctx, "resolvectl", "show-cache", "--no-pager").Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return nil, errors.New("resolvectl: " + strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, fmt.Errorf("resolvectl: %w", err)
+	}
+
+	var entries []types.DNSCacheEntry
+	scanner := bufio.NewScanner(strings.NewReader(string(output)))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 || fields[1] != "IN" {
+			continue
+		}
+		entries = append(entries, types.DNSCacheEntry{
+			Name: fields[0],
+			Type: fields[2],
+			Data: strings.Join(fields[3:], " "),
+		})
+	}
+	return entries, nil
+}
+
+// windowsCache reads the DNS Client service's cache with
+// Get-DnsClientCache
+func windowsCache(ctx context.Context) ([]types.DNSCacheEntry, error) {
+	psScript := `
+		@(Get-DnsClientCache | ForEach-Object {
+			[pscustomobject]@{
+				name = $_.Entry
+				type = [int]$_.Type
+				data = [string]$_.Data
+				ttl = [int]$_.TimeToLive
+			}
+		}) | ConvertTo-Json -Compress
+	`
+	output, err := exec.CommandContext(ctx, "powershell", "-Command", psScript).Output()
+	if err != nil {
+		return nil, errors.New("failed to read the DNS cache with Get-DnsClientCache")
+	}
+	output = []byte(strings.TrimSpace(string(output)))
+	if len(output) == 0 {
+		return nil, nil
+	}
+	// ConvertTo-Json writes a lone record as an object
+	if output[0] == '{' {
+		output = append(append([]byte{'['}, output...), ']')
+	}
+	var parsed []struct {
+		Name string `json:"name"`
+		Type int    `json:"type"`
+		Data string `json:"data"`
+		TTL  int    `json:"ttl"`
+	}
+	if err := json.Unmarshal(output, &parsed); err != nil {
+		return nil, fmt.Errorf("parsing Get-DnsClientCache output: %w", err)
+	}
+
+	entries := make([]types.DNSCacheEntry, 0, len(parsed))
+	for _, p := range parsed {
+		recordType, known := dnsRecordTypes[p.Type]
+		if !known {
+			recordType = "TYPE" + strconv.Itoa(p.Type)
+		}
+		entries = append(entries, types.DNSCacheEntry{
+			Name: p.Name,
+			Type: recordType,
+			Data: p.Data,
+			TTL:  p.TTL,
+		})
+	}
+	return entries, nil
+}
@@ -0,0 +1,89 @@
+package network
+
+import (
+	"context"
+	stdnet "net"
+	"sort"
+	"strings"
+
+	"github.com/borankux/gops/pkg/types"
+	"github.com/shirou/gopsutil/v3/net"
+)
+
+// Link states reported in InterfaceInfo.State
+const (
+	StateUp   = "up"
+	StateDown = "down"
+)
+
+// GetInterfaces returns the network interfaces with their addresses, link
+// state and traffic counters, ordered by index. Interfaces the platform
+// reports no counters for have them zero.
+func GetInterfaces(ctx context.Context) ([]types.InterfaceInfo, error) {
+	ifaces, err := net.InterfacesWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// gopsutil leaves out whether the link is running
+	flags := make(map[string]stdnet.Flags)
+	if std, err := stdnet.Interfaces(); err == nil {
+		for _, iface := range std {
+			flags[iface.Name] = iface.Flags
+		}
+	}
+	counters := make(map[string]net.IOCountersStat)
+	if stats, err := net.IOCountersWithContext(ctx, true); err == nil {
+		for _, s := range stats {
+			counters[s.Name] = s
+		}
+	}
+
+	result := make([]types.InterfaceInfo, 0, len(ifaces))
+	for _, iface := range ifaces {
+		info := types.InterfaceInfo{
+			Name:      iface.Name,
+			Index:     iface.Index,
+			MAC:       iface.HardwareAddr,
+			MTU:       iface.MTU,
+			State:     linkState(flags[iface.Name]),
+			Flags:     flagNames(flags[iface.Name]),
+			Addresses: make([]string, 0, len(iface.Addrs)),
+		}
+		for _, addr := range iface.Addrs {
+			info.Addresses = append(info.Addresses, addr.Addr)
+		}
+		if c, ok := counters[iface.Name]; ok {
+			info.BytesSent = c.BytesSent
+			info.BytesReceived = c.BytesRecv
+			info.PacketsSent = c.PacketsSent
+			info.PacketsReceived = c.PacketsRecv
+			info.ErrorsIn = c.Errin
+			info.ErrorsOut = c.Errout
+			info.DropsIn = c.Dropin
+			info.DropsOut = c.Dropout
+		}
+		result = append(result, info)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Index < result[j].Index
+	})
+	return result, nil
+}
+
+// linkState reads whether an interface is up from its flags: it is
+// administratively up and its link is running
+func linkState(flags stdnet.Flags) string {
+	if flags&stdnet.FlagUp != 0 && flags&stdnet.FlagRunning != 0 {
+		return StateUp
+	}
+	return StateDown
+}
+
+// flagNames lists the names of the flags set, e.g. up and multicast
+func flagNames(flags stdnet.Flags) []string {
+	if flags == 0 {
+		return []string{}
+	}
+	return strings.Split(flags.String(), "|")
+}
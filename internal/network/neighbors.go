@@ -0,0 +1,239 @@
+package network
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/borankux/gops/pkg/types"
+)
+
+// NeighborOptions selects neighbors. Zero filters are ignored.
+type NeighborOptions struct {
+	// Family is ipv4 (ARP) or ipv6 (NDP)
+	Family string
+	// Interface keeps neighbors reached through this interface
+	Interface string
+}
+
+// GetNeighbors returns the ARP and NDP neighbor tables: the hosts on the
+// local networks this one has recently exchanged packets with, and their
+// link-layer addresses. macOS reads them with arp and ndp, Linux with ip
+// and Windows with Get-NetNeighbor.
+func GetNeighbors(ctx context.Context, opts NeighborOptions) ([]types.NeighborInfo, error) {
+	var neighbors []types.NeighborInfo
+	var err error
+	switch runtime.GOOS {
+	case "darwin":
+		neighbors, err = macOSNeighbors(ctx)
+	case "linux":
+		neighbors, err = ipNeighbors(ctx)
+	case "windows":
+		neighbors, err = windowsNeighbors(ctx)
+	default:
+		err = errors.New("listing neighbors is not supported on " + runtime.GOOS)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var result []types.NeighborInfo
+	for _, n := range neighbors {
+		if (opts.Family != "" && n.Family != opts.Family) ||
+			(opts.Interface != "" && n.Interface != opts.Interface) {
+			continue
+		}
+		result = append(result, n)
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Family != result[j].Family {
+			return result[i].Family < result[j].Family
+		}
+		if result[i].Interface != result[j].Interface {
+			return result[i].Interface < result[j].Interface
+		}
+		return result[i].IP < result[j].IP
+	})
+	return result, nil
+}
+
+// arpEntry matches a line of arp -an:
+//
+//	? (192.168.1.1) at 0:11:22:33:44:55 on en0 ifscope [ethernet]
+//	? (192.168.1.7) at (incomplete) on en0 ifscope [ethernet]
+var arpEntry = regexp.MustCompile(`\(([^)]+)\) at (\S+) on (\S+)`)
+
+// ndpStates names the states in the St column of ndp -an
+var ndpStates = map[string]string{
+	"R": "reachable",
+	"S": "stale",
+	"D": "delay",
+	"P": "probe",
+	"I": "incomplete",
+	"N": "none",
+	"W": "waitdelete",
+}
+
+// macOSNeighbors reads the ARP table with arp and the IPv6 neighbor cache
+// with ndp:
+//
+//	Neighbor                        Linklayer Address  Netif Expire    St Flgs Prbs
+//	fe80::1%en0                     0:11:22:33:44:55     en0 23h59m58s S  R
+func macOSNeighbors(ctx context.Context) ([]types.NeighborInfo, error) {
+	output, err := exec.CommandContext(ctx, "arp", "-an").Output()
+	if err != nil {
+		return nil, fmt.Errorf("arp: %w", err)
+	}
+	var neighbors []types.NeighborInfo
+	scanner := bufio.NewScanner(strings.NewReader(string(output)))
+	for scanner.Scan() {
+		line := scanner.Text()
+		m := arpEntry.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		n := types.NeighborInfo{IP: m[1], Interface: m[3], Family: FamilyIPv4}
+		switch {
+		case m[2] == "(incomplete)":
+			n.State = "incomplete"
+		case strings.Contains(line, "permanent"):
+			n.MAC, n.State = normalizeMAC(m[2]), "permanent"
+		default:
+			n.MAC = normalizeMAC(m[2])
+		}
+		neighbors = append(neighbors, n)
+	}
+
+	// ndp may be missing from stripped-down systems; ARP is still useful
+	output, err = exec.CommandContext(ctx, "ndp", "-an").Output()
+	if err != nil {
+		return neighbors, nil
+	}
+	scanner = bufio.NewScanner(strings.NewReader(string(output)))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 5 || fields[0] == "Neighbor" {
+			continue
+		}
+		ip, _, _ := strings.Cut(fields[0], "%")
+		n := types.NeighborInfo{IP: ip, Interface: fields[2], Family: FamilyIPv6, State: ndpStates[fields[4]]}
+		if fields[1] != "(incomplete)" {
+			n.MAC = normalizeMAC(fields[1])
+		}
+		if fields[3] == "permanent" {
+			n.State = "permanent"
+		}
+		neighbors = append(neighbors, n)
+	}
+	return neighbors, nil
+}
+
+// normalizeMAC writes a MAC address as lower-case two-digit hex pairs;
+// macOS drops leading zeros, as in 0:1b:63:84:45:e6
+func normalizeMAC(mac string) string {
+	parts := strings.FieldsFunc(strings.ToLower(mac), func(r rune) bool { return r == ':' || r == '-' })
+	for i, p := range parts {
+		if len(p) == 1 {
+			parts[i] = "0" + p
+		}
+	}
+	return strings.Join(parts, ":")
+}
+
+// ipNeighbors reads the neighbor table with ip's JSON output:
+//
+//	[{"dst":"192.0.2.1","dev":"eth0","lladdr":"02:fc:00:00:00:02","state":["REACHABLE"]}]
+func ipNeighbors(ctx context.Context) ([]types.NeighborInfo, error) {
+	output, err := exec.CommandContext(ctx, "ip", "-j", "neigh", "show").Output()
+	if err != nil {
+		return nil, fmt.Errorf("ip neigh: %w", err)
+	}
+	var parsed []struct {
+		Dst    string   `json:"dst"`
+		Dev    string   `json:"dev"`
+		LLAddr string   `json:"lladdr"`
+		State  []string `json:"state"`
+	}
+	if err := json.Unmarshal(output, &parsed); err != nil {
+		return nil, fmt.Errorf("parsing ip neigh output: %w", err)
+	}
+
+	neighbors := make([]types.NeighborInfo, 0, len(parsed))
+	for _, p := range parsed {
+		n := types.NeighborInfo{
+			IP:        p.Dst,
+			MAC:       p.LLAddr,
+			Interface: p.Dev,
+			Family:    FamilyIPv4,
+			State:     strings.ToLower(strings.Join(p.State, ",")),
+		}
+		if strings.Contains(p.Dst, ":") {
+			n.Family = FamilyIPv6
+		}
+		neighbors = append(neighbors, n)
+	}
+	return neighbors, nil
+}
+
+// windowsNeighbors reads the neighbor table with Get-NetNeighbor, leaving
+// out the unreachable entries Windows keeps for every multicast address
+func windowsNeighbors(ctx context.Context) ([]types.NeighborInfo, error) {
+	psScript := `
+		@(Get-NetNeighbor | Where-Object { $_.State -ne 'Unreachable' } | ForEach-Object {
+			[pscustomobject]@{
+				ip = $_.IPAddress
+				mac = $_.LinkLayerAddress
+				dev = $_.InterfaceAlias
+				state = [string]$_.State
+				family = [string]$_.AddressFamily
+			}
+		}) | ConvertTo-Json -Compress
+	`
+	output, err := exec.CommandContext(ctx, "powershell", "-Command", psScript).Output()
+	if err != nil {
+		return nil, errors.New("failed to read neighbors with Get-NetNeighbor")
+	}
+	output = []byte(strings.TrimSpace(string(output)))
+	if len(output) == 0 {
+		return nil, nil
+	}
+	// ConvertTo-Json writes a lone neighbor as an object
+	if output[0] == '{' {
+		output = append(append([]byte{'['}, output...), ']')
+	}
+	var parsed []struct {
+		IP     string `json:"ip"`
+		MAC    string `json:"mac"`
+		Dev    string `json:"dev"`
+		State  string `json:"state"`
+		Family string `json:"family"`
+	}
+	if err := json.Unmarshal(output, &parsed); err != nil {
+		return nil, fmt.Errorf("parsing Get-NetNeighbor output: %w", err)
+	}
+
+	neighbors := make([]types.NeighborInfo, 0, len(parsed))
+	for _, p := range parsed {
+		n := types.NeighborInfo{
+			IP:        p.IP,
+			Interface: p.Dev,
+			Family:    FamilyIPv4,
+			State:     strings.ToLower(p.State),
+		}
+		if p.Family == "IPv6" {
+			n.Family = FamilyIPv6
+		}
+		if mac := normalizeMAC(p.MAC); mac != "00:00:00:00:00:00" {
+			n.MAC = mac
+		}
+		neighbors = append(neighbors, n)
+	}
+	return neighbors, nil
+}
@@ -0,0 +1,259 @@
+package network
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/borankux/gops/pkg/types"
+)
+
+// Address families reported in RouteInfo.Family
+const (
+	FamilyIPv4 = "ipv4"
+	FamilyIPv6 = "ipv6"
+)
+
+// Families lists the values accepted by RouteOptions.Family and
+// NeighborOptions.Family
+var Families = []string{FamilyIPv4, FamilyIPv6}
+
+// vpnPrefixes are the interface name prefixes of VPN tunnels: utun and
+// ipsec on macOS, tun, tap, wg and ppp on Linux, and those of common VPN
+// clients
+var vpnPrefixes = []string{"utun", "ipsec", "tun", "tap", "wg", "ppp", "tailscale", "zt", "nordlynx"}
+
+// vpnNames are substrings of Windows interface aliases naming VPN
+// adapters
+var vpnNames = []string{"vpn", "wireguard", "tap-windows", "tailscale", "openvpn", "wintun"}
+
+// RouteOptions selects routes. Zero filters are ignored.
+type RouteOptions struct {
+	// Family is ipv4 or ipv6
+	Family string
+	// Interface keeps routes through this interface
+	Interface string
+	// VPN keeps routes through VPN tunnels
+	VPN bool
+}
+
+// GetRoutes returns the routing table, default routes first, then by
+// family and destination. macOS reads it with netstat, Linux with ip and
+// Windows with Get-NetRoute.
+func GetRoutes(ctx context.Context, opts RouteOptions) ([]types.RouteInfo, error) {
+	var routes []types.RouteInfo
+	var err error
+	switch runtime.GOOS {
+	case "darwin":
+		routes, err = netstatRoutes(ctx)
+	case "linux":
+		routes, err = ipRoutes(ctx)
+	case "windows":
+		routes, err = windowsRoutes(ctx)
+	default:
+		err = errors.New("listing routes is not supported on " + runtime.GOOS)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var result []types.RouteInfo
+	for _, r := range routes {
+		r.Default = isDefault(r.Destination)
+		r.VPN = isVPN(r.Interface)
+		if (opts.Family != "" && r.Family != opts.Family) ||
+			(opts.Interface != "" && r.Interface != opts.Interface) ||
+			(opts.VPN && !r.VPN) {
+			continue
+		}
+		result = append(result, r)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if a.Default != b.Default {
+			return a.Default
+		}
+		if a.Family != b.Family {
+			return a.Family < b.Family
+		}
+		if a.Default && a.Metric != b.Metric {
+			return a.Metric < b.Metric
+		}
+		return a.Destination < b.Destination
+	})
+	return result, nil
+}
+
+// isDefault reports whether destination covers every address
+func isDefault(destination string) bool {
+	switch destination {
+	case "default", "0.0.0.0/0", "::/0":
+		return true
+	}
+	return false
+}
+
+// isVPN reports whether iface is a VPN tunnel, going by its name
+func isVPN(iface string) bool {
+	name := strings.ToLower(iface)
+	for _, prefix := range vpnPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	if runtime.GOOS == "windows" {
+		for _, s := range vpnNames {
+			if strings.Contains(name, s) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// netstatRoutes parses netstat -rn, whose IPv4 and IPv6 tables follow
+// "Internet:" and "Internet6:" headings:
+//
+//	Destination        Gateway            Flags           Netif Expire
+//	default            192.168.1.1        UGScg             en0
+//	10.8.0/24          link#21            UCS             utun3
+func netstatRoutes(ctx context.Context) ([]types.RouteInfo, error) {
+	output, err := exec.CommandContext(ctx, "netstat", "-rn").Output()
+	if err != nil {
+		return nil, err
+	}
+
+	var routes []types.RouteInfo
+	var family string
+	scanner := bufio.NewScanner(strings.NewReader(string(output)))
+	for scanner.Scan() {
+		line := scanner.Text()
+		fields := strings.Fields(line)
+		switch {
+		case line == "Internet:":
+			family = FamilyIPv4
+		case line == "Internet6:":
+			family = FamilyIPv6
+		case family == "" || len(fields) < 4 || fields[0] == "Destination":
+		default:
+			routes = append(routes, types.RouteInfo{
+				Destination: fields[0],
+				Gateway:     fields[1],
+				Interface:   fields[3],
+				Flags:       fields[2],
+				Family:      family,
+			})
+		}
+	}
+	return routes, nil
+}
+
+// ipRoute is a route as ip -j route prints it
+type ipRoute struct {
+	Dst      string   `json:"dst"`
+	Gateway  string   `json:"gateway"`
+	Dev      string   `json:"dev"`
+	Protocol string   `json:"protocol"`
+	Metric   int      `json:"metric"`
+	Flags    []string `json:"flags"`
+}
+
+// ipRoutes reads the main IPv4 and IPv6 routing tables with ip's JSON
+// output
+func ipRoutes(ctx context.Context) ([]types.RouteInfo, error) {
+	var routes []types.RouteInfo
+	for _, family := range Families {
+		flag := "-4"
+		if family == FamilyIPv6 {
+			flag = "-6"
+		}
+		output, err := exec.CommandContext(ctx, "ip", "-j", flag, "route", "show").Output()
+		if err != nil {
+			// Hosts with IPv6 disabled have no IPv6 table
+			if family == FamilyIPv6 {
+				continue
+			}
+			return nil, fmt.Errorf("ip route: %w", err)
+		}
+		var parsed []ipRoute
+		if err := json.Unmarshal(output, &parsed); err != nil {
+			return nil, fmt.Errorf("parsing ip route output: %w", err)
+		}
+		for _, r := range parsed {
+			routes = append(routes, types.RouteInfo{
+				Destination: r.Dst,
+				Gateway:     r.Gateway,
+				Interface:   r.Dev,
+				Metric:      r.Metric,
+				Flags:       strings.Join(r.Flags, ","),
+				Protocol:    r.Protocol,
+				Family:      family,
+			})
+		}
+	}
+	return routes, nil
+}
+
+// windowsRoutes reads the routing table with Get-NetRoute
+func windowsRoutes(ctx context.Context) ([]types.RouteInfo, error) {
+	psScript := `
+		@(Get-NetRoute | ForEach-Object {
+			[pscustomobject]@{
+				dst = $_.DestinationPrefix
+				gateway = $_.NextHop
+				dev = $_.InterfaceAlias
+				metric = [int]($_.RouteMetric + $_.InterfaceMetric)
+				protocol = [string]$_.Protocol
+				family = [string]$_.AddressFamily
+			}
+		}) | ConvertTo-Json -Compress
+	`
+	output, err := exec.CommandContext(ctx, "powershell", "-Command", psScript).Output()
+	if err != nil {
+		return nil, errors.New("failed to read routes with Get-NetRoute")
+	}
+	output = []byte(strings.TrimSpace(string(output)))
+	if len(output) == 0 {
+		return nil, nil
+	}
+	// ConvertTo-Json writes a lone route as an object
+	if output[0] == '{' {
+		output = append(append([]byte{'['}, output...), ']')
+	}
+	var parsed []struct {
+		ipRoute
+		Family string `json:"family"`
+	}
+	if err := json.Unmarshal(output, &parsed); err != nil {
+		return nil, fmt.Errorf("parsing Get-NetRoute output: %w", err)
+	}
+
+	routes := make([]types.RouteInfo, 0, len(parsed))
+	for _, r := range parsed {
+		family := FamilyIPv4
+		if r.Family == "IPv6" {
+			family = FamilyIPv6
+		}
+		gateway := r.Gateway
+		// On-link routes have an unspecified next hop
+		if gateway == "0.0.0.0" || gateway == "::" {
+			gateway = ""
+		}
+		routes = append(routes, types.RouteInfo{
+			Destination: r.Dst,
+			Gateway:     gateway,
+			Interface:   r.Dev,
+			Metric:      r.Metric,
+			Protocol:    strings.ToLower(r.Protocol),
+			Family:      family,
+		})
+	}
+	return routes, nil
+}
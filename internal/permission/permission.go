@@ -0,0 +1,186 @@
+package permission
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/borankux/gops/pkg/types"
+)
+
+// Permissions that collectors depend on
+const (
+	Accessibility   = "accessibility"
+	Automation      = "automation"
+	ScreenRecording = "screen_recording"
+	FullDiskAccess  = "full_disk_access"
+)
+
+// settings describes where each permission is granted
+var settings = map[string]struct {
+	label string
+	pane  string // Privacy & Security pane anchor for x-apple.systempreferences URLs
+}{
+	Accessibility:   {"Accessibility", "Privacy_Accessibility"},
+	Automation:      {"Automation", "Privacy_Automation"},
+	ScreenRecording: {"Screen Recording", "Privacy_ScreenCapture"},
+	FullDiskAccess:  {"Full Disk Access", "Privacy_AllFiles"},
+}
+
+// Label returns the name System Settings shows for a permission
+func Label(name string) string {
+	return settings[name].label
+}
+
+// SettingsPath returns where a permission is granted in System Settings
+func SettingsPath(name string) string {
+	return "System Settings > Privacy & Security > " + Label(name)
+}
+
+// SettingsURL returns a URL that opens the System Settings pane granting a
+// permission, e.g. with open(1)
+func SettingsURL(name string) string {
+	return "x-apple.systempreferences:com.apple.preference.security?" + settings[name].pane
+}
+
+// Error reports that an operation failed because gops lacks a permission
+type Error struct {
+	Permission string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s permission required: grant it to gops (or the terminal running it) in %s",
+		Label(e.Permission), SettingsPath(e.Permission))
+}
+
+// FromAppleScript returns an *Error when osascript output shows that a
+// script was refused for lack of a permission, and nil otherwise
+func FromAppleScript(output string) error {
+	switch {
+	case strings.Contains(output, "-1743"):
+		// errAEEventNotPermitted: Automation of the target app was denied
+		return &Error{Permission: Automation}
+	case strings.Contains(output, "-25211"), strings.Contains(output, "-1719"),
+		strings.Contains(output, "assistive access"):
+		return &Error{Permission: Accessibility}
+	default:
+		return nil
+	}
+}
+
+// Permission states
+const (
+	StatusGranted     = "granted"
+	StatusDenied      = "denied"
+	StatusNotRequired = "not_required"
+	StatusUnknown     = "unknown"
+)
+
+// tccDatabase is only readable by processes with Full Disk Access
+const tccDatabase = "/Library/Application Support/com.apple.TCC/TCC.db"
+
+// Check reports the status of each permission the collectors need. Only
+// macOS gates collectors behind privacy permissions.
+func Check(ctx context.Context) []types.PermissionStatus {
+	if runtime.GOOS != "darwin" {
+		return []types.PermissionStatus{
+			{Name: Accessibility, Status: StatusNotRequired},
+			{Name: Automation, Status: StatusNotRequired},
+			{Name: ScreenRecording, Status: StatusNotRequired},
+			{Name: FullDiskAccess, Status: StatusNotRequired},
+		}
+	}
+	return []types.PermissionStatus{
+		checkAccessibility(ctx),
+		checkAutomation(ctx),
+		checkScreenRecording(),
+		checkFullDiskAccess(),
+	}
+}
+
+// Denied reports whether a permission is known to be missing
+func Denied(ctx context.Context, name string) bool {
+	if runtime.GOOS != "darwin" {
+		return false
+	}
+	var status types.PermissionStatus
+	switch name {
+	case Accessibility:
+		status = checkAccessibility(ctx)
+	case Automation:
+		status = checkAutomation(ctx)
+	case ScreenRecording:
+		status = checkScreenRecording()
+	case FullDiskAccess:
+		status = checkFullDiskAccess()
+	}
+	return status.Status == StatusDenied
+}
+
+// denied fills in status for a missing permission
+func denied(status types.PermissionStatus, detail string) types.PermissionStatus {
+	status.Status = StatusDenied
+	status.Detail = detail
+	status.Settings = SettingsPath(status.Name)
+	return status
+}
+
+// checkAccessibility asks System Events whether UI scripting is allowed for
+// this process; window listing without Screen Recording access and every
+// window action need it
+func checkAccessibility(ctx context.Context) types.PermissionStatus {
+	status := types.PermissionStatus{Name: Accessibility}
+	cmd := exec.CommandContext(ctx, "osascript", "-e", `tell application "System Events" to get UI elements enabled`)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		status.Status = StatusUnknown
+		status.Detail = strings.TrimSpace(string(output) + " " + err.Error())
+		return status
+	}
+	if strings.TrimSpace(string(output)) == "true" {
+		status.Status = StatusGranted
+		return status
+	}
+	return denied(status, "needed to list windows through AppleScript and to focus, close or minimize them")
+}
+
+// checkAutomation sends System Events a harmless Apple event, which macOS
+// refuses with error -1743 when Automation of System Events was denied
+func checkAutomation(ctx context.Context) types.PermissionStatus {
+	status := types.PermissionStatus{Name: Automation}
+	cmd := exec.CommandContext(ctx, "osascript", "-e", `tell application "System Events" to count processes`)
+	output, err := cmd.CombinedOutput()
+	switch {
+	case err == nil:
+		status.Status = StatusGranted
+	case FromAppleScript(string(output)) != nil:
+		return denied(status, "needed to control System Events, which gops scripts windows through")
+	default:
+		status.Status = StatusUnknown
+		status.Detail = strings.TrimSpace(string(output) + " " + err.Error())
+	}
+	return status
+}
+
+// checkFullDiskAccess probes the TCC database, which the system only lets
+// processes with Full Disk Access open
+func checkFullDiskAccess() types.PermissionStatus {
+	status := types.PermissionStatus{Name: FullDiskAccess}
+	f, err := os.Open(tccDatabase)
+	switch {
+	case err == nil:
+		f.Close()
+		status.Status = StatusGranted
+	case errors.Is(err, fs.ErrPermission):
+		return denied(status, "needed to read details of protected processes")
+	default:
+		status.Status = StatusUnknown
+		status.Detail = err.Error()
+	}
+	return status
+}
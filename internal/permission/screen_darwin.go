@@ -0,0 +1,22 @@
+//go:build darwin && cgo
+
+package permission
+
+/*
+#cgo LDFLAGS: -framework CoreGraphics
+#include <CoreGraphics/CoreGraphics.h>
+*/
+import "C"
+
+import "github.com/borankux/gops/pkg/types"
+
+// checkScreenRecording asks CoreGraphics, without prompting, whether this
+// process may read other apps' window titles
+func checkScreenRecording() types.PermissionStatus {
+	status := types.PermissionStatus{Name: ScreenRecording}
+	if C.CGPreflightScreenCaptureAccess() {
+		status.Status = StatusGranted
+		return status
+	}
+	return denied(status, "needed to read window titles from the window server")
+}
@@ -0,0 +1,15 @@
+//go:build !darwin || !cgo
+
+package permission
+
+import "github.com/borankux/gops/pkg/types"
+
+// checkScreenRecording can't query the permission without cgo, and
+// builds without cgo list windows through AppleScript instead
+func checkScreenRecording() types.PermissionStatus {
+	return types.PermissionStatus{
+		Name:   ScreenRecording,
+		Status: StatusNotRequired,
+		Detail: "windows are listed through AppleScript in builds without cgo",
+	}
+}
@@ -0,0 +1,110 @@
+package port
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/borankux/gops/pkg/types"
+	"github.com/shirou/gopsutil/v3/net"
+)
+
+// Sort keys accepted by ConnectionOptions.SortBy
+const (
+	SortRemote = "remote"
+	SortLocal  = "local"
+)
+
+// ConnectionSortKeys lists the valid connection sort keys
+var ConnectionSortKeys = []string{SortRemote, SortLocal, SortPID, SortName}
+
+// ConnectionOptions controls how connections are collected. Zero filters
+// are ignored.
+type ConnectionOptions struct {
+	// SortBy is one of ConnectionSortKeys; the default is SortRemote
+	SortBy     string
+	Descending bool
+	PID        int32
+	// Port matches connections whose local or remote port it is
+	Port uint32
+	// GeoIP, when set, locates each remote address
+	GeoIP *GeoIP
+}
+
+// GetConnections returns the established TCP connections with their
+// local and remote endpoints and owning processes
+func GetConnections(ctx context.Context, opts ConnectionOptions) ([]types.ConnectionInfo, error) {
+	connections, err := net.ConnectionsWithContext(ctx, "tcp")
+	if err != nil {
+		return nil, err
+	}
+
+	procs := make(processCache)
+	var result []types.ConnectionInfo
+	for _, conn := range connections {
+		if conn.Status != "ESTABLISHED" {
+			continue
+		}
+		if (opts.PID != 0 && conn.Pid != opts.PID) ||
+			(opts.Port != 0 && conn.Laddr.Port != opts.Port && conn.Raddr.Port != opts.Port) {
+			continue
+		}
+		name, exe := procs.lookup(ctx, conn.Pid)
+		result = append(result, types.ConnectionInfo{
+			Protocol:   getProtocol(conn),
+			Family:     getFamily(conn),
+			LocalIP:    conn.Laddr.IP,
+			LocalPort:  conn.Laddr.Port,
+			RemoteIP:   conn.Raddr.IP,
+			RemotePort: conn.Raddr.Port,
+			State:      conn.Status,
+			PID:        conn.Pid,
+			Name:       name,
+			Path:       exe,
+		})
+	}
+
+	if opts.GeoIP != nil {
+		annotateGeo(opts.GeoIP, result)
+	}
+	if err := sortConnections(result, opts); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// sortConnections orders connections by the key in opts, breaking ties by
+// remote endpoint
+func sortConnections(conns []types.ConnectionInfo, opts ConnectionOptions) error {
+	remote := func(a, b types.ConnectionInfo) bool {
+		if a.RemoteIP != b.RemoteIP {
+			return a.RemoteIP < b.RemoteIP
+		}
+		return a.RemotePort < b.RemotePort
+	}
+	var less func(a, b types.ConnectionInfo) bool
+	switch opts.SortBy {
+	case "", SortRemote:
+		less = remote
+	case SortLocal:
+		less = func(a, b types.ConnectionInfo) bool { return a.LocalPort < b.LocalPort }
+	case SortPID:
+		less = func(a, b types.ConnectionInfo) bool { return a.PID < b.PID }
+	case SortName:
+		less = func(a, b types.ConnectionInfo) bool { return strings.ToLower(a.Name) < strings.ToLower(b.Name) }
+	default:
+		return fmt.Errorf("invalid sort key: %s", opts.SortBy)
+	}
+
+	sort.Slice(conns, func(i, j int) bool {
+		return remote(conns[i], conns[j])
+	})
+	sort.SliceStable(conns, func(i, j int) bool {
+		if opts.Descending {
+			return less(conns[j], conns[i])
+		}
+		return less(conns[i], conns[j])
+	})
+	return nil
+}
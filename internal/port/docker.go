@@ -0,0 +1,143 @@
+package port
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/borankux/gops/pkg/types"
+)
+
+// dockerTimeout bounds the Docker API request, so a hung daemon doesn't
+// stall a port listing
+const dockerTimeout = 2 * time.Second
+
+// dockerProxies are the processes that listen on published container
+// ports on the host, on behalf of the containers
+var dockerProxies = map[string]bool{
+	"com.docker.backend": true,
+	"com.docker.vpnkit":  true,
+	"vpnkit":             true,
+	"docker-proxy":       true,
+}
+
+// dockerContainer is the part of a container the Docker API lists that
+// gops needs
+type dockerContainer struct {
+	ID    string   `json:"Id"`
+	Names []string `json:"Names"`
+	Image string   `json:"Image"`
+	Ports []struct {
+		PublicPort uint32 `json:"PublicPort"`
+		Type       string `json:"Type"`
+	} `json:"Ports"`
+}
+
+// annotateContainers fills in the container behind each port a Docker
+// proxy listens on. Ports are left as they are when Docker can't be
+// reached.
+func annotateContainers(ctx context.Context, ports []types.PortInfo) {
+	proxied := false
+	for _, p := range ports {
+		if dockerProxies[strings.TrimSuffix(p.Name, ".exe")] {
+			proxied = true
+			break
+		}
+	}
+	if !proxied {
+		return
+	}
+	containers, err := dockerContainers(ctx)
+	if err != nil {
+		return
+	}
+
+	published := make(map[string]dockerContainer)
+	for _, c := range containers {
+		for _, p := range c.Ports {
+			if p.PublicPort != 0 {
+				published[fmt.Sprintf("%s/%d", strings.ToUpper(p.Type), p.PublicPort)] = c
+			}
+		}
+	}
+	for i, p := range ports {
+		if !dockerProxies[strings.TrimSuffix(p.Name, ".exe")] {
+			continue
+		}
+		c, ok := published[fmt.Sprintf("%s/%d", p.Protocol, p.Port)]
+		if !ok {
+			continue
+		}
+		ports[i].ContainerID = c.ID
+		if len(c.ID) > 12 {
+			ports[i].ContainerID = c.ID[:12]
+		}
+		if len(c.Names) > 0 {
+			ports[i].ContainerName = strings.TrimPrefix(c.Names[0], "/")
+		}
+		ports[i].ContainerImage = c.Image
+	}
+}
+
+// dockerContainers lists the running containers through the Docker API on
+// its Unix socket
+func dockerContainers(ctx context.Context) ([]dockerContainer, error) {
+	socket, err := dockerSocket()
+	if err != nil {
+		return nil, err
+	}
+	client := &http.Client{
+		Timeout: dockerTimeout,
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, "unix", socket)
+			},
+		},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://docker/containers/json", nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("docker API: %s", resp.Status)
+	}
+
+	var containers []dockerContainer
+	if err := json.NewDecoder(resp.Body).Decode(&containers); err != nil {
+		return nil, fmt.Errorf("parsing docker API response: %w", err)
+	}
+	return containers, nil
+}
+
+// dockerSocket finds the Docker API socket: DOCKER_HOST when it names a
+// Unix socket, then the system socket, then Docker Desktop's per-user one
+func dockerSocket() (string, error) {
+	if host := os.Getenv("DOCKER_HOST"); host != "" {
+		if path, ok := strings.CutPrefix(host, "unix://"); ok {
+			return path, nil
+		}
+		return "", fmt.Errorf("unsupported DOCKER_HOST: %s", host)
+	}
+	candidates := []string{"/var/run/docker.sock"}
+	if home, err := os.UserHomeDir(); err == nil {
+		candidates = append(candidates, filepath.Join(home, ".docker", "run", "docker.sock"))
+	}
+	for _, path := range candidates {
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("docker socket not found")
+}
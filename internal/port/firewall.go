@@ -0,0 +1,292 @@
+package port
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/borankux/gops/pkg/types"
+)
+
+// Firewall verdicts reported in PortInfo.Firewall
+const (
+	FirewallAllowed = "allowed"
+	FirewallBlocked = "blocked"
+)
+
+// Firewall backends reported in FirewallStatus.Backend
+const (
+	BackendApplicationFirewall = "application-firewall"
+	BackendUFW                 = "ufw"
+	BackendFirewalld           = "firewalld"
+)
+
+const socketfilterfw = "/usr/libexec/ApplicationFirewall/socketfilterfw"
+
+// CheckFirewall reads the state of the host firewall and sets the
+// Firewall verdict of each port it filters: those not bound to loopback.
+// macOS consults the Application Firewall, which filters by application;
+// Linux consults ufw, or firewalld when ufw isn't active. A firewall that
+// can't be read is reported in the status's Error, leaving the ports
+// without a verdict.
+func CheckFirewall(ctx context.Context, ports []types.PortInfo) *types.FirewallStatus {
+	var status *types.FirewallStatus
+	var err error
+	switch runtime.GOOS {
+	case "darwin":
+		status, err = applicationFirewall(ctx, ports)
+	case "linux":
+		status, err = ufwFirewall(ctx, ports)
+		if err != nil || !status.Enabled {
+			fwd, fwdErr := firewalldFirewall(ctx, ports)
+			switch {
+			case fwdErr == nil:
+				status, err = fwd, nil
+			case errors.Is(err, exec.ErrNotFound) && errors.Is(fwdErr, exec.ErrNotFound):
+				err = errors.New("neither ufw nor firewalld is installed")
+			}
+		}
+	default:
+		err = errors.New("reading the firewall is not supported on " + runtime.GOOS)
+	}
+	if err != nil {
+		return &types.FirewallStatus{Error: err.Error()}
+	}
+	return status
+}
+
+// applicationFirewall reads the macOS Application Firewall, which allows
+// or blocks incoming connections per application, and whether pf is
+// enabled. pf's rules aren't evaluated.
+func applicationFirewall(ctx context.Context, ports []types.PortInfo) (*types.FirewallStatus, error) {
+	global, err := exec.CommandContext(ctx, socketfilterfw, "--getglobalstate").Output()
+	if err != nil {
+		return nil, err
+	}
+	status := &types.FirewallStatus{
+		Backend: BackendApplicationFirewall,
+		Enabled: stateEnabled(string(global)),
+	}
+	if output, err := exec.CommandContext(ctx, socketfilterfw, "--getblockall").Output(); err == nil {
+		status.BlockAll = stateEnabled(string(output))
+	}
+	// pfctl needs root
+	if output, err := exec.CommandContext(ctx, "pfctl", "-s", "info").Output(); err == nil {
+		status.PacketFilter = "disabled"
+		if strings.Contains(string(output), "Status: Enabled") {
+			status.PacketFilter = "enabled"
+		}
+	}
+	status.DefaultIncoming = "allow"
+	if status.Enabled && status.BlockAll {
+		status.DefaultIncoming = "deny"
+	}
+
+	apps := make(map[string]string)
+	for i, p := range ports {
+		if p.Exposure == ExposureLoopback {
+			continue
+		}
+		switch {
+		case !status.Enabled:
+			ports[i].Firewall = FirewallAllowed
+		case status.BlockAll:
+			ports[i].Firewall = FirewallBlocked
+		case p.Path != "":
+			verdict, checked := apps[p.Path]
+			if !checked {
+				verdict = applicationVerdict(ctx, p.Path)
+				apps[p.Path] = verdict
+			}
+			ports[i].Firewall = verdict
+		}
+	}
+	return status, nil
+}
+
+// applicationVerdict asks the Application Firewall whether it lets exe
+// receive incoming connections, empty when it has no rule for exe
+func applicationVerdict(ctx context.Context, exe string) string {
+	output, err := exec.CommandContext(ctx, socketfilterfw, "--getappblocked", exe).Output()
+	if err != nil {
+		return ""
+	}
+	text := strings.ToLower(string(output))
+	switch {
+	case strings.Contains(text, "not part of the firewall"):
+		return ""
+	case strings.Contains(text, "blocked"):
+		return FirewallBlocked
+	case strings.Contains(text, "permitted"), strings.Contains(text, "allowed"):
+		return FirewallAllowed
+	default:
+		return ""
+	}
+}
+
+// stateEnabled reads socketfilterfw's answer to a --get option
+func stateEnabled(output string) bool {
+	output = strings.ToLower(output)
+	return !strings.Contains(output, "disabled") && strings.Contains(output, "enabled")
+}
+
+// ufwRule matches a rule in the output of ufw status verbose:
+//
+//	22/tcp                     ALLOW IN    Anywhere
+//	8000:8100/udp (v6)         DENY IN     Anywhere (v6)
+var ufwRule = regexp.MustCompile(`^(\S+)(?: \(v6\))?\s+(ALLOW|DENY|REJECT|LIMIT)(?: (IN|OUT|FWD))?\s`)
+
+// ufwFirewall reads ufw's rules, which needs root, and applies the first
+// incoming rule matching each port, or the default policy
+func ufwFirewall(ctx context.Context, ports []types.PortInfo) (*types.FirewallStatus, error) {
+	output, err := exec.CommandContext(ctx, "ufw", "status", "verbose").Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return nil, errors.New("ufw: " + strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, err
+	}
+
+	status := &types.FirewallStatus{Backend: BackendUFW}
+	type rule struct {
+		ports string
+		allow bool
+	}
+	var rules []rule
+	scanner := bufio.NewScanner(strings.NewReader(string(output)))
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "Status:"):
+			status.Enabled = strings.TrimSpace(strings.TrimPrefix(line, "Status:")) == "active"
+		case strings.HasPrefix(line, "Default:"):
+			// Default: deny (incoming), allow (outgoing), disabled (routed)
+			for _, policy := range strings.Split(strings.TrimPrefix(line, "Default:"), ",") {
+				if value, found := strings.CutSuffix(strings.TrimSpace(policy), " (incoming)"); found {
+					status.DefaultIncoming = value
+				}
+			}
+		default:
+			if m := ufwRule.FindStringSubmatch(line); m != nil && m[3] != "OUT" && m[3] != "FWD" {
+				rules = append(rules, rule{ports: m[1], allow: m[2] == "ALLOW" || m[2] == "LIMIT"})
+			}
+		}
+	}
+	if status.DefaultIncoming == "reject" {
+		status.DefaultIncoming = "deny"
+	}
+
+	for i, p := range ports {
+		if p.Exposure == ExposureLoopback {
+			continue
+		}
+		if !status.Enabled {
+			ports[i].Firewall = FirewallAllowed
+			continue
+		}
+		ports[i].Firewall = verdict(status.DefaultIncoming == "allow")
+		for _, r := range rules {
+			if portSpecMatches(r.ports, p.Port, p.Protocol) {
+				ports[i].Firewall = verdict(r.allow)
+				break
+			}
+		}
+	}
+	return status, nil
+}
+
+// firewalldFirewall reads the ports and services the default zone of
+// firewalld opens. Services are matched to ports by their names in the
+// services database, which firewalld's service names mostly follow.
+func firewalldFirewall(ctx context.Context, ports []types.PortInfo) (*types.FirewallStatus, error) {
+	state, err := exec.CommandContext(ctx, "firewall-cmd", "--state").Output()
+	status := &types.FirewallStatus{Backend: BackendFirewalld, Enabled: err == nil && strings.TrimSpace(string(state)) == "running"}
+	if !status.Enabled {
+		if _, lookErr := exec.LookPath("firewall-cmd"); lookErr != nil {
+			return nil, lookErr
+		}
+		for i, p := range ports {
+			if p.Exposure != ExposureLoopback {
+				ports[i].Firewall = FirewallAllowed
+			}
+		}
+		return status, nil
+	}
+
+	output, err := exec.CommandContext(ctx, "firewall-cmd", "--list-all").Output()
+	if err != nil {
+		return nil, err
+	}
+	// public (active)
+	//   target: default
+	//   services: dhcpv6-client ssh
+	//   ports: 8080/tcp 5000-5100/udp
+	var openPorts []string
+	services := make(map[string]bool)
+	status.DefaultIncoming = "deny"
+	scanner := bufio.NewScanner(strings.NewReader(string(output)))
+	for scanner.Scan() {
+		key, value, found := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if !found {
+			continue
+		}
+		switch key {
+		case "target":
+			if strings.TrimSpace(value) == "ACCEPT" {
+				status.DefaultIncoming = "allow"
+			}
+		case "services":
+			for _, s := range strings.Fields(value) {
+				services[s] = true
+			}
+		case "ports":
+			openPorts = strings.Fields(value)
+		}
+	}
+
+	for i, p := range ports {
+		if p.Exposure == ExposureLoopback {
+			continue
+		}
+		allowed := status.DefaultIncoming == "allow" || (p.Service != "" && services[p.Service])
+		for _, spec := range openPorts {
+			allowed = allowed || portSpecMatches(strings.ReplaceAll(spec, "-", ":"), p.Port, p.Protocol)
+		}
+		ports[i].Firewall = verdict(allowed)
+	}
+	return status, nil
+}
+
+// portSpecMatches reports whether a firewall port spec such as 22,
+// 22/tcp, 80,443/tcp or 8000:8100/udp covers port and protocol
+func portSpecMatches(spec string, port uint32, protocol string) bool {
+	numbers, proto, hasProto := strings.Cut(spec, "/")
+	if hasProto && !strings.EqualFold(proto, protocol) {
+		return false
+	}
+	for _, part := range strings.Split(numbers, ",") {
+		low, high, isRange := strings.Cut(part, ":")
+		if !isRange {
+			high = low
+		}
+		from, err1 := strconv.ParseUint(low, 10, 16)
+		to, err2 := strconv.ParseUint(high, 10, 16)
+		if err1 == nil && err2 == nil && uint64(port) >= from && uint64(port) <= to {
+			return true
+		}
+	}
+	return false
+}
+
+func verdict(allowed bool) string {
+	if allowed {
+		return FirewallAllowed
+	}
+	return FirewallBlocked
+}
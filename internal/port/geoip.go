@@ -0,0 +1,135 @@
+package port
+
+import (
+	"errors"
+	"fmt"
+	stdnet "net"
+	"strings"
+
+	"github.com/borankux/gops/pkg/types"
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// GeoIP looks up the country and autonomous system of IP addresses in
+// local MaxMind databases: a Country or City database, an ASN database,
+// or both. It is safe for concurrent use.
+type GeoIP struct {
+	country *maxminddb.Reader
+	asn     *maxminddb.Reader
+}
+
+// countryRecord is the part of a Country or City record gops reads
+type countryRecord struct {
+	Country struct {
+		ISOCode string            `maxminddb:"iso_code"`
+		Names   map[string]string `maxminddb:"names"`
+	} `maxminddb:"country"`
+	// RegisteredCountry stands in for addresses whose location is
+	// unknown, such as anycast ranges
+	RegisteredCountry struct {
+		ISOCode string            `maxminddb:"iso_code"`
+		Names   map[string]string `maxminddb:"names"`
+	} `maxminddb:"registered_country"`
+}
+
+// asnRecord is a record of an ASN database
+type asnRecord struct {
+	Number       uint   `maxminddb:"autonomous_system_number"`
+	Organization string `maxminddb:"autonomous_system_organization"`
+}
+
+// OpenGeoIP opens the MaxMind databases at paths, telling ASN databases
+// from Country and City ones by their metadata
+func OpenGeoIP(paths []string) (*GeoIP, error) {
+	if len(paths) == 0 {
+		return nil, errors.New("no GeoIP database given")
+	}
+	g := &GeoIP{}
+	for _, path := range paths {
+		reader, err := maxminddb.Open(path)
+		if err != nil {
+			g.Close()
+			return nil, fmt.Errorf("opening GeoIP database %s: %w", path, err)
+		}
+		dbType := reader.Metadata.DatabaseType
+		slot := &g.country
+		if strings.Contains(dbType, "ASN") {
+			slot = &g.asn
+		} else if !strings.Contains(dbType, "Country") && !strings.Contains(dbType, "City") {
+			reader.Close()
+			g.Close()
+			return nil, fmt.Errorf("%s is a %s database; expected a Country, City or ASN database", path, dbType)
+		}
+		if *slot != nil {
+			reader.Close()
+			g.Close()
+			return nil, fmt.Errorf("%s is a second %s database", path, dbType)
+		}
+		*slot = reader
+	}
+	return g, nil
+}
+
+// Close closes the databases
+func (g *GeoIP) Close() error {
+	var err error
+	for _, reader := range []*maxminddb.Reader{g.country, g.asn} {
+		if reader != nil {
+			if closeErr := reader.Close(); closeErr != nil {
+				err = closeErr
+			}
+		}
+	}
+	return err
+}
+
+// Lookup returns the country and autonomous system of ip, zero for
+// addresses not routed on the internet, such as private and loopback
+// ones, and for addresses the databases don't cover
+func (g *GeoIP) Lookup(ip string) types.GeoInfo {
+	// Strip an IPv6 zone such as fe80::1%en0
+	addr, _, _ := strings.Cut(ip, "%")
+	parsed := stdnet.ParseIP(addr)
+	if parsed == nil || parsed.IsLoopback() || parsed.IsPrivate() || parsed.IsUnspecified() ||
+		parsed.IsLinkLocalUnicast() || parsed.IsMulticast() {
+		return types.GeoInfo{}
+	}
+
+	var info types.GeoInfo
+	if g.country != nil {
+		var record countryRecord
+		if err := g.country.Lookup(parsed, &record); err == nil {
+			country := record.Country
+			if country.ISOCode == "" {
+				country = record.RegisteredCountry
+			}
+			info.Country = country.ISOCode
+			info.CountryName = country.Names["en"]
+		}
+	}
+	if g.asn != nil {
+		var record asnRecord
+		if err := g.asn.Lookup(parsed, &record); err == nil {
+			info.ASN = record.Number
+			info.ASOrg = record.Organization
+		}
+	}
+	return info
+}
+
+// annotateGeo sets the country and autonomous system of each
+// connection's remote address, looking each address up once
+func annotateGeo(g *GeoIP, conns []types.ConnectionInfo) {
+	seen := make(map[string]types.GeoInfo)
+	for i, c := range conns {
+		info, ok := seen[c.RemoteIP]
+		if !ok {
+			info = g.Lookup(c.RemoteIP)
+			seen[c.RemoteIP] = info
+		}
+		conns[i].Country = info.Country
+		conns[i].CountryName = info.CountryName
+		conns[i].ASN = info.ASN
+		conns[i].ASOrg = info.ASOrg
+	}
+}
@@ -3,25 +3,127 @@ package port
 import (
 	"context"
 	"fmt"
+	stdnet "net"
+	"sort"
 	"strings"
+	"syscall"
 
 	"github.com/borankux/gops/pkg/types"
 	"github.com/shirou/gopsutil/v3/net"
 	"github.com/shirou/gopsutil/v3/process"
 )
 
+// Sort keys accepted by ListOptions.SortBy
+const (
+	SortPort     = "port"
+	SortPID      = "pid"
+	SortName     = "name"
+	SortProtocol = "protocol"
+	// SortConnections orders listeners by their established connections
+	SortConnections = "connections"
+)
+
+// SortKeys lists the valid port sort keys
+var SortKeys = []string{SortPort, SortPID, SortName, SortProtocol, SortConnections}
+
+// maxTopPeers is how many remote peers PortInfo.TopPeers lists
+const maxTopPeers = 5
+
+// Transport protocols reported in PortInfo.Protocol
+const (
+	ProtocolTCP = "TCP"
+	ProtocolUDP = "UDP"
+)
+
+// Address families reported in PortInfo.Family
+const (
+	FamilyIPv4 = "ipv4"
+	FamilyIPv6 = "ipv6"
+)
+
+// Exposures reported in PortInfo.Exposure, by bind address
+const (
+	// ExposureLoopback ports are bound to a loopback address and reachable
+	// from this machine only
+	ExposureLoopback = "loopback"
+	// ExposureLAN ports are bound to one non-loopback address, reachable
+	// from the networks of that interface
+	ExposureLAN = "lan"
+	// ExposureAll ports are bound to the wildcard address, reachable on
+	// every interface
+	ExposureAll = "all"
+)
+
+// Protocols lists the values accepted by ListOptions.Protocol: a
+// transport, alone or with an address family as in tcp6
+var Protocols = []string{"tcp", "udp", "tcp4", "tcp6", "udp4", "udp6"}
+
+// Socket states accepted by ListOptions.State besides the TCP states
+// themselves
+const (
+	StateListen = "LISTEN"
+	StateAll    = "ALL"
+)
+
+// States lists the values accepted by ListOptions.State: the TCP states
+// as the OS reports them, and ALL
+var States = []string{StateListen, "ESTABLISHED", "SYN_SENT", "SYN_RECV", "FIN_WAIT1", "FIN_WAIT2",
+	"TIME_WAIT", "CLOSE", "CLOSE_WAIT", "LAST_ACK", "CLOSING", StateAll}
+
+// ListOptions controls how ports are collected
+type ListOptions struct {
+	// SortBy is one of SortKeys; the default is SortPort
+	SortBy     string
+	Descending bool
+	// Protocol, when set, limits ports to one of Protocols, ignoring
+	// case: "tcp" or "udp", or "tcp6" for TCP over IPv6 alone and so on
+	Protocol string
+	// State selects sockets by TCP state, ignoring case: one of States.
+	// The default, LISTEN, lists ports open for incoming traffic, with
+	// UDP sockets not connected to a peer; other TCP states leave UDP out,
+	// and ALL lists every socket.
+	State string
+	// Exposed leaves out ports bound to a loopback address, keeping those
+	// reachable from other machines
+	Exposed bool
+	// Resolve looks up the host name of each port's bind address with
+	// reverse DNS
+	Resolve bool
+}
+
 // GetOpenPorts returns a list of open ports with associated processes
-func GetOpenPorts(ctx context.Context) ([]types.PortInfo, error) {
-	connections, err := net.ConnectionsWithContext(ctx, "inet")
+func GetOpenPorts(ctx context.Context, opts ListOptions) ([]types.PortInfo, error) {
+	kind := "inet"
+	if opts.Protocol != "" {
+		if !validProtocol(strings.ToLower(opts.Protocol)) {
+			return nil, fmt.Errorf("invalid protocol: %s", opts.Protocol)
+		}
+		// gopsutil takes the same names as connection kinds
+		kind = strings.ToLower(opts.Protocol)
+	}
+	state := strings.ToUpper(opts.State)
+	if state == "" {
+		state = StateListen
+	}
+	if !validState(state) {
+		return nil, fmt.Errorf("invalid state: %s", opts.State)
+	}
+	connections, err := net.ConnectionsWithContext(ctx, kind)
 	if err != nil {
 		return nil, err
 	}
 
 	portMap := make(map[string]*types.PortInfo)
+	procs := make(processCache)
+	hosts := make(hostResolver)
+	peers := make(peerCounter)
 
 	for _, conn := range connections {
-		// Only show listening connections (ports that are open and listening)
-		if conn.Status != "LISTEN" {
+		protocol := getProtocol(conn)
+		if state == StateListen && protocol == ProtocolTCP && conn.Status == "ESTABLISHED" {
+			peers.add(conn)
+		}
+		if !matchesState(conn, protocol, state) {
 			continue
 		}
 
@@ -29,36 +131,41 @@ func GetOpenPorts(ctx context.Context) ([]types.PortInfo, error) {
 		if port == 0 {
 			continue
 		}
+		exposure := getExposure(conn.Laddr.IP)
+		if opts.Exposed && exposure == ExposureLoopback {
+			continue
+		}
 
-		key := fmt.Sprintf("%s:%d", conn.Laddr.IP, port)
-
-		// Get process info
-		var procName string
-		var exePath string
-		if conn.Pid > 0 {
-			p, err := process.NewProcessWithContext(ctx, conn.Pid)
-			if err == nil {
-				if name, err := p.NameWithContext(ctx); err == nil {
-					procName = name
-				}
-				if exe, err := p.ExeWithContext(ctx); err == nil {
-					exePath = exe
-				}
-			}
+		key := fmt.Sprintf("%s/%s:%d", protocol, conn.Laddr.IP, port)
+		if conn.Raddr.Port != 0 {
+			key += fmt.Sprintf("-%s:%d", conn.Raddr.IP, conn.Raddr.Port)
 		}
 
-		protocol := getProtocol(conn)
+		procName, exePath := procs.lookup(ctx, conn.Pid)
+
 		portInfo := &types.PortInfo{
 			Port:     uint32(port),
 			Protocol: protocol,
+			Family:   getFamily(conn),
 			PID:      conn.Pid,
 			Name:     procName,
 			Path:     exePath,
-			State:    conn.Status,
 			LocalIP:  conn.Laddr.IP,
+			Exposure: exposure,
+			Service:  serviceName(port, protocol),
+		}
+		if conn.Raddr.Port != 0 {
+			portInfo.RemoteIP = conn.Raddr.IP
+			portInfo.RemotePort = conn.Raddr.Port
+		}
+		if opts.Resolve {
+			portInfo.Hostname = hosts.lookup(ctx, conn.Laddr.IP)
+		}
+		if protocol == ProtocolTCP {
+			portInfo.State = conn.Status
 		}
 
-		// Store port info (we only get LISTEN connections now)
+		// Store port info
 		if existing, exists := portMap[key]; exists {
 			// If port exists, keep the existing one unless this has better info
 			if existing.Name == "" && procName != "" {
@@ -71,34 +178,222 @@ func GetOpenPorts(ctx context.Context) ([]types.PortInfo, error) {
 
 	var ports []types.PortInfo
 	for _, portInfo := range portMap {
+		if portInfo.Protocol == ProtocolTCP && state == StateListen {
+			portInfo.Connections, portInfo.TopPeers = peers.top(portInfo)
+		}
 		ports = append(ports, *portInfo)
 	}
+	markDualStack(ports)
+	annotateContainers(ctx, ports)
 
-	// Sort by port number
-	for i := 0; i < len(ports)-1; i++ {
-		for j := i + 1; j < len(ports); j++ {
-			if ports[i].Port > ports[j].Port {
-				ports[i], ports[j] = ports[j], ports[i]
-			}
-		}
+	if err := sortPorts(ports, opts); err != nil {
+		return nil, err
 	}
 
 	return ports, nil
 }
 
-// getProtocol determines protocol from connection
+// validProtocol reports whether protocol, in lower case, is one of
+// Protocols
+func validProtocol(protocol string) bool {
+	for _, p := range Protocols {
+		if p == protocol {
+			return true
+		}
+	}
+	return false
+}
+
+// validState reports whether state, in upper case, is one of States
+func validState(state string) bool {
+	for _, s := range States {
+		if s == state {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesState reports whether conn is selected by state. LISTEN selects
+// the sockets open for incoming traffic: listening TCP sockets, and UDP
+// sockets not connected to a single peer, since UDP has no listening
+// state.
+func matchesState(conn net.ConnectionStat, protocol, state string) bool {
+	switch {
+	case state == StateAll:
+		return true
+	case protocol == ProtocolUDP:
+		return state == StateListen && conn.Raddr.Port == 0
+	default:
+		return conn.Status == state
+	}
+}
+
+// peerKey identifies the listening socket an accepted connection belongs
+// to: the process, local port and family
+type peerKey struct {
+	pid    int32
+	port   uint32
+	family string
+}
+
+// peerCounter counts established TCP connections per listening socket
+// and remote address
+type peerCounter map[peerKey]map[string]int
+
+func (c peerCounter) add(conn net.ConnectionStat) {
+	key := peerKey{conn.Pid, conn.Laddr.Port, getFamily(conn)}
+	if c[key] == nil {
+		c[key] = make(map[string]int)
+	}
+	c[key][conn.Raddr.IP]++
+}
+
+// top returns how many connections the listener p has accepted and its
+// busiest remote peers. Connections are told from outgoing ones by
+// sharing the listener's process and local port.
+func (c peerCounter) top(p *types.PortInfo) (int, []types.PeerCount) {
+	counts := c[peerKey{p.PID, p.Port, p.Family}]
+	if len(counts) == 0 {
+		return 0, nil
+	}
+	total := 0
+	peers := make([]types.PeerCount, 0, len(counts))
+	for ip, n := range counts {
+		total += n
+		peers = append(peers, types.PeerCount{IP: ip, Connections: n})
+	}
+	sort.Slice(peers, func(i, j int) bool {
+		if peers[i].Connections != peers[j].Connections {
+			return peers[i].Connections > peers[j].Connections
+		}
+		return peers[i].IP < peers[j].IP
+	})
+	if len(peers) > maxTopPeers {
+		peers = peers[:maxTopPeers]
+	}
+	return total, peers
+}
+
+// processCache holds the name and executable path of the processes
+// owning sockets, looked up once per PID
+type processCache map[int32][2]string
+
+// lookup returns the name and executable path of pid, empty when it is
+// unknown or has exited
+func (c processCache) lookup(ctx context.Context, pid int32) (name, exe string) {
+	if pid <= 0 {
+		return "", ""
+	}
+	if cached, ok := c[pid]; ok {
+		return cached[0], cached[1]
+	}
+	if p, err := process.NewProcessWithContext(ctx, pid); err == nil {
+		name, _ = p.NameWithContext(ctx)
+		exe, _ = p.ExeWithContext(ctx)
+	}
+	c[pid] = [2]string{name, exe}
+	return name, exe
+}
+
+// sortPorts orders ports by the key in opts, breaking ties by port number
+func sortPorts(ports []types.PortInfo, opts ListOptions) error {
+	var less func(a, b types.PortInfo) bool
+	switch opts.SortBy {
+	case "", SortPort:
+		less = func(a, b types.PortInfo) bool { return a.Port < b.Port }
+	case SortPID:
+		less = func(a, b types.PortInfo) bool { return a.PID < b.PID }
+	case SortName:
+		less = func(a, b types.PortInfo) bool { return strings.ToLower(a.Name) < strings.ToLower(b.Name) }
+	case SortProtocol:
+		less = func(a, b types.PortInfo) bool { return a.Protocol < b.Protocol }
+	case SortConnections:
+		less = func(a, b types.PortInfo) bool { return a.Connections < b.Connections }
+	default:
+		return fmt.Errorf("invalid sort key: %s", opts.SortBy)
+	}
+
+	sort.Slice(ports, func(i, j int) bool {
+		return ports[i].Port < ports[j].Port
+	})
+	sort.SliceStable(ports, func(i, j int) bool {
+		if opts.Descending {
+			return less(ports[j], ports[i])
+		}
+		return less(ports[i], ports[j])
+	})
+	return nil
+}
+
+// getProtocol determines the transport protocol of a connection from its
+// socket type
 func getProtocol(conn net.ConnectionStat) string {
-	// Try to determine from local port or system call
-	// For now, default to TCP, can be enhanced
-	if strings.Contains(strings.ToLower(conn.Status), "udp") {
-		return "UDP"
+	if conn.Type == syscall.SOCK_DGRAM {
+		return ProtocolUDP
+	}
+	return ProtocolTCP
+}
+
+// getFamily determines the address family of a connection
+func getFamily(conn net.ConnectionStat) string {
+	if conn.Family == syscall.AF_INET6 || strings.Contains(conn.Laddr.IP, ":") {
+		return FamilyIPv6
+	}
+	return FamilyIPv4
+}
+
+// getExposure classifies who can reach a socket bound to ip
+func getExposure(ip string) string {
+	if ip == "" || ip == "*" {
+		return ExposureAll
+	}
+	// Strip an IPv6 zone such as fe80::1%en0
+	addr, _, _ := strings.Cut(ip, "%")
+	parsed := stdnet.ParseIP(addr)
+	switch {
+	case parsed == nil:
+		return ExposureLAN
+	case parsed.IsUnspecified():
+		return ExposureAll
+	case parsed.IsLoopback():
+		return ExposureLoopback
+	default:
+		return ExposureLAN
+	}
+}
+
+// markDualStack flags the ports a process serves over both IPv4 and IPv6
+// with the same protocol, leaving connected sockets out
+func markDualStack(ports []types.PortInfo) {
+	type service struct {
+		protocol string
+		port     uint32
+		pid      int32
+	}
+	families := make(map[service]map[string]bool)
+	for _, p := range ports {
+		if p.RemotePort != 0 {
+			continue
+		}
+		s := service{p.Protocol, p.Port, p.PID}
+		if families[s] == nil {
+			families[s] = make(map[string]bool)
+		}
+		families[s][p.Family] = true
+	}
+	for i, p := range ports {
+		if p.RemotePort != 0 {
+			continue
+		}
+		f := families[service{p.Protocol, p.Port, p.PID}]
+		ports[i].DualStack = f[FamilyIPv4] && f[FamilyIPv6]
 	}
-	return "TCP"
 }
 
 // GetPortInfoByPort returns information about a specific port
-func GetPortInfoByPort(ctx context.Context, port uint32) ([]types.PortInfo, error) {
-	allPorts, err := GetOpenPorts(ctx)
+func GetPortInfoByPort(ctx context.Context, port uint32, opts ListOptions) ([]types.PortInfo, error) {
+	allPorts, err := GetOpenPorts(ctx, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -114,8 +409,8 @@ func GetPortInfoByPort(ctx context.Context, port uint32) ([]types.PortInfo, erro
 }
 
 // GetPortsByPID returns ports used by a specific process
-func GetPortsByPID(ctx context.Context, pid int32) ([]types.PortInfo, error) {
-	allPorts, err := GetOpenPorts(ctx)
+func GetPortsByPID(ctx context.Context, pid int32, opts ListOptions) ([]types.PortInfo, error) {
+	allPorts, err := GetOpenPorts(ctx, opts)
 	if err != nil {
 		return nil, err
 	}
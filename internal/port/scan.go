@@ -0,0 +1,166 @@
+package port
+
+import (
+	"context"
+	"fmt"
+	stdnet "net"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/borankux/gops/pkg/types"
+)
+
+// Scan statuses reported in ScanResult.Status
+const (
+	// ScanAnswering ports accepted a connection on a loopback address
+	ScanAnswering = "answering"
+	// ScanBound ports have a listening socket but accepted no connection
+	// on loopback, because they are bound to another address or the
+	// listener isn't accepting
+	ScanBound = "bound"
+)
+
+// Scan limits
+const (
+	// MaxScanPorts is the most ports a single scan probes
+	MaxScanPorts = 10000
+	// DefaultScanTimeout is how long each connection attempt waits
+	DefaultScanTimeout = 300 * time.Millisecond
+	// MaxScanTimeout is the longest allowed connection timeout
+	MaxScanTimeout = 5 * time.Second
+)
+
+// scanWorkers is how many connection attempts run at once
+const scanWorkers = 128
+
+// scanAddresses are the loopback addresses each port is probed on
+var scanAddresses = []string{"127.0.0.1", "::1"}
+
+// ScanOptions selects the ports to probe
+type ScanOptions struct {
+	// From and To bound the range of ports, inclusive
+	From, To uint32
+	// Timeout is how long each connection attempt waits; the default is
+	// DefaultScanTimeout
+	Timeout time.Duration
+}
+
+// ScanPorts connects to each TCP port in the range on 127.0.0.1 and ::1
+// and merges the outcome with the listening sockets, so ports that answer
+// can be told from ports that are merely bound. Ports that neither answer
+// nor are bound are left out. Connections are closed as soon as they are
+// established; nothing is sent.
+func ScanPorts(ctx context.Context, opts ScanOptions) ([]types.ScanResult, error) {
+	if opts.From == 0 || opts.To > 65535 || opts.From > opts.To {
+		return nil, fmt.Errorf("invalid port range: %d-%d", opts.From, opts.To)
+	}
+	if n := opts.To - opts.From + 1; n > MaxScanPorts {
+		return nil, fmt.Errorf("port range too large: %d ports (at most %d)", n, MaxScanPorts)
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultScanTimeout
+	}
+	if opts.Timeout > MaxScanTimeout {
+		return nil, fmt.Errorf("invalid timeout: %s (at most %s)", opts.Timeout, MaxScanTimeout)
+	}
+
+	listeners, err := GetOpenPorts(ctx, ListOptions{Protocol: "tcp"})
+	if err != nil {
+		return nil, err
+	}
+
+	results := make(map[uint32]*types.ScanResult)
+	for _, l := range listeners {
+		if l.Port < opts.From || l.Port > opts.To {
+			continue
+		}
+		r := results[l.Port]
+		if r == nil {
+			r = &types.ScanResult{
+				Port:           l.Port,
+				Status:         ScanBound,
+				PID:            l.PID,
+				Name:           l.Name,
+				Path:           l.Path,
+				Service:        l.Service,
+				ContainerName:  l.ContainerName,
+				ContainerImage: l.ContainerImage,
+			}
+			results[l.Port] = r
+		}
+		r.BindAddresses = append(r.BindAddresses, l.LocalIP)
+	}
+
+	var mu sync.Mutex
+	probe := func(port uint32) {
+		for _, addr := range scanAddresses {
+			latency, ok := dialPort(ctx, addr, port, opts.Timeout)
+			if !ok {
+				continue
+			}
+			mu.Lock()
+			r := results[port]
+			if r == nil {
+				r = &types.ScanResult{Port: port, Service: serviceName(port, ProtocolTCP)}
+				results[port] = r
+			}
+			r.Status = ScanAnswering
+			r.Answered = append(r.Answered, addr)
+			if ms := float64(latency.Microseconds()) / 1000; r.LatencyMs == 0 || ms < r.LatencyMs {
+				r.LatencyMs = ms
+			}
+			mu.Unlock()
+		}
+	}
+
+	ports := make(chan uint32)
+	var wg sync.WaitGroup
+	for i := 0; i < scanWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for port := range ports {
+				probe(port)
+			}
+		}()
+	}
+send:
+	for port := opts.From; port <= opts.To; port++ {
+		select {
+		case ports <- port:
+		case <-ctx.Done():
+			break send
+		}
+	}
+	close(ports)
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	scanned := make([]types.ScanResult, 0, len(results))
+	for _, r := range results {
+		sort.Strings(r.BindAddresses)
+		scanned = append(scanned, *r)
+	}
+	sort.Slice(scanned, func(i, j int) bool {
+		return scanned[i].Port < scanned[j].Port
+	})
+	return scanned, nil
+}
+
+// dialPort connects to addr:port over TCP, reporting whether the
+// connection was accepted and how long it took
+func dialPort(ctx context.Context, addr string, port uint32, timeout time.Duration) (time.Duration, bool) {
+	dialer := stdnet.Dialer{Timeout: timeout}
+	start := time.Now()
+	conn, err := dialer.DialContext(ctx, "tcp", stdnet.JoinHostPort(addr, strconv.Itoa(int(port))))
+	if err != nil {
+		return 0, false
+	}
+	latency := time.Since(start)
+	conn.Close()
+	return latency, true
+}
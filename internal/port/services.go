@@ -0,0 +1,95 @@
+package port
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// resolveTimeout bounds each reverse DNS lookup, so an unreachable
+// resolver doesn't stall a port listing
+const resolveTimeout = 2 * time.Second
+
+var (
+	servicesOnce sync.Once
+	services     map[string]string
+)
+
+// serviceName returns the well-known service name registered for port
+// and protocol ("TCP" or "UDP") in the system services database, empty
+// when there is none
+func serviceName(port uint32, protocol string) string {
+	servicesOnce.Do(func() {
+		services = loadServices(servicesPath())
+	})
+	return services[strconv.FormatUint(uint64(port), 10)+"/"+strings.ToLower(protocol)]
+}
+
+// servicesPath returns where the services database lives on this platform
+func servicesPath() string {
+	if runtime.GOOS == "windows" {
+		root := os.Getenv("SystemRoot")
+		if root == "" {
+			root = `C:\Windows`
+		}
+		return filepath.Join(root, "System32", "drivers", "etc", "services")
+	}
+	return "/etc/services"
+}
+
+// loadServices reads a services database, keyed by port/protocol, keeping
+// the first name listed for each. A missing file leaves every port
+// unnamed.
+//
+//	postgresql      5432/tcp    postgres    # PostgreSQL Database
+func loadServices(path string) map[string]string {
+	names := make(map[string]string)
+	f, err := os.Open(path)
+	if err != nil {
+		return names
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		key := strings.ToLower(fields[1])
+		if _, exists := names[key]; !exists {
+			names[key] = fields[0]
+		}
+	}
+	return names
+}
+
+// hostResolver reverse-resolves addresses, looking each up once
+type hostResolver map[string]string
+
+// lookup returns the host name ip resolves to, empty for wildcard
+// addresses and addresses without a PTR record
+func (r hostResolver) lookup(ctx context.Context, ip string) string {
+	if ip == "" || ip == "*" || net.ParseIP(ip) == nil || net.ParseIP(ip).IsUnspecified() {
+		return ""
+	}
+	if name, ok := r[ip]; ok {
+		return name
+	}
+	ctx, cancel := context.WithTimeout(ctx, resolveTimeout)
+	defer cancel()
+	var name string
+	if names, err := net.DefaultResolver.LookupAddr(ctx, ip); err == nil && len(names) > 0 {
+		name = strings.TrimSuffix(names[0], ".")
+	}
+	r[ip] = name
+	return name
+}
@@ -0,0 +1,132 @@
+package port
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+	"syscall"
+
+	"github.com/borankux/gops/pkg/types"
+	"github.com/shirou/gopsutil/v3/net"
+)
+
+// Sort keys accepted by UnixSocketOptions.SortBy
+const SortPath = "path"
+
+// UnixSocketSortKeys lists the valid Unix socket sort keys
+var UnixSocketSortKeys = []string{SortPath, SortPID, SortName}
+
+// UnixSocketOptions controls how Unix domain sockets are collected. Zero
+// filters are ignored.
+type UnixSocketOptions struct {
+	// SortBy is one of UnixSocketSortKeys; the default is SortPath
+	SortBy     string
+	Descending bool
+	PID        int32
+	// Path keeps sockets whose path contains it, ignoring case
+	Path string
+}
+
+// GetUnixSockets returns the Unix domain sockets bound to a path, or to a
+// name in Linux's abstract namespace, with their owning processes. The
+// sockets a process has on the same path, such as a server's listening
+// socket and its accepted connections, are listed once. Unnamed sockets,
+// like the two ends of a socketpair, are left out.
+func GetUnixSockets(ctx context.Context, opts UnixSocketOptions) ([]types.UnixSocketInfo, error) {
+	if runtime.GOOS == "windows" {
+		return nil, errors.New("listing Unix domain sockets is not supported on " + runtime.GOOS)
+	}
+	connections, err := net.ConnectionsWithContext(ctx, "unix")
+	if err != nil {
+		return nil, err
+	}
+
+	path := strings.ToLower(opts.Path)
+	procs := make(processCache)
+	byKey := make(map[string]*types.UnixSocketInfo)
+	for _, conn := range connections {
+		socketPath := conn.Laddr.IP
+		// lsof names unnamed sockets after their peer, like ->0x1234
+		if socketPath == "" || strings.HasPrefix(socketPath, "->") {
+			continue
+		}
+		if (opts.PID != 0 && conn.Pid != opts.PID) ||
+			(path != "" && !strings.Contains(strings.ToLower(socketPath), path)) {
+			continue
+		}
+		key := fmt.Sprintf("%s/%d", socketPath, conn.Pid)
+		if existing, exists := byKey[key]; exists {
+			existing.Sockets++
+			continue
+		}
+		name, exe := procs.lookup(ctx, conn.Pid)
+		byKey[key] = &types.UnixSocketInfo{
+			Path:       socketPath,
+			Type:       unixSocketType(conn),
+			Abstract:   strings.HasPrefix(socketPath, "@"),
+			PID:        conn.Pid,
+			Name:       name,
+			Executable: exe,
+			Sockets:    1,
+		}
+	}
+
+	result := make([]types.UnixSocketInfo, 0, len(byKey))
+	for _, s := range byKey {
+		result = append(result, *s)
+	}
+	if err := sortUnixSockets(result, opts); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// unixSocketType names the type of a Unix socket. lsof on macOS doesn't
+// report it, so it is empty there.
+func unixSocketType(conn net.ConnectionStat) string {
+	if runtime.GOOS != "linux" {
+		return ""
+	}
+	switch conn.Type {
+	case syscall.SOCK_STREAM:
+		return "stream"
+	case syscall.SOCK_DGRAM:
+		return "dgram"
+	case syscall.SOCK_SEQPACKET:
+		return "seqpacket"
+	default:
+		return ""
+	}
+}
+
+// sortUnixSockets orders sockets by the key in opts, breaking ties by path
+func sortUnixSockets(sockets []types.UnixSocketInfo, opts UnixSocketOptions) error {
+	var less func(a, b types.UnixSocketInfo) bool
+	switch opts.SortBy {
+	case "", SortPath:
+		less = func(a, b types.UnixSocketInfo) bool { return a.Path < b.Path }
+	case SortPID:
+		less = func(a, b types.UnixSocketInfo) bool { return a.PID < b.PID }
+	case SortName:
+		less = func(a, b types.UnixSocketInfo) bool { return strings.ToLower(a.Name) < strings.ToLower(b.Name) }
+	default:
+		return fmt.Errorf("invalid sort key: %s", opts.SortBy)
+	}
+
+	sort.Slice(sockets, func(i, j int) bool {
+		if sockets[i].Path != sockets[j].Path {
+			return sockets[i].Path < sockets[j].Path
+		}
+		return sockets[i].PID < sockets[j].PID
+	})
+	sort.SliceStable(sockets, func(i, j int) bool {
+		if opts.Descending {
+			return less(sockets[j], sockets[i])
+		}
+		return less(sockets[i], sockets[j])
+	})
+	return nil
+}
@@ -0,0 +1,37 @@
+//go:build darwin
+
+package process
+
+import (
+	"sync"
+
+	"golang.org/x/sys/unix"
+)
+
+// pTranslated is the kinfo_proc p_flag bit set for processes running
+// under Rosetta 2
+const pTranslated = 0x20000
+
+// appleSilicon reports whether the host has an arm64 CPU. hw.optional.arm64
+// is checked rather than hw.machine, which reads x86_64 when gops itself
+// runs under Rosetta.
+var appleSilicon = sync.OnceValue(func() bool {
+	v, err := unix.SysctlUint32("hw.optional.arm64")
+	return err == nil && v == 1
+})
+
+// processArch returns the CPU architecture a process runs as and whether
+// it is translated by Rosetta 2
+func processArch(pid int32) (string, bool) {
+	if !appleSilicon() {
+		return "x86_64", false
+	}
+	kinfo, err := unix.SysctlKinfoProc("kern.proc.pid", int(pid))
+	if err != nil {
+		return "", false
+	}
+	if kinfo.Proc.P_flag&pTranslated != 0 {
+		return "x86_64", true
+	}
+	return "arm64", false
+}
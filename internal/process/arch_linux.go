@@ -0,0 +1,58 @@
+//go:build linux
+
+package process
+
+import (
+	"debug/elf"
+	"encoding/binary"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// elfArchs names the common ELF machine types the way uname -m does
+var elfArchs = map[elf.Machine]string{
+	elf.EM_X86_64:  "x86_64",
+	elf.EM_AARCH64: "arm64",
+	elf.EM_386:     "i386",
+	elf.EM_ARM:     "arm",
+	elf.EM_RISCV:   "riscv64",
+	elf.EM_PPC64:   "ppc64",
+	elf.EM_S390:    "s390x",
+}
+
+// processArch returns the CPU architecture of a process's executable,
+// read from its ELF header. Linux has no Rosetta equivalent to report.
+func processArch(pid int32) (string, bool) {
+	f, err := os.Open("/proc/" + strconv.Itoa(int(pid)) + "/exe")
+	if err != nil {
+		return "", false
+	}
+	defer f.Close()
+	return elfArch(f), false
+}
+
+// elfArch returns the architecture named by the ELF header r starts
+// with, or "" if it is not an ELF file
+func elfArch(r io.Reader) string {
+	// e_ident is 16 bytes, followed by e_type and e_machine
+	header := make([]byte, 20)
+	if _, err := io.ReadFull(r, header); err != nil || string(header[:4]) != elf.ELFMAG {
+		return ""
+	}
+	data := elf.Data(header[elf.EI_DATA])
+	var order binary.ByteOrder = binary.LittleEndian
+	if data == elf.ELFDATA2MSB {
+		order = binary.BigEndian
+	}
+	machine := elf.Machine(order.Uint16(header[18:]))
+	if arch, ok := elfArchs[machine]; ok {
+		// 64-bit PowerPC runs either byte order
+		if machine == elf.EM_PPC64 && data == elf.ELFDATA2LSB {
+			arch += "le"
+		}
+		return arch
+	}
+	return strings.ToLower(strings.TrimPrefix(machine.String(), "EM_"))
+}
@@ -0,0 +1,48 @@
+//go:build linux
+
+package process
+
+import (
+	"bytes"
+	"debug/elf"
+	"encoding/binary"
+	"testing"
+)
+
+// elfHeader returns the first bytes of an ELF file for machine in the
+// given byte order
+func elfHeader(machine elf.Machine, data elf.Data) []byte {
+	header := make([]byte, 64)
+	copy(header, elf.ELFMAG)
+	header[elf.EI_CLASS] = byte(elf.ELFCLASS64)
+	header[elf.EI_DATA] = byte(data)
+	var order binary.ByteOrder = binary.LittleEndian
+	if data == elf.ELFDATA2MSB {
+		order = binary.BigEndian
+	}
+	order.PutUint16(header[16:], uint16(elf.ET_EXEC))
+	order.PutUint16(header[18:], uint16(machine))
+	return header
+}
+
+func TestElfArch(t *testing.T) {
+	tests := []struct {
+		name   string
+		header []byte
+		want   string
+	}{
+		{"x86_64", elfHeader(elf.EM_X86_64, elf.ELFDATA2LSB), "x86_64"},
+		{"arm64", elfHeader(elf.EM_AARCH64, elf.ELFDATA2LSB), "arm64"},
+		{"ppc64 big-endian", elfHeader(elf.EM_PPC64, elf.ELFDATA2MSB), "ppc64"},
+		{"ppc64 little-endian", elfHeader(elf.EM_PPC64, elf.ELFDATA2LSB), "ppc64le"},
+		{"s390x", elfHeader(elf.EM_S390, elf.ELFDATA2MSB), "s390x"},
+		{"unlisted machine", elfHeader(elf.EM_MIPS, elf.ELFDATA2MSB), "mips"},
+		{"not ELF", []byte("#!/bin/sh\necho hello world\n"), ""},
+		{"truncated", elfHeader(elf.EM_X86_64, elf.ELFDATA2LSB)[:10], ""},
+	}
+	for _, tt := range tests {
+		if got := elfArch(bytes.NewReader(tt.header)); got != tt.want {
+			t.Errorf("%s: elfArch = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
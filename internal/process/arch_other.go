@@ -0,0 +1,8 @@
+//go:build !darwin && !linux
+
+package process
+
+// processArch is only available on macOS and Linux
+func processArch(pid int32) (string, bool) {
+	return "", false
+}
@@ -0,0 +1,78 @@
+package process
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/borankux/gops/internal/utils"
+)
+
+// appInfo describes the macOS app bundle a process belongs to
+type appInfo struct {
+	BundleID string
+	Name     string
+	Version  string
+}
+
+// appInfos caches app bundle details by bundle path
+var appInfos sync.Map
+
+// bundleApp returns the details of the outermost macOS app bundle
+// containing exe, so helpers nested inside an app (such as "Google Chrome
+// Helper (Renderer)") resolve to the app that owns them. It returns the
+// zero value if exe is not inside an app bundle.
+func bundleApp(ctx context.Context, exe string) appInfo {
+	app := appBundle(exe)
+	if app == "" {
+		return appInfo{}
+	}
+	if info, ok := appInfos.Load(app); ok {
+		return info.(appInfo)
+	}
+
+	keys := readPlist(ctx, filepath.Join(app, "Contents", "Info.plist"))
+	info := appInfo{
+		BundleID: keys["CFBundleIdentifier"],
+		Name:     keys["CFBundleDisplayName"],
+		Version:  keys["CFBundleShortVersionString"],
+	}
+	if info.Name == "" {
+		info.Name = keys["CFBundleName"]
+	}
+	if info.Name == "" {
+		info.Name = strings.TrimSuffix(filepath.Base(app), ".app")
+	}
+	if info.Version == "" {
+		info.Version = keys["CFBundleVersion"]
+	}
+	appInfos.Store(app, info)
+	return info
+}
+
+// appBundle returns the outermost .app directory containing exe
+func appBundle(exe string) string {
+	i := strings.Index(exe, ".app/")
+	if i < 0 {
+		return ""
+	}
+	return exe[:i+len(".app")]
+}
+
+// readPlist returns the top-level string values of a property list.
+// Binary plists are converted to XML with plutil first.
+func readPlist(ctx context.Context, plist string) map[string]string {
+	values := make(map[string]string)
+	root, err := utils.ReadPlist(ctx, plist)
+	if err != nil {
+		return values
+	}
+	dict, _ := root.(map[string]interface{})
+	for key, value := range dict {
+		if str, ok := value.(string); ok {
+			values[key] = str
+		}
+	}
+	return values
+}
@@ -0,0 +1,71 @@
+package process
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+var (
+	// ErrNotFound is returned when the target process does not exist
+	ErrNotFound = errors.New("process not found")
+	// ErrProtected is returned when asked to act on a process whose loss
+	// would take down the system or gops itself
+	ErrProtected = errors.New("refusing to modify protected process")
+)
+
+// target looks up a process that is about to be signalled or modified,
+// refusing PID 0 and 1, gops itself and critical system processes
+func target(ctx context.Context, pid int32) (*process.Process, string, error) {
+	if pid <= 1 || int(pid) == os.Getpid() {
+		return nil, "", fmt.Errorf("%w: PID %d", ErrProtected, pid)
+	}
+
+	p, name, err := lookup(ctx, pid)
+	if err != nil {
+		return nil, "", err
+	}
+	if isCriticalProcess(name) {
+		return nil, "", fmt.Errorf("%w: %s (PID %d) is a critical system process", ErrProtected, name, pid)
+	}
+	return p, name, nil
+}
+
+// lookup returns the process with the given PID and its name, or
+// ErrNotFound if it does not exist
+func lookup(ctx context.Context, pid int32) (*process.Process, string, error) {
+	p, err := process.NewProcessWithContext(ctx, pid)
+	if err != nil {
+		if errors.Is(err, process.ErrorProcessNotRunning) {
+			return nil, "", fmt.Errorf("%w: PID %d", ErrNotFound, pid)
+		}
+		return nil, "", err
+	}
+	name, _ := p.NameWithContext(ctx)
+	return p, name, nil
+}
+
+// isCriticalProcess reports whether name is a process the OS cannot
+// survive losing
+func isCriticalProcess(name string) bool {
+	var critical []string
+	switch runtime.GOOS {
+	case "darwin":
+		critical = []string{"launchd", "kernel_task", "WindowServer", "loginwindow", "logd", "opendirectoryd", "securityd"}
+	case "linux":
+		critical = []string{"init", "systemd", "systemd-journald", "systemd-logind", "dbus-daemon", "kthreadd"}
+	case "windows":
+		critical = []string{"System", "smss.exe", "csrss.exe", "wininit.exe", "winlogon.exe", "services.exe", "lsass.exe"}
+	}
+	for _, c := range critical {
+		if strings.EqualFold(name, c) {
+			return true
+		}
+	}
+	return false
+}
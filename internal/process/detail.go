@@ -0,0 +1,77 @@
+package process
+
+import (
+	"context"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/borankux/gops/pkg/types"
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// GetProcess returns the full view of a single process, including its
+// command line, its parent and the processes it spawned
+func GetProcess(ctx context.Context, pid int32) (types.ProcessDetail, error) {
+	p, name, err := lookup(ctx, pid)
+	if err != nil {
+		return types.ProcessDetail{}, err
+	}
+
+	exe, _ := p.ExeWithContext(ctx)
+	username, _ := p.UsernameWithContext(ctx)
+	detail := types.ProcessDetail{
+		ProcessInfo: newProcessInfo(ctx, p, name, exe, username, ListOptions{Cmdline: true}),
+		Children:    []types.ProcessRef{},
+	}
+	detail.CPUPercent, _ = p.CPUPercentWithContext(ctx)
+	if mem, err := p.MemoryInfoWithContext(ctx); err == nil && mem != nil {
+		detail.MemoryRSS = mem.RSS
+	}
+
+	detail.Cwd = workingDir(ctx, p)
+	detail.Security = codeSecurity(ctx, exe)
+
+	if detail.PPID != 0 {
+		if parent, err := process.NewProcessWithContext(ctx, detail.PPID); err == nil {
+			detail.ParentName, _ = parent.NameWithContext(ctx)
+		}
+	}
+
+	procs, err := process.ProcessesWithContext(ctx)
+	if err != nil {
+		return types.ProcessDetail{}, err
+	}
+	for _, child := range procs {
+		if ppid, err := child.PpidWithContext(ctx); err != nil || ppid != pid || child.Pid == pid {
+			continue
+		}
+		childName, _ := child.NameWithContext(ctx)
+		detail.Children = append(detail.Children, types.ProcessRef{PID: child.Pid, Name: childName})
+	}
+	sort.Slice(detail.Children, func(i, j int) bool {
+		return detail.Children[i].PID < detail.Children[j].PID
+	})
+
+	return detail, nil
+}
+
+// workingDir returns the current directory of a process. gopsutil cannot
+// read it on macOS without cgo, so lsof is used as a fallback.
+func workingDir(ctx context.Context, p *process.Process) string {
+	if cwd, err := p.CwdWithContext(ctx); err == nil {
+		return cwd
+	}
+
+	out, err := exec.CommandContext(ctx, "lsof", "-a", "-d", "cwd", "-p", strconv.Itoa(int(p.Pid)), "-Fn").Output()
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(out), "\n") {
+		if name, found := strings.CutPrefix(line, "n"); found {
+			return name
+		}
+	}
+	return ""
+}
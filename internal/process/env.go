@@ -0,0 +1,66 @@
+package process
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/borankux/gops/pkg/types"
+)
+
+// RedactedValue replaces the values of secret environment variables
+const RedactedValue = "[REDACTED]"
+
+// SecretKeyPatterns mark an environment variable as a secret when its key
+// contains one of them, ignoring case
+var SecretKeyPatterns = []string{"TOKEN", "SECRET", "PASSWORD", "API_KEY"}
+
+// GetEnvironment returns the environment variables of a process with the
+// values of secrets redacted. Most systems only allow reading the
+// environment of processes owned by the same user.
+func GetEnvironment(ctx context.Context, pid int32) (types.EnvironmentResponse, error) {
+	p, name, err := lookup(ctx, pid)
+	if err != nil {
+		return types.EnvironmentResponse{}, err
+	}
+
+	vars, err := environ(ctx, p)
+	if err != nil {
+		return types.EnvironmentResponse{}, fmt.Errorf("failed to read environment of PID %d: %w", pid, err)
+	}
+
+	env := make(map[string]string, len(vars))
+	var redacted []string
+	for _, kv := range vars {
+		key, value, found := strings.Cut(kv, "=")
+		if !found || key == "" {
+			continue
+		}
+		if isSecretKey(key) {
+			value = RedactedValue
+			redacted = append(redacted, key)
+		}
+		env[key] = value
+	}
+	sort.Strings(redacted)
+
+	return types.EnvironmentResponse{
+		PID:      pid,
+		Name:     name,
+		Env:      env,
+		Redacted: redacted,
+		Count:    len(env),
+	}, nil
+}
+
+// isSecretKey reports whether an environment variable holds a secret
+func isSecretKey(key string) bool {
+	key = strings.ToUpper(key)
+	for _, pattern := range SecretKeyPatterns {
+		if strings.Contains(key, pattern) {
+			return true
+		}
+	}
+	return false
+}
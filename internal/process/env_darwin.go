@@ -0,0 +1,54 @@
+//go:build darwin
+
+package process
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/shirou/gopsutil/v3/process"
+	"golang.org/x/sys/unix"
+)
+
+// environ reads the environment from the kern.procargs2 sysctl, which
+// gopsutil does not implement on macOS. The buffer holds argc, the
+// executable path, the arguments and then the environment, all
+// NUL-terminated.
+func environ(ctx context.Context, p *process.Process) ([]string, error) {
+	buf, err := unix.SysctlRaw("kern.procargs2", int(p.Pid))
+	if err != nil {
+		if errors.Is(err, unix.EINVAL) || errors.Is(err, unix.EPERM) {
+			// The kernel refuses processes owned by other users with EINVAL
+			return nil, fmt.Errorf("%w: not permitted for this process", os.ErrPermission)
+		}
+		return nil, err
+	}
+	if len(buf) < 4 {
+		return nil, errors.New("malformed process arguments")
+	}
+	argc := int(binary.LittleEndian.Uint32(buf))
+	buf = buf[4:]
+
+	// Skip the executable path and the padding that follows it
+	if i := bytes.IndexByte(buf, 0); i >= 0 {
+		buf = buf[i:]
+	}
+	buf = bytes.TrimLeft(buf, "\x00")
+
+	var env []string
+	for _, field := range bytes.Split(buf, []byte{0}) {
+		if argc > 0 {
+			argc--
+			continue
+		}
+		if len(field) == 0 {
+			break
+		}
+		env = append(env, string(field))
+	}
+	return env, nil
+}
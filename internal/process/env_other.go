@@ -0,0 +1,13 @@
+//go:build !darwin
+
+package process
+
+import (
+	"context"
+
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+func environ(ctx context.Context, p *process.Process) ([]string, error) {
+	return p.EnvironWithContext(ctx)
+}
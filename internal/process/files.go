@@ -0,0 +1,199 @@
+package process
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/borankux/gops/pkg/types"
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// Open file types reported in types.OpenFile
+const (
+	FileTypeFile   = "file"
+	FileTypeDir    = "dir"
+	FileTypeSocket = "socket"
+	FileTypePipe   = "pipe"
+	FileTypeDevice = "device"
+	FileTypeOther  = "other"
+)
+
+// GetOpenFiles returns the file descriptors a process holds open. It uses
+// gopsutil where supported and falls back to lsof elsewhere, notably on
+// macOS.
+func GetOpenFiles(ctx context.Context, pid int32) (types.OpenFilesResponse, error) {
+	p, name, err := lookup(ctx, pid)
+	if err != nil {
+		return types.OpenFilesResponse{}, err
+	}
+
+	files, err := openFiles(ctx, p)
+	if err != nil {
+		var lsofErr error
+		if files, lsofErr = lsofOpenFiles(ctx, pid); lsofErr != nil {
+			return types.OpenFilesResponse{}, fmt.Errorf("failed to list open files of PID %d: %w", pid, err)
+		}
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].FD < files[j].FD
+	})
+
+	return types.OpenFilesResponse{
+		PID:   pid,
+		Name:  name,
+		Files: files,
+		Count: len(files),
+	}, nil
+}
+
+// openFiles lists open files with gopsutil
+func openFiles(ctx context.Context, p *process.Process) ([]types.OpenFile, error) {
+	stats, err := p.OpenFilesWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]types.OpenFile, 0, len(stats))
+	for _, st := range stats {
+		files = append(files, types.OpenFile{
+			FD:   st.Fd,
+			Path: st.Path,
+			Type: fileType(st.Path),
+			Mode: fdMode(p.Pid, st.Fd),
+		})
+	}
+	return files, nil
+}
+
+// fileType classifies an open file by its path. Linux reports sockets and
+// pipes as pseudo-paths such as socket:[1234].
+func fileType(path string) string {
+	switch {
+	case strings.HasPrefix(path, "socket:"):
+		return FileTypeSocket
+	case strings.HasPrefix(path, "pipe:"):
+		return FileTypePipe
+	case strings.HasPrefix(path, "anon_inode:"):
+		return FileTypeOther
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return FileTypeOther
+	}
+	mode := info.Mode()
+	switch {
+	case mode.IsDir():
+		return FileTypeDir
+	case mode&os.ModeSocket != 0:
+		return FileTypeSocket
+	case mode&os.ModeNamedPipe != 0:
+		return FileTypePipe
+	case mode&os.ModeDevice != 0:
+		return FileTypeDevice
+	case mode.IsRegular():
+		return FileTypeFile
+	default:
+		return FileTypeOther
+	}
+}
+
+// fdMode returns the access mode of a file descriptor from
+// /proc/<pid>/fdinfo on Linux, or "" where it is unknown
+func fdMode(pid int32, fd uint64) string {
+	if runtime.GOOS != "linux" {
+		return ""
+	}
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/fdinfo/%d", pid, fd))
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		value, found := strings.CutPrefix(line, "flags:")
+		if !found {
+			continue
+		}
+		flags, err := strconv.ParseUint(strings.TrimSpace(value), 8, 64)
+		if err != nil {
+			return ""
+		}
+		switch flags & 3 { // O_ACCMODE
+		case 0:
+			return "r"
+		case 1:
+			return "w"
+		case 2:
+			return "rw"
+		}
+	}
+	return ""
+}
+
+// lsofOpenFiles lists numbered file descriptors with lsof's field output
+func lsofOpenFiles(ctx context.Context, pid int32) ([]types.OpenFile, error) {
+	out, err := exec.CommandContext(ctx, "lsof", "-n", "-P", "-p", strconv.Itoa(int(pid)), "-F", "ftan").Output()
+	if err != nil && len(out) == 0 {
+		return nil, err
+	}
+
+	var files []types.OpenFile
+	var current *types.OpenFile
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+		value := line[1:]
+		switch line[0] {
+		case 'f':
+			current = nil
+			// Skip cwd, txt, mem and other non-descriptor entries
+			fd, err := strconv.ParseUint(strings.TrimRight(value, "rwuNRW-"), 10, 64)
+			if err != nil {
+				continue
+			}
+			files = append(files, types.OpenFile{FD: fd, Type: FileTypeOther})
+			current = &files[len(files)-1]
+		case 'a':
+			if current != nil {
+				current.Mode = map[string]string{"r": "r", "w": "w", "u": "rw"}[value]
+			}
+		case 't':
+			if current != nil {
+				current.Type = lsofType(value)
+			}
+		case 'n':
+			if current != nil {
+				current.Path = value
+			}
+		}
+	}
+	return files, scanner.Err()
+}
+
+// lsofType maps an lsof TYPE column to an open file type
+func lsofType(t string) string {
+	switch t {
+	case "REG":
+		return FileTypeFile
+	case "DIR":
+		return FileTypeDir
+	case "IPv4", "IPv6", "unix", "sock", "systm":
+		return FileTypeSocket
+	case "PIPE", "FIFO":
+		return FileTypePipe
+	case "CHR", "BLK":
+		return FileTypeDevice
+	default:
+		return FileTypeOther
+	}
+}
@@ -0,0 +1,73 @@
+package process
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/borankux/gops/pkg/types"
+)
+
+// Query selects processes by name or bundle identifier. Every non-empty
+// criterion must match.
+type Query struct {
+	// Name matches process names containing it, ignoring case
+	Name string
+	// Exact requires Name to equal the whole process name
+	Exact bool
+	// Regex is a regular expression matched against process names
+	Regex string
+	// BundleID matches the macOS bundle identifier of the app a process
+	// belongs to, e.g. com.apple.Safari
+	BundleID string
+}
+
+// IsZero reports whether q has no criteria and so matches everything
+func (q Query) IsZero() bool {
+	return q.Name == "" && q.Regex == "" && q.BundleID == ""
+}
+
+// Find returns every running process matched by q, sorted by PID
+func Find(ctx context.Context, q Query) ([]types.ProcessInfo, error) {
+	procs, err := GetAllProcesses(ctx, ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return Filter(procs, q)
+}
+
+// Filter returns the processes in procs matched by q, keeping their order
+func Filter(procs []types.ProcessInfo, q Query) ([]types.ProcessInfo, error) {
+	if q.IsZero() {
+		return procs, nil
+	}
+
+	var re *regexp.Regexp
+	if q.Regex != "" {
+		var err error
+		if re, err = regexp.Compile(q.Regex); err != nil {
+			return nil, fmt.Errorf("invalid regular expression: %v", err)
+		}
+	}
+
+	matched := []types.ProcessInfo{}
+	for _, p := range procs {
+		if q.Name != "" {
+			if q.Exact && !strings.EqualFold(p.Name, q.Name) {
+				continue
+			}
+			if !q.Exact && !strings.Contains(strings.ToLower(p.Name), strings.ToLower(q.Name)) {
+				continue
+			}
+		}
+		if re != nil && !re.MatchString(p.Name) {
+			continue
+		}
+		if q.BundleID != "" && !strings.EqualFold(p.BundleID, q.BundleID) {
+			continue
+		}
+		matched = append(matched, p)
+	}
+	return matched, nil
+}
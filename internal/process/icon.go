@@ -0,0 +1,78 @@
+package process
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image/png"
+
+	"github.com/borankux/gops/pkg/types"
+)
+
+// ErrNoIcon is returned when no icon can be found for a process
+var ErrNoIcon = errors.New("no icon found")
+
+// DefaultIconSize is the icon size in pixels returned when none is asked for
+const DefaultIconSize = 128
+
+// iconImage is one PNG rendition of an icon, with its width in pixels
+type iconImage struct {
+	source string
+	size   int
+	data   []byte
+}
+
+// GetIcon returns the icon of the application a process belongs to as a
+// PNG, choosing the rendition closest to size pixels. The icon is taken
+// from the app bundle on macOS, the matching .desktop entry on Linux and
+// an .ico file next to the executable on Windows.
+func GetIcon(ctx context.Context, pid int32, size int) (types.ProcessIconResponse, error) {
+	p, name, err := lookup(ctx, pid)
+	if err != nil {
+		return types.ProcessIconResponse{}, err
+	}
+	exe, _ := p.ExeWithContext(ctx)
+	if exe == "" {
+		return types.ProcessIconResponse{}, fmt.Errorf("%w: %s has no readable executable path", ErrNoIcon, name)
+	}
+	if size <= 0 {
+		size = DefaultIconSize
+	}
+
+	images, err := findIcons(ctx, exe, name, size)
+	if err != nil {
+		return types.ProcessIconResponse{}, err
+	}
+	if len(images) == 0 {
+		return types.ProcessIconResponse{}, fmt.Errorf("%w: %s", ErrNoIcon, name)
+	}
+	img := closestIcon(images, size)
+
+	resp := types.ProcessIconResponse{
+		PID:      pid,
+		Name:     name,
+		Source:   img.source,
+		MimeType: "image/png",
+		Data:     img.data,
+	}
+	if cfg, err := png.DecodeConfig(bytes.NewReader(img.data)); err == nil {
+		resp.Width, resp.Height = cfg.Width, cfg.Height
+	}
+	return resp, nil
+}
+
+// closestIcon returns the smallest image at least size pixels wide, or
+// the largest one if all are smaller
+func closestIcon(images []iconImage, size int) iconImage {
+	best := images[0]
+	for _, img := range images[1:] {
+		switch {
+		case best.size < size && img.size > best.size:
+			best = img
+		case img.size >= size && img.size < best.size:
+			best = img
+		}
+	}
+	return best
+}
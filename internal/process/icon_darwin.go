@@ -0,0 +1,97 @@
+package process
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// icnsSizes maps the icns element types holding PNG data to their width
+// in pixels
+var icnsSizes = map[string]int{
+	"icp4": 16, "icp5": 32, "icp6": 64,
+	"ic07": 128, "ic08": 256, "ic09": 512, "ic10": 1024,
+	"ic11": 32, "ic12": 64, "ic13": 256, "ic14": 512,
+}
+
+// pngSignature starts every PNG file
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// findIcons reads the icon named by CFBundleIconFile from the app bundle
+// containing exe
+func findIcons(ctx context.Context, exe, name string, size int) ([]iconImage, error) {
+	app := appBundle(exe)
+	if app == "" {
+		return nil, fmt.Errorf("%w: %s is not part of an app bundle", ErrNoIcon, name)
+	}
+	keys := readPlist(ctx, filepath.Join(app, "Contents", "Info.plist"))
+	file := keys["CFBundleIconFile"]
+	if file == "" {
+		return nil, fmt.Errorf("%w: %s declares no icon file", ErrNoIcon, name)
+	}
+	if filepath.Ext(file) == "" {
+		file += ".icns"
+	}
+	icns := filepath.Join(app, "Contents", "Resources", file)
+	data, err := os.ReadFile(icns)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNoIcon, err)
+	}
+
+	if images := parseIcns(icns, data); len(images) > 0 {
+		return images, nil
+	}
+	// Older icns files store raw bitmaps rather than PNG; sips converts them
+	img, err := convertIcns(ctx, icns, size)
+	if err != nil {
+		return nil, err
+	}
+	return []iconImage{img}, nil
+}
+
+// parseIcns returns the PNG renditions stored in an icns file
+func parseIcns(source string, data []byte) []iconImage {
+	if len(data) < 8 || string(data[:4]) != "icns" {
+		return nil
+	}
+	var images []iconImage
+	for off := 8; off+8 <= len(data); {
+		kind := string(data[off : off+4])
+		length := int(binary.BigEndian.Uint32(data[off+4 : off+8]))
+		if length < 8 || off+length > len(data) {
+			break
+		}
+		body := data[off+8 : off+length]
+		if size, ok := icnsSizes[kind]; ok && bytes.HasPrefix(body, pngSignature) {
+			images = append(images, iconImage{source: source, size: size, data: body})
+		}
+		off += length
+	}
+	return images
+}
+
+// convertIcns renders an icns file as a PNG of the given size with sips
+func convertIcns(ctx context.Context, icns string, size int) (iconImage, error) {
+	tmp, err := os.CreateTemp("", "gops-icon-*.png")
+	if err != nil {
+		return iconImage{}, err
+	}
+	tmp.Close()
+	defer os.Remove(tmp.Name())
+
+	cmd := exec.CommandContext(ctx, "sips", "-s", "format", "png", "-Z", strconv.Itoa(size), icns, "--out", tmp.Name())
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return iconImage{}, fmt.Errorf("sips: %s", strings.TrimSpace(string(out)))
+	}
+	data, err := os.ReadFile(tmp.Name())
+	if err != nil {
+		return iconImage{}, err
+	}
+	return iconImage{source: icns, size: size, data: data}, nil
+}
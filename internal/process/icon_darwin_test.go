@@ -0,0 +1,53 @@
+package process
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+// icnsElement encodes one element of an icns file
+func icnsElement(kind string, body []byte) []byte {
+	element := make([]byte, 8, 8+len(body))
+	copy(element, kind)
+	binary.BigEndian.PutUint32(element[4:], uint32(8+len(body)))
+	return append(element, body...)
+}
+
+// icnsFile encodes an icns file holding elements
+func icnsFile(elements ...[]byte) []byte {
+	file := make([]byte, 8)
+	copy(file, "icns")
+	for _, e := range elements {
+		file = append(file, e...)
+	}
+	binary.BigEndian.PutUint32(file[4:], uint32(len(file)))
+	return file
+}
+
+func TestParseIcns(t *testing.T) {
+	png := append(append([]byte{}, pngSignature...), "rest of a png"...)
+	tests := []struct {
+		name  string
+		data  []byte
+		sizes []int
+	}{
+		{"png renditions", icnsFile(icnsElement("ic07", png), icnsElement("ic10", png)), []int{128, 1024}},
+		{"raw bitmaps skipped", icnsFile(icnsElement("is32", []byte("bitmap")), icnsElement("ic08", png)), []int{256}},
+		{"unknown png element skipped", icnsFile(icnsElement("zzzz", png)), nil},
+		{"not icns", append([]byte("ICNS0000"), png...), nil},
+		{"truncated element", icnsFile(icnsElement("ic09", png))[:20], nil},
+		{"too short", []byte("icn"), nil},
+	}
+	for _, tt := range tests {
+		images := parseIcns("Test.icns", tt.data)
+		if len(images) != len(tt.sizes) {
+			t.Errorf("%s: %d images, want %d", tt.name, len(images), len(tt.sizes))
+			continue
+		}
+		for i, img := range images {
+			if img.size != tt.sizes[i] || string(img.data) != string(png) || img.source != "Test.icns" {
+				t.Errorf("%s: image %d = %d px from %s, want %d px", tt.name, i, img.size, img.source, tt.sizes[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package process
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// findIcons looks up the icon of the .desktop entry that launches exe in
+// the hicolor icon theme and /usr/share/pixmaps. Without a matching
+// entry the executable name is tried as the icon name.
+func findIcons(ctx context.Context, exe, name string, size int) ([]iconImage, error) {
+	icon := desktopIcon(exe)
+	if icon == "" {
+		icon = filepath.Base(exe)
+	}
+	if filepath.IsAbs(icon) {
+		if !strings.HasSuffix(icon, ".png") {
+			return nil, nil
+		}
+		data, err := os.ReadFile(icon)
+		if err != nil {
+			return nil, nil
+		}
+		return []iconImage{{source: icon, size: size, data: data}}, nil
+	}
+
+	var images []iconImage
+	for _, dir := range xdgDataDirs() {
+		sizes, _ := filepath.Glob(filepath.Join(dir, "icons", "hicolor", "*x*", "apps", icon+".png"))
+		for _, path := range sizes {
+			px, err := strconv.Atoi(strings.SplitN(filepath.Base(filepath.Dir(filepath.Dir(path))), "x", 2)[0])
+			if err != nil {
+				continue
+			}
+			if data, err := os.ReadFile(path); err == nil {
+				images = append(images, iconImage{source: path, size: px, data: data})
+			}
+		}
+	}
+	if len(images) == 0 {
+		path := filepath.Join("/usr/share/pixmaps", icon+".png")
+		if data, err := os.ReadFile(path); err == nil {
+			images = append(images, iconImage{source: path, size: size, data: data})
+		}
+	}
+	return images, nil
+}
+
+// xdgDataDirs returns the XDG data directories, the user's first
+func xdgDataDirs() []string {
+	var dirs []string
+	if home := os.Getenv("XDG_DATA_HOME"); home != "" {
+		dirs = append(dirs, home)
+	} else if home, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs, filepath.Join(home, ".local", "share"))
+	}
+	system := os.Getenv("XDG_DATA_DIRS")
+	if system == "" {
+		system = "/usr/local/share:/usr/share"
+	}
+	dirs = append(dirs, filepath.SplitList(system)...)
+	return append(dirs, "/var/lib/flatpak/exports/share", "/var/lib/snapd/desktop")
+}
+
+// desktopIcon returns the Icon key of the first .desktop entry whose
+// TryExec or Exec command is exe
+func desktopIcon(exe string) string {
+	for _, dir := range xdgDataDirs() {
+		entries, _ := filepath.Glob(filepath.Join(dir, "applications", "*.desktop"))
+		for _, entry := range entries {
+			if icon, ok := desktopEntryIcon(entry, exe); ok {
+				return icon
+			}
+		}
+	}
+	return ""
+}
+
+// desktopEntryIcon reads the [Desktop Entry] group of a .desktop file,
+// returning its icon if the entry launches exe
+func desktopEntryIcon(path, exe string) (string, bool) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", false
+	}
+	defer f.Close()
+
+	var icon string
+	var launches, inEntry bool
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") {
+			// Actions follow the main entry and have their own Exec lines
+			if inEntry {
+				break
+			}
+			inEntry = line == "[Desktop Entry]"
+			continue
+		}
+		key, value, found := strings.Cut(line, "=")
+		if !inEntry || !found {
+			continue
+		}
+		switch strings.TrimSpace(key) {
+		case "Icon":
+			icon = strings.TrimSpace(value)
+		case "Exec", "TryExec":
+			fields := strings.Fields(value)
+			if len(fields) > 0 && execMatches(strings.Trim(fields[0], `"`), exe) {
+				launches = true
+			}
+		}
+	}
+	return icon, launches && icon != ""
+}
+
+// execMatches reports whether the command of a desktop entry runs exe,
+// either by absolute path or by name on the PATH
+func execMatches(command, exe string) bool {
+	if filepath.IsAbs(command) {
+		if command == exe {
+			return true
+		}
+		resolved, err := filepath.EvalSymlinks(command)
+		return err == nil && resolved == exe
+	}
+	return command == filepath.Base(exe)
+}
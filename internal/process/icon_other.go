@@ -0,0 +1,14 @@
+//go:build !darwin && !linux && !windows
+
+package process
+
+import (
+	"context"
+	"errors"
+	"runtime"
+)
+
+// findIcons is not implemented on this platform
+func findIcons(ctx context.Context, exe, name string, size int) ([]iconImage, error) {
+	return nil, errors.New("process icons are not supported on " + runtime.GOOS)
+}
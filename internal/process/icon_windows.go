@@ -0,0 +1,103 @@
+package process
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// pngSignature starts every PNG file
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// findIcons reads an .ico file named after exe, or else any .ico file,
+// from the executable's directory. Icons embedded in the executable's
+// resources are not read.
+func findIcons(ctx context.Context, exe, name string, size int) ([]iconImage, error) {
+	dir := filepath.Dir(exe)
+	candidates := []string{strings.TrimSuffix(exe, filepath.Ext(exe)) + ".ico"}
+	others, _ := filepath.Glob(filepath.Join(dir, "*.ico"))
+	candidates = append(candidates, others...)
+
+	for _, path := range candidates {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		if images := parseIco(path, data); len(images) > 0 {
+			return images, nil
+		}
+	}
+	return nil, nil
+}
+
+// parseIco returns the renditions of an .ico file as PNGs. PNG entries
+// are used as they are; 32-bit bitmap entries are converted.
+func parseIco(source string, data []byte) []iconImage {
+	if len(data) < 6 || binary.LittleEndian.Uint16(data[2:]) != 1 {
+		return nil
+	}
+	count := int(binary.LittleEndian.Uint16(data[4:]))
+	var images []iconImage
+	for i := 0; i < count; i++ {
+		entry := 6 + i*16
+		if entry+16 > len(data) {
+			break
+		}
+		size := int(data[entry])
+		if size == 0 {
+			size = 256
+		}
+		length := int(binary.LittleEndian.Uint32(data[entry+8:]))
+		offset := int(binary.LittleEndian.Uint32(data[entry+12:]))
+		if offset < 0 || length <= 0 || offset+length > len(data) {
+			continue
+		}
+		body := data[offset : offset+length]
+		if bytes.HasPrefix(body, pngSignature) {
+			images = append(images, iconImage{source: source, size: size, data: body})
+			continue
+		}
+		if encoded := bitmapToPNG(body); encoded != nil {
+			images = append(images, iconImage{source: source, size: size, data: encoded})
+		}
+	}
+	return images
+}
+
+// bitmapToPNG converts a 32-bit BGRA icon bitmap to PNG, returning nil
+// for other formats
+func bitmapToPNG(dib []byte) []byte {
+	if len(dib) < 40 {
+		return nil
+	}
+	headerSize := int(binary.LittleEndian.Uint32(dib))
+	width := int(int32(binary.LittleEndian.Uint32(dib[4:])))
+	// The height covers the colour bitmap and the AND mask below it
+	height := int(int32(binary.LittleEndian.Uint32(dib[8:]))) / 2
+	bpp := binary.LittleEndian.Uint16(dib[14:])
+	if bpp != 32 || width <= 0 || height <= 0 || headerSize+width*height*4 > len(dib) {
+		return nil
+	}
+
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	pixels := dib[headerSize:]
+	for y := 0; y < height; y++ {
+		// Rows are stored bottom-up
+		row := pixels[(height-1-y)*width*4:]
+		for x := 0; x < width; x++ {
+			b, g, r, a := row[x*4], row[x*4+1], row[x*4+2], row[x*4+3]
+			img.SetNRGBA(x, y, color.NRGBA{R: r, G: g, B: b, A: a})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,133 @@
+package process
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/borankux/gops/pkg/types"
+)
+
+// launchTimeout bounds how long Launch waits for an app opened through
+// open(1) to appear in the process table
+const launchTimeout = 10 * time.Second
+
+// LaunchRequest describes an application to start. Exactly one of
+// BundleID and Path is set.
+type LaunchRequest struct {
+	// BundleID is the identifier of a macOS app, e.g. com.apple.Safari
+	BundleID string
+	// Path is an executable, looked up in PATH if it has no slash, or on
+	// macOS an .app bundle
+	Path string
+	Args []string
+	// Dir is the working directory of an executable; gops's own if empty
+	Dir string
+}
+
+// Launch starts an application and returns its process. macOS apps are
+// opened with open(1), as if the user had started them; if the app is
+// already running, open activates it and its existing process is
+// returned. Other executables are started directly, detached from gops.
+func Launch(ctx context.Context, req LaunchRequest) (types.LaunchResponse, error) {
+	if req.BundleID != "" || isAppBundle(req.Path) {
+		if runtime.GOOS != "darwin" {
+			return types.LaunchResponse{}, errors.New("launching app bundles is only supported on macOS")
+		}
+		return openApp(ctx, req)
+	}
+
+	cmd := exec.Command(req.Path, req.Args...)
+	cmd.Dir = req.Dir
+	detach(cmd)
+	if err := cmd.Start(); err != nil {
+		return types.LaunchResponse{}, err
+	}
+	// Reap the child when it exits so it doesn't linger as a zombie
+	go cmd.Wait()
+
+	pid := int32(cmd.Process.Pid)
+	info, err := GetProcessInfo(ctx, pid)
+	if err != nil {
+		// Already exited; report what was started
+		info = types.ProcessInfo{PID: pid, Name: filepath.Base(req.Path), Path: req.Path}
+	}
+	return launchResponse(info, false), nil
+}
+
+// openApp launches a macOS app with open(1) and waits for its process
+func openApp(ctx context.Context, req LaunchRequest) (types.LaunchResponse, error) {
+	bundleID := req.BundleID
+	args := []string{"-b", bundleID}
+	if bundleID == "" {
+		bundleID = bundleApp(ctx, strings.TrimSuffix(req.Path, "/")+"/").BundleID
+		args = []string{"-a", req.Path}
+	}
+	if len(req.Args) > 0 {
+		args = append(append(args, "--args"), req.Args...)
+	}
+
+	var running []types.ProcessInfo
+	if bundleID != "" {
+		var err error
+		if running, err = Find(ctx, Query{BundleID: bundleID}); err != nil {
+			return types.LaunchResponse{}, err
+		}
+	}
+
+	if out, err := exec.CommandContext(ctx, "open", args...).CombinedOutput(); err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return types.LaunchResponse{}, fmt.Errorf("open: %s", msg)
+		}
+		return types.LaunchResponse{}, fmt.Errorf("open: %w", err)
+	}
+
+	switch {
+	case bundleID == "":
+		// Without a bundle identifier the new process can't be told apart
+		return types.LaunchResponse{Name: strings.TrimSuffix(filepath.Base(req.Path), ".app"), Path: req.Path}, nil
+	case len(running) > 0:
+		// The app's main process is its oldest
+		return launchResponse(running[0], true), nil
+	}
+
+	deadline := time.Now().Add(launchTimeout)
+	for {
+		procs, err := Find(ctx, Query{BundleID: bundleID})
+		if err != nil {
+			return types.LaunchResponse{}, err
+		}
+		if len(procs) > 0 {
+			return launchResponse(procs[0], false), nil
+		}
+		if time.Now().After(deadline) {
+			return types.LaunchResponse{}, fmt.Errorf("%s did not start within %s", bundleID, launchTimeout)
+		}
+
+		select {
+		case <-ctx.Done():
+			return types.LaunchResponse{}, ctx.Err()
+		case <-time.After(250 * time.Millisecond):
+		}
+	}
+}
+
+// isAppBundle reports whether path names a macOS .app bundle
+func isAppBundle(path string) bool {
+	return strings.HasSuffix(strings.TrimSuffix(path, "/"), ".app")
+}
+
+func launchResponse(info types.ProcessInfo, alreadyRunning bool) types.LaunchResponse {
+	return types.LaunchResponse{
+		PID:            info.PID,
+		Name:           info.Name,
+		Path:           info.Path,
+		BundleID:       info.BundleID,
+		AlreadyRunning: alreadyRunning,
+	}
+}
@@ -0,0 +1,62 @@
+package process
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/borankux/gops/pkg/types"
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// resourceNames maps the rlimit resources to their names and units
+var resourceNames = map[int32][2]string{
+	process.RLIMIT_CPU:        {"cpu_time", types.LimitUnitSeconds},
+	process.RLIMIT_FSIZE:      {"file_size", types.LimitUnitBytes},
+	process.RLIMIT_DATA:       {"data", types.LimitUnitBytes},
+	process.RLIMIT_STACK:      {"stack", types.LimitUnitBytes},
+	process.RLIMIT_CORE:       {"core_file", types.LimitUnitBytes},
+	process.RLIMIT_RSS:        {"resident", types.LimitUnitBytes},
+	process.RLIMIT_NPROC:      {"processes", types.LimitUnitCount},
+	process.RLIMIT_NOFILE:     {"open_files", types.LimitUnitCount},
+	process.RLIMIT_MEMLOCK:    {"locked_memory", types.LimitUnitBytes},
+	process.RLIMIT_AS:         {"address_space", types.LimitUnitBytes},
+	process.RLIMIT_LOCKS:      {"file_locks", types.LimitUnitCount},
+	process.RLIMIT_SIGPENDING: {"pending_signals", types.LimitUnitCount},
+	process.RLIMIT_MSGQUEUE:   {"message_queue", types.LimitUnitBytes},
+	process.RLIMIT_NICE:       {"nice", ""},
+	process.RLIMIT_RTPRIO:     {"realtime_priority", ""},
+	process.RLIMIT_RTTIME:     {"realtime_timeout", types.LimitUnitMicroseconds},
+}
+
+// GetResourceLimits returns the soft and hard resource limits of a
+// process with current usage where it is known, including the number of
+// open files. macOS doesn't expose the limits of other processes, so
+// there the defaults launchd gives the processes it starts are reported.
+func GetResourceLimits(ctx context.Context, pid int32) (types.ResourceLimitsResponse, error) {
+	p, name, err := lookup(ctx, pid)
+	if err != nil {
+		return types.ResourceLimitsResponse{}, err
+	}
+
+	limits, source, err := resourceLimits(ctx, p)
+	if err != nil {
+		return types.ResourceLimitsResponse{}, fmt.Errorf("failed to read resource limits of PID %d: %w", pid, err)
+	}
+
+	return types.ResourceLimitsResponse{
+		PID:    pid,
+		Name:   name,
+		Source: source,
+		Limits: limits,
+		Count:  len(limits),
+	}, nil
+}
+
+// limitValue converts a raw limit to its API form, nil when unlimited
+func limitValue(v uint64) *uint64 {
+	if v == math.MaxUint64 {
+		return nil
+	}
+	return &v
+}
@@ -0,0 +1,76 @@
+//go:build darwin
+
+package process
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"math"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/borankux/gops/pkg/types"
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// launchctlLimits maps the resource names of launchctl limit to rlimit
+// resources
+var launchctlLimits = map[string]int32{
+	"cpu":      process.RLIMIT_CPU,
+	"filesize": process.RLIMIT_FSIZE,
+	"data":     process.RLIMIT_DATA,
+	"stack":    process.RLIMIT_STACK,
+	"core":     process.RLIMIT_CORE,
+	"rss":      process.RLIMIT_RSS,
+	"memlock":  process.RLIMIT_MEMLOCK,
+	"maxproc":  process.RLIMIT_NPROC,
+	"maxfiles": process.RLIMIT_NOFILE,
+}
+
+// resourceLimits reports the launchd defaults from launchctl limit, with
+// the process's open file count from lsof
+func resourceLimits(ctx context.Context, p *process.Process) ([]types.ResourceLimit, string, error) {
+	out, err := exec.CommandContext(ctx, "launchctl", "limit").Output()
+	if err != nil {
+		return nil, "", err
+	}
+
+	var limits []types.ResourceLimit
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 3 {
+			continue
+		}
+		resource, ok := launchctlLimits[fields[0]]
+		if !ok {
+			continue
+		}
+		res := resourceNames[resource]
+		limit := types.ResourceLimit{
+			Resource: res[0],
+			Unit:     res[1],
+			Soft:     limitValue(parseLaunchctlLimit(fields[1])),
+			Hard:     limitValue(parseLaunchctlLimit(fields[2])),
+		}
+		if resource == process.RLIMIT_NOFILE {
+			if files, err := GetOpenFiles(ctx, p.Pid); err == nil {
+				used := uint64(files.Count)
+				limit.Used = &used
+			}
+		}
+		limits = append(limits, limit)
+	}
+	return limits, types.LimitSourceLaunchd, scanner.Err()
+}
+
+func parseLaunchctlLimit(s string) uint64 {
+	n, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		// "unlimited"
+		return math.MaxUint64
+	}
+	return n
+}
@@ -0,0 +1,52 @@
+//go:build linux
+
+package process
+
+import (
+	"context"
+
+	"github.com/borankux/gops/pkg/types"
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// resourceLimits reads /proc/PID/limits with the usage gopsutil can
+// gather; usage is skipped if the process's status is unreadable
+func resourceLimits(ctx context.Context, p *process.Process) ([]types.ResourceLimit, string, error) {
+	stats, err := p.RlimitUsageWithContext(ctx, true)
+	used := err == nil
+	if err != nil {
+		if stats, err = p.RlimitWithContext(ctx); err != nil {
+			return nil, "", err
+		}
+	}
+
+	limits := make([]types.ResourceLimit, 0, len(stats))
+	for _, st := range stats {
+		res, ok := resourceNames[st.Resource]
+		if !ok {
+			continue
+		}
+		limit := types.ResourceLimit{
+			Resource: res[0],
+			Unit:     res[1],
+			Soft:     limitValue(st.Soft),
+			Hard:     limitValue(st.Hard),
+		}
+		if used && hasUsage(st.Resource) {
+			usage := st.Used
+			limit.Used = &usage
+		}
+		limits = append(limits, limit)
+	}
+	return limits, types.LimitSourceProcess, nil
+}
+
+// hasUsage reports whether gopsutil measures the usage of a resource
+func hasUsage(resource int32) bool {
+	switch resource {
+	case process.RLIMIT_CPU, process.RLIMIT_DATA, process.RLIMIT_STACK, process.RLIMIT_RSS,
+		process.RLIMIT_NOFILE, process.RLIMIT_MEMLOCK, process.RLIMIT_AS, process.RLIMIT_SIGPENDING:
+		return true
+	}
+	return false
+}
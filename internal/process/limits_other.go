@@ -0,0 +1,16 @@
+//go:build !darwin && !linux
+
+package process
+
+import (
+	"context"
+	"errors"
+	"runtime"
+
+	"github.com/borankux/gops/pkg/types"
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+func resourceLimits(ctx context.Context, p *process.Process) ([]types.ResourceLimit, string, error) {
+	return nil, "", errors.New("resource limits are not supported on " + runtime.GOOS)
+}
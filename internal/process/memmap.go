@@ -0,0 +1,89 @@
+package process
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/borankux/gops/pkg/types"
+)
+
+// Memory region types
+const (
+	RegionExecutable = "executable"
+	RegionLibrary    = "library"
+	RegionHeap       = "heap"
+	RegionStack      = "stack"
+	RegionAnonymous  = "anonymous"
+	RegionFile       = "file"
+	RegionSystem     = "system"
+	RegionOther      = "other"
+)
+
+// RegionTypes lists the memory region types
+var RegionTypes = []string{
+	RegionExecutable, RegionLibrary, RegionHeap, RegionStack,
+	RegionAnonymous, RegionFile, RegionSystem, RegionOther,
+}
+
+// GetMemoryMap returns the memory regions of a process with a per-type
+// summary, from vmmap on macOS and /proc/PID/smaps on Linux. If
+// regionType is set only regions of that type are listed; the summary
+// always covers every region.
+func GetMemoryMap(ctx context.Context, pid int32, regionType string) (types.MemoryMapResponse, error) {
+	p, name, err := lookup(ctx, pid)
+	if err != nil {
+		return types.MemoryMapResponse{}, err
+	}
+	exe, _ := p.ExeWithContext(ctx)
+
+	regions, err := memoryRegions(ctx, pid, exe)
+	if err != nil {
+		return types.MemoryMapResponse{}, fmt.Errorf("failed to read memory map of PID %d: %w", pid, err)
+	}
+
+	totals := make(map[string]*types.MemoryRegionSummary)
+	listed := []types.MemoryRegion{}
+	for _, r := range regions {
+		sum, ok := totals[r.Type]
+		if !ok {
+			sum = &types.MemoryRegionSummary{Type: r.Type}
+			totals[r.Type] = sum
+		}
+		sum.Regions++
+		sum.Size += r.Size
+		sum.Resident += r.Resident
+
+		if regionType == "" || r.Type == regionType {
+			listed = append(listed, r)
+		}
+	}
+
+	summary := make([]types.MemoryRegionSummary, 0, len(totals))
+	for _, sum := range totals {
+		summary = append(summary, *sum)
+	}
+	sort.Slice(summary, func(i, j int) bool {
+		if summary[i].Resident != summary[j].Resident {
+			return summary[i].Resident > summary[j].Resident
+		}
+		return summary[i].Size > summary[j].Size
+	})
+
+	return types.MemoryMapResponse{
+		PID:     pid,
+		Name:    name,
+		Regions: listed,
+		Summary: summary,
+		Count:   len(listed),
+	}, nil
+}
+
+// isLibrary reports whether path is a shared library
+func isLibrary(path string) bool {
+	base := path[strings.LastIndex(path, "/")+1:]
+	return strings.HasSuffix(base, ".dylib") || strings.HasSuffix(base, ".so") ||
+		strings.Contains(base, ".so.") || strings.Contains(path, ".framework/") ||
+		strings.Contains(path, "dyld_shared_cache")
+}
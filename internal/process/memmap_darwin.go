@@ -0,0 +1,107 @@
+//go:build darwin
+
+package process
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/borankux/gops/pkg/types"
+)
+
+// vmmapRegion matches a region line of vmmap -wide -interleaved:
+// TYPE  START-END  [ VSIZE RSDNT DIRTY SWAP] PRT/MAX SHRMOD [PURGE] DETAIL
+var vmmapRegion = regexp.MustCompile(`^(.+?)\s+([0-9a-f]+)-([0-9a-f]+)\s+\[\s*(\S+)\s+(\S+)\s+\S+\s+\S+\]\s+(\S+)/\S+\s+SM=\S+\s*(?:PURGE=\S+\s*)?(.*)$`)
+
+// memoryRegions parses the region list printed by vmmap
+func memoryRegions(ctx context.Context, pid int32, exe string) ([]types.MemoryRegion, error) {
+	out, err := exec.CommandContext(ctx, "vmmap", "-wide", "-interleaved", strconv.Itoa(int(pid))).Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return nil, errors.New(strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, err
+	}
+
+	var regions []types.MemoryRegion
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		m := vmmapRegion.FindStringSubmatch(scanner.Text())
+		if m == nil {
+			continue
+		}
+		lo, err1 := strconv.ParseUint(m[2], 16, 64)
+		hi, err2 := strconv.ParseUint(m[3], 16, 64)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+
+		label := strings.TrimSpace(m[1])
+		path, detail := "", label
+		if extra := strings.TrimSpace(m[7]); strings.HasPrefix(extra, "/") {
+			path = extra
+		} else if extra != "" {
+			detail = label + ": " + extra
+		}
+		regions = append(regions, types.MemoryRegion{
+			Start:       "0x" + m[2],
+			End:         "0x" + m[3],
+			Size:        hi - lo,
+			Resident:    parseVmmapSize(m[5]),
+			Permissions: m[6],
+			Type:        darwinRegionType(label, path, exe),
+			Detail:      detail,
+			Path:        path,
+		})
+	}
+	return regions, scanner.Err()
+}
+
+// darwinRegionType classifies a region by its vmmap label and path
+func darwinRegionType(label, path, exe string) string {
+	switch {
+	case strings.HasPrefix(label, "MALLOC"):
+		return RegionHeap
+	case strings.HasPrefix(label, "Stack"), strings.HasPrefix(label, "STACK"):
+		return RegionStack
+	case strings.HasPrefix(label, "__"):
+		// Mach-O segments of the executable or a loaded library
+		if path != "" && path == exe {
+			return RegionExecutable
+		}
+		return RegionLibrary
+	case label == "mapped file":
+		return RegionFile
+	case strings.HasPrefix(label, "VM_ALLOCATE"):
+		return RegionAnonymous
+	case strings.Contains(label, "dyld"), strings.HasPrefix(label, "Kernel"), strings.HasPrefix(label, "shared memory"):
+		return RegionSystem
+	default:
+		return RegionOther
+	}
+}
+
+// parseVmmapSize converts a vmmap size such as 16K or 1.5M to bytes
+func parseVmmapSize(s string) uint64 {
+	mult := 1.0
+	switch {
+	case strings.HasSuffix(s, "K"):
+		mult = 1 << 10
+	case strings.HasSuffix(s, "M"):
+		mult = 1 << 20
+	case strings.HasSuffix(s, "G"):
+		mult = 1 << 30
+	}
+	n, err := strconv.ParseFloat(strings.TrimRight(s, "KMG"), 64)
+	if err != nil {
+		return 0
+	}
+	return uint64(n * mult)
+}
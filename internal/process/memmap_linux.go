@@ -0,0 +1,89 @@
+//go:build linux
+
+package process
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/borankux/gops/pkg/types"
+)
+
+// memoryRegions parses /proc/PID/smaps, which lists each mapping followed
+// by its memory counters
+func memoryRegions(ctx context.Context, pid int32, exe string) ([]types.MemoryRegion, error) {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/smaps", pid))
+	if err != nil {
+		return nil, err
+	}
+
+	var regions []types.MemoryRegion
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		// Counter lines look like "Rss:  120 kB"
+		if strings.HasSuffix(fields[0], ":") {
+			if fields[0] == "Rss:" && len(fields) >= 2 && len(regions) > 0 {
+				kb, _ := strconv.ParseUint(fields[1], 10, 64)
+				regions[len(regions)-1].Resident = kb * 1024
+			}
+			continue
+		}
+
+		// Mapping lines: start-end perms offset dev inode [path]
+		start, end, found := strings.Cut(fields[0], "-")
+		if !found || len(fields) < 5 {
+			continue
+		}
+		lo, err1 := strconv.ParseUint(start, 16, 64)
+		hi, err2 := strconv.ParseUint(end, 16, 64)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		path := ""
+		if len(fields) > 5 {
+			path = strings.Join(fields[5:], " ")
+		}
+		regions = append(regions, types.MemoryRegion{
+			Start:       "0x" + start,
+			End:         "0x" + end,
+			Size:        hi - lo,
+			Permissions: strings.TrimSuffix(fields[1], "p"),
+			Type:        linuxRegionType(path, exe),
+			Path:        path,
+		})
+	}
+	return regions, scanner.Err()
+}
+
+// linuxRegionType classifies a mapping by its path
+func linuxRegionType(path, exe string) string {
+	switch {
+	case path == "", strings.HasPrefix(path, "[anon"):
+		return RegionAnonymous
+	case path == "[heap]":
+		return RegionHeap
+	case strings.HasPrefix(path, "[stack"):
+		return RegionStack
+	case strings.HasPrefix(path, "["):
+		// [vdso], [vvar] and [vsyscall] are mapped in by the kernel
+		return RegionSystem
+	case path == exe:
+		return RegionExecutable
+	case isLibrary(path):
+		return RegionLibrary
+	case strings.HasPrefix(path, "/"):
+		return RegionFile
+	default:
+		return RegionOther
+	}
+}
@@ -0,0 +1,15 @@
+//go:build !darwin && !linux
+
+package process
+
+import (
+	"context"
+	"errors"
+	"runtime"
+
+	"github.com/borankux/gops/pkg/types"
+)
+
+func memoryRegions(ctx context.Context, pid int32, exe string) ([]types.MemoryRegion, error) {
+	return nil, errors.New("memory maps are not supported on " + runtime.GOOS)
+}
@@ -0,0 +1,66 @@
+package process
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/borankux/gops/pkg/types"
+)
+
+// Nice value bounds accepted by SetNice
+const (
+	MinNice = -20
+	MaxNice = 19
+)
+
+// PriorityClasses lists the Windows priority classes accepted by
+// SetPriorityClass, from lowest to highest
+var PriorityClasses = []string{"idle", "below_normal", "normal", "above_normal", "high", "realtime"}
+
+// SetNice changes the nice value of a process on Unix systems. Lowering
+// it below the current value usually requires root.
+func SetNice(ctx context.Context, pid int32, nice int) (types.PriorityResponse, error) {
+	if nice < MinNice || nice > MaxNice {
+		return types.PriorityResponse{}, fmt.Errorf("nice value %d out of range (%d to %d)", nice, MinNice, MaxNice)
+	}
+	_, name, err := target(ctx, pid)
+	if err != nil {
+		return types.PriorityResponse{}, err
+	}
+
+	old, err := getNice(pid)
+	if err != nil {
+		return types.PriorityResponse{}, fmt.Errorf("failed to read priority of PID %d: %w", pid, err)
+	}
+	if err := setNice(pid, nice); err != nil {
+		return types.PriorityResponse{}, fmt.Errorf("failed to set priority of PID %d: %w", pid, err)
+	}
+
+	return types.PriorityResponse{
+		PID:         pid,
+		Name:        name,
+		OldPriority: strconv.Itoa(old),
+		NewPriority: strconv.Itoa(nice),
+	}, nil
+}
+
+// SetPriorityClass changes the priority class of a process on Windows
+func SetPriorityClass(ctx context.Context, pid int32, class string) (types.PriorityResponse, error) {
+	p, name, err := target(ctx, pid)
+	if err != nil {
+		return types.PriorityResponse{}, err
+	}
+
+	old, err := setPriorityClass(p.Pid, class)
+	if err != nil {
+		return types.PriorityResponse{}, fmt.Errorf("failed to set priority class of PID %d: %w", pid, err)
+	}
+
+	return types.PriorityResponse{
+		PID:         pid,
+		Name:        name,
+		OldPriority: old,
+		NewPriority: class,
+	}, nil
+}
@@ -0,0 +1,29 @@
+//go:build !windows
+
+package process
+
+import (
+	"errors"
+	"runtime"
+	"syscall"
+)
+
+func getNice(pid int32) (int, error) {
+	prio, err := syscall.Getpriority(syscall.PRIO_PROCESS, int(pid))
+	if err != nil {
+		return 0, err
+	}
+	if runtime.GOOS == "linux" {
+		// The raw Linux syscall returns 20 - nice to avoid negative results
+		prio = 20 - prio
+	}
+	return prio, nil
+}
+
+func setNice(pid int32, nice int) error {
+	return syscall.Setpriority(syscall.PRIO_PROCESS, int(pid), nice)
+}
+
+func setPriorityClass(pid int32, class string) (string, error) {
+	return "", errors.New("priority classes are only supported on windows; set a nice value instead")
+}
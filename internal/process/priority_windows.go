@@ -0,0 +1,57 @@
+//go:build windows
+
+package process
+
+import (
+	"errors"
+	"fmt"
+
+	"golang.org/x/sys/windows"
+)
+
+// priorityClassValues maps PriorityClasses to their Win32 constants
+var priorityClassValues = map[string]uint32{
+	"idle":         windows.IDLE_PRIORITY_CLASS,
+	"below_normal": windows.BELOW_NORMAL_PRIORITY_CLASS,
+	"normal":       windows.NORMAL_PRIORITY_CLASS,
+	"above_normal": windows.ABOVE_NORMAL_PRIORITY_CLASS,
+	"high":         windows.HIGH_PRIORITY_CLASS,
+	"realtime":     windows.REALTIME_PRIORITY_CLASS,
+}
+
+func getNice(pid int32) (int, error) {
+	return 0, errors.New("nice values are not supported on windows; set a priority class instead")
+}
+
+func setNice(pid int32, nice int) error {
+	return errors.New("nice values are not supported on windows; set a priority class instead")
+}
+
+func setPriorityClass(pid int32, class string) (string, error) {
+	value, ok := priorityClassValues[class]
+	if !ok {
+		return "", fmt.Errorf("unknown priority class: %s", class)
+	}
+
+	h, err := windows.OpenProcess(windows.PROCESS_SET_INFORMATION|windows.PROCESS_QUERY_LIMITED_INFORMATION, false, uint32(pid))
+	if err != nil {
+		return "", err
+	}
+	defer windows.CloseHandle(h)
+
+	current, err := windows.GetPriorityClass(h)
+	if err != nil {
+		return "", err
+	}
+	if err := windows.SetPriorityClass(h, value); err != nil {
+		return "", err
+	}
+
+	old := fmt.Sprintf("0x%x", current)
+	for name, v := range priorityClassValues {
+		if v == current {
+			old = name
+		}
+	}
+	return old, nil
+}
@@ -2,16 +2,45 @@ package process
 
 import (
 	"context"
+	"fmt"
 	"runtime"
 	"sort"
 	"strings"
+	"time"
 
+	"github.com/borankux/gops/internal/utils"
 	"github.com/borankux/gops/pkg/types"
 	"github.com/shirou/gopsutil/v3/process"
 )
 
+// Sort keys accepted by ListOptions.SortBy
+const (
+	SortPID    = "pid"
+	SortName   = "name"
+	SortUser   = "user"
+	SortCPU    = "cpu"
+	SortMemory = "memory"
+	SortUptime = "uptime"
+)
+
+// SortKeys lists the valid process sort keys
+var SortKeys = []string{SortPID, SortName, SortUser, SortCPU, SortMemory, SortUptime}
+
+// ListOptions controls how processes are collected
+type ListOptions struct {
+	// SortBy is one of SortKeys; the default is SortPID
+	SortBy     string
+	Descending bool
+	// Cmdline populates the argument vector of each process
+	Cmdline bool
+	// Rules adjust which processes GetUserApplications treats as system
+	Rules FilterRules
+	// Translated keeps only processes running under Rosetta 2
+	Translated bool
+}
+
 // GetUserApplications returns a list of non-system user applications
-func GetUserApplications(ctx context.Context) ([]types.ProcessInfo, error) {
+func GetUserApplications(ctx context.Context, opts ListOptions) ([]types.ProcessInfo, error) {
 	procs, err := process.ProcessesWithContext(ctx)
 	if err != nil {
 		return nil, err
@@ -19,67 +48,290 @@ func GetUserApplications(ctx context.Context) ([]types.ProcessInfo, error) {
 
 	var userProcs []types.ProcessInfo
 	systemPrefixes := getSystemPrefixes()
+	include, exclude := opts.Rules.Include.compile(), opts.Rules.Exclude.compile()
 
 	for _, p := range procs {
 		name, err := p.NameWithContext(ctx)
 		if err != nil {
 			continue
 		}
+		exe, exeErr := p.ExeWithContext(ctx)
+		username, _ := p.UsernameWithContext(ctx)
+
+		// Exclude rules win over everything, include rules over the
+		// built-in system checks
+		if exclude.matches(name, username, exe) {
+			continue
+		}
+		if !include.matches(name, username, exe) &&
+			isBuiltinSystem(name, username, exeErr, systemPrefixes) {
+			continue
+		}
 
-		// Skip system processes by name
-		if isSystemProcess(name, systemPrefixes) {
+		info := newProcessInfo(ctx, p, name, exe, username, opts)
+		if opts.Translated && !info.Translated {
 			continue
 		}
+		userProcs = append(userProcs, info)
+	}
+
+	if err := sortProcesses(userProcs, opts); err != nil {
+		return nil, err
+	}
 
-		// Skip kernel processes
-		exe, err := p.ExeWithContext(ctx)
+	return userProcs, nil
+}
+
+// isBuiltinSystem applies the built-in system process checks
+func isBuiltinSystem(name, username string, exeErr error, systemPrefixes []string) bool {
+	// System processes by name
+	if isSystemProcess(name, systemPrefixes) {
+		return true
+	}
+	// No executable path might indicate kernel process
+	if exeErr != nil {
+		return true
+	}
+	// Processes with no user, or owned by system users (varies by OS)
+	return username == "" || isSystemUser(username, runtime.GOOS)
+}
+
+// GetAllProcesses returns every running process, including system
+// daemons, kernel threads and processes owned by system users
+func GetAllProcesses(ctx context.Context, opts ListOptions) ([]types.ProcessInfo, error) {
+	procs, err := process.ProcessesWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var all []types.ProcessInfo
+	for _, p := range procs {
+		name, err := p.NameWithContext(ctx)
 		if err != nil {
-			// No executable path might indicate kernel process
 			continue
 		}
+		// Kernel threads and protected processes have no readable path
+		// or owner; list them anyway
+		exe, _ := p.ExeWithContext(ctx)
+		username, _ := p.UsernameWithContext(ctx)
 
-		// Get username to filter system users
-		username := ""
-		if u, err := p.UsernameWithContext(ctx); err == nil {
-			username = u
-			// Skip processes owned by system users (varies by OS)
-			if isSystemUser(username, runtime.GOOS) {
-				continue
-			}
+		info := newProcessInfo(ctx, p, name, exe, username, opts)
+		if opts.Translated && !info.Translated {
+			continue
 		}
+		all = append(all, info)
+	}
+
+	if err := sortProcesses(all, opts); err != nil {
+		return nil, err
+	}
+
+	return all, nil
+}
+
+// newProcessInfo builds the listing entry for p
+func newProcessInfo(ctx context.Context, p *process.Process, name, exe, username string, opts ListOptions) types.ProcessInfo {
+	status := ""
+	if st, err := p.StatusWithContext(ctx); err == nil {
+		status = strings.Join(st, ",")
+	}
+
+	startTime, uptime := "", ""
+	if st, err := p.CreateTimeWithContext(ctx); err == nil && st > 0 {
+		startTime = formatTime(st)
+		uptime = utils.FormatDuration(uint64(time.Since(time.UnixMilli(st)).Seconds()))
+	}
+
+	ppid, _ := p.PpidWithContext(ctx)
 
-		// Skip processes with no user (typically system processes)
-		if username == "" {
+	info := types.ProcessInfo{
+		PID:       p.Pid,
+		PPID:      ppid,
+		Name:      name,
+		Path:      exe,
+		Status:    status,
+		User:      username,
+		StartTime: startTime,
+		Uptime:    uptime,
+	}
+	info.Arch, info.Translated = processArch(p.Pid)
+	if app := bundleApp(ctx, exe); app.BundleID != "" {
+		info.BundleID = app.BundleID
+		info.AppName = app.Name
+		info.AppVersion = app.Version
+	}
+
+	if opts.Cmdline {
+		info.Cmdline, _ = p.CmdlineSliceWithContext(ctx)
+	}
+
+	// Usage metrics are only collected when they are needed for sorting
+	switch opts.SortBy {
+	case SortCPU:
+		info.CPUPercent, _ = p.CPUPercentWithContext(ctx)
+	case SortMemory:
+		if mem, err := p.MemoryInfoWithContext(ctx); err == nil && mem != nil {
+			info.MemoryRSS = mem.RSS
+		}
+	}
+	return info
+}
+
+// sortProcesses orders procs by the key in opts
+func sortProcesses(procs []types.ProcessInfo, opts ListOptions) error {
+	var less func(a, b types.ProcessInfo) bool
+	switch opts.SortBy {
+	case "", SortPID:
+		less = func(a, b types.ProcessInfo) bool { return a.PID < b.PID }
+	case SortName:
+		less = func(a, b types.ProcessInfo) bool { return strings.ToLower(a.Name) < strings.ToLower(b.Name) }
+	case SortUser:
+		less = func(a, b types.ProcessInfo) bool { return a.User < b.User }
+	case SortCPU:
+		less = func(a, b types.ProcessInfo) bool { return a.CPUPercent < b.CPUPercent }
+	case SortMemory:
+		less = func(a, b types.ProcessInfo) bool { return a.MemoryRSS < b.MemoryRSS }
+	case SortUptime:
+		// A later start means a shorter uptime
+		less = func(a, b types.ProcessInfo) bool { return startedAt(a).After(startedAt(b)) }
+	default:
+		return fmt.Errorf("invalid sort key: %s", opts.SortBy)
+	}
+
+	sort.SliceStable(procs, func(i, j int) bool {
+		if opts.Descending {
+			return less(procs[j], procs[i])
+		}
+		return less(procs[i], procs[j])
+	})
+	return nil
+}
+
+// GetProcessTree returns every process arranged by parent. Processes
+// whose parent is gone or unreadable are roots. If root is non-zero only
+// the subtree under that PID is returned.
+func GetProcessTree(ctx context.Context, root int32) ([]types.ProcessNode, error) {
+	procs, err := process.ProcessesWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make(map[int32]types.ProcessNode, len(procs))
+	for _, p := range procs {
+		name, err := p.NameWithContext(ctx)
+		if err != nil {
 			continue
 		}
+		ppid, _ := p.PpidWithContext(ctx)
+		username, _ := p.UsernameWithContext(ctx)
+		exe, _ := p.ExeWithContext(ctx)
+		nodes[p.Pid] = types.ProcessNode{
+			PID:  p.Pid,
+			PPID: ppid,
+			Name: name,
+			User: username,
+			Path: exe,
+		}
+	}
 
-		pid := p.Pid
-		status := ""
-		if st, err := p.StatusWithContext(ctx); err == nil {
-			status = strings.Join(st, ",")
+	if root != 0 {
+		if _, exists := nodes[root]; !exists {
+			return nil, fmt.Errorf("process %d not found", root)
 		}
+	}
+	return buildTree(nodes, root), nil
+}
 
-		startTime := ""
-		if st, err := p.CreateTimeWithContext(ctx); err == nil {
-			startTime = formatTime(st)
+// buildTree links nodes into trees under root, or under every process
+// without a listed parent when root is 0. Parent PIDs read while
+// processes exit and PIDs are reused can form cycles, so each process is
+// placed once, and a cycle is rooted at its lowest PID.
+func buildTree(nodes map[int32]types.ProcessNode, root int32) []types.ProcessNode {
+	children := make(map[int32][]int32)
+	var roots []int32
+	for pid, node := range nodes {
+		if _, hasParent := nodes[node.PPID]; hasParent && node.PPID != pid {
+			children[node.PPID] = append(children[node.PPID], pid)
+		} else {
+			roots = append(roots, pid)
 		}
+	}
 
-		userProcs = append(userProcs, types.ProcessInfo{
-			PID:       pid,
-			Name:      name,
-			Path:      exe,
-			Status:    status,
-			User:      username,
-			StartTime: startTime,
-		})
+	visited := make(map[int32]bool, len(nodes))
+	var build func(pid int32) types.ProcessNode
+	build = func(pid int32) types.ProcessNode {
+		visited[pid] = true
+		node := nodes[pid]
+		kids := children[pid]
+		sort.Slice(kids, func(i, j int) bool { return kids[i] < kids[j] })
+		for _, child := range kids {
+			if !visited[child] {
+				node.Children = append(node.Children, build(child))
+			}
+		}
+		return node
 	}
 
-	// Sort by PID
-	sort.Slice(userProcs, func(i, j int) bool {
-		return userProcs[i].PID < userProcs[j].PID
-	})
+	if root != 0 {
+		return []types.ProcessNode{build(root)}
+	}
 
-	return userProcs, nil
+	sort.Slice(roots, func(i, j int) bool { return roots[i] < roots[j] })
+	tree := make([]types.ProcessNode, 0, len(roots))
+	for _, pid := range roots {
+		tree = append(tree, build(pid))
+	}
+	// Processes left over are only reachable from a cycle
+	pids := make([]int32, 0, len(nodes))
+	for pid := range nodes {
+		pids = append(pids, pid)
+	}
+	sort.Slice(pids, func(i, j int) bool { return pids[i] < pids[j] })
+	for _, pid := range pids {
+		if !visited[pid] {
+			tree = append(tree, build(pid))
+		}
+	}
+	return tree
+}
+
+// CountNodes returns the number of processes in a tree
+func CountNodes(tree []types.ProcessNode) int {
+	n := len(tree)
+	for _, node := range tree {
+		n += CountNodes(node.Children)
+	}
+	return n
+}
+
+// GetProcessInfo returns the listing entry of a single process
+func GetProcessInfo(ctx context.Context, pid int32) (types.ProcessInfo, error) {
+	p, name, err := lookup(ctx, pid)
+	if err != nil {
+		return types.ProcessInfo{}, err
+	}
+	exe, _ := p.ExeWithContext(ctx)
+	username, _ := p.UsernameWithContext(ctx)
+	return newProcessInfo(ctx, p, name, exe, username, ListOptions{}), nil
+}
+
+// GetProcessNames returns the name of every running process keyed by PID
+func GetProcessNames(ctx context.Context) (map[int32]string, error) {
+	procs, err := process.ProcessesWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[int32]string, len(procs))
+	for _, p := range procs {
+		name, err := p.NameWithContext(ctx)
+		if err != nil {
+			continue
+		}
+		names[p.Pid] = name
+	}
+
+	return names, nil
 }
 
 // getSystemPrefixes returns OS-specific system process prefixes
@@ -133,7 +385,27 @@ func getSystemUsers(os string) []string {
 	}
 }
 
+// formatTime formats a creation time in milliseconds since the epoch as
+// RFC 3339
 func formatTime(timestamp int64) string {
-	return ""
-	// Can be expanded to format timestamp to readable date
+	return time.UnixMilli(timestamp).Format(time.RFC3339)
+}
+
+// startedAt parses the start time of a listed process, returning the zero
+// time if it is unknown
+func startedAt(info types.ProcessInfo) time.Time {
+	t, _ := time.Parse(time.RFC3339, info.StartTime)
+	return t
+}
+
+// Ready verifies that processes can be enumerated on this host
+func Ready(ctx context.Context) error {
+	pids, err := process.PidsWithContext(ctx)
+	if err != nil {
+		return err
+	}
+	if len(pids) == 0 {
+		return fmt.Errorf("no processes visible")
+	}
+	return nil
 }
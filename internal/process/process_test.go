@@ -0,0 +1,46 @@
+package process
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/borankux/gops/pkg/types"
+)
+
+// treePIDs flattens a tree into PIDs, depth first
+func treePIDs(tree []types.ProcessNode) []int32 {
+	var result []int32
+	for _, node := range tree {
+		result = append(result, node.PID)
+		result = append(result, treePIDs(node.Children)...)
+	}
+	return result
+}
+
+func TestBuildTree(t *testing.T) {
+	nodes := map[int32]types.ProcessNode{
+		1:  {PID: 1, PPID: 0},
+		10: {PID: 10, PPID: 1},
+		11: {PID: 11, PPID: 1},
+		12: {PID: 12, PPID: 10},
+		// A parent-child cycle left by PID reuse, with a child of its own
+		20: {PID: 20, PPID: 21},
+		21: {PID: 21, PPID: 20},
+		22: {PID: 22, PPID: 21},
+		// A process that is its own parent
+		30: {PID: 30, PPID: 30},
+	}
+
+	tree := buildTree(nodes, 0)
+	if got, want := CountNodes(tree), len(nodes); got != want {
+		t.Fatalf("tree has %d processes, want %d: %v", got, want, treePIDs(tree))
+	}
+	if got, want := treePIDs(tree), []int32{1, 10, 12, 11, 30, 20, 21, 22}; !slices.Equal(got, want) {
+		t.Fatalf("tree = %v, want %v", got, want)
+	}
+
+	sub := buildTree(nodes, 21)
+	if got, want := treePIDs(sub), []int32{21, 20, 22}; !slices.Equal(got, want) {
+		t.Errorf("subtree of 21 = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,78 @@
+package process
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Rules select processes by name, owning user or executable path. Patterns
+// are case-insensitive globs where * matches any run of characters,
+// including path separators, and ? matches one character.
+type Rules struct {
+	Names []string
+	Users []string
+	Paths []string
+}
+
+// FilterRules tune which processes count as user applications on top of
+// the built-in system prefixes and users. Exclude wins over Include, and
+// Include wins over the built-in rules.
+type FilterRules struct {
+	Include Rules
+	Exclude Rules
+}
+
+// IsZero reports whether r has no patterns
+func (r Rules) IsZero() bool {
+	return len(r.Names) == 0 && len(r.Users) == 0 && len(r.Paths) == 0
+}
+
+// ruleMatcher holds the patterns of Rules compiled for one listing.
+// Patterns may come from each request, so they aren't kept any longer.
+type ruleMatcher struct {
+	names, users, paths []*regexp.Regexp
+}
+
+// compile compiles the patterns of r
+func (r Rules) compile() ruleMatcher {
+	return ruleMatcher{
+		names: globPatterns(r.Names),
+		users: globPatterns(r.Users),
+		paths: globPatterns(r.Paths),
+	}
+}
+
+// matches reports whether any pattern matches the process
+func (m ruleMatcher) matches(name, user, exe string) bool {
+	return matchAny(m.names, name) || matchAny(m.users, user) || matchAny(m.paths, exe)
+}
+
+// matchAny reports whether value matches one of the compiled patterns
+func matchAny(patterns []*regexp.Regexp, value string) bool {
+	if value == "" {
+		return false
+	}
+	for _, re := range patterns {
+		if re.MatchString(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// globPatterns compiles each glob with globPattern
+func globPatterns(patterns []string) []*regexp.Regexp {
+	compiled := make([]*regexp.Regexp, len(patterns))
+	for i, pattern := range patterns {
+		compiled[i] = globPattern(pattern)
+	}
+	return compiled
+}
+
+// globPattern compiles a glob into an anchored, case-insensitive regexp
+func globPattern(pattern string) *regexp.Regexp {
+	expr := regexp.QuoteMeta(pattern)
+	expr = strings.ReplaceAll(expr, `\*`, ".*")
+	expr = strings.ReplaceAll(expr, `\?`, ".")
+	return regexp.MustCompile("(?i)^" + expr + "$")
+}
@@ -0,0 +1,62 @@
+//go:build darwin
+
+package process
+
+import (
+	"context"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/borankux/gops/internal/utils"
+	"github.com/borankux/gops/pkg/types"
+)
+
+// codesignRuntime is the CodeDirectory flag set by the hardened runtime
+const codesignRuntime = 0x10000
+
+// codesignFlags matches the flags field of codesign -dv output, e.g.
+// "flags=0x10000(runtime)"
+var codesignFlags = regexp.MustCompile(`flags=0x([0-9a-fA-F]+)`)
+
+// codeSecurity reads the code signature and entitlements of exe with
+// codesign. It returns nil if exe is empty or codesign can't be run.
+func codeSecurity(ctx context.Context, exe string) *types.ProcessSecurity {
+	if exe == "" {
+		return nil
+	}
+
+	// codesign writes the signature details to stderr and fails for
+	// unsigned code
+	out, err := exec.CommandContext(ctx, "codesign", "-dv", exe).CombinedOutput()
+	if _, exited := err.(*exec.ExitError); err != nil && !exited {
+		return nil
+	}
+	security := &types.ProcessSecurity{}
+	if err != nil {
+		return security
+	}
+	security.Signed = true
+	for _, line := range strings.Split(string(out), "\n") {
+		if team, found := strings.CutPrefix(line, "TeamIdentifier="); found && team != "not set" {
+			security.TeamID = team
+		}
+		if m := codesignFlags.FindStringSubmatch(line); m != nil {
+			flags, _ := strconv.ParseUint(m[1], 16, 32)
+			security.HardenedRuntime = flags&codesignRuntime != 0
+		}
+	}
+
+	out, err = exec.CommandContext(ctx, "codesign", "-d", "--entitlements", "-", "--xml", exe).Output()
+	if err != nil || len(strings.TrimSpace(string(out))) == 0 {
+		return security
+	}
+	if root, err := utils.DecodePlist(out); err == nil {
+		if entitlements, ok := root.(map[string]interface{}); ok && len(entitlements) > 0 {
+			security.Entitlements = entitlements
+			security.Sandboxed, _ = entitlements["com.apple.security.app-sandbox"].(bool)
+		}
+	}
+	return security
+}
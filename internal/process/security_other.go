@@ -0,0 +1,15 @@
+//go:build !darwin
+
+package process
+
+import (
+	"context"
+
+	"github.com/borankux/gops/pkg/types"
+)
+
+// codeSecurity is only available on macOS, where codesign can read the
+// signature and entitlements
+func codeSecurity(ctx context.Context, exe string) *types.ProcessSecurity {
+	return nil
+}
@@ -0,0 +1,79 @@
+package process
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/borankux/gops/pkg/types"
+)
+
+// ParseSignal converts a signal name such as TERM, SIGTERM or a signal
+// number into a signal
+func ParseSignal(name string) (syscall.Signal, error) {
+	name = strings.ToUpper(strings.TrimSpace(name))
+	if n, err := strconv.Atoi(name); err == nil {
+		for _, sig := range signals {
+			if int(sig) == n {
+				return sig, nil
+			}
+		}
+		return 0, fmt.Errorf("unsupported signal: %s", name)
+	}
+	if sig, ok := signals[strings.TrimPrefix(name, "SIG")]; ok {
+		return sig, nil
+	}
+	return 0, fmt.Errorf("unsupported signal: %s", name)
+}
+
+// SignalName returns the canonical name of sig, e.g. SIGTERM
+func SignalName(sig syscall.Signal) string {
+	for name, s := range signals {
+		if s == sig {
+			return "SIG" + name
+		}
+	}
+	return strconv.Itoa(int(sig))
+}
+
+// SignalNames lists the supported signal names without the SIG prefix
+func SignalNames() []string {
+	names := make([]string, 0, len(signals))
+	for name := range signals {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Signal sends sig to the process with the given PID. Protected processes
+// are refused with ErrProtected.
+func Signal(ctx context.Context, pid int32, sig syscall.Signal) (types.SignalResponse, error) {
+	p, name, err := target(ctx, pid)
+	if err != nil {
+		return types.SignalResponse{}, err
+	}
+
+	if runtime.GOOS == "windows" {
+		// Windows has no signals; termination is the only supported action
+		if sig != syscall.SIGTERM && sig != syscall.SIGKILL {
+			return types.SignalResponse{}, fmt.Errorf("%s is not supported on windows", SignalName(sig))
+		}
+		err = p.KillWithContext(ctx)
+	} else {
+		err = p.SendSignalWithContext(ctx, sig)
+	}
+	if err != nil {
+		return types.SignalResponse{}, fmt.Errorf("failed to send %s to PID %d: %w", SignalName(sig), pid, err)
+	}
+
+	return types.SignalResponse{
+		PID:    pid,
+		Name:   name,
+		Signal: SignalName(sig),
+	}, nil
+}
@@ -0,0 +1,12 @@
+//go:build windows
+
+package process
+
+import "syscall"
+
+// signals maps the supported signal names, without the SIG prefix. Both
+// terminate the process, as Windows has no signal delivery.
+var signals = map[string]syscall.Signal{
+	"KILL": syscall.SIGKILL,
+	"TERM": syscall.SIGTERM,
+}
@@ -0,0 +1,181 @@
+package process
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/borankux/gops/pkg/types"
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// ErrSnapshotNotFound is returned for an unknown or expired snapshot ID
+var ErrSnapshotNotFound = errors.New("snapshot not found")
+
+// DefaultSnapshotLimit is how many snapshots a SnapshotStore keeps
+const DefaultSnapshotLimit = 16
+
+// Snapshot is the process table captured at one point in time
+type Snapshot struct {
+	ID    string
+	Time  time.Time
+	procs map[int32]snapshotEntry
+}
+
+// snapshotEntry is a process as seen by a snapshot, with its cumulative
+// CPU time in seconds
+type snapshotEntry struct {
+	info    types.ProcessInfo
+	cpuTime float64
+}
+
+// Len returns the number of processes in the snapshot
+func (s *Snapshot) Len() int {
+	return len(s.procs)
+}
+
+// TakeSnapshot captures every running process with its memory and
+// CPU time
+func TakeSnapshot(ctx context.Context) (*Snapshot, error) {
+	procs, err := process.ProcessesWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, 6)
+	rand.Read(buf)
+	snap := &Snapshot{
+		ID:    hex.EncodeToString(buf),
+		Time:  time.Now(),
+		procs: make(map[int32]snapshotEntry, len(procs)),
+	}
+	for _, p := range procs {
+		name, err := p.NameWithContext(ctx)
+		if err != nil {
+			continue
+		}
+		exe, _ := p.ExeWithContext(ctx)
+		username, _ := p.UsernameWithContext(ctx)
+
+		entry := snapshotEntry{info: newProcessInfo(ctx, p, name, exe, username, ListOptions{})}
+		if mem, err := p.MemoryInfoWithContext(ctx); err == nil && mem != nil {
+			entry.info.MemoryRSS = mem.RSS
+		}
+		if times, err := p.TimesWithContext(ctx); err == nil {
+			entry.cpuTime = times.User + times.System
+		}
+		snap.procs[p.Pid] = entry
+	}
+	return snap, nil
+}
+
+// Diff compares two snapshots. A PID whose start time changed in between
+// was reused, so it counts as both stopped and started. Changed lists
+// processes present in both whose CPU time or memory changed, busiest
+// first.
+func Diff(from, to *Snapshot) types.ProcessDiffResponse {
+	elapsed := to.Time.Sub(from.Time)
+	diff := types.ProcessDiffResponse{
+		Since:     from.ID,
+		SinceTime: from.Time.Format(time.RFC3339),
+		Snapshot:  to.ID,
+		Time:      to.Time.Format(time.RFC3339),
+		Elapsed:   elapsed.Round(time.Millisecond).String(),
+		Started:   []types.ProcessInfo{},
+		Stopped:   []types.ProcessInfo{},
+		Changed:   []types.ProcessChange{},
+	}
+
+	for pid, now := range to.procs {
+		before, existed := from.procs[pid]
+		if !existed || before.info.StartTime != now.info.StartTime {
+			diff.Started = append(diff.Started, now.info)
+			continue
+		}
+
+		cpuSeconds := now.cpuTime - before.cpuTime
+		memDelta := int64(now.info.MemoryRSS) - int64(before.info.MemoryRSS)
+		if cpuSeconds <= 0 && memDelta == 0 {
+			continue
+		}
+		change := types.ProcessChange{
+			PID:         pid,
+			Name:        now.info.Name,
+			CPUSeconds:  cpuSeconds,
+			MemoryRSS:   now.info.MemoryRSS,
+			MemoryDelta: memDelta,
+		}
+		if elapsed > 0 {
+			change.CPUPercent = cpuSeconds / elapsed.Seconds() * 100
+		}
+		diff.Changed = append(diff.Changed, change)
+	}
+	for pid, before := range from.procs {
+		if now, exists := to.procs[pid]; !exists || now.info.StartTime != before.info.StartTime {
+			diff.Stopped = append(diff.Stopped, before.info)
+		}
+	}
+
+	sort.Slice(diff.Started, func(i, j int) bool { return diff.Started[i].PID < diff.Started[j].PID })
+	sort.Slice(diff.Stopped, func(i, j int) bool { return diff.Stopped[i].PID < diff.Stopped[j].PID })
+	sort.Slice(diff.Changed, func(i, j int) bool { return diff.Changed[i].CPUSeconds > diff.Changed[j].CPUSeconds })
+	return diff
+}
+
+// SnapshotStore keeps the most recent snapshots so later requests can
+// diff against them
+type SnapshotStore struct {
+	mu    sync.Mutex
+	limit int
+	snaps []*Snapshot
+}
+
+// NewSnapshotStore creates a store keeping up to limit snapshots, or
+// DefaultSnapshotLimit if limit is not positive
+func NewSnapshotStore(limit int) *SnapshotStore {
+	if limit <= 0 {
+		limit = DefaultSnapshotLimit
+	}
+	return &SnapshotStore{limit: limit}
+}
+
+// Take captures a snapshot and stores it, evicting the oldest one when
+// the store is full
+func (s *SnapshotStore) Take(ctx context.Context) (*Snapshot, error) {
+	snap, err := TakeSnapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.snaps = append(s.snaps, snap)
+	if len(s.snaps) > s.limit {
+		s.snaps = s.snaps[len(s.snaps)-s.limit:]
+	}
+	return snap, nil
+}
+
+// Get returns the snapshot with the given ID, or the most recent one if
+// id is empty
+func (s *SnapshotStore) Get(id string) (*Snapshot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if id == "" && len(s.snaps) > 0 {
+		return s.snaps[len(s.snaps)-1], nil
+	}
+	for _, snap := range s.snaps {
+		if snap.ID == id {
+			return snap, nil
+		}
+	}
+	if id == "" {
+		return nil, fmt.Errorf("%w: no snapshot has been taken", ErrSnapshotNotFound)
+	}
+	return nil, fmt.Errorf("%w: %s", ErrSnapshotNotFound, id)
+}
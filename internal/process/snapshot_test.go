@@ -0,0 +1,92 @@
+package process
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/borankux/gops/pkg/types"
+)
+
+// testSnapshot builds a snapshot from entries keyed by PID
+func testSnapshot(id string, at time.Time, entries map[int32]snapshotEntry) *Snapshot {
+	for pid, entry := range entries {
+		entry.info.PID = pid
+		entries[pid] = entry
+	}
+	return &Snapshot{ID: id, Time: at, procs: entries}
+}
+
+func testEntry(name, started string, rss uint64, cpu float64) snapshotEntry {
+	return snapshotEntry{info: types.ProcessInfo{Name: name, StartTime: started, MemoryRSS: rss}, cpuTime: cpu}
+}
+
+func pids(procs []types.ProcessInfo) []int32 {
+	var result []int32
+	for _, p := range procs {
+		result = append(result, p.PID)
+	}
+	return result
+}
+
+func TestDiff(t *testing.T) {
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	from := testSnapshot("a", start, map[int32]snapshotEntry{
+		1:   testEntry("init", "2024-05-01T08:00:00Z", 1000, 5),
+		100: testEntry("worker", "2024-05-01T11:00:00Z", 2000, 10),
+		200: testEntry("old", "2024-05-01T11:30:00Z", 3000, 1),
+		300: testEntry("gone", "2024-05-01T11:40:00Z", 4000, 1),
+	})
+	to := testSnapshot("b", start.Add(10*time.Second), map[int32]snapshotEntry{
+		1:   testEntry("init", "2024-05-01T08:00:00Z", 1000, 5),
+		100: testEntry("worker", "2024-05-01T11:00:00Z", 2500, 15),
+		// PID 200 was reused by a process started after the first snapshot
+		200: testEntry("new", "2024-05-01T12:00:05Z", 500, 0.1),
+		400: testEntry("fresh", "2024-05-01T12:00:02Z", 600, 0.2),
+	})
+
+	diff := Diff(from, to)
+	if got, want := pids(diff.Started), []int32{200, 400}; !slices.Equal(got, want) {
+		t.Errorf("started = %v, want %v", got, want)
+	}
+	if got, want := pids(diff.Stopped), []int32{200, 300}; !slices.Equal(got, want) {
+		t.Errorf("stopped = %v, want %v", got, want)
+	}
+	if len(diff.Stopped) == 2 && diff.Stopped[0].Name != "old" {
+		t.Errorf("stopped PID 200 is %q, want the process that exited", diff.Stopped[0].Name)
+	}
+
+	if len(diff.Changed) != 1 {
+		t.Fatalf("changed = %+v, want only PID 100", diff.Changed)
+	}
+	change := diff.Changed[0]
+	if change.PID != 100 || change.CPUSeconds != 5 || change.MemoryDelta != 500 || change.CPUPercent != 50 {
+		t.Errorf("change = %+v, want PID 100 with 5 CPU seconds (50%%) and 500 more bytes", change)
+	}
+	if diff.Elapsed != "10s" || diff.Since != "a" || diff.Snapshot != "b" {
+		t.Errorf("diff header = %s since %s to %s", diff.Elapsed, diff.Since, diff.Snapshot)
+	}
+}
+
+func TestDiffChangedOrder(t *testing.T) {
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	from := testSnapshot("a", start, map[int32]snapshotEntry{
+		1: testEntry("idle", "s", 100, 1),
+		2: testEntry("busy", "s", 100, 1),
+		3: testEntry("growing", "s", 100, 1),
+	})
+	to := testSnapshot("b", start.Add(time.Second), map[int32]snapshotEntry{
+		1: testEntry("idle", "s", 100, 1.5),
+		2: testEntry("busy", "s", 100, 4),
+		3: testEntry("growing", "s", 900, 1),
+	})
+
+	diff := Diff(from, to)
+	var got []int32
+	for _, c := range diff.Changed {
+		got = append(got, c.PID)
+	}
+	if want := []int32{2, 1, 3}; !slices.Equal(got, want) {
+		t.Fatalf("changed order = %v, want busiest first %v", got, want)
+	}
+}
@@ -0,0 +1,104 @@
+package process
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/borankux/gops/pkg/types"
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// StatusZombie is the status of a terminated process that has not been
+// reaped by its parent
+const StatusZombie = process.Zombie
+
+// Statuses lists the process states accepted as status filters
+var Statuses = []string{
+	process.Running, process.Sleep, process.Idle, process.Stop,
+	process.Zombie, process.Wait, process.Lock, process.Blocked,
+}
+
+// FilterStatus returns the processes in procs whose status includes
+// status, keeping their order. status must be one of Statuses.
+func FilterStatus(procs []types.ProcessInfo, status string) ([]types.ProcessInfo, error) {
+	if !slices.Contains(Statuses, status) {
+		return nil, fmt.Errorf("invalid status: %s", status)
+	}
+	matched := []types.ProcessInfo{}
+	for _, p := range procs {
+		for _, st := range strings.Split(p.Status, ",") {
+			if st == status {
+				matched = append(matched, p)
+				break
+			}
+		}
+	}
+	return matched, nil
+}
+
+// GetStrayProcesses returns zombie processes, with the parent that has
+// not reaped them, and orphans: processes whose parent no longer exists
+// or, on Linux, user processes re-parented to init
+func GetStrayProcesses(ctx context.Context) (zombies, orphans []types.StrayProcess, err error) {
+	procs, err := process.ProcessesWithContext(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	names := make(map[int32]string, len(procs))
+	for _, p := range procs {
+		names[p.Pid], _ = p.NameWithContext(ctx)
+	}
+
+	zombies, orphans = []types.StrayProcess{}, []types.StrayProcess{}
+	for _, p := range procs {
+		ppid, err := p.PpidWithContext(ctx)
+		if err != nil {
+			continue
+		}
+		st, _ := p.StatusWithContext(ctx)
+		status := strings.Join(st, ",")
+		_, parentExists := names[ppid]
+
+		isZombie := false
+		for _, s := range st {
+			if s == StatusZombie {
+				isZombie = true
+			}
+		}
+		username, _ := p.UsernameWithContext(ctx)
+		// PID 0 is the kernel's own parent, not a missing process
+		isOrphan := ppid != 0 && !parentExists
+		if ppid == 1 && runtime.GOOS == "linux" && username != "" && !isSystemUser(username, runtime.GOOS) {
+			// User processes adopted by init outlived the parent that
+			// started them. On macOS launchd is every app's parent, so
+			// adoption says nothing there.
+			isOrphan = true
+		}
+		if !isZombie && !isOrphan {
+			continue
+		}
+
+		stray := types.StrayProcess{
+			PID:        p.Pid,
+			Name:       names[p.Pid],
+			User:       username,
+			Status:     status,
+			PPID:       ppid,
+			ParentName: names[ppid],
+		}
+		if isZombie {
+			zombies = append(zombies, stray)
+		} else {
+			orphans = append(orphans, stray)
+		}
+	}
+
+	sort.Slice(zombies, func(i, j int) bool { return zombies[i].PID < zombies[j].PID })
+	sort.Slice(orphans, func(i, j int) bool { return orphans[i].PID < orphans[j].PID })
+	return zombies, orphans, nil
+}
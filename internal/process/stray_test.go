@@ -0,0 +1,29 @@
+package process
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/borankux/gops/pkg/types"
+)
+
+func TestFilterStatus(t *testing.T) {
+	procs := []types.ProcessInfo{
+		{PID: 1, Status: "sleep"},
+		{PID: 2, Status: "zombie"},
+		{PID: 3, Status: "running,lock"},
+	}
+	got, err := FilterStatus(procs, StatusZombie)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int32{2}; !slices.Equal(pids(got), want) {
+		t.Errorf("zombies = %v, want %v", pids(got), want)
+	}
+	if got, _ := FilterStatus(procs, "lock"); !slices.Equal(pids(got), []int32{3}) {
+		t.Errorf("locked = %v, want [3]", pids(got))
+	}
+	if _, err := FilterStatus(procs, "zombi"); err == nil {
+		t.Error("unknown status accepted")
+	}
+}
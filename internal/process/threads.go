@@ -0,0 +1,62 @@
+package process
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/borankux/gops/pkg/types"
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// threadStates maps the one-letter thread states of ps and /proc to the
+// names used for process statuses
+var threadStates = map[string]string{
+	"R": process.Running,
+	"S": process.Sleep,
+	"I": process.Idle,
+	"T": process.Stop,
+	"t": process.Stop,
+	"Z": process.Zombie,
+	"D": process.Wait,
+	"U": process.Wait,
+}
+
+// GetThreads returns the threads of a process, busiest first, so a
+// spinning thread stands out
+func GetThreads(ctx context.Context, pid int32) (types.ThreadsResponse, error) {
+	_, name, err := lookup(ctx, pid)
+	if err != nil {
+		return types.ThreadsResponse{}, err
+	}
+
+	threads, err := listThreads(ctx, pid)
+	if err != nil {
+		return types.ThreadsResponse{}, fmt.Errorf("failed to list threads of PID %d: %w", pid, err)
+	}
+	sort.SliceStable(threads, func(i, j int) bool {
+		a, b := threads[i], threads[j]
+		if a.CPUPercent != b.CPUPercent {
+			return a.CPUPercent > b.CPUPercent
+		}
+		return a.UserTime+a.SystemTime > b.UserTime+b.SystemTime
+	})
+
+	return types.ThreadsResponse{
+		PID:     pid,
+		Name:    name,
+		Threads: threads,
+		Count:   len(threads),
+	}, nil
+}
+
+// threadState returns the name of a one-letter thread state
+func threadState(code string) string {
+	if code == "" {
+		return ""
+	}
+	if state, ok := threadStates[code[:1]]; ok {
+		return state
+	}
+	return code
+}
@@ -0,0 +1,63 @@
+//go:build darwin
+
+package process
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/borankux/gops/pkg/types"
+)
+
+// psTime matches the STIME and UTIME columns of ps, e.g. 1:22.57
+var psTime = regexp.MustCompile(`^\d+(:\d+)+(\.\d+)?$`)
+
+// listThreads parses ps -M, which prints one line per thread. macOS
+// doesn't expose thread IDs to ps, so threads are numbered in the order
+// ps lists them, starting at 1.
+func listThreads(ctx context.Context, pid int32) ([]types.ThreadInfo, error) {
+	out, err := exec.CommandContext(ctx, "ps", "-M", "-p", strconv.Itoa(int(pid))).Output()
+	if err != nil {
+		return nil, err
+	}
+
+	var threads []types.ThreadInfo
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	scanner.Scan() // header
+	for scanner.Scan() {
+		// Columns: [USER] PID [TT] %CPU STAT PRI STIME UTIME [COMMAND];
+		// only the first line has the optional ones, so find the times
+		fields := strings.Fields(scanner.Text())
+		i := 0
+		for i < len(fields) && !psTime.MatchString(fields[i]) {
+			i++
+		}
+		if i < 3 || i+1 >= len(fields) {
+			continue
+		}
+		cpu, _ := strconv.ParseFloat(fields[i-3], 64)
+		threads = append(threads, types.ThreadInfo{
+			ID:         int32(len(threads) + 1),
+			State:      threadState(fields[i-2]),
+			CPUPercent: cpu,
+			SystemTime: parsePSTime(fields[i]),
+			UserTime:   parsePSTime(fields[i+1]),
+		})
+	}
+	return threads, scanner.Err()
+}
+
+// parsePSTime converts [[hh:]mm:]ss.ss to seconds
+func parsePSTime(s string) float64 {
+	var seconds float64
+	for _, part := range strings.Split(s, ":") {
+		n, _ := strconv.ParseFloat(part, 64)
+		seconds = seconds*60 + n
+	}
+	return seconds
+}
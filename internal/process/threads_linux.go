@@ -0,0 +1,114 @@
+//go:build linux
+
+package process
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/borankux/gops/pkg/types"
+)
+
+const (
+	// clockTicks is the USER_HZ unit of /proc CPU times
+	clockTicks = 100
+
+	// threadSampleInterval is how long CPU usage is measured for
+	threadSampleInterval = 250 * time.Millisecond
+)
+
+// taskStat is the parsed /proc/PID/task/TID/stat of a thread
+type taskStat struct {
+	name  string
+	state string
+	utime uint64
+	stime uint64
+}
+
+// listThreads reads each thread under /proc/PID/task twice, a short
+// interval apart, to measure its current CPU usage
+func listThreads(ctx context.Context, pid int32) ([]types.ThreadInfo, error) {
+	before, err := readTaskStats(pid)
+	if err != nil {
+		return nil, err
+	}
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(threadSampleInterval):
+	}
+	after, err := readTaskStats(pid)
+	if err != nil {
+		return nil, err
+	}
+
+	threads := make([]types.ThreadInfo, 0, len(after))
+	for tid, st := range after {
+		info := types.ThreadInfo{
+			ID:         tid,
+			Name:       st.name,
+			State:      threadState(st.state),
+			UserTime:   float64(st.utime) / clockTicks,
+			SystemTime: float64(st.stime) / clockTicks,
+		}
+		if prev, ok := before[tid]; ok {
+			used := float64(st.utime+st.stime-prev.utime-prev.stime) / clockTicks
+			info.CPUPercent = used / threadSampleInterval.Seconds() * 100
+		}
+		threads = append(threads, info)
+	}
+	return threads, nil
+}
+
+// readTaskStats reads the stat file of every thread of a process
+func readTaskStats(pid int32) (map[int32]taskStat, error) {
+	dir := fmt.Sprintf("/proc/%d/task", pid)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make(map[int32]taskStat, len(entries))
+	for _, e := range entries {
+		tid, err := strconv.ParseInt(e.Name(), 10, 32)
+		if err != nil {
+			continue
+		}
+		data, err := os.ReadFile(dir + "/" + e.Name() + "/stat")
+		if err != nil {
+			// The thread exited while we were listing
+			continue
+		}
+		if st, ok := parseTaskStat(string(data)); ok {
+			stats[int32(tid)] = st
+		}
+	}
+	return stats, nil
+}
+
+// parseTaskStat parses "tid (comm) state ppid ... utime stime ...". The
+// name may itself contain spaces and parentheses, so fields are counted
+// from the last closing parenthesis.
+func parseTaskStat(stat string) (taskStat, bool) {
+	open, end := strings.IndexByte(stat, '('), strings.LastIndexByte(stat, ')')
+	if open < 0 || end < open {
+		return taskStat{}, false
+	}
+	fields := strings.Fields(stat[end+1:])
+	// utime and stime are fields 14 and 15 of the whole line
+	if len(fields) < 13 {
+		return taskStat{}, false
+	}
+	utime, _ := strconv.ParseUint(fields[11], 10, 64)
+	stime, _ := strconv.ParseUint(fields[12], 10, 64)
+	return taskStat{
+		name:  stat[open+1 : end],
+		state: fields[0],
+		utime: utime,
+		stime: stime,
+	}, true
+}
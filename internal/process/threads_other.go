@@ -0,0 +1,15 @@
+//go:build !darwin && !linux
+
+package process
+
+import (
+	"context"
+	"errors"
+	"runtime"
+
+	"github.com/borankux/gops/pkg/types"
+)
+
+func listThreads(ctx context.Context, pid int32) ([]types.ThreadInfo, error) {
+	return nil, errors.New("thread listing is not supported on " + runtime.GOOS)
+}
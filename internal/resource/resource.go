@@ -15,8 +15,56 @@ func GetProcessResourceUsage(ctx context.Context, pid int32) (*types.ResourceUsa
 		return nil, err
 	}
 
-	name, _ := p.NameWithContext(ctx)
 	cpuPercent, _ := p.CPUPercentWithContext(ctx)
+	return collectUsage(ctx, p, cpuPercent)
+}
+
+// Sampler measures a process repeatedly, reporting CPU usage over the time
+// since the previous sample rather than averaged over the process lifetime
+type Sampler struct {
+	proc *process.Process
+	// net measures network throughput, nil where it can't be
+	net *NetworkTracker
+}
+
+// NewSampler creates a sampler for pid and records the initial CPU times
+func NewSampler(ctx context.Context, pid int32) (*Sampler, error) {
+	p, err := process.NewProcessWithContext(ctx, pid)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.PercentWithContext(ctx, 0); err != nil {
+		return nil, err
+	}
+	s := &Sampler{proc: p, net: NewNetworkTracker()}
+	if _, err := s.net.Sample(ctx); err != nil {
+		s.net = nil
+	}
+	return s, nil
+}
+
+// Sample returns the current resource usage of the sampled process
+func (s *Sampler) Sample(ctx context.Context) (*types.ResourceUsage, error) {
+	cpuPercent, err := s.proc.PercentWithContext(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+	usage, err := collectUsage(ctx, s.proc, cpuPercent)
+	if err != nil {
+		return nil, err
+	}
+	if s.net != nil {
+		if rates, err := s.net.Sample(ctx); err == nil {
+			rate := rates[s.proc.Pid]
+			usage.Network = &rate
+		}
+	}
+	return usage, nil
+}
+
+// collectUsage gathers memory, thread and file usage for p
+func collectUsage(ctx context.Context, p *process.Process, cpuPercent float64) (*types.ResourceUsage, error) {
+	name, _ := p.NameWithContext(ctx)
 	memPercent, _ := p.MemoryPercentWithContext(ctx)
 
 	memInfo, err := p.MemoryInfoWithContext(ctx)
@@ -38,7 +86,7 @@ func GetProcessResourceUsage(ctx context.Context, pid int32) (*types.ResourceUsa
 	openFiles, _ := p.NumFDsWithContext(ctx)
 
 	return &types.ResourceUsage{
-		PID:           pid,
+		PID:           p.Pid,
 		Name:          name,
 		CPUPercent:    cpuPercent,
 		MemoryPercent: memPercent,
@@ -94,3 +142,45 @@ func GetTopProcesses(ctx context.Context, limit int, sortBy string) ([]types.Res
 
 	return usages, nil
 }
+
+// CPUTracker measures the CPU usage of every process between calls to
+// Sample, keeping process handles so deltas survive across polls
+type CPUTracker struct {
+	procs map[int32]*process.Process
+}
+
+// NewCPUTracker creates a tracker with no history
+func NewCPUTracker() *CPUTracker {
+	return &CPUTracker{procs: make(map[int32]*process.Process)}
+}
+
+// Sample returns the CPU percentage of each process since the previous
+// sample. Processes seen for the first time have no delta yet and are
+// omitted.
+func (t *CPUTracker) Sample(ctx context.Context) (map[int32]float64, error) {
+	pids, err := process.PidsWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	usage := make(map[int32]float64)
+	current := make(map[int32]*process.Process, len(pids))
+	for _, pid := range pids {
+		p, seen := t.procs[pid]
+		if !seen {
+			if p, err = process.NewProcessWithContext(ctx, pid); err != nil {
+				continue
+			}
+		}
+		percent, err := p.PercentWithContext(ctx, 0)
+		if err != nil {
+			continue
+		}
+		if seen {
+			usage[pid] = percent
+		}
+		current[pid] = p
+	}
+	t.procs = current
+	return usage, nil
+}
@@ -0,0 +1,452 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/borankux/gops/pkg/types"
+)
+
+// Actions accepted by Control
+const (
+	ActionStart   = "start"
+	ActionStop    = "stop"
+	ActionRestart = "restart"
+	// ActionReload asks the service to reload its configuration without
+	// restarting: systemctl reload, or SIGHUP for launchd jobs
+	ActionReload = "reload"
+	// ActionEnable and ActionDisable decide whether the service starts
+	// at boot or login, without starting or stopping it now
+	ActionEnable  = "enable"
+	ActionDisable = "disable"
+)
+
+// Actions lists the valid service actions
+var Actions = []string{ActionStart, ActionStop, ActionRestart, ActionReload, ActionEnable, ActionDisable}
+
+// Enablement states reported in ServiceInfo.Enablement besides systemd's
+// own, such as static and masked
+const (
+	Enabled  = "enabled"
+	Disabled = "disabled"
+	// StartAutomatic and StartManual are Windows start types, the
+	// services started at boot and those started on demand; disabled
+	// services are Disabled
+	StartAutomatic = "automatic"
+	StartManual    = "manual"
+)
+
+// ErrNotFound is returned when no service has the given name
+var ErrNotFound = errors.New("service not found")
+
+// ControlOptions modify how Control acts on a service
+type ControlOptions struct {
+	// User acts on a systemd user unit of the user gops runs as, rather
+	// than a system unit. Only Linux has user units.
+	User bool
+}
+
+// Control starts, stops, restarts, reloads, enables or disables the
+// service name and reports whether it runs afterwards. macOS uses
+// launchctl bootstrap, bootout, kickstart, kill, enable and disable,
+// Linux systemctl and Windows Start-Service, Stop-Service,
+// Restart-Service and Set-Service. Controlling system services usually
+// requires root or an administrator.
+func Control(ctx context.Context, name, action string, opts ControlOptions) (types.ServiceActionResponse, error) {
+	if err := ValidName(name); err != nil {
+		return types.ServiceActionResponse{}, err
+	}
+	if !validAction(action) {
+		return types.ServiceActionResponse{}, fmt.Errorf("invalid action: %s (expected one of %s)", action, strings.Join(Actions, ", "))
+	}
+
+	if opts.User && runtime.GOOS != "linux" {
+		return types.ServiceActionResponse{}, errors.New("user units are only supported on linux")
+	}
+
+	var pid int32
+	var enablement string
+	var err error
+	switch runtime.GOOS {
+	case "darwin":
+		pid, enablement, err = controlLaunchd(ctx, name, action)
+	case "linux":
+		pid, enablement, err = controlSystemd(ctx, name, action, opts.User)
+	case "windows":
+		pid, enablement, err = controlWindows(ctx, name, action)
+	default:
+		err = errors.New("controlling services is not supported on " + runtime.GOOS)
+	}
+	if err != nil {
+		return types.ServiceActionResponse{}, err
+	}
+
+	return types.ServiceActionResponse{
+		Name:       name,
+		Action:     action,
+		User:       opts.User,
+		Running:    pid > 0,
+		PID:        pid,
+		Enablement: enablement,
+	}, nil
+}
+
+// namePatterns are the characters service names may hold on each OS.
+// launchd labels are reverse-DNS names, possibly with an @ instance, and
+// systemd unit names may also hold : and \x escapes. Windows service
+// names may hold spaces and most punctuation, but not slashes.
+var namePatterns = map[string]*regexp.Regexp{
+	"darwin":  regexp.MustCompile(`^[A-Za-z0-9._@-]+$`),
+	"linux":   regexp.MustCompile(`^[A-Za-z0-9._@:\\-]+$`),
+	"windows": regexp.MustCompile(`^[^/\\]+$`),
+}
+
+// ValidName checks that name can be a service name on this OS. Names a
+// service manager would take for an option, and names that could point
+// outside a directory of launchd property lists, are refused.
+func ValidName(name string) error {
+	if name == "" {
+		return errors.New("no service name given")
+	}
+	pattern := namePatterns[runtime.GOOS]
+	if strings.HasPrefix(name, "-") || strings.Contains(name, "..") || (pattern != nil && !pattern.MatchString(name)) {
+		return fmt.Errorf("invalid service name: %q", name)
+	}
+	return nil
+}
+
+// validAction reports whether action is one of Actions
+func validAction(action string) bool {
+	for _, a := range Actions {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
+
+// runControl runs a service manager command, turning its output into the
+// error when it fails
+func runControl(ctx context.Context, name string, args ...string) error {
+	output, err := exec.CommandContext(ctx, name, args...).CombinedOutput()
+	if err == nil {
+		return nil
+	}
+	return controlError(name+" "+args[0], output, err)
+}
+
+// controlError describes a failed service manager command by its output,
+// wrapping os.ErrPermission or ErrNotFound when the output says so
+func controlError(command string, output []byte, err error) error {
+	msg := strings.TrimSpace(string(output))
+	if msg == "" {
+		msg = err.Error()
+	}
+	lower := strings.ToLower(msg)
+	switch {
+	case strings.Contains(lower, "not permitted"), strings.Contains(lower, "permission denied"),
+		strings.Contains(lower, "access is denied"):
+		return fmt.Errorf("%s: %s: %w", command, msg, os.ErrPermission)
+	case strings.Contains(lower, "not found"), strings.Contains(lower, "cannot find any service"),
+		strings.Contains(lower, "could not find service"):
+		return fmt.Errorf("%s: %s: %w", command, msg, ErrNotFound)
+	}
+	return fmt.Errorf("%s: %s", command, msg)
+}
+
+// launchdJob is a launchd job and the domain it belongs to
+type launchdJob struct {
+	label string
+	// domain is system for daemons, or gui/<uid> or user/<uid> for agents
+	domain string
+	// plist is the job's property list, empty if it wasn't found
+	plist string
+}
+
+// target names the job in launchctl commands, e.g. system/com.example.job
+func (j launchdJob) target() string {
+	return j.domain + "/" + j.label
+}
+
+// launchdDirs are where launchd property lists live, and whether the
+// jobs in them are daemons of the system domain
+var launchdDirs = []struct {
+	dir    string
+	system bool
+}{
+	{"~/Library/LaunchAgents", false},
+	{"/Library/LaunchAgents", false},
+	{"/Library/LaunchDaemons", true},
+	{"/System/Library/LaunchAgents", false},
+	{"/System/Library/LaunchDaemons", true},
+}
+
+// findLaunchdJob locates the job labelled name, by its property list if
+// it is named after the label and otherwise among the loaded jobs. Agents
+// belong to the gui domain of the user launchdUser returns.
+func findLaunchdJob(ctx context.Context, name string) (launchdJob, error) {
+	uid := launchdUser(ctx)
+	gui := "gui/" + uid
+	home, _ := os.UserHomeDir()
+	for _, d := range launchdDirs {
+		if !d.system && uid == "" {
+			continue
+		}
+		dir := d.dir
+		if strings.HasPrefix(dir, "~/") {
+			if home == "" {
+				continue
+			}
+			dir = filepath.Join(home, dir[2:])
+		}
+		plist := filepath.Join(dir, name+".plist")
+		if filepath.Dir(plist) != filepath.Clean(dir) {
+			// The name holds a path; ValidName refuses those
+			continue
+		}
+		if _, err := os.Stat(plist); err != nil {
+			continue
+		}
+		domain := gui
+		if d.system {
+			domain = DomainSystem
+		}
+		return launchdJob{label: name, domain: domain, plist: plist}, nil
+	}
+
+	domains := []string{DomainSystem}
+	if uid != "" {
+		domains = []string{gui, "user/" + uid, DomainSystem}
+	}
+	for _, domain := range domains {
+		job := launchdJob{label: name, domain: domain}
+		if launchdLoaded(ctx, job) {
+			return job, nil
+		}
+	}
+	return launchdJob{}, fmt.Errorf("%w: %s", ErrNotFound, name)
+}
+
+// launchdLoaded reports whether the job is loaded into its domain
+func launchdLoaded(ctx context.Context, job launchdJob) bool {
+	return exec.CommandContext(ctx, "launchctl", "print", job.target()).Run() == nil
+}
+
+// launchdDisabled returns the jobs of a launchd domain whose enablement
+// has been overridden, and whether each is disabled, from launchctl
+// print-disabled:
+//
+//	disabled services = {
+//		"com.apple.ftpd" => disabled
+//		"com.example.job" => enabled
+//	}
+//
+// Older macOS versions print true for disabled and false for enabled.
+func launchdDisabled(ctx context.Context, domain string) map[string]bool {
+	disabled := make(map[string]bool)
+	output, err := exec.CommandContext(ctx, "launchctl", "print-disabled", domain).Output()
+	if err != nil {
+		return disabled
+	}
+	inDisabled := false
+	scanner := bufio.NewScanner(strings.NewReader(string(output)))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "disabled services = {":
+			inDisabled = true
+		case line == "}":
+			inDisabled = false
+		case inDisabled:
+			label, state, ok := strings.Cut(line, " => ")
+			if ok {
+				disabled[strings.Trim(label, `"`)] = state == Disabled || state == "true"
+			}
+		}
+	}
+	return disabled
+}
+
+// launchdEnablement returns whether the job is enabled or disabled
+func launchdEnablement(ctx context.Context, job launchdJob) string {
+	if launchdDisabled(ctx, job.domain)[job.label] {
+		return Disabled
+	}
+	return Enabled
+}
+
+// launchdPIDPattern matches the PID line of launchctl print
+var launchdPIDPattern = regexp.MustCompile(`(?m)^\s*pid = (\d+)`)
+
+// launchdPID returns the PID of the job, 0 if it isn't running
+func launchdPID(ctx context.Context, job launchdJob) int32 {
+	output, err := exec.CommandContext(ctx, "launchctl", "print", job.target()).Output()
+	if err != nil {
+		return 0
+	}
+	m := launchdPIDPattern.FindSubmatch(output)
+	if m == nil {
+		return 0
+	}
+	pid, _ := strconv.ParseInt(string(m[1]), 10, 32)
+	return int32(pid)
+}
+
+// controlLaunchd acts on a launchd job and returns its PID and
+// enablement. Jobs that aren't loaded are bootstrapped from their
+// property list to start them; stopping boots the job out, so KeepAlive
+// doesn't bring it straight back. Enabling and disabling record an
+// override launchd keeps across reboots.
+func controlLaunchd(ctx context.Context, name, action string) (int32, string, error) {
+	job, err := findLaunchdJob(ctx, name)
+	if err != nil {
+		return 0, "", err
+	}
+	loaded := launchdLoaded(ctx, job)
+
+	switch {
+	case action == ActionEnable, action == ActionDisable:
+		err = runControl(ctx, "launchctl", action, job.target())
+	case action == ActionStop && !loaded:
+		// Already stopped
+	case action == ActionStop:
+		err = runControl(ctx, "launchctl", "bootout", job.target())
+	case action == ActionReload && !loaded:
+		return 0, "", fmt.Errorf("%s is not loaded", name)
+	case action == ActionReload:
+		// launchd has no reload; daemons conventionally reread their
+		// configuration on SIGHUP
+		err = runControl(ctx, "launchctl", "kill", "SIGHUP", job.target())
+	case !loaded && job.plist == "":
+		return 0, "", fmt.Errorf("%s is not loaded and its property list was not found", name)
+	case !loaded:
+		err = runControl(ctx, "launchctl", "bootstrap", job.domain, job.plist)
+	case action == ActionRestart:
+		err = runControl(ctx, "launchctl", "kickstart", "-k", job.target())
+	default:
+		err = runControl(ctx, "launchctl", "kickstart", job.target())
+	}
+	if err != nil {
+		return 0, "", err
+	}
+	return launchdPID(ctx, job), launchdEnablement(ctx, job), nil
+}
+
+// systemdAuthErrors are what systemctl prints when polkit refuses an
+// action or would have to ask for a password
+var systemdAuthErrors = []string{
+	"interactive authentication required",
+	"access denied",
+	"authentication is required",
+	"not authorized",
+}
+
+// controlSystemd acts on a systemd unit with systemctl and returns its
+// main PID and enablement. systemctl is told not to ask for a password,
+// so a missing privilege fails at once rather than waiting on a polkit
+// prompt nobody sees, and the name follows -- so it is never read as an
+// option.
+func controlSystemd(ctx context.Context, name, action string, user bool) (int32, string, error) {
+	args := systemctlArgs(user, "--no-ask-password", action, "--", name)
+	output, err := exec.CommandContext(ctx, "systemctl", args...).CombinedOutput()
+	if err != nil {
+		lower := strings.ToLower(string(output))
+		for _, msg := range systemdAuthErrors {
+			if strings.Contains(lower, msg) {
+				return 0, "", fmt.Errorf("systemctl %s %s: root or polkit authorization is required; run gops with sudo or allow org.freedesktop.systemd1.manage-units for this user: %w",
+					action, name, os.ErrPermission)
+			}
+		}
+		return 0, "", controlError("systemctl "+action, output, err)
+	}
+	return systemdPID(ctx, name, user), systemdEnablement(ctx, name, user), nil
+}
+
+// systemctlArgs prefixes args with --user for user units
+func systemctlArgs(user bool, args ...string) []string {
+	if user {
+		return append([]string{"--user"}, args...)
+	}
+	return args
+}
+
+// systemdPID returns the main PID of the unit, 0 if it isn't running
+func systemdPID(ctx context.Context, name string, user bool) int32 {
+	args := systemctlArgs(user, "show", "--property=MainPID", "--value", "--", name)
+	output, err := exec.CommandContext(ctx, "systemctl", args...).Output()
+	if err != nil {
+		return 0
+	}
+	pid, _ := strconv.ParseInt(strings.TrimSpace(string(output)), 10, 32)
+	return int32(pid)
+}
+
+// systemdEnablement returns the unit file state of the unit, such as
+// enabled, disabled or static, as systemctl is-enabled reports it
+func systemdEnablement(ctx context.Context, name string, user bool) string {
+	// is-enabled exits non-zero for anything but enabled, still printing
+	// the state
+	output, _ := exec.CommandContext(ctx, "systemctl", systemctlArgs(user, "is-enabled", "--", name)...).Output()
+	return strings.TrimSpace(string(output))
+}
+
+// windowsCommands are the cmdlets carrying out each action; enabling and
+// disabling sets the start type
+var windowsCommands = map[string]string{
+	ActionStart:   "Start-Service",
+	ActionStop:    "Stop-Service",
+	ActionRestart: "Restart-Service",
+	ActionEnable:  "Set-Service -StartupType Automatic",
+	ActionDisable: "Set-Service -StartupType Disabled",
+}
+
+// controlWindows acts on a Windows service, then reads the PID of its
+// process from Win32_Service and its start type
+func controlWindows(ctx context.Context, name, action string) (int32, string, error) {
+	command, ok := windowsCommands[action]
+	if !ok {
+		return 0, "", fmt.Errorf("%s is not supported for windows services", action)
+	}
+	psScript := fmt.Sprintf(`
+		$ErrorActionPreference = 'Stop'
+		$svc = %s -Name $env:GOPS_SERVICE -PassThru
+		[PSCustomObject]@{
+			ProcessId = (Get-CimInstance Win32_Service -Filter "Name='$($svc.Name)'").ProcessId
+			StartType = (Get-Service -Name $svc.Name).StartType.ToString()
+		} | ConvertTo-Json -Compress
+	`, command)
+	cmdlet, _, _ := strings.Cut(command, " ")
+	output, err := powershell(ctx, psScript, name).CombinedOutput()
+	if err != nil {
+		return 0, "", controlError(cmdlet, output, err)
+	}
+	var result struct {
+		ProcessID int32 `json:"ProcessId"`
+		StartType string
+	}
+	if err := json.Unmarshal(output, &result); err != nil {
+		return 0, "", fmt.Errorf("parsing %s output: %w", cmdlet, err)
+	}
+	return result.ProcessID, strings.ToLower(result.StartType), nil
+}
+
+// powershell returns a command running a PowerShell script that reads
+// the service name from $env:GOPS_SERVICE. The name is never written into
+// the script: doubling single quotes doesn't escape it, as PowerShell also
+// takes typographic quotes for quotes, and arguments after -Command are
+// appended to the script and parsed as code too.
+func powershell(ctx context.Context, script, name string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, "powershell", "-Command", script)
+	cmd.Env = append(os.Environ(), "GOPS_SERVICE="+name)
+	return cmd
+}
@@ -0,0 +1,321 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/borankux/gops/internal/utils"
+	"github.com/borankux/gops/pkg/types"
+)
+
+// Service managers reported in ServiceDetailResponse.Manager
+const (
+	ManagerLaunchd = "launchd"
+	ManagerSystemd = "systemd"
+	ManagerWindows = "windows"
+)
+
+// launchdThrottleInterval is how many seconds launchd waits between
+// starts of a job whose plist sets no ThrottleInterval
+const launchdThrottleInterval = 10
+
+// GetService returns how the service name is configured and how it last
+// ran: its launchd property list and job state on macOS, its systemd unit
+// properties on Linux, or its Win32_Service entry on Windows. User acts
+// as in ControlOptions.
+func GetService(ctx context.Context, name string, opts ControlOptions) (types.ServiceDetailResponse, error) {
+	if err := ValidName(name); err != nil {
+		return types.ServiceDetailResponse{}, err
+	}
+	if opts.User && runtime.GOOS != "linux" {
+		return types.ServiceDetailResponse{}, errors.New("user units are only supported on linux")
+	}
+	switch runtime.GOOS {
+	case "darwin":
+		return launchdDetail(ctx, name)
+	case "linux":
+		return systemdDetail(ctx, name, opts.User)
+	case "windows":
+		return windowsDetail(ctx, name)
+	default:
+		return types.ServiceDetailResponse{}, errors.New("service details are not supported on " + runtime.GOOS)
+	}
+}
+
+// launchdDetail combines a job's property list with the state launchctl
+// print reports for it
+func launchdDetail(ctx context.Context, name string) (types.ServiceDetailResponse, error) {
+	job, err := findLaunchdJob(ctx, name)
+	if err != nil {
+		return types.ServiceDetailResponse{}, err
+	}
+	detail := types.ServiceDetailResponse{
+		Name:             name,
+		Manager:          ManagerLaunchd,
+		Domain:           job.domain,
+		State:            "not loaded",
+		File:             job.plist,
+		ThrottleInterval: launchdThrottleInterval,
+		Enablement:       launchdEnablement(ctx, job),
+	}
+
+	if output, err := exec.CommandContext(ctx, "launchctl", "print", job.target()).Output(); err == nil {
+		printed := parseLaunchctlPrint(string(output))
+		detail.State = printed["state"]
+		if detail.File == "" {
+			detail.File = printed["path"]
+		}
+		if pid, err := strconv.ParseInt(printed["pid"], 10, 32); err == nil {
+			detail.PID = int32(pid)
+		}
+		detail.Runs, _ = strconv.Atoi(printed["runs"])
+		// "78: Function not implemented", or "(never exited)"
+		code, _, _ := strings.Cut(printed["last exit code"], ":")
+		if status, err := strconv.Atoi(code); err == nil {
+			detail.LastExitStatus = &status
+		}
+		detail.LastSignal = printed["last terminating signal"]
+		// launchd holds a job that exits too soon after starting until
+		// its throttle interval has passed
+		detail.Throttled = detail.State == "spawn scheduled"
+		if detail.Program == nil && printed["program"] != "" {
+			detail.Program = []string{printed["program"]}
+		}
+	}
+
+	if detail.File != "" {
+		root, err := utils.ReadPlist(ctx, detail.File)
+		if err != nil {
+			return types.ServiceDetailResponse{}, fmt.Errorf("reading %s: %w", detail.File, err)
+		}
+		plist, _ := root.(map[string]interface{})
+		if args, ok := plist["ProgramArguments"].([]interface{}); ok {
+			detail.Program = detail.Program[:0]
+			for _, arg := range args {
+				detail.Program = append(detail.Program, fmt.Sprint(arg))
+			}
+		} else if program, ok := plist["Program"].(string); ok {
+			detail.Program = []string{program}
+		}
+		for _, key := range []string{"StandardOutPath", "StandardErrorPath"} {
+			if path, ok := plist[key].(string); ok && path != "" && !slices.Contains(detail.LogFiles, path) {
+				detail.LogFiles = append(detail.LogFiles, path)
+			}
+		}
+		detail.RunAtLoad, _ = plist["RunAtLoad"].(bool)
+		detail.KeepAlive = plist["KeepAlive"]
+		if interval, ok := plist["ThrottleInterval"].(int64); ok {
+			detail.ThrottleInterval = float64(interval)
+		}
+	}
+	return detail, nil
+}
+
+// parseLaunchctlPrint returns the top-level "key = value" lines of
+// launchctl print, whose nested sections are indented further:
+//
+//	system/com.example.job = {
+//		state = running
+//		pid = 412
+//		last exit code = (never exited)
+func parseLaunchctlPrint(output string) map[string]string {
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "\t") || strings.HasPrefix(line, "\t\t") {
+			continue
+		}
+		key, value, ok := strings.Cut(strings.TrimSpace(line), " = ")
+		if !ok || value == "{" {
+			continue
+		}
+		values[key] = value
+	}
+	return values
+}
+
+// systemdProperties are the unit properties systemdDetail reads
+var systemdProperties = []string{
+	"Id", "Description", "LoadState", "ActiveState", "SubState", "UnitFileState",
+	"FragmentPath", "ExecStart", "MainPID", "Restart", "RestartUSec", "NRestarts",
+	"ExecMainCode", "ExecMainStatus", "Result", "StartLimitBurst", "StartLimitIntervalUSec",
+	"ActiveEnterTimestamp", "InactiveEnterTimestamp",
+}
+
+// systemdDetail reads a unit's properties with systemctl show
+func systemdDetail(ctx context.Context, name string, user bool) (types.ServiceDetailResponse, error) {
+	args := systemctlArgs(user, "show", "--property="+strings.Join(systemdProperties, ","), "--", name)
+	output, err := exec.CommandContext(ctx, "systemctl", args...).CombinedOutput()
+	if err != nil {
+		return types.ServiceDetailResponse{}, controlError("systemctl show", output, err)
+	}
+	props := make(map[string]string)
+	scanner := bufio.NewScanner(strings.NewReader(string(output)))
+	for scanner.Scan() {
+		if key, value, ok := strings.Cut(scanner.Text(), "="); ok {
+			props[key] = value
+		}
+	}
+	if props["LoadState"] == "not-found" {
+		return types.ServiceDetailResponse{}, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+
+	detail := types.ServiceDetailResponse{
+		Name:       name,
+		Manager:    ManagerSystemd,
+		User:       user,
+		State:      props["ActiveState"],
+		File:       props["FragmentPath"],
+		Program:    execArgv(props["ExecStart"]),
+		Restart:    props["Restart"],
+		Enablement: props["UnitFileState"],
+		Throttled:  props["Result"] == "start-limit-hit",
+		Properties: props,
+	}
+	if sub := props["SubState"]; sub != "" && sub != detail.State {
+		detail.State += " (" + sub + ")"
+	}
+	if pid, err := strconv.ParseInt(props["MainPID"], 10, 32); err == nil {
+		detail.PID = int32(pid)
+	}
+	detail.Restarts, _ = strconv.Atoi(props["NRestarts"])
+	if interval, ok := parseTimespan(props["RestartUSec"]); ok {
+		detail.ThrottleInterval = interval.Seconds()
+	}
+	// ExecMainCode is a CLD_* code: 1 for a normal exit, whose status is
+	// the exit code, and 2 or 3 for a signal, whose number is the status
+	if status, err := strconv.Atoi(props["ExecMainStatus"]); err == nil {
+		switch props["ExecMainCode"] {
+		case "1":
+			detail.LastExitStatus = &status
+		case "2", "3":
+			detail.LastSignal = "signal " + strconv.Itoa(status)
+		}
+	}
+	return detail, nil
+}
+
+// execArgv extracts the command line from an ExecStart property such as
+//
+//	{ path=/usr/sbin/nginx ; argv[]=/usr/sbin/nginx -g daemon off; ; ignore_errors=no ; ... }
+//
+// Arguments are split on spaces, as systemctl prints them unquoted.
+func execArgv(execStart string) []string {
+	_, rest, ok := strings.Cut(execStart, "argv[]=")
+	if !ok {
+		return nil
+	}
+	argv, _, _ := strings.Cut(rest, " ; ")
+	return strings.Fields(argv)
+}
+
+// parseTimespan parses a systemd time span such as 100ms, 5s, "1min 30s"
+// or "1d 2h"
+func parseTimespan(s string) (time.Duration, bool) {
+	if s == "" || s == "infinity" {
+		return 0, false
+	}
+	var total time.Duration
+	for _, part := range strings.Fields(s) {
+		var d time.Duration
+		var err error
+		switch {
+		case strings.HasSuffix(part, "d"):
+			var days float64
+			days, err = strconv.ParseFloat(strings.TrimSuffix(part, "d"), 64)
+			d = time.Duration(days * float64(24*time.Hour))
+		case strings.HasSuffix(part, "w"):
+			var weeks float64
+			weeks, err = strconv.ParseFloat(strings.TrimSuffix(part, "w"), 64)
+			d = time.Duration(weeks * float64(7*24*time.Hour))
+		default:
+			d, err = time.ParseDuration(strings.Replace(part, "min", "m", 1))
+		}
+		if err != nil {
+			return 0, false
+		}
+		total += d
+	}
+	return total, true
+}
+
+// windowsStartTypes maps the StartMode of Win32_Service to the start
+// types Get-Service reports
+var windowsStartTypes = map[string]string{
+	"Auto":     StartAutomatic,
+	"Manual":   StartManual,
+	"Disabled": Disabled,
+	"Boot":     "boot",
+	"System":   "system",
+}
+
+// windowsDetail reads a service's Win32_Service entry
+func windowsDetail(ctx context.Context, name string) (types.ServiceDetailResponse, error) {
+	// WQL escapes quotes and backslashes with a backslash
+	psScript := `
+		$name = $env:GOPS_SERVICE -replace '\\', '\\' -replace "'", "\'"
+		$svc = Get-CimInstance Win32_Service -Filter "Name='$name'"
+		if ($svc) {
+			$svc | Select-Object Name, DisplayName, Description, State, ProcessId, PathName,
+				StartMode, StartName, ExitCode, ServiceSpecificExitCode | ConvertTo-Json -Compress
+		}
+	`
+	output, err := powershell(ctx, psScript, name).Output()
+	if err != nil {
+		return types.ServiceDetailResponse{}, errors.New("failed to read the service with Get-CimInstance")
+	}
+	output = []byte(strings.TrimSpace(string(output)))
+	if len(output) == 0 {
+		return types.ServiceDetailResponse{}, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	var svc struct {
+		Name                    string
+		DisplayName             string
+		Description             string
+		State                   string
+		ProcessID               int32 `json:"ProcessId"`
+		PathName                string
+		StartMode               string
+		StartName               string
+		ExitCode                int
+		ServiceSpecificExitCode int
+	}
+	if err := json.Unmarshal(output, &svc); err != nil {
+		return types.ServiceDetailResponse{}, fmt.Errorf("parsing Get-CimInstance output: %w", err)
+	}
+
+	detail := types.ServiceDetailResponse{
+		Name:       svc.Name,
+		Manager:    ManagerWindows,
+		State:      strings.ToLower(svc.State),
+		PID:        svc.ProcessID,
+		File:       svc.PathName,
+		Enablement: windowsStartTypes[svc.StartMode],
+		Properties: map[string]string{
+			"DisplayName": svc.DisplayName,
+			"Description": svc.Description,
+			"StartMode":   svc.StartMode,
+			"StartName":   svc.StartName,
+		},
+	}
+	if svc.PathName != "" {
+		detail.Program = []string{svc.PathName}
+	}
+	// 1066 means the service reported its own exit code instead
+	status := svc.ExitCode
+	if status == 1066 {
+		status = svc.ServiceSpecificExitCode
+	}
+	detail.LastExitStatus = &status
+	return detail, nil
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/borankux/gops/pkg/types"
+)
+
+// Log lines of each service in the failed services report
+const (
+	// DefaultFailedLogLines is how many log lines GetFailedServices
+	// returns per service by default
+	DefaultFailedLogLines = 5
+	// MaxFailedLogLines is the most log lines GetFailedServices returns
+	// per service
+	MaxFailedLogLines = 100
+)
+
+// failedConcurrency bounds how many services GetFailedServices reads the
+// details and logs of at once, as log show takes seconds each
+const failedConcurrency = 4
+
+// FailedOptions controls the failed services report
+type FailedOptions struct {
+	// LogLines is how many of the last log lines to include per service,
+	// at most MaxFailedLogLines; 0 leaves logs out
+	LogLines int
+}
+
+// GetFailedServices returns the services whose last run ended with a
+// non-zero exit status or a signal, those restarted most often first,
+// with how often launchd or systemd started them again and their last
+// log lines. On macOS these are the launchd jobs reporting a non-zero
+// last exit status, including those KeepAlive has restarted; on Linux
+// the units in the failed state and those waiting to be restarted after
+// a failure. Windows is not supported.
+func GetFailedServices(ctx context.Context, opts FailedOptions) ([]types.FailedService, error) {
+	if opts.LogLines < 0 || opts.LogLines > MaxFailedLogLines {
+		return nil, fmt.Errorf("invalid log lines: %d (at most %d)", opts.LogLines, MaxFailedLogLines)
+	}
+	if runtime.GOOS != "darwin" && runtime.GOOS != "linux" {
+		return nil, errors.New("the failed services report is not supported on " + runtime.GOOS)
+	}
+	services, err := GetServices(ctx, ListOptions{SkipUsage: true})
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []types.ServiceInfo
+	for _, svc := range services {
+		// systemd units restarting after a failure are activating
+		if Failed(svc.Status) || svc.Status == "activating" {
+			candidates = append(candidates, svc)
+		}
+	}
+
+	results := make([]*types.FailedService, len(candidates))
+	sem := make(chan struct{}, failedConcurrency)
+	var wg sync.WaitGroup
+	for i, svc := range candidates {
+		wg.Add(1)
+		go func(i int, svc types.ServiceInfo) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			results[i] = failedService(ctx, svc, opts)
+		}(i, svc)
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	failed := []types.FailedService{}
+	for _, f := range results {
+		if f != nil {
+			failed = append(failed, *f)
+		}
+	}
+	sort.SliceStable(failed, func(i, j int) bool {
+		a, b := failed[i].Restarts+failed[i].Runs, failed[j].Restarts+failed[j].Runs
+		if a != b {
+			return a > b
+		}
+		return strings.ToLower(failed[i].Name) < strings.ToLower(failed[j].Name)
+	})
+	return failed, nil
+}
+
+// failedService reads the details and last log lines of a service that
+// may have failed, returning nil if it turns out not to have, such as a
+// unit activating for the first time
+func failedService(ctx context.Context, svc types.ServiceInfo, opts FailedOptions) *types.FailedService {
+	user := svc.Domain == DomainUser
+	f := &types.FailedService{
+		Name:    svc.Name,
+		Domain:  svc.Domain,
+		Scope:   svc.Scope,
+		State:   svc.Status,
+		Running: svc.PID > 0,
+		PID:     svc.PID,
+	}
+
+	detail, err := GetService(ctx, svc.Name, ControlOptions{User: user})
+	if err != nil {
+		if svc.Status == "activating" {
+			return nil
+		}
+		f.Error = err.Error()
+		return f
+	}
+	exited := detail.LastExitStatus != nil && *detail.LastExitStatus != 0
+	if svc.Status == "activating" && !exited && detail.LastSignal == "" {
+		return nil
+	}
+	f.State = detail.State
+	f.LastExitStatus = detail.LastExitStatus
+	f.LastSignal = detail.LastSignal
+	f.Runs = detail.Runs
+	f.Restarts = detail.Restarts
+	f.Restart = detail.Restart
+	f.Throttled = detail.Throttled
+
+	if opts.LogLines > 0 {
+		logs, err := GetLogs(ctx, svc.Name, LogOptions{Lines: opts.LogLines, User: user})
+		if err != nil {
+			f.Error = err.Error()
+		}
+		// macOS adds the tail of each output file after the unified log
+		f.Logs = lastEntries(logs, opts.LogLines)
+	}
+	return f
+}
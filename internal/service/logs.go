@@ -0,0 +1,263 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/borankux/gops/pkg/types"
+)
+
+// Log line limits
+const (
+	// DefaultLogLines is how many log lines GetLogs returns by default
+	DefaultLogLines = 200
+	// MaxLogLines is the most log lines GetLogs returns
+	MaxLogLines = 5000
+	// DefaultLogWindow is how far back macOS logs are searched unless
+	// LogOptions.Since is set, as log show reads every entry in range
+	DefaultLogWindow = time.Hour
+)
+
+// maxTailBytes is how much of the end of a log file is read for its last
+// lines
+const maxTailBytes = 1 << 20
+
+// LogOptions selects the log lines of a service
+type LogOptions struct {
+	// Lines is how many of the most recent lines to return, at most
+	// MaxLogLines; the default is DefaultLogLines
+	Lines int
+	// Since leaves out older lines
+	Since time.Time
+	// User reads the logs of a systemd user unit
+	User bool
+}
+
+// GetLogs returns the most recent log lines of the service name, oldest
+// first. Linux reads the unit's journal with journalctl. macOS searches
+// the unified log with log show for the job's process and launchd's
+// messages about it, and adds the tail of the files its property list
+// sends standard output and error to. Reading other users' and system
+// logs may require root.
+func GetLogs(ctx context.Context, name string, opts LogOptions) ([]types.ServiceLogEntry, error) {
+	if opts.Lines <= 0 {
+		opts.Lines = DefaultLogLines
+	}
+	if opts.Lines > MaxLogLines {
+		return nil, fmt.Errorf("invalid lines: %d (at most %d)", opts.Lines, MaxLogLines)
+	}
+	if opts.User && runtime.GOOS != "linux" {
+		return nil, errors.New("user units are only supported on linux")
+	}
+	switch runtime.GOOS {
+	case "darwin":
+		return launchdLogs(ctx, name, opts)
+	case "linux":
+		return journalLogs(ctx, name, opts)
+	default:
+		return nil, errors.New("service logs are not supported on " + runtime.GOOS)
+	}
+}
+
+// logShowEntry is an entry of log show --style ndjson
+type logShowEntry struct {
+	Timestamp        string `json:"timestamp"`
+	ProcessImagePath string `json:"processImagePath"`
+	ProcessID        int32  `json:"processID"`
+	MessageType      string `json:"messageType"`
+	EventMessage     string `json:"eventMessage"`
+}
+
+// logShowTime is the timestamp format of log show, e.g.
+// 2025-01-01 12:00:00.123456-0800
+const logShowTime = "2006-01-02 15:04:05.000000-0700"
+
+// launchdLogs searches the unified log for the job's process, by the name
+// of its executable, and for launchd's messages about the job, such as
+// its exits, then adds the tail of its output files
+func launchdLogs(ctx context.Context, name string, opts LogOptions) ([]types.ServiceLogEntry, error) {
+	detail, err := launchdDetail(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	predicate := fmt.Sprintf(`(process == "launchd" AND eventMessage CONTAINS %q)`, name)
+	if len(detail.Program) > 0 {
+		predicate = fmt.Sprintf(`process == %q OR %s`, filepath.Base(detail.Program[0]), predicate)
+	}
+	args := []string{"show", "--style", "ndjson", "--predicate", predicate}
+	if opts.Since.IsZero() {
+		args = append(args, "--last", fmt.Sprintf("%dm", int(DefaultLogWindow.Minutes())))
+	} else {
+		args = append(args, "--start", opts.Since.Local().Format("2006-01-02 15:04:05"))
+	}
+	output, err := exec.CommandContext(ctx, "log", args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("log show: %w", err)
+	}
+
+	var entries []types.ServiceLogEntry
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	scanner.Buffer(make([]byte, 64*1024), 1<<20)
+	for scanner.Scan() {
+		var e logShowEntry
+		// The last line is a summary rather than an entry
+		if json.Unmarshal(scanner.Bytes(), &e) != nil || e.Timestamp == "" {
+			continue
+		}
+		entry := types.ServiceLogEntry{
+			Time:    e.Timestamp,
+			Process: filepath.Base(e.ProcessImagePath),
+			PID:     e.ProcessID,
+			Level:   strings.ToLower(e.MessageType),
+			Message: e.EventMessage,
+		}
+		if t, err := time.Parse(logShowTime, e.Timestamp); err == nil {
+			entry.Time = t.Format(time.RFC3339Nano)
+		}
+		entries = append(entries, entry)
+	}
+	entries = lastEntries(entries, opts.Lines)
+
+	for _, path := range detail.LogFiles {
+		lines, err := tailFile(path, opts.Lines)
+		if err != nil {
+			continue
+		}
+		for _, line := range lines {
+			entries = append(entries, types.ServiceLogEntry{Source: path, Message: line})
+		}
+	}
+	return entries, nil
+}
+
+// tailFile returns the last n lines of the file at path, looking no
+// further back than maxTailBytes
+func tailFile(path string, n int) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	offset := info.Size() - maxTailBytes
+	if offset < 0 {
+		offset = 0
+	}
+	data, err := io.ReadAll(io.NewSectionReader(f, offset, info.Size()-offset))
+	if err != nil {
+		return nil, err
+	}
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	if offset > 0 {
+		// The first line is likely cut off
+		lines = lines[1:]
+	}
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	if len(lines) == 1 && lines[0] == "" {
+		return nil, nil
+	}
+	return lines, nil
+}
+
+// journalEntry is an entry of journalctl -o json. MESSAGE is an array of
+// bytes rather than a string when it isn't valid UTF-8.
+type journalEntry struct {
+	Timestamp  string          `json:"__REALTIME_TIMESTAMP"` // Microseconds since the epoch
+	PID        string          `json:"_PID"`
+	Priority   string          `json:"PRIORITY"`
+	Identifier string          `json:"SYSLOG_IDENTIFIER"`
+	Message    json.RawMessage `json:"MESSAGE"`
+}
+
+// journalPriorities names the syslog priorities of journal entries
+var journalPriorities = []string{"emerg", "alert", "crit", "err", "warning", "notice", "info", "debug"}
+
+// journalLogs reads the last lines of a unit's journal with journalctl
+func journalLogs(ctx context.Context, name string, opts LogOptions) ([]types.ServiceLogEntry, error) {
+	unitFlag := "--unit"
+	if opts.User {
+		unitFlag = "--user-unit"
+	}
+	args := []string{unitFlag, name, "--lines", strconv.Itoa(opts.Lines), "--output", "json", "--no-pager"}
+	if !opts.Since.IsZero() {
+		args = append(args, "--since", opts.Since.Local().Format("2006-01-02 15:04:05"))
+	}
+	output, err := exec.CommandContext(ctx, "journalctl", args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("journalctl: %w", err)
+	}
+
+	var entries []types.ServiceLogEntry
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	scanner.Buffer(make([]byte, 64*1024), 4<<20)
+	for scanner.Scan() {
+		var e journalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			continue
+		}
+		entry := types.ServiceLogEntry{
+			Process: e.Identifier,
+			Message: journalMessage(e.Message),
+		}
+		if us, err := strconv.ParseInt(e.Timestamp, 10, 64); err == nil {
+			entry.Time = time.UnixMicro(us).Format(time.RFC3339Nano)
+		}
+		if pid, err := strconv.ParseInt(e.PID, 10, 32); err == nil {
+			entry.PID = int32(pid)
+		}
+		if p, err := strconv.Atoi(e.Priority); err == nil && p >= 0 && p < len(journalPriorities) {
+			entry.Level = journalPriorities[p]
+		}
+		entries = append(entries, entry)
+	}
+
+	// journalctl prints nothing for a unit it has never heard of
+	if len(entries) == 0 {
+		if _, err := systemdDetail(ctx, name, opts.User); errors.Is(err, ErrNotFound) {
+			return nil, err
+		}
+	}
+	return entries, nil
+}
+
+// journalMessage decodes a MESSAGE field, a string or an array of bytes
+func journalMessage(raw json.RawMessage) string {
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s
+	}
+	var b []byte
+	var ints []int
+	if json.Unmarshal(raw, &ints) == nil {
+		for _, n := range ints {
+			b = append(b, byte(n))
+		}
+	}
+	return string(b)
+}
+
+// lastEntries returns the last n entries
+func lastEntries(entries []types.ServiceLogEntry, n int) []types.ServiceLogEntry {
+	if len(entries) > n {
+		return entries[len(entries)-n:]
+	}
+	return entries
+}
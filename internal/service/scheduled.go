@@ -0,0 +1,422 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/borankux/gops/internal/utils"
+	"github.com/borankux/gops/pkg/types"
+	"github.com/shirou/gopsutil/v3/host"
+)
+
+// Sources reported in ScheduledJob.Source
+const (
+	SourceCron    = "cron"
+	SourceLaunchd = "launchd"
+	SourceSystemd = "systemd"
+)
+
+// cronSpoolDirs hold the users' crontabs, named after their owners, and
+// are only readable by root
+var cronSpoolDirs = []string{
+	"/var/spool/cron/crontabs", // Debian
+	"/var/spool/cron",          // Red Hat
+	"/var/at/tabs",             // macOS
+}
+
+// GetScheduledJobs returns what runs on a schedule, the next to run first
+// and those whose next run is unknown last: cron entries on macOS and
+// Linux, launchd jobs with StartCalendarInterval or StartInterval on
+// macOS, and systemd timers, including the user's own, on Linux. As root
+// every user's crontab is read; otherwise only the user's own.
+func GetScheduledJobs(ctx context.Context) ([]types.ScheduledJob, error) {
+	var jobs []types.ScheduledJob
+	switch runtime.GOOS {
+	case "darwin":
+		jobs = append(cronJobs(ctx), launchdScheduledJobs(ctx)...)
+	case "linux":
+		// Containers often run cron without systemd, and there is no user
+		// manager without a login session
+		timers, _ := systemdTimers(ctx, false)
+		userTimers, _ := systemdTimers(ctx, true)
+		jobs = append(cronJobs(ctx), timers...)
+		jobs = append(jobs, userTimers...)
+	default:
+		return nil, errors.New("scheduled jobs are not supported on " + runtime.GOOS)
+	}
+
+	sort.SliceStable(jobs, func(i, j int) bool {
+		a, errA := time.Parse(time.RFC3339, jobs[i].NextRun)
+		b, errB := time.Parse(time.RFC3339, jobs[j].NextRun)
+		if (errA == nil) != (errB == nil) {
+			return errA == nil
+		}
+		return a.Before(b)
+	})
+	return jobs, nil
+}
+
+// cronJobs reads /etc/crontab, /etc/cron.d and the users' crontabs
+func cronJobs(ctx context.Context) []types.ScheduledJob {
+	now := time.Now()
+	var jobs []types.ScheduledJob
+	if data, err := os.ReadFile("/etc/crontab"); err == nil {
+		jobs = append(jobs, parseCrontab(string(data), "/etc/crontab", "", now)...)
+	}
+	if entries, err := os.ReadDir("/etc/cron.d"); err == nil {
+		for _, e := range entries {
+			// cron skips files with dots in their names, such as backups
+			if e.IsDir() || strings.ContainsAny(e.Name(), ".~") {
+				continue
+			}
+			path := filepath.Join("/etc/cron.d", e.Name())
+			if data, err := os.ReadFile(path); err == nil {
+				jobs = append(jobs, parseCrontab(string(data), path, "", now)...)
+			}
+		}
+	}
+
+	readSpool := false
+	for _, dir := range cronSpoolDirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			if !e.Type().IsRegular() {
+				continue
+			}
+			path := filepath.Join(dir, e.Name())
+			if data, err := os.ReadFile(path); err == nil {
+				readSpool = true
+				jobs = append(jobs, parseCrontab(string(data), path, e.Name(), now)...)
+			}
+		}
+	}
+	if !readSpool {
+		// crontab -l fails when the user has no crontab
+		if output, err := exec.CommandContext(ctx, "crontab", "-l").Output(); err == nil {
+			jobs = append(jobs, parseCrontab(string(output), "", currentUser(), now)...)
+		}
+	}
+	return jobs
+}
+
+// parseCrontab parses the entries of a crontab. A user's crontab, whose
+// owner is given, has the five time fields, or a nickname such as @daily,
+// and the command; system crontabs name the user to run it as in
+// between. Comments and environment settings are skipped.
+func parseCrontab(data, path, owner string, now time.Time) []types.ScheduledJob {
+	name := owner
+	if path != "" {
+		name = filepath.Base(path)
+	}
+	timeFields := 5
+	var jobs []types.ScheduledJob
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' || isCronVariable(line) {
+			continue
+		}
+		n := timeFields
+		if line[0] == '@' {
+			n = 1
+		}
+		if owner == "" {
+			n++
+		}
+		fields, command := cutFields(line, n)
+		if len(fields) < n || command == "" {
+			continue
+		}
+
+		job := types.ScheduledJob{
+			Source:  SourceCron,
+			Name:    name,
+			Command: command,
+			User:    owner,
+			File:    path,
+		}
+		if owner == "" {
+			job.User = fields[n-1]
+			fields = fields[:n-1]
+		}
+		job.Schedule = strings.Join(fields, " ")
+		schedule, err := parseCron(job.Schedule)
+		if err != nil && !errors.Is(err, errCronReboot) {
+			continue
+		}
+		if err == nil {
+			if next := schedule.next(now); !next.IsZero() {
+				job.NextRun = next.Format(time.RFC3339)
+			}
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs
+}
+
+// isCronVariable reports whether a crontab line sets an environment
+// variable, such as SHELL=/bin/sh
+func isCronVariable(line string) bool {
+	name, _, ok := strings.Cut(line, "=")
+	if !ok {
+		return false
+	}
+	name = strings.TrimSpace(name)
+	return name != "" && strings.IndexFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+	}) < 0
+}
+
+// cutFields splits the first n whitespace-separated fields off line and
+// returns them with the rest of the line as it is
+func cutFields(line string, n int) ([]string, string) {
+	var fields []string
+	rest := line
+	for len(fields) < n {
+		rest = strings.TrimLeftFunc(rest, unicode.IsSpace)
+		if rest == "" {
+			break
+		}
+		end := strings.IndexFunc(rest, unicode.IsSpace)
+		if end < 0 {
+			end = len(rest)
+		}
+		fields = append(fields, rest[:end])
+		rest = rest[end:]
+	}
+	return fields, strings.TrimSpace(rest)
+}
+
+// currentUser returns the name of the user gops runs as
+func currentUser() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return ""
+}
+
+// launchdScheduledJobs reads the property lists of launchd jobs that run
+// at calendar times or at an interval
+func launchdScheduledJobs(ctx context.Context) []types.ScheduledJob {
+	now := time.Now()
+	home, _ := os.UserHomeDir()
+	var jobs []types.ScheduledJob
+	for _, d := range launchdDirs {
+		dir := d.dir
+		if strings.HasPrefix(dir, "~/") {
+			if home == "" {
+				continue
+			}
+			dir = filepath.Join(home, dir[2:])
+		}
+		paths, _ := filepath.Glob(filepath.Join(dir, "*.plist"))
+		for _, path := range paths {
+			// Binary plists spell out their keys too, so most files are
+			// ruled out without converting them
+			data, err := os.ReadFile(path)
+			if err != nil || !bytes.Contains(data, []byte("StartCalendarInterval")) && !bytes.Contains(data, []byte("StartInterval")) {
+				continue
+			}
+			root, err := utils.ReadPlist(ctx, path)
+			if err != nil {
+				continue
+			}
+			plist, _ := root.(map[string]interface{})
+			if job, ok := launchdScheduledJob(plist, path, now); ok {
+				if job.User == "" && strings.HasPrefix(d.dir, "~/") {
+					job.User = currentUser()
+				}
+				jobs = append(jobs, job)
+			}
+		}
+	}
+	return jobs
+}
+
+// launchdScheduledJob describes the schedule of a launchd job, if it has
+// one
+func launchdScheduledJob(plist map[string]interface{}, path string, now time.Time) (types.ScheduledJob, bool) {
+	job := types.ScheduledJob{Source: SourceLaunchd, File: path}
+	job.Name, _ = plist["Label"].(string)
+	if job.Name == "" {
+		job.Name = strings.TrimSuffix(filepath.Base(path), ".plist")
+	}
+	job.User, _ = plist["UserName"].(string)
+	if args, ok := plist["ProgramArguments"].([]interface{}); ok {
+		var argv []string
+		for _, arg := range args {
+			argv = append(argv, fmt.Sprint(arg))
+		}
+		job.Command = strings.Join(argv, " ")
+	} else {
+		job.Command, _ = plist["Program"].(string)
+	}
+
+	// StartCalendarInterval is a dictionary or an array of them
+	var intervals []map[string]interface{}
+	switch v := plist["StartCalendarInterval"].(type) {
+	case map[string]interface{}:
+		intervals = append(intervals, v)
+	case []interface{}:
+		for _, item := range v {
+			if interval, ok := item.(map[string]interface{}); ok {
+				intervals = append(intervals, interval)
+			}
+		}
+	}
+	if len(intervals) > 0 {
+		var specs []string
+		var next time.Time
+		for _, interval := range intervals {
+			spec := calendarCron(interval)
+			specs = append(specs, spec)
+			if schedule, err := parseCron(spec); err == nil {
+				if t := schedule.next(now); !t.IsZero() && (next.IsZero() || t.Before(next)) {
+					next = t
+				}
+			}
+		}
+		job.Schedule = strings.Join(specs, ", ")
+		if !next.IsZero() {
+			job.NextRun = next.Format(time.RFC3339)
+		}
+		return job, true
+	}
+
+	// launchd doesn't say when an interval job last ran
+	if seconds, ok := plist["StartInterval"].(int64); ok && seconds > 0 {
+		job.Schedule = "every " + (time.Duration(seconds) * time.Second).String()
+		return job, true
+	}
+	return job, false
+}
+
+// calendarCron writes a StartCalendarInterval dictionary as a cron
+// expression; missing keys match any value, like * does
+func calendarCron(interval map[string]interface{}) string {
+	field := func(key string) string {
+		if v, ok := interval[key].(int64); ok {
+			return strconv.FormatInt(v, 10)
+		}
+		return "*"
+	}
+	return strings.Join([]string{field("Minute"), field("Hour"), field("Day"), field("Month"), field("Weekday")}, " ")
+}
+
+// systemdTimerProperties are the timer properties systemdTimers reads
+var systemdTimerProperties = []string{
+	"Id", "Unit", "TimersCalendar", "TimersMonotonic",
+	"NextElapseUSecRealtime", "NextElapseUSecMonotonic", "LastTriggerUSec",
+}
+
+// systemdTimestamp is how systemctl show prints times, in local time
+const systemdTimestamp = "Mon 2006-01-02 15:04:05 MST"
+
+// systemdTimers lists the timer units of the system manager, or of the
+// user's manager if user is set, with systemctl
+func systemdTimers(ctx context.Context, user bool) ([]types.ScheduledJob, error) {
+	args := systemctlArgs(user, "list-units", "--type=timer", "--all", "--no-pager", "--no-legend")
+	output, err := exec.CommandContext(ctx, "systemctl", args...).Output()
+	if err != nil {
+		return nil, err
+	}
+	var units []string
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Fields(line)
+		// Failed units are marked with a leading bullet
+		if len(fields) > 0 && (fields[0] == "●" || fields[0] == "*") {
+			fields = fields[1:]
+		}
+		if len(fields) > 0 && strings.HasSuffix(fields[0], ".timer") {
+			units = append(units, fields[0])
+		}
+	}
+	if len(units) == 0 {
+		return nil, nil
+	}
+
+	args = systemctlArgs(user, append([]string{"show", "--property=" + strings.Join(systemdTimerProperties, ",")}, units...)...)
+	output, err = exec.CommandContext(ctx, "systemctl", args...).Output()
+	if err != nil {
+		return nil, err
+	}
+	owner := ""
+	if user {
+		owner = currentUser()
+	}
+	var bootTime time.Time
+	if boot, err := host.BootTimeWithContext(ctx); err == nil {
+		bootTime = time.Unix(int64(boot), 0)
+	}
+
+	// systemctl show separates units with a blank line and repeats list
+	// properties such as TimersCalendar for each item
+	var jobs []types.ScheduledJob
+	for _, block := range strings.Split(string(output), "\n\n") {
+		props := make(map[string][]string)
+		for _, line := range strings.Split(block, "\n") {
+			if key, value, ok := strings.Cut(line, "="); ok {
+				props[key] = append(props[key], value)
+			}
+		}
+		first := func(key string) string {
+			if values := props[key]; len(values) > 0 {
+				return values[0]
+			}
+			return ""
+		}
+		if first("Id") == "" {
+			continue
+		}
+
+		job := types.ScheduledJob{
+			Source:  SourceSystemd,
+			Name:    strings.TrimSuffix(first("Id"), ".timer"),
+			Command: first("Unit"),
+			User:    owner,
+		}
+		var settings []string
+		for _, timer := range append(props["TimersCalendar"], props["TimersMonotonic"]...) {
+			settings = append(settings, timerSetting(timer))
+		}
+		job.Schedule = strings.Join(settings, ", ")
+
+		if t, err := time.ParseInLocation(systemdTimestamp, first("NextElapseUSecRealtime"), time.Local); err == nil {
+			job.NextRun = t.Format(time.RFC3339)
+		} else if span, ok := parseTimespan(first("NextElapseUSecMonotonic")); ok && span > 0 && !bootTime.IsZero() {
+			// Monotonic timers elapse at a time since boot
+			job.NextRun = bootTime.Add(span).Format(time.RFC3339)
+		}
+		if t, err := time.ParseInLocation(systemdTimestamp, first("LastTriggerUSec"), time.Local); err == nil {
+			job.LastRun = t.Format(time.RFC3339)
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
+
+// timerSetting extracts the setting from a TimersCalendar or
+// TimersMonotonic item such as
+//
+//	{ OnCalendar=*-*-* 00:00:00 ; next_elapse=Fri 2025-01-03 00:00:00 UTC }
+func timerSetting(item string) string {
+	item = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(item), "{"))
+	setting, _, _ := strings.Cut(item, " ;")
+	return strings.TrimSpace(setting)
+}
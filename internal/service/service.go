@@ -1,10 +1,16 @@
 package service
 
 import (
+	"bufio"
 	"context"
 	"encoding/json"
+	"fmt"
+	"os"
 	"os/exec"
+	"path"
 	"runtime"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -12,90 +18,342 @@ import (
 	"github.com/borankux/gops/pkg/types"
 )
 
+// Sort keys accepted by ListOptions.SortBy
+const (
+	SortName   = "name"
+	SortStatus = "status"
+	SortPID    = "pid"
+	SortCPU    = "cpu"
+	SortMemory = "memory"
+)
+
+// SortKeys lists the valid service sort keys
+var SortKeys = []string{SortName, SortStatus, SortPID, SortCPU, SortMemory}
+
+// Service states accepted by ListOptions.Status
+const (
+	// StatusRunning services have a process
+	StatusRunning = "running"
+	// StatusStopped services have no process and didn't fail
+	StatusStopped = "stopped"
+	// StatusFailed services have no process and failed: systemd units in
+	// the failed state and launchd jobs whose last exit status isn't zero
+	StatusFailed = "failed"
+)
+
+// Statuses lists the values accepted by ListOptions.Status
+var Statuses = []string{StatusRunning, StatusStopped, StatusFailed}
+
+// Domains lists the values accepted by ListOptions.Domain
+var Domains = []string{DomainSystem, DomainUser}
+
+// ListOptions controls how services are collected
+type ListOptions struct {
+	// SortBy is one of SortKeys; services keep the platform's order if empty
+	SortBy     string
+	Descending bool
+	// SkipUsage leaves CPU and memory unset, which makes listing much
+	// cheaper when only names, states and PIDs are needed
+	SkipUsage bool
+	// Status, when set, keeps services in one of Statuses
+	Status string
+	// Name, when set, keeps services whose name matches this glob, such
+	// as com.apple.*, ignoring case; without wildcards it matches names
+	// containing it
+	Name string
+	// Domain, when set, keeps the system-wide services of DomainSystem,
+	// daemons and Windows services, or the per-user agents of DomainUser
+	Domain string
+}
+
 // GetServices returns a list of system services with resource usage
-func GetServices(ctx context.Context) ([]types.ServiceInfo, error) {
+func GetServices(ctx context.Context, opts ListOptions) ([]types.ServiceInfo, error) {
+	if opts.Status != "" && !slices.Contains(Statuses, opts.Status) {
+		return nil, fmt.Errorf("invalid status: %s", opts.Status)
+	}
+	if opts.Domain != "" && !slices.Contains(Domains, opts.Domain) {
+		return nil, fmt.Errorf("invalid domain: %s", opts.Domain)
+	}
+	pattern := strings.ToLower(opts.Name)
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, fmt.Errorf("invalid name pattern: %s", opts.Name)
+	}
+	if pattern != "" && !strings.ContainsAny(pattern, "*?[") {
+		pattern = "*" + pattern + "*"
+	}
+
+	var services []types.ServiceInfo
+	var err error
+
 	switch runtime.GOOS {
 	case "darwin":
-		return getMacOSServices(ctx)
+		services, err = getMacOSServices(ctx)
 	case "linux":
-		return getLinuxServices(ctx)
+		services, err = getLinuxServices(ctx)
 	case "windows":
-		return getWindowsServices(ctx)
+		services, err = getWindowsServices(ctx)
 	default:
 		return nil, nil
 	}
-}
-
-// getMacOSServices gets services on macOS using launchctl
-func getMacOSServices(ctx context.Context) ([]types.ServiceInfo, error) {
-	cmd := exec.CommandContext(ctx, "launchctl", "list")
-	output, err := cmd.Output()
 	if err != nil {
 		return nil, err
 	}
 
-	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
-	var services []types.ServiceInfo
+	// Filtering first spares reading the usage of services left out
+	kept := services[:0]
+	for _, svc := range services {
+		if matchService(svc, opts.Status, pattern, opts.Domain) {
+			kept = append(kept, svc)
+		}
+	}
+	services = kept
+	if !opts.SkipUsage {
+		for i := range services {
+			if services[i].PID > 0 {
+				addUsage(ctx, &services[i])
+			}
+		}
+	}
 
-	// Skip header line
-	for i := 1; i < len(lines); i++ {
-		line := strings.TrimSpace(lines[i])
-		if line == "" {
-			continue
+	if err := sortServices(services, opts); err != nil {
+		return nil, err
+	}
+
+	return services, nil
+}
+
+// matchService reports whether svc is in status and domain and its name
+// matches the lower-case glob pattern; empty criteria match any service
+func matchService(svc types.ServiceInfo, status, pattern, domain string) bool {
+	switch status {
+	case StatusRunning:
+		if svc.PID == 0 {
+			return false
+		}
+	case StatusStopped:
+		if svc.PID != 0 || Failed(svc.Status) {
+			return false
+		}
+	case StatusFailed:
+		if svc.PID != 0 || !Failed(svc.Status) {
+			return false
+		}
+	}
+	switch domain {
+	case DomainSystem:
+		if svc.Scope == ScopeAgent {
+			return false
+		}
+	case DomainUser:
+		if svc.Scope != ScopeAgent {
+			return false
 		}
+	}
+	if pattern != "" {
+		if ok, _ := path.Match(pattern, strings.ToLower(svc.Name)); !ok {
+			return false
+		}
+	}
+	return true
+}
 
-		fields := strings.Fields(line)
-		if len(fields) < 3 {
-			continue
+// Failed reports whether a service status denotes a failure: systemd's
+// "failed" state or a non-zero launchd exit status
+func Failed(status string) bool {
+	if status == "failed" {
+		return true
+	}
+	code, err := strconv.Atoi(status)
+	return err == nil && code != 0
+}
+
+// sortServices orders services by the key in opts
+func sortServices(services []types.ServiceInfo, opts ListOptions) error {
+	var less func(a, b types.ServiceInfo) bool
+	switch opts.SortBy {
+	case "":
+		return nil
+	case SortName:
+		less = func(a, b types.ServiceInfo) bool { return strings.ToLower(a.Name) < strings.ToLower(b.Name) }
+	case SortStatus:
+		less = func(a, b types.ServiceInfo) bool { return a.Status < b.Status }
+	case SortPID:
+		less = func(a, b types.ServiceInfo) bool { return a.PID < b.PID }
+	case SortCPU:
+		less = func(a, b types.ServiceInfo) bool { return a.CPUPercent < b.CPUPercent }
+	case SortMemory:
+		less = func(a, b types.ServiceInfo) bool { return a.MemoryPercent < b.MemoryPercent }
+	default:
+		return fmt.Errorf("invalid sort key: %s", opts.SortBy)
+	}
+
+	sort.SliceStable(services, func(i, j int) bool {
+		if opts.Descending {
+			return less(services[j], services[i])
 		}
+		return less(services[i], services[j])
+	})
+	return nil
+}
+
+// Service scopes reported in ServiceInfo.Scope
+const (
+	// ScopeDaemon services run system-wide, usually as root
+	ScopeDaemon = "daemon"
+	// ScopeAgent services run on behalf of a logged-in user
+	ScopeAgent = "agent"
+)
 
-		pidStr := fields[0]
-		status := fields[1]
-		name := strings.Join(fields[2:], " ")
+// Service domains reported in ServiceInfo.Domain besides launchd's
+// per-user user/<uid> and gui/<uid> domains
+const (
+	DomainSystem = "system"
+	// DomainUser holds systemd user units
+	DomainUser = "user"
+)
+
+// getMacOSServices gets the jobs of the launchd system domain and of the
+// user's user/<uid> and gui/<uid> domains, the latter holding the agents
+// of their login session, using launchctl print
+func getMacOSServices(ctx context.Context) ([]types.ServiceInfo, error) {
+	domains := []string{DomainSystem}
+	if uid := launchdUser(ctx); uid != "" {
+		domains = append(domains, "user/"+uid, "gui/"+uid)
+	}
 
-		// Skip if pid is 0 or -1 (not running)
-		pid, err := strconv.ParseInt(pidStr, 10, 32)
-		if err != nil || pid <= 0 {
-			services = append(services, types.ServiceInfo{
-				Name:   name,
-				Status: status,
-			})
+	var services []types.ServiceInfo
+	var firstErr error
+	for _, domain := range domains {
+		output, err := exec.CommandContext(ctx, "launchctl", "print", domain).Output()
+		if err != nil {
+			// The gui domain only exists while the user is logged in
+			if firstErr == nil && domain == DomainSystem {
+				firstErr = err
+			}
 			continue
 		}
+		scope := ScopeAgent
+		if domain == DomainSystem {
+			scope = ScopeDaemon
+		}
+		disabled := launchdDisabled(ctx, domain)
+		for _, svc := range parseLaunchdServices(string(output)) {
+			svc.Domain = domain
+			svc.Scope = scope
+			svc.Enablement = Enabled
+			if disabled[svc.Name] {
+				svc.Enablement = Disabled
+			}
+			services = append(services, svc)
+		}
+	}
+	if len(services) == 0 && firstErr != nil {
+		return nil, firstErr
+	}
+	return services, nil
+}
 
-		// Get resource usage
-		usage, err := resource.GetProcessResourceUsage(ctx, int32(pid))
-		if err != nil {
-			services = append(services, types.ServiceInfo{
-				Name:   name,
-				Status: status,
-				PID:    int32(pid),
-			})
+// launchdUser returns the uid whose per-user domains are listed: the
+// user gops runs as or, for root, the user logged in at the console
+func launchdUser(ctx context.Context) string {
+	if uid := os.Getuid(); uid != 0 {
+		return strconv.Itoa(uid)
+	}
+	output, err := exec.CommandContext(ctx, "stat", "-f", "%u", "/dev/console").Output()
+	if err != nil {
+		return ""
+	}
+	// Nobody is logged in when the console belongs to root
+	if uid := strings.TrimSpace(string(output)); uid != "0" {
+		return uid
+	}
+	return ""
+}
+
+// parseLaunchdServices parses the services section of launchctl print
+// for a domain, listing each job's PID (0 if not running), last exit
+// status ("-" if it never exited) and label:
+//
+//	services = {
+//		     374      -	com.apple.coreservicesd
+//		       0     78	com.example.job
+//	}
+func parseLaunchdServices(output string) []types.ServiceInfo {
+	var services []types.ServiceInfo
+	inServices := false
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "services = {":
+			inServices = true
 			continue
+		case !inServices:
+			continue
+		case line == "}":
+			return services
 		}
 
+		fields := strings.Fields(line)
+		if len(fields) < 3 {
+			continue
+		}
+		pid, _ := strconv.ParseInt(fields[0], 10, 32)
+		if pid < 0 {
+			pid = 0
+		}
 		services = append(services, types.ServiceInfo{
-			Name:          name,
-			Status:        status,
-			PID:           int32(pid),
-			CPUPercent:    usage.CPUPercent,
-			MemoryPercent: usage.MemoryPercent,
-			MemoryHuman:   usage.MemoryHuman,
-			CPUHuman:      usage.CPUHuman,
+			Name:   strings.Join(fields[2:], " "),
+			Status: fields[1],
+			PID:    int32(pid),
 		})
 	}
+	return services
+}
 
-	return services, nil
+// addUsage sets the CPU and memory usage of a running service
+func addUsage(ctx context.Context, svc *types.ServiceInfo) {
+	usage, err := resource.GetProcessResourceUsage(ctx, svc.PID)
+	if err != nil {
+		return
+	}
+	svc.CPUPercent = usage.CPUPercent
+	svc.MemoryPercent = usage.MemoryPercent
+	svc.MemoryHuman = usage.MemoryHuman
+	svc.CPUHuman = usage.CPUHuman
 }
 
-// getLinuxServices gets services on Linux using systemctl
+// getLinuxServices gets the system's systemd services and, when a user
+// service manager is reachable, the user's own units, using systemctl
 func getLinuxServices(ctx context.Context) ([]types.ServiceInfo, error) {
-	cmd := exec.CommandContext(ctx, "systemctl", "list-units", "--type=service", "--no-pager", "--no-legend")
+	services, err := getSystemdUnits(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+	// There is no user manager without a login session, e.g. for root
+	// under sudo
+	if userUnits, err := getSystemdUnits(ctx, true); err == nil {
+		services = append(services, userUnits...)
+	}
+	return services, nil
+}
+
+// getSystemdUnits lists the loaded service units of the system manager,
+// or of the user's manager if user is set
+func getSystemdUnits(ctx context.Context, user bool) ([]types.ServiceInfo, error) {
+	args := systemctlArgs(user, "list-units", "--type=service", "--no-pager", "--no-legend")
+	cmd := exec.CommandContext(ctx, "systemctl", args...)
 	output, err := cmd.Output()
 	if err != nil {
 		return nil, err
 	}
 
+	domain, scope := DomainSystem, ScopeDaemon
+	if user {
+		domain, scope = DomainUser, ScopeAgent
+	}
+	unitFiles := systemdUnitFiles(ctx, user)
+
 	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
 	var services []types.ServiceInfo
 
@@ -106,61 +364,72 @@ func getLinuxServices(ctx context.Context) ([]types.ServiceInfo, error) {
 		}
 
 		fields := strings.Fields(line)
+		// Failed units are marked with a leading bullet
+		if fields[0] == "●" || fields[0] == "*" {
+			fields = fields[1:]
+		}
 		if len(fields) < 4 {
 			continue
 		}
 
-		name := strings.ReplaceAll(fields[0], ".service", "")
-		status := fields[2] // loaded, active, etc.
-
-		// Try to get PID from systemctl show
-		pidCmd := exec.CommandContext(ctx, "systemctl", "show", "--property=MainPID", "--value", fields[0])
-		pidOutput, err := pidCmd.Output()
-		var pid int32
-		if err == nil {
-			pidStr := strings.TrimSpace(string(pidOutput))
-			if pidStr != "0" && pidStr != "" {
-				if p, err := strconv.ParseInt(pidStr, 10, 32); err == nil && p > 0 {
-					pid = int32(p)
-
-					// Get resource usage
-					usage, err := resource.GetProcessResourceUsage(ctx, pid)
-					if err == nil {
-						services = append(services, types.ServiceInfo{
-							Name:          name,
-							Status:        status,
-							PID:           pid,
-							CPUPercent:    usage.CPUPercent,
-							MemoryPercent: usage.MemoryPercent,
-							MemoryHuman:   usage.MemoryHuman,
-							CPUHuman:      usage.CPUHuman,
-						})
-						continue
-					}
-				}
-			}
+		svc := types.ServiceInfo{
+			Name:       strings.TrimSuffix(fields[0], ".service"),
+			Status:     fields[2], // active, failed, etc.
+			Domain:     domain,
+			Scope:      scope,
+			Enablement: unitFileState(unitFiles, fields[0]),
+			PID:        systemdPID(ctx, fields[0], user),
 		}
-
-		services = append(services, types.ServiceInfo{
-			Name:   name,
-			Status: status,
-			PID:    pid,
-		})
+		services = append(services, svc)
 	}
 
 	return services, nil
 }
 
-// getWindowsServices gets services on Windows
+// systemdUnitFiles returns the state of each service unit file, such as
+// enabled, disabled or static, from systemctl list-unit-files
+func systemdUnitFiles(ctx context.Context, user bool) map[string]string {
+	states := make(map[string]string)
+	args := systemctlArgs(user, "list-unit-files", "--type=service", "--no-pager", "--no-legend")
+	output, err := exec.CommandContext(ctx, "systemctl", args...).Output()
+	if err != nil {
+		return states
+	}
+	for _, line := range strings.Split(string(output), "\n") {
+		if fields := strings.Fields(line); len(fields) >= 2 {
+			states[fields[0]] = fields[1]
+		}
+	}
+	return states
+}
+
+// unitFileState returns the unit file state of unit, looking instances
+// such as getty@tty1.service up by their template getty@.service
+func unitFileState(states map[string]string, unit string) string {
+	if state, ok := states[unit]; ok {
+		return state
+	}
+	if prefix, _, ok := strings.Cut(unit, "@"); ok {
+		return states[prefix+"@.service"]
+	}
+	return ""
+}
+
+// getWindowsServices gets services on Windows with their start type,
+// reading PIDs from Win32_Service
 func getWindowsServices(ctx context.Context) ([]types.ServiceInfo, error) {
 	psScript := `
+		$pids = @{}
+		Get-CimInstance Win32_Service -ErrorAction SilentlyContinue | ForEach-Object { $pids[$_.Name] = $_.ProcessId }
 		Get-Service | ForEach-Object {
-			$pid = (Get-WmiObject Win32_Service -Filter "Name='$($_.Name)'" -ErrorAction SilentlyContinue).ProcessId
-			if ($pid -eq $null) { $pid = 0 }
+			# $PID is taken by PowerShell for its own process ID
+			$processId = $pids[$_.Name]
+			if ($processId -eq $null) { $processId = 0 }
 			[PSCustomObject]@{
 				Name = $_.Name
 				Status = $_.Status.ToString()
-				PID = $pid
+				StartType = $_.StartType.ToString()
+				PID = $processId
 			}
 		} | ConvertTo-Json -Compress
 	`
@@ -173,51 +442,47 @@ func getWindowsServices(ctx context.Context) ([]types.ServiceInfo, error) {
 
 	var services []types.ServiceInfo
 
-	// Parse JSON output
-	var serviceObjs []struct {
-		Name   string `json:"Name"`
-		Status string `json:"Status"`
-		PID    int    `json:"PID"`
+	type serviceObj struct {
+		Name      string `json:"Name"`
+		Status    string `json:"Status"`
+		StartType string `json:"StartType"`
+		PID       int    `json:"PID"`
 	}
 
+	// Parse JSON output
+	var serviceObjs []serviceObj
 	if err := json.Unmarshal(output, &serviceObjs); err != nil {
 		// If array parsing fails, try single object
-		var serviceObj struct {
-			Name   string `json:"Name"`
-			Status string `json:"Status"`
-			PID    int    `json:"PID"`
-		}
-		if err2 := json.Unmarshal(output, &serviceObj); err2 == nil {
-			serviceObjs = []struct {
-				Name   string `json:"Name"`
-				Status string `json:"Status"`
-				PID    int    `json:"PID"`
-			}{serviceObj}
-		} else {
+		var single serviceObj
+		if err2 := json.Unmarshal(output, &single); err2 != nil {
 			return nil, err
 		}
+		serviceObjs = []serviceObj{single}
 	}
 
 	for _, s := range serviceObjs {
-		serviceInfo := types.ServiceInfo{
-			Name:   s.Name,
-			Status: strings.ToLower(s.Status),
-			PID:    int32(s.PID),
-		}
-
-		// Get resource usage if PID is available
-		if s.PID > 0 {
-			usage, err := resource.GetProcessResourceUsage(ctx, int32(s.PID))
-			if err == nil {
-				serviceInfo.CPUPercent = usage.CPUPercent
-				serviceInfo.MemoryPercent = usage.MemoryPercent
-				serviceInfo.MemoryHuman = usage.MemoryHuman
-				serviceInfo.CPUHuman = usage.CPUHuman
-			}
-		}
-
-		services = append(services, serviceInfo)
+		services = append(services, types.ServiceInfo{
+			Name:       s.Name,
+			Status:     strings.ToLower(s.Status),
+			Enablement: strings.ToLower(s.StartType),
+			PID:        int32(s.PID),
+		})
 	}
 
 	return services, nil
 }
+
+// Ready verifies that the service manager for this platform responds
+func Ready(ctx context.Context) error {
+	switch runtime.GOOS {
+	case "darwin":
+		return exec.CommandContext(ctx, "launchctl", "managerpid").Run()
+	case "linux":
+		return exec.CommandContext(ctx, "systemctl", "show", "--property=Version").Run()
+	case "windows":
+		_, err := exec.LookPath("powershell")
+		return err
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,121 @@
+package system
+
+import (
+	"context"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/borankux/gops/internal/utils"
+	"github.com/borankux/gops/pkg/types"
+	"github.com/shirou/gopsutil/v3/cpu"
+	"github.com/shirou/gopsutil/v3/disk"
+	"github.com/shirou/gopsutil/v3/host"
+	"github.com/shirou/gopsutil/v3/load"
+	"github.com/shirou/gopsutil/v3/mem"
+)
+
+// cpuSampleInterval is how long GetMetrics measures CPU usage over
+const cpuSampleInterval = 500 * time.Millisecond
+
+// GetMetrics returns how the machine is doing overall: CPU usage over a
+// short sample, load averages, memory, swap, disk usage per mount and
+// uptime. Load averages are left out where the OS has none (Windows),
+// and mounts whose usage can't be read are skipped.
+func GetMetrics(ctx context.Context) (*types.SystemMetricsResponse, error) {
+	info, err := host.InfoWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	vm, err := mem.VirtualMemoryWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	percent, err := cpu.PercentWithContext(ctx, cpuSampleInterval, false)
+	if err != nil {
+		return nil, err
+	}
+
+	metrics := &types.SystemMetricsResponse{
+		Hostname: info.Hostname,
+		CPUs:     runtime.NumCPU(),
+		Memory: types.MemoryStats{
+			Total:       vm.Total,
+			Used:        vm.Used,
+			Free:        vm.Free,
+			Available:   vm.Available,
+			Cached:      vm.Cached,
+			UsedPercent: vm.UsedPercent,
+		},
+		Disks:       diskUsage(ctx),
+		BootTime:    time.Unix(int64(info.BootTime), 0).Format(time.RFC3339),
+		Uptime:      info.Uptime,
+		UptimeHuman: utils.FormatDuration(info.Uptime),
+	}
+	if len(percent) > 0 {
+		metrics.CPUPercent = percent[0]
+	}
+	if runtime.GOOS != "windows" {
+		if avg, err := load.AvgWithContext(ctx); err == nil {
+			metrics.Load = &types.LoadAverage{Load1: avg.Load1, Load5: avg.Load5, Load15: avg.Load15}
+		}
+	}
+	if swap, err := mem.SwapMemoryWithContext(ctx); err == nil {
+		metrics.Swap = types.SwapStats{
+			Total:       swap.Total,
+			Used:        swap.Used,
+			Free:        swap.Free,
+			UsedPercent: swap.UsedPercent,
+		}
+	}
+	return metrics, nil
+}
+
+// diskUsage returns the usage of each mounted disk, sorted by mount
+// point. Read-only images that are always full, such as snap packages,
+// and the macOS system volumes other than the data volume are left out.
+func diskUsage(ctx context.Context) []types.DiskUsage {
+	disks := []types.DiskUsage{}
+	partitions, err := disk.PartitionsWithContext(ctx, false)
+	if err != nil {
+		return disks
+	}
+
+	seen := make(map[string]bool)
+	for _, p := range partitions {
+		if seen[p.Mountpoint] || skipMount(p) {
+			continue
+		}
+		seen[p.Mountpoint] = true
+
+		usage, err := disk.UsageWithContext(ctx, p.Mountpoint)
+		if err != nil || usage.Total == 0 {
+			continue
+		}
+		disks = append(disks, types.DiskUsage{
+			Mount:       p.Mountpoint,
+			Device:      p.Device,
+			FSType:      p.Fstype,
+			Total:       usage.Total,
+			Used:        usage.Used,
+			Free:        usage.Free,
+			UsedPercent: usage.UsedPercent,
+		})
+	}
+	sort.Slice(disks, func(i, j int) bool {
+		return disks[i].Mount < disks[j].Mount
+	})
+	return disks
+}
+
+// skipMount reports whether a partition is left out of the disk usage
+func skipMount(p disk.PartitionStat) bool {
+	switch {
+	case p.Fstype == "squashfs":
+		return true
+	case runtime.GOOS == "darwin" && strings.HasPrefix(p.Mountpoint, "/System/Volumes/"):
+		return p.Mountpoint != "/System/Volumes/Data"
+	}
+	return false
+}
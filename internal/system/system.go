@@ -0,0 +1,33 @@
+package system
+
+import (
+	"context"
+	"runtime"
+	"time"
+
+	"github.com/borankux/gops/internal/utils"
+	"github.com/borankux/gops/pkg/types"
+	"github.com/shirou/gopsutil/v3/host"
+)
+
+// GetSystemInfo returns a snapshot of host identity and uptime
+func GetSystemInfo(ctx context.Context) (*types.SystemInfo, error) {
+	info, err := host.InfoWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.SystemInfo{
+		Hostname:        info.Hostname,
+		OS:              info.OS,
+		Platform:        info.Platform,
+		PlatformVersion: info.PlatformVersion,
+		KernelVersion:   info.KernelVersion,
+		Arch:            info.KernelArch,
+		CPUs:            runtime.NumCPU(),
+		Processes:       info.Procs,
+		BootTime:        time.Unix(int64(info.BootTime), 0).Format(time.RFC3339),
+		Uptime:          info.Uptime,
+		UptimeHuman:     utils.FormatDuration(info.Uptime),
+	}, nil
+}
@@ -0,0 +1,112 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// ReadPlist reads and decodes the property list at path. Binary plists
+// are converted to XML with plutil first, so those need macOS.
+func ReadPlist(ctx context.Context, path string) (interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.HasPrefix(data, []byte("bplist")) {
+		data, err = exec.CommandContext(ctx, "plutil", "-convert", "xml1", "-o", "-", path).Output()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return DecodePlist(data)
+}
+
+// DecodePlist decodes an XML property list into maps, slices, strings,
+// booleans and numbers
+func DecodePlist(data []byte) (interface{}, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	dec.Strict = false
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		if el, ok := tok.(xml.StartElement); ok && el.Name.Local != "plist" {
+			return plistValue(dec, el)
+		}
+	}
+}
+
+// plistValue decodes the value starting at el
+func plistValue(dec *xml.Decoder, el xml.StartElement) (interface{}, error) {
+	switch el.Name.Local {
+	case "dict":
+		dict := make(map[string]interface{})
+		var key string
+		for {
+			tok, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			switch t := tok.(type) {
+			case xml.EndElement:
+				return dict, nil
+			case xml.StartElement:
+				if t.Name.Local == "key" {
+					if err := dec.DecodeElement(&key, &t); err != nil {
+						return nil, err
+					}
+					continue
+				}
+				value, err := plistValue(dec, t)
+				if err != nil {
+					return nil, err
+				}
+				dict[key] = value
+			}
+		}
+	case "array":
+		array := []interface{}{}
+		for {
+			tok, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			switch t := tok.(type) {
+			case xml.EndElement:
+				return array, nil
+			case xml.StartElement:
+				value, err := plistValue(dec, t)
+				if err != nil {
+					return nil, err
+				}
+				array = append(array, value)
+			}
+		}
+	case "true", "false":
+		return el.Name.Local == "true", dec.Skip()
+	}
+
+	var text string
+	if err := dec.DecodeElement(&text, &el); err != nil {
+		return nil, err
+	}
+	text = strings.TrimSpace(text)
+	switch el.Name.Local {
+	case "integer":
+		if n, err := strconv.ParseInt(text, 10, 64); err == nil {
+			return n, nil
+		}
+	case "real":
+		if f, err := strconv.ParseFloat(text, 64); err == nil {
+			return f, nil
+		}
+	}
+	// string, date and base64 data are kept as text
+	return text, nil
+}
@@ -0,0 +1,15 @@
+package utils
+
+// Descending resolves an order parameter ("asc", "desc" or empty) for the
+// given sort key. Usage metrics and uptime default to descending so the
+// heaviest consumers and longest-running processes come first; everything
+// else defaults to ascending.
+func Descending(sortBy string, order string) bool {
+	switch order {
+	case "desc":
+		return true
+	case "asc":
+		return false
+	}
+	return sortBy == "cpu" || sortBy == "memory" || sortBy == "uptime"
+}
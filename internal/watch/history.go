@@ -0,0 +1,149 @@
+package watch
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/borankux/gops/pkg/types"
+)
+
+const (
+	// DefaultTitleLimit is how many titles TitleHistory keeps per window
+	DefaultTitleLimit = 100
+
+	// maxTitleWindows bounds how many windows TitleHistory tracks; the
+	// windows retitled longest ago are forgotten first
+	maxTitleWindows = 500
+)
+
+// TitleHistory records the titles each window has had while the watcher
+// is running, so progress shown in a title bar can be followed after the
+// fact. It is safe for concurrent use.
+type TitleHistory struct {
+	mu      sync.Mutex
+	limit   int
+	started time.Time
+	windows map[string]*types.WindowTitleHistory
+}
+
+// NewTitleHistory creates a history keeping up to limit titles per
+// window, or DefaultTitleLimit if limit is zero
+func NewTitleHistory(limit int) *TitleHistory {
+	if limit <= 0 {
+		limit = DefaultTitleLimit
+	}
+	return &TitleHistory{
+		limit:   limit,
+		windows: make(map[string]*types.WindowTitleHistory),
+	}
+}
+
+// Record notes the title of win at the given time, unless it is the
+// title last recorded for the window
+func (h *TitleHistory) Record(win types.WindowInfo, at time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.started.IsZero() {
+		h.started = at
+	}
+	key := windowKey(win)
+	entry, exists := h.windows[key]
+	if !exists {
+		if len(h.windows) >= maxTitleWindows {
+			h.evictOldest()
+		}
+		entry = &types.WindowTitleHistory{ID: win.ID, PID: win.PID, Process: win.Process}
+		h.windows[key] = entry
+	}
+	entry.Closed = false
+	if entry.Title == win.Title {
+		return
+	}
+	entry.Title = win.Title
+	entry.Titles = append(entry.Titles, types.TitleChange{
+		Title: win.Title,
+		Time:  at.Format(time.RFC3339),
+	})
+	if len(entry.Titles) > h.limit {
+		entry.Titles = entry.Titles[len(entry.Titles)-h.limit:]
+	}
+}
+
+// Closed marks win as closed, keeping its history
+func (h *TitleHistory) Closed(win types.WindowInfo) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if entry, exists := h.windows[windowKey(win)]; exists {
+		entry.Closed = true
+	}
+}
+
+// Started returns when the first title was recorded, the zero time if
+// none has been
+func (h *TitleHistory) Started() time.Time {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.started
+}
+
+// evictOldest forgets the window whose title changed longest ago
+func (h *TitleHistory) evictOldest() {
+	var oldestKey, oldest string
+	for key, entry := range h.windows {
+		last := entry.Titles[len(entry.Titles)-1].Time
+		if oldestKey == "" || last < oldest {
+			oldestKey, oldest = key, last
+		}
+	}
+	delete(h.windows, oldestKey)
+}
+
+// TitleQuery selects windows and titles from a TitleHistory. Zero fields
+// are ignored.
+type TitleQuery struct {
+	ID  uint32
+	PID int32
+	// Title keeps windows that had a title containing it, ignoring case
+	Title string
+	// Since drops titles recorded before it, and windows left with none
+	Since time.Time
+}
+
+// Query returns the history of the windows matching q, the most recently
+// retitled first
+func (h *TitleHistory) Query(q TitleQuery) []types.WindowTitleHistory {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	title := strings.ToLower(q.Title)
+	var result []types.WindowTitleHistory
+	for _, entry := range h.windows {
+		if (q.ID != 0 && entry.ID != q.ID) || (q.PID != 0 && entry.PID != q.PID) {
+			continue
+		}
+		var titles []types.TitleChange
+		matched := title == ""
+		for _, change := range entry.Titles {
+			if !q.Since.IsZero() {
+				if at, err := time.Parse(time.RFC3339, change.Time); err == nil && at.Before(q.Since) {
+					continue
+				}
+			}
+			titles = append(titles, change)
+			matched = matched || strings.Contains(strings.ToLower(change.Title), title)
+		}
+		if len(titles) == 0 || !matched {
+			continue
+		}
+		w := *entry
+		w.Titles = titles
+		result = append(result, w)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Titles[len(result[i].Titles)-1].Time > result[j].Titles[len(result[j].Titles)-1].Time
+	})
+	return result
+}
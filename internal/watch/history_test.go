@@ -0,0 +1,92 @@
+package watch
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/borankux/gops/pkg/types"
+)
+
+func TestTitleHistoryRecord(t *testing.T) {
+	h := NewTitleHistory(3)
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	win := types.WindowInfo{ID: 7, PID: 42, Process: "make"}
+
+	titles := []string{"build 10%", "build 10%", "build 40%", "build 70%", "build 90%", "done"}
+	for i, title := range titles {
+		win.Title = title
+		h.Record(win, start.Add(time.Duration(i)*time.Second))
+	}
+
+	got := h.Query(TitleQuery{})
+	if len(got) != 1 {
+		t.Fatalf("%d windows recorded, want 1", len(got))
+	}
+	want := []string{"build 70%", "build 90%", "done"}
+	if len(got[0].Titles) != len(want) {
+		t.Fatalf("titles = %v, want %v", got[0].Titles, want)
+	}
+	for i, change := range got[0].Titles {
+		if change.Title != want[i] {
+			t.Errorf("title %d = %q, want %q", i, change.Title, want[i])
+		}
+	}
+	if !h.Started().Equal(start) {
+		t.Errorf("Started = %s, want %s", h.Started(), start)
+	}
+}
+
+func TestTitleHistoryQuery(t *testing.T) {
+	h := NewTitleHistory(0)
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	h.Record(types.WindowInfo{ID: 1, PID: 10, Title: "Downloading 1%"}, start)
+	h.Record(types.WindowInfo{ID: 1, PID: 10, Title: "Downloading 99%"}, start.Add(time.Minute))
+	h.Record(types.WindowInfo{ID: 2, PID: 20, Title: "Editor"}, start.Add(2*time.Minute))
+
+	tests := []struct {
+		name  string
+		query TitleQuery
+		want  []uint32
+	}{
+		{"all, newest first", TitleQuery{}, []uint32{2, 1}},
+		{"by id", TitleQuery{ID: 1}, []uint32{1}},
+		{"by pid", TitleQuery{PID: 20}, []uint32{2}},
+		{"by title, any case", TitleQuery{Title: "download"}, []uint32{1}},
+		{"since", TitleQuery{Since: start.Add(90 * time.Second)}, []uint32{2}},
+		{"nothing", TitleQuery{Title: "missing"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := h.Query(tt.query)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d windows, want %v", len(got), tt.want)
+			}
+			for i, w := range got {
+				if w.ID != tt.want[i] {
+					t.Errorf("window %d = %d, want %d", i, w.ID, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestTitleHistoryEviction(t *testing.T) {
+	h := NewTitleHistory(0)
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i <= maxTitleWindows; i++ {
+		win := types.WindowInfo{ID: uint32(i + 1), Title: fmt.Sprintf("window %d", i)}
+		h.Record(win, start.Add(time.Duration(i)*time.Second))
+	}
+
+	got := h.Query(TitleQuery{})
+	if len(got) != maxTitleWindows {
+		t.Fatalf("%d windows kept, want %d", len(got), maxTitleWindows)
+	}
+	if len(h.Query(TitleQuery{ID: 1})) != 0 {
+		t.Error("the window retitled longest ago was kept")
+	}
+	if len(h.Query(TitleQuery{ID: maxTitleWindows + 1})) != 1 {
+		t.Error("the newest window was evicted")
+	}
+}
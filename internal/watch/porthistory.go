@@ -0,0 +1,280 @@
+package watch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/borankux/gops/pkg/types"
+)
+
+const (
+	// DefaultPortRetention is how long PortHistory keeps closed listeners
+	DefaultPortRetention = 7 * 24 * time.Hour
+
+	// portSaveInterval is how often PortHistory writes its file while
+	// nothing opens or closes, to keep last_seen of open listeners fresh
+	portSaveInterval = time.Minute
+
+	// maxPortSpans bounds how many closed listeners PortHistory keeps
+	// within the retention; the oldest are forgotten first
+	maxPortSpans = 50000
+)
+
+// DefaultPortHistoryFile returns where the port history is saved unless
+// configured otherwise: gops/port-history.json in the user's
+// configuration directory
+func DefaultPortHistoryFile() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "gops", "port-history.json"), nil
+}
+
+// PortHistory records when each listening port was open and which
+// process held it, so past listeners can be looked up by time. It is
+// persisted to a JSON file when it has a path. It is safe for concurrent
+// use.
+type PortHistory struct {
+	mu        sync.Mutex
+	path      string
+	retention time.Duration
+	started   time.Time
+	lastSave  time.Time
+	dirty     bool
+
+	closed []types.PortSpan
+	open   map[string]*types.PortSpan
+}
+
+// portHistoryFile is the on-disk form of a PortHistory
+type portHistoryFile struct {
+	Spans []types.PortSpan `json:"spans"`
+}
+
+// NewPortHistory creates a history keeping closed listeners for
+// retention, or DefaultPortRetention if it is zero, and saving to path
+// unless it is empty. Spans saved by an earlier run are loaded; those
+// still open then are closed at the time they were last seen.
+func NewPortHistory(path string, retention time.Duration) (*PortHistory, error) {
+	if retention <= 0 {
+		retention = DefaultPortRetention
+	}
+	h := &PortHistory{
+		path:      path,
+		retention: retention,
+		open:      make(map[string]*types.PortSpan),
+	}
+	if path == "" {
+		return h, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return h, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var file portHistoryFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	for _, span := range file.Spans {
+		if span.Closed == "" {
+			span.Closed = span.LastSeen
+		}
+		h.closed = append(h.closed, span)
+	}
+	h.prune(time.Now())
+	return h, nil
+}
+
+// Record notes the listening ports seen at the given time: listeners not
+// seen before are opened, and those no longer seen are closed
+func (h *PortHistory) Record(ports []types.PortInfo, at time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.started.IsZero() {
+		h.started = at
+	}
+	// UTC keeps the stamps ordered as strings across time zone changes
+	stamp := at.UTC().Format(time.RFC3339)
+	seen := make(map[string]bool, len(ports))
+	for _, p := range ports {
+		key := spanKey(p)
+		seen[key] = true
+		if span, exists := h.open[key]; exists {
+			span.LastSeen = stamp
+			continue
+		}
+		h.open[key] = &types.PortSpan{
+			Port:          p.Port,
+			Protocol:      p.Protocol,
+			Family:        p.Family,
+			LocalIP:       p.LocalIP,
+			Exposure:      p.Exposure,
+			PID:           p.PID,
+			Name:          p.Name,
+			Path:          p.Path,
+			ContainerName: p.ContainerName,
+			Opened:        stamp,
+			LastSeen:      stamp,
+		}
+		h.dirty = true
+	}
+	for key, span := range h.open {
+		if seen[key] {
+			continue
+		}
+		span.Closed = stamp
+		h.closed = append(h.closed, *span)
+		delete(h.open, key)
+		h.dirty = true
+	}
+	h.prune(at)
+
+	if h.path != "" && (h.dirty || at.Sub(h.lastSave) >= portSaveInterval) {
+		// A failed save is retried on the next poll
+		if err := h.save(); err == nil {
+			h.lastSave, h.dirty = at, false
+		}
+	}
+}
+
+// Save writes the history to its file, if it has one
+func (h *PortHistory) Save() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.path == "" {
+		return nil
+	}
+	return h.save()
+}
+
+// save writes every span to a temporary file and renames it over the
+// history file, so a crash never leaves it half written
+func (h *PortHistory) save() error {
+	spans := make([]types.PortSpan, 0, len(h.closed)+len(h.open))
+	spans = append(spans, h.closed...)
+	for _, span := range h.open {
+		spans = append(spans, *span)
+	}
+	data, err := json.Marshal(portHistoryFile{Spans: spans})
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(h.path), 0o755); err != nil {
+		return err
+	}
+	tmp := h.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, h.path)
+}
+
+// prune forgets listeners closed before the retention, then the oldest
+// beyond maxPortSpans
+func (h *PortHistory) prune(now time.Time) {
+	cutoff := now.Add(-h.retention).UTC().Format(time.RFC3339)
+	kept := h.closed[:0]
+	for _, span := range h.closed {
+		if span.Closed >= cutoff {
+			kept = append(kept, span)
+		}
+	}
+	if len(kept) != len(h.closed) {
+		h.dirty = true
+	}
+	if len(kept) > maxPortSpans {
+		sort.SliceStable(kept, func(i, j int) bool { return kept[i].Closed < kept[j].Closed })
+		kept = kept[len(kept)-maxPortSpans:]
+		h.dirty = true
+	}
+	h.closed = kept
+}
+
+// Started returns when the first ports were recorded in this run, the
+// zero time if none have been
+func (h *PortHistory) Started() time.Time {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.started
+}
+
+// Retention returns how long closed listeners are kept
+func (h *PortHistory) Retention() time.Duration {
+	return h.retention
+}
+
+// PortHistoryQuery selects listeners from a PortHistory. Zero fields are
+// ignored.
+type PortHistoryQuery struct {
+	Port uint32
+	PID  int32
+	// Name keeps listeners whose process name contains it, ignoring case
+	Name string
+	// At keeps listeners that were open at this time
+	At time.Time
+	// Since and Until keep listeners open at some point between them
+	Since, Until time.Time
+}
+
+// Query returns the listeners matching q, the most recently opened first
+func (h *PortHistory) Query(q PortHistoryQuery) []types.PortSpan {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if !q.At.IsZero() {
+		q.Since, q.Until = q.At, q.At
+	}
+	name := strings.ToLower(q.Name)
+	var result []types.PortSpan
+	match := func(span types.PortSpan) {
+		if (q.Port != 0 && span.Port != q.Port) ||
+			(q.PID != 0 && span.PID != q.PID) ||
+			(name != "" && !strings.Contains(strings.ToLower(span.Name), name)) {
+			return
+		}
+		opened, _ := time.Parse(time.RFC3339, span.Opened)
+		if !q.Until.IsZero() && opened.After(q.Until) {
+			return
+		}
+		if span.Closed != "" && !q.Since.IsZero() {
+			// Polls are seconds apart; a listener closed at the poll
+			// after q.Since was still open then
+			if closed, err := time.Parse(time.RFC3339, span.Closed); err == nil && !closed.After(q.Since) {
+				return
+			}
+		}
+		result = append(result, span)
+	}
+	for _, span := range h.closed {
+		match(span)
+	}
+	for _, span := range h.open {
+		match(*span)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Opened != result[j].Opened {
+			return result[i].Opened > result[j].Opened
+		}
+		return result[i].Port < result[j].Port
+	})
+	return result
+}
+
+// spanKey identifies a listener: the socket and the process holding it
+func spanKey(p types.PortInfo) string {
+	return fmt.Sprintf("%s/%d", portKey(p), p.PID)
+}
@@ -0,0 +1,107 @@
+package watch
+
+import (
+	"testing"
+	"time"
+
+	"github.com/borankux/gops/pkg/types"
+)
+
+func TestPortHistoryRecord(t *testing.T) {
+	h, err := NewPortHistory("", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	web := types.PortInfo{Port: 8080, Protocol: "tcp", LocalIP: "127.0.0.1", PID: 100, Name: "web"}
+	db := types.PortInfo{Port: 5432, Protocol: "tcp", LocalIP: "127.0.0.1", PID: 200, Name: "postgres"}
+
+	h.Record([]types.PortInfo{web, db}, start)
+	h.Record([]types.PortInfo{db}, start.Add(time.Minute))
+	// PID reuse of the port by another process is a new listener
+	web.PID, web.Name = 300, "web2"
+	h.Record([]types.PortInfo{web, db}, start.Add(2*time.Minute))
+
+	tests := []struct {
+		name  string
+		query PortHistoryQuery
+		want  []int32
+	}{
+		{"all, newest first", PortHistoryQuery{}, []int32{300, 200, 100}},
+		{"by port", PortHistoryQuery{Port: 8080}, []int32{300, 100}},
+		{"at a time", PortHistoryQuery{At: start.Add(30 * time.Second)}, []int32{200, 100}},
+		{"closed by then", PortHistoryQuery{At: start.Add(90 * time.Second)}, []int32{200}},
+		{"by name", PortHistoryQuery{Name: "POST"}, []int32{200}},
+		{"until", PortHistoryQuery{Until: start.Add(time.Second)}, []int32{200, 100}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := h.Query(tt.query)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want PIDs %v", got, tt.want)
+			}
+			for i, span := range got {
+				if span.PID != tt.want[i] {
+					t.Errorf("span %d PID = %d, want %d", i, span.PID, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestPortHistoryPrune(t *testing.T) {
+	h, err := NewPortHistory("", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	stamp := func(d time.Duration) string { return now.Add(d).Format(time.RFC3339) }
+	h.closed = []types.PortSpan{
+		{Port: 1, Opened: stamp(-3 * time.Hour), Closed: stamp(-2 * time.Hour)},
+		{Port: 2, Opened: stamp(-2 * time.Hour), Closed: stamp(-30 * time.Minute)},
+		{Port: 3, Opened: stamp(-time.Hour), Closed: stamp(-time.Minute)},
+	}
+	h.prune(now)
+	if len(h.closed) != 2 || h.closed[0].Port != 2 || h.closed[1].Port != 3 {
+		t.Fatalf("kept %v, want ports 2 and 3", h.closed)
+	}
+	if !h.dirty {
+		t.Error("pruning didn't mark the history for saving")
+	}
+
+	// Beyond maxPortSpans the listeners closed longest ago are dropped
+	h.closed = nil
+	for i := 0; i < maxPortSpans+10; i++ {
+		closed := stamp(-time.Duration(maxPortSpans+10-i) * time.Millisecond * 50)
+		h.closed = append(h.closed, types.PortSpan{Port: uint32(i), Closed: closed})
+	}
+	h.prune(now)
+	if len(h.closed) != maxPortSpans {
+		t.Fatalf("kept %d spans, want %d", len(h.closed), maxPortSpans)
+	}
+	if h.closed[0].Closed < stamp(-time.Duration(maxPortSpans)*50*time.Millisecond) {
+		t.Errorf("oldest kept span closed at %s", h.closed[0].Closed)
+	}
+}
+
+func TestPortHistoryPersist(t *testing.T) {
+	path := t.TempDir() + "/ports.json"
+	h, err := NewPortHistory(path, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	h.Record([]types.PortInfo{{Port: 22, Protocol: "tcp", LocalIP: "0.0.0.0", PID: 1, Name: "sshd"}}, now)
+
+	reloaded, err := NewPortHistory(path, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := reloaded.Query(PortHistoryQuery{})
+	if len(got) != 1 || got[0].Port != 22 {
+		t.Fatalf("reloaded %v, want the sshd listener", got)
+	}
+	if got[0].Closed != got[0].LastSeen {
+		t.Errorf("listener open at exit closed at %q, want last seen %q", got[0].Closed, got[0].LastSeen)
+	}
+}
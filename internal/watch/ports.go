@@ -0,0 +1,97 @@
+package watch
+
+import (
+	"context"
+	"time"
+
+	"github.com/borankux/gops/internal/events"
+	"github.com/borankux/gops/internal/port"
+	"github.com/borankux/gops/pkg/types"
+)
+
+// PortWatcher polls listening ports alone and publishes port.opened and
+// port.closed, for watching ports without the cost of a full Watcher
+type PortWatcher struct {
+	bus      *events.Bus
+	interval time.Duration
+	list     port.ListOptions
+	only     []uint32
+	ports    map[string]types.PortInfo
+}
+
+// NewPortWatcher creates a watcher publishing to bus the changes to the
+// ports list selects, limited to the port numbers in only when it is set
+func NewPortWatcher(bus *events.Bus, interval time.Duration, list port.ListOptions, only []uint32) *PortWatcher {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	return &PortWatcher{bus: bus, interval: interval, list: list, only: only}
+}
+
+// Run polls until ctx is cancelled. The first poll records a baseline; if
+// it fails, Run returns its error, while later failures skip a poll.
+func (w *PortWatcher) Run(ctx context.Context) error {
+	current, err := w.collect(ctx)
+	if err != nil {
+		return err
+	}
+	w.ports = current
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			current, err := w.collect(ctx)
+			if err != nil {
+				continue
+			}
+			publishPortChanges(w.bus, w.ports, current, func(p uint32) bool {
+				return watchingPort(w.only, p)
+			})
+			w.ports = current
+		}
+	}
+}
+
+func (w *PortWatcher) collect(ctx context.Context) (map[string]types.PortInfo, error) {
+	ports, err := port.GetOpenPorts(ctx, w.list)
+	if err != nil {
+		return nil, err
+	}
+	current := make(map[string]types.PortInfo, len(ports))
+	for _, p := range ports {
+		current[portKey(p)] = p
+	}
+	return current, nil
+}
+
+// publishPortChanges publishes port.opened for the ports in current but
+// not prev, and port.closed for the reverse, among the watched ones
+func publishPortChanges(bus *events.Bus, prev, current map[string]types.PortInfo, watching func(uint32) bool) {
+	for key, p := range current {
+		if _, existed := prev[key]; !existed && watching(p.Port) {
+			bus.Publish(events.PortOpened, p)
+		}
+	}
+	for key, p := range prev {
+		if _, exists := current[key]; !exists && watching(p.Port) {
+			bus.Publish(events.PortClosed, p)
+		}
+	}
+}
+
+// watchingPort reports whether port is in only, or only is empty
+func watchingPort(only []uint32, port uint32) bool {
+	if len(only) == 0 {
+		return true
+	}
+	for _, p := range only {
+		if p == port {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,315 @@
+package watch
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/borankux/gops/internal/events"
+	"github.com/borankux/gops/internal/port"
+	"github.com/borankux/gops/internal/process"
+	"github.com/borankux/gops/internal/resource"
+	"github.com/borankux/gops/internal/service"
+	"github.com/borankux/gops/internal/utils"
+	"github.com/borankux/gops/internal/window"
+	"github.com/borankux/gops/pkg/types"
+)
+
+const (
+	// DefaultInterval is how often the watcher polls for changes
+	DefaultInterval = 2 * time.Second
+
+	// servicePollEvery is how many polls pass between service checks;
+	// listing services is slower than the other collectors
+	servicePollEvery = 5
+)
+
+// Options configures a Watcher
+type Options struct {
+	// Interval is the polling period; DefaultInterval if zero
+	Interval time.Duration
+	// CPUThreshold publishes process.cpu_high when a process rises above
+	// this CPU percentage; zero disables CPU monitoring
+	CPUThreshold float64
+	// Processes and Ports, when set, limit process and port events to
+	// these process names and port numbers
+	Processes []string
+	Ports     []uint32
+	// Titles, when set, records the titles each window has had
+	Titles *TitleHistory
+	// PortHistory, when set, records when each port was listening
+	PortHistory *PortHistory
+}
+
+// Watcher polls the process table, listening ports, open and focused
+// windows and services and publishes the differences between polls as
+// events
+type Watcher struct {
+	bus  *events.Bus
+	opts Options
+
+	primed   bool
+	polls    int
+	procs    map[int32]string
+	started  map[int32]types.ProcessInfo
+	ports    map[string]types.PortInfo
+	windows  map[string]types.WindowInfo
+	focused  *types.WindowInfo
+	services map[string]types.ServiceInfo
+
+	cpu     *resource.CPUTracker
+	cpuHigh map[int32]bool
+}
+
+// New creates a watcher publishing to bus
+func New(bus *events.Bus, opts Options) *Watcher {
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultInterval
+	}
+	w := &Watcher{
+		bus:  bus,
+		opts: opts,
+	}
+	if opts.CPUThreshold > 0 {
+		w.cpu = resource.NewCPUTracker()
+		w.cpuHigh = make(map[int32]bool)
+	}
+	return w
+}
+
+// Run polls until ctx is cancelled
+func (w *Watcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.opts.Interval)
+	defer ticker.Stop()
+
+	w.poll(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.poll(ctx)
+		}
+	}
+}
+
+// poll collects the current state and publishes changes. The first poll
+// only records a baseline.
+func (w *Watcher) poll(ctx context.Context) {
+	if procs, err := process.GetProcessNames(ctx); err == nil {
+		if w.primed {
+			w.diffProcesses(ctx, procs)
+		} else {
+			w.recordProcesses(ctx)
+		}
+		w.procs = procs
+	}
+
+	if ports, err := port.GetOpenPorts(ctx, port.ListOptions{}); err == nil {
+		current := make(map[string]types.PortInfo, len(ports))
+		for _, p := range ports {
+			current[portKey(p)] = p
+		}
+		if w.primed {
+			w.diffPorts(current)
+		}
+		if w.opts.PortHistory != nil {
+			w.opts.PortHistory.Record(ports, time.Now())
+		}
+		w.ports = current
+	}
+
+	if windows, err := window.GetOpenWindows(ctx); err == nil {
+		current := make(map[string]types.WindowInfo, len(windows))
+		for _, win := range windows {
+			current[windowKey(win)] = win
+		}
+		if w.primed {
+			w.diffWindows(current)
+		}
+		if w.opts.Titles != nil {
+			now := time.Now()
+			for _, win := range windows {
+				w.opts.Titles.Record(win, now)
+			}
+		}
+		w.windows = current
+	}
+
+	if focused, err := window.GetFocusedWindow(ctx); err == nil && focused != nil {
+		if w.primed && !sameWindow(w.focused, focused) {
+			w.bus.Publish(events.WindowFocused, focused)
+		}
+		w.focused = focused
+	}
+
+	if w.cpu != nil {
+		if usage, err := w.cpu.Sample(ctx); err == nil {
+			w.checkCPU(usage)
+		}
+	}
+
+	if w.polls%servicePollEvery == 0 {
+		if services, err := service.GetServices(ctx, service.ListOptions{SkipUsage: true}); err == nil {
+			current := make(map[string]types.ServiceInfo, len(services))
+			for _, svc := range services {
+				// The same label can be loaded in several launchd domains
+				current[svc.Domain+"/"+svc.Name] = svc
+			}
+			if w.services != nil {
+				w.diffServices(current)
+			}
+			w.services = current
+		}
+	}
+
+	w.polls++
+	w.primed = true
+}
+
+// checkCPU publishes process.cpu_high once each time a process crosses
+// the threshold
+func (w *Watcher) checkCPU(usage map[int32]float64) {
+	high := make(map[int32]bool)
+	for pid, percent := range usage {
+		if percent < w.opts.CPUThreshold || !w.watchingProcess(w.procs[pid]) {
+			continue
+		}
+		high[pid] = true
+		if !w.cpuHigh[pid] {
+			w.bus.Publish(events.ProcessCPUHigh, types.ProcessInfo{
+				PID:        pid,
+				Name:       w.procs[pid],
+				CPUPercent: percent,
+			})
+		}
+	}
+	w.cpuHigh = high
+}
+
+// diffServices publishes service.crashed for services that were running
+// and have stopped with a failure
+func (w *Watcher) diffServices(current map[string]types.ServiceInfo) {
+	for key, svc := range current {
+		prev, existed := w.services[key]
+		if existed && prev.PID > 0 && svc.PID == 0 && service.Failed(svc.Status) {
+			w.bus.Publish(events.ServiceCrashed, svc)
+		}
+	}
+}
+
+// recordProcesses remembers the details of the processes running when
+// watching begins, so their exit events carry them
+func (w *Watcher) recordProcesses(ctx context.Context) {
+	procs, err := process.GetAllProcesses(ctx, process.ListOptions{})
+	if err != nil {
+		return
+	}
+	w.started = make(map[int32]types.ProcessInfo, len(procs))
+	for _, p := range procs {
+		if w.watchingProcess(p.Name) {
+			w.started[p.PID] = p
+		}
+	}
+}
+
+// diffProcesses publishes process.started with the details of each new
+// process, and process.exited with those details and its final uptime
+func (w *Watcher) diffProcesses(ctx context.Context, current map[int32]string) {
+	if w.started == nil {
+		w.started = make(map[int32]types.ProcessInfo)
+	}
+	for pid, name := range current {
+		if _, existed := w.procs[pid]; existed || !w.watchingProcess(name) {
+			continue
+		}
+		info, err := process.GetProcessInfo(ctx, pid)
+		if err != nil {
+			// Exited before it could be inspected
+			info = types.ProcessInfo{PID: pid, Name: name}
+		}
+		w.started[pid] = info
+		w.bus.Publish(events.ProcessStarted, info)
+	}
+	for pid, name := range w.procs {
+		if _, exists := current[pid]; exists || !w.watchingProcess(name) {
+			continue
+		}
+		info, known := w.started[pid]
+		if !known {
+			info = types.ProcessInfo{PID: pid, Name: name}
+		}
+		delete(w.started, pid)
+		if started, err := time.Parse(time.RFC3339, info.StartTime); err == nil {
+			info.Uptime = utils.FormatDuration(uint64(time.Since(started).Seconds()))
+		}
+		w.bus.Publish(events.ProcessExited, info)
+	}
+}
+
+func (w *Watcher) diffPorts(current map[string]types.PortInfo) {
+	publishPortChanges(w.bus, w.ports, current, w.watchingPort)
+}
+
+// diffWindows publishes window.opened, window.closed and
+// window.title_changed. Windows are matched by ID where the platform
+// reports one; elsewhere a retitled window shows up as closed and opened.
+func (w *Watcher) diffWindows(current map[string]types.WindowInfo) {
+	for key, win := range current {
+		prev, existed := w.windows[key]
+		switch {
+		case !existed:
+			w.bus.Publish(events.WindowOpened, win)
+		case prev.Title != win.Title:
+			w.bus.Publish(events.WindowRetitled, types.WindowTitleChange{
+				WindowInfo:    win,
+				PreviousTitle: prev.Title,
+			})
+		}
+	}
+	for key, win := range w.windows {
+		if _, exists := current[key]; !exists {
+			w.bus.Publish(events.WindowClosed, win)
+			if w.opts.Titles != nil {
+				w.opts.Titles.Closed(win)
+			}
+		}
+	}
+}
+
+// watchingProcess reports whether events for a process name are wanted
+func (w *Watcher) watchingProcess(name string) bool {
+	if len(w.opts.Processes) == 0 {
+		return true
+	}
+	for _, watched := range w.opts.Processes {
+		if strings.EqualFold(watched, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// watchingPort reports whether events for a port number are wanted
+func (w *Watcher) watchingPort(port uint32) bool {
+	return watchingPort(w.opts.Ports, port)
+}
+
+func portKey(p types.PortInfo) string {
+	return fmt.Sprintf("%s/%s:%d", p.Protocol, p.LocalIP, p.Port)
+}
+
+func windowKey(w types.WindowInfo) string {
+	if w.ID != 0 {
+		return fmt.Sprintf("id/%d", w.ID)
+	}
+	return fmt.Sprintf("%d/%s", w.PID, w.Title)
+}
+
+func sameWindow(a, b *types.WindowInfo) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.PID == b.PID && a.Title == b.Title
+}
@@ -0,0 +1,355 @@
+package watch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/user"
+	"strings"
+	"time"
+
+	"github.com/borankux/gops/internal/events"
+	"github.com/borankux/gops/internal/process"
+	"github.com/borankux/gops/internal/service"
+	"github.com/borankux/gops/pkg/types"
+)
+
+// Watchdog defaults
+const (
+	// DefaultWatchdogInterval is how often the watchdog checks its targets
+	DefaultWatchdogInterval = 5 * time.Second
+	// DefaultRestartBackoff is the delay before the second restart of a
+	// target, doubling with each restart after it
+	DefaultRestartBackoff = 2 * time.Second
+	// DefaultMaxBackoff caps the delay between restarts
+	DefaultMaxBackoff = 5 * time.Minute
+	// DefaultMaxRestarts is how many restarts within the restart window
+	// the watchdog attempts before giving up on a target
+	DefaultMaxRestarts = 5
+	// DefaultRestartWindow is the period restarts are counted over
+	DefaultRestartWindow = time.Hour
+)
+
+// Kinds of watchdog target, as reported in watchdog events
+const (
+	TargetService = "service"
+	TargetProcess = "process"
+)
+
+// WatchdogTarget is a service or process the watchdog keeps running.
+// Exactly one of Service and Process is set.
+type WatchdogTarget struct {
+	// Service is a launchd label, systemd unit or Windows service name
+	Service string
+	// User selects a systemd user unit
+	User bool
+	// Process is a process name, matched case-insensitively
+	Process string
+	// Command starts the process again. Without it the watchdog reuses
+	// the command line and working directory the process was last seen
+	// running with, so a process that was never seen, or only seen
+	// running as another user than gops, can't be restarted.
+	Command []string
+	// NotifyOnly publishes watchdog.down without restarting the target
+	NotifyOnly bool
+}
+
+// Name returns the service or process name of the target
+func (t WatchdogTarget) Name() string {
+	if t.Service != "" {
+		return t.Service
+	}
+	return t.Process
+}
+
+// Kind returns TargetService or TargetProcess
+func (t WatchdogTarget) Kind() string {
+	if t.Service != "" {
+		return TargetService
+	}
+	return TargetProcess
+}
+
+// WatchdogOptions configures a Watchdog; zero values take the defaults
+type WatchdogOptions struct {
+	Interval time.Duration
+	// Backoff is the delay before the second restart within the restart
+	// window, doubling for each one after it up to MaxBackoff. The first
+	// restart is attempted as soon as the target is found down.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// MaxRestarts is how many restarts within RestartWindow are attempted
+	// before the watchdog gives up on a target, until it is seen running
+	// again
+	MaxRestarts   int
+	RestartWindow time.Duration
+}
+
+// Watchdog checks that services and processes are running, publishing
+// watchdog.down when one is not and restarting it with backoff, then
+// watchdog.restarted or watchdog.restart_failed, and watchdog.gave_up
+// once too many restarts were needed
+type Watchdog struct {
+	bus     *events.Bus
+	opts    WatchdogOptions
+	targets []WatchdogTarget
+	states  []*targetState
+}
+
+// targetState is what the watchdog remembers about a target between
+// checks
+type targetState struct {
+	down   bool
+	gaveUp bool
+	// restarts are the times of the restarts within the restart window
+	restarts    []time.Time
+	nextAttempt time.Time
+
+	// pid is the process last seen running, and command and dir how it
+	// was started
+	pid     int32
+	command []string
+	dir     string
+}
+
+// NewWatchdog creates a watchdog publishing to bus, checking that the
+// opts are valid and that each target names a service or a process
+func NewWatchdog(bus *events.Bus, targets []WatchdogTarget, opts WatchdogOptions) (*Watchdog, error) {
+	if len(targets) == 0 {
+		return nil, errors.New("no services or processes to watch")
+	}
+	for _, t := range targets {
+		switch {
+		case t.Service == "" && t.Process == "":
+			return nil, errors.New("watchdog target needs a service or a process")
+		case t.Service != "" && t.Process != "":
+			return nil, fmt.Errorf("watchdog target %s: set either a service or a process, not both", t.Service)
+		case t.Service != "" && len(t.Command) > 0:
+			return nil, fmt.Errorf("watchdog target %s: a command only applies to processes", t.Service)
+		case t.Process != "" && t.User:
+			return nil, fmt.Errorf("watchdog target %s: user only applies to services", t.Process)
+		}
+	}
+	if opts.Interval < 0 || opts.Backoff < 0 || opts.MaxBackoff < 0 || opts.MaxRestarts < 0 || opts.RestartWindow < 0 {
+		return nil, errors.New("watchdog intervals and limits can't be negative")
+	}
+	if opts.Interval == 0 {
+		opts.Interval = DefaultWatchdogInterval
+	}
+	if opts.Backoff == 0 {
+		opts.Backoff = DefaultRestartBackoff
+	}
+	if opts.MaxBackoff == 0 {
+		opts.MaxBackoff = DefaultMaxBackoff
+	}
+	if opts.MaxRestarts == 0 {
+		opts.MaxRestarts = DefaultMaxRestarts
+	}
+	if opts.RestartWindow == 0 {
+		opts.RestartWindow = DefaultRestartWindow
+	}
+
+	states := make([]*targetState, len(targets))
+	for i := range states {
+		states[i] = &targetState{}
+	}
+	return &Watchdog{bus: bus, opts: opts, targets: targets, states: states}, nil
+}
+
+// Options returns the options in effect, with defaults filled in
+func (d *Watchdog) Options() WatchdogOptions {
+	return d.opts
+}
+
+// Run checks the targets every interval until ctx is cancelled
+func (d *Watchdog) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.opts.Interval)
+	defer ticker.Stop()
+	for {
+		d.check(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// check looks at every target once, restarting those that are down and
+// due for a restart
+func (d *Watchdog) check(ctx context.Context) {
+	var names map[int32]string
+	var namesErr error
+	for _, t := range d.targets {
+		if t.Process != "" {
+			names, namesErr = process.GetProcessNames(ctx)
+			break
+		}
+	}
+
+	for i, t := range d.targets {
+		if ctx.Err() != nil {
+			return
+		}
+		s := d.states[i]
+
+		var running bool
+		if t.Service != "" {
+			var known bool
+			if running, known = serviceRunning(ctx, t); !known {
+				continue
+			}
+		} else {
+			if namesErr != nil {
+				continue
+			}
+			pid := findProcess(names, t.Process)
+			running = pid > 0
+			if running && pid != s.pid {
+				s.remember(ctx, pid)
+			}
+		}
+
+		if running {
+			s.down, s.gaveUp = false, false
+			continue
+		}
+		if !s.down {
+			s.down = true
+			d.publish(events.WatchdogDown, t, s, types.WatchdogEvent{})
+		}
+		if t.NotifyOnly || s.gaveUp {
+			continue
+		}
+		d.restart(ctx, t, s, time.Now())
+	}
+}
+
+// restart starts a target that is down if its backoff has passed, or
+// gives up on it after MaxRestarts within the restart window
+func (d *Watchdog) restart(ctx context.Context, t WatchdogTarget, s *targetState, now time.Time) {
+	if now.Before(s.nextAttempt) {
+		return
+	}
+	recent := s.restarts[:0]
+	for _, at := range s.restarts {
+		if now.Sub(at) < d.opts.RestartWindow {
+			recent = append(recent, at)
+		}
+	}
+	s.restarts = recent
+	if len(s.restarts) >= d.opts.MaxRestarts {
+		s.gaveUp = true
+		d.publish(events.WatchdogGaveUp, t, s, types.WatchdogEvent{})
+		return
+	}
+
+	s.restarts = append(s.restarts, now)
+	s.nextAttempt = now.Add(d.backoff(len(s.restarts)))
+
+	var pid int32
+	var err error
+	if t.Service != "" {
+		var result types.ServiceActionResponse
+		result, err = service.Control(ctx, t.Service, service.ActionStart, service.ControlOptions{User: t.User})
+		pid = result.PID
+	} else {
+		pid, err = s.start(ctx, t)
+	}
+	if err != nil {
+		d.publish(events.WatchdogRestartFailed, t, s, types.WatchdogEvent{
+			NextAttempt: s.nextAttempt.UTC().Format(time.RFC3339),
+			Error:       err.Error(),
+		})
+		return
+	}
+	d.publish(events.WatchdogRestarted, t, s, types.WatchdogEvent{PID: pid})
+}
+
+// backoff returns the delay after the nth restart within the window
+func (d *Watchdog) backoff(n int) time.Duration {
+	delay := d.opts.Backoff
+	for i := 1; i < n && delay < d.opts.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > d.opts.MaxBackoff {
+		delay = d.opts.MaxBackoff
+	}
+	return delay
+}
+
+func (d *Watchdog) publish(eventType string, t WatchdogTarget, s *targetState, data types.WatchdogEvent) {
+	data.Kind = t.Kind()
+	data.Name = t.Name()
+	data.User = t.User
+	data.Restarts = len(s.restarts)
+	data.MaxRestarts = d.opts.MaxRestarts
+	d.bus.Publish(eventType, data)
+}
+
+// remember records how a running process was started, so it can be
+// started the same way after it exits. Only processes of the user gops
+// runs as are remembered: the watchdog starts processes as that user,
+// so another user's command line, such as one of a process with a name
+// a root watchdog watches, would let them choose what it runs.
+func (s *targetState) remember(ctx context.Context, pid int32) {
+	s.pid = pid
+	detail, err := process.GetProcess(ctx, pid)
+	if err != nil {
+		return
+	}
+	if current, err := user.Current(); err != nil || detail.User != current.Username {
+		return
+	}
+	command := detail.Cmdline
+	if len(command) == 0 && detail.Path != "" {
+		command = []string{detail.Path}
+	}
+	if len(command) > 0 {
+		s.command, s.dir = command, detail.Cwd
+	}
+}
+
+// start launches a process target with its configured command, or the
+// one it was last seen running with
+func (s *targetState) start(ctx context.Context, t WatchdogTarget) (int32, error) {
+	command, dir := t.Command, ""
+	if len(command) == 0 {
+		command, dir = s.command, s.dir
+	}
+	if len(command) == 0 {
+		return 0, fmt.Errorf("no command to start %s with: it was not seen running as the user gops runs as and none is configured", t.Process)
+	}
+	launched, err := process.Launch(ctx, process.LaunchRequest{Path: command[0], Args: command[1:], Dir: dir})
+	if err != nil {
+		return 0, err
+	}
+	s.pid = launched.PID
+	return launched.PID, nil
+}
+
+// serviceRunning reports whether a service target has a running process.
+// known is false when its state couldn't be read, so it is checked again
+// at the next poll rather than restarted.
+func serviceRunning(ctx context.Context, t WatchdogTarget) (running, known bool) {
+	detail, err := service.GetService(ctx, t.Service, service.ControlOptions{User: t.User})
+	if errors.Is(err, service.ErrNotFound) {
+		return false, true
+	}
+	if err != nil {
+		return false, false
+	}
+	return detail.PID > 0, true
+}
+
+// findProcess returns the lowest PID of the processes with the given
+// name, or 0 if none is running
+func findProcess(names map[int32]string, name string) int32 {
+	var found int32
+	for pid, n := range names {
+		if strings.EqualFold(n, name) && (found == 0 || pid < found) {
+			found = pid
+		}
+	}
+	return found
+}
@@ -0,0 +1,112 @@
+package watch
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/borankux/gops/internal/events"
+)
+
+func TestWatchdogBackoff(t *testing.T) {
+	d := &Watchdog{opts: WatchdogOptions{Backoff: 2 * time.Second, MaxBackoff: 30 * time.Second}}
+	tests := []struct {
+		restarts int
+		want     time.Duration
+	}{
+		{1, 2 * time.Second},
+		{2, 4 * time.Second},
+		{3, 8 * time.Second},
+		{4, 16 * time.Second},
+		{5, 30 * time.Second},
+		{40, 30 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := d.backoff(tt.restarts); got != tt.want {
+			t.Errorf("backoff(%d) = %s, want %s", tt.restarts, got, tt.want)
+		}
+	}
+}
+
+// newRestartTest returns a watchdog for a process that was never seen
+// running, so every restart fails without starting anything, and a
+// subscription to its events
+func newRestartTest(t *testing.T) (*Watchdog, WatchdogTarget, *targetState, *events.Subscription) {
+	t.Helper()
+	bus := events.NewBus()
+	target := WatchdogTarget{Process: "gops-watchdog-test"}
+	d, err := NewWatchdog(bus, []WatchdogTarget{target}, WatchdogOptions{
+		Backoff:       time.Second,
+		MaxBackoff:    time.Minute,
+		MaxRestarts:   3,
+		RestartWindow: time.Hour,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sub := bus.Subscribe(64)
+	t.Cleanup(sub.Close)
+	return d, target, d.states[0], sub
+}
+
+// nextEvent returns the type of the next event published, or "" if there
+// is none
+func nextEvent(sub *events.Subscription) string {
+	select {
+	case event := <-sub.C:
+		return event.Type
+	default:
+		return ""
+	}
+}
+
+func TestWatchdogRestart(t *testing.T) {
+	d, target, s, sub := newRestartTest(t)
+	ctx := context.Background()
+	start := time.Now()
+
+	tests := []struct {
+		name     string
+		at       time.Duration
+		want     string
+		restarts int
+	}{
+		{"first restart at once", 0, events.WatchdogRestartFailed, 1},
+		{"within backoff", 500 * time.Millisecond, "", 1},
+		{"after backoff", time.Second, events.WatchdogRestartFailed, 2},
+		{"backoff doubled", 2 * time.Second, "", 2},
+		{"third restart", 3 * time.Second, events.WatchdogRestartFailed, 3},
+		{"gives up", 10 * time.Second, events.WatchdogGaveUp, 3},
+	}
+	for _, tt := range tests {
+		d.restart(ctx, target, s, start.Add(tt.at))
+		if got := nextEvent(sub); got != tt.want {
+			t.Fatalf("%s: event = %q, want %q", tt.name, got, tt.want)
+		}
+		if len(s.restarts) != tt.restarts {
+			t.Fatalf("%s: %d restarts counted, want %d", tt.name, len(s.restarts), tt.restarts)
+		}
+	}
+	if !s.gaveUp {
+		t.Fatal("watchdog didn't give up after MaxRestarts")
+	}
+}
+
+func TestWatchdogRestartWindow(t *testing.T) {
+	d, target, s, sub := newRestartTest(t)
+	ctx := context.Background()
+	start := time.Now()
+
+	// Restarts that left the window no longer count towards MaxRestarts
+	s.restarts = []time.Time{start.Add(-2 * time.Hour), start.Add(-90 * time.Minute), start.Add(-10 * time.Minute)}
+	d.restart(ctx, target, s, start)
+	if got := nextEvent(sub); got != events.WatchdogRestartFailed {
+		t.Fatalf("event = %q, want %q", got, events.WatchdogRestartFailed)
+	}
+	if len(s.restarts) != 2 {
+		t.Fatalf("%d restarts counted, want 2: %v", len(s.restarts), s.restarts)
+	}
+	if want := start.Add(d.backoff(2)); !s.nextAttempt.Equal(want) {
+		t.Fatalf("next attempt at %s, want %s", s.nextAttempt, want)
+	}
+}
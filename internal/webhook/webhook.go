@@ -0,0 +1,290 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/borankux/gops/internal/events"
+	"github.com/borankux/gops/pkg/types"
+)
+
+const (
+	// maxAttempts is how many times a delivery is tried before giving up
+	maxAttempts = 5
+
+	// initialBackoff is the delay before the first retry; it doubles on
+	// each further attempt up to maxBackoff
+	initialBackoff = time.Second
+	maxBackoff     = 30 * time.Second
+
+	// deliveryTimeout bounds a single POST
+	deliveryTimeout = 10 * time.Second
+
+	userAgent = "gops-webhook/1.0"
+
+	// MaxHooks is how many webhooks can be registered at once
+	MaxHooks = 32
+
+	// queueSize is how many events may wait for delivery to a webhook;
+	// further events are dropped until it catches up
+	queueSize = 64
+)
+
+// ErrTooManyHooks is returned by Add once MaxHooks webhooks are registered
+var ErrTooManyHooks = fmt.Errorf("at most %d webhooks can be registered", MaxHooks)
+
+// Manager holds the registered webhooks and delivers bus events to them
+type Manager struct {
+	bus    *events.Bus
+	client *http.Client
+
+	mu    sync.RWMutex
+	hooks map[string]*subscriber
+}
+
+// subscriber is a registered webhook and the queue of events its worker
+// delivers one at a time
+type subscriber struct {
+	hook  types.Webhook
+	queue chan types.Event
+	// stop ends the worker and any delivery in flight
+	stop context.CancelFunc
+}
+
+// NewManager creates a manager with no webhooks
+func NewManager(bus *events.Bus) *Manager {
+	return &Manager{
+		bus:    bus,
+		client: &http.Client{Timeout: deliveryTimeout},
+		hooks:  make(map[string]*subscriber),
+	}
+}
+
+// Add validates and registers a webhook, assigning its ID, and starts
+// the worker delivering its events
+func (m *Manager) Add(hook types.Webhook) (types.Webhook, error) {
+	u, err := url.Parse(hook.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return types.Webhook{}, fmt.Errorf("invalid webhook URL %q: must be an absolute http or https URL", hook.URL)
+	}
+
+	buf := make([]byte, 8)
+	rand.Read(buf)
+	hook.ID = hex.EncodeToString(buf)
+	hook.CreatedAt = time.Now().Format(time.RFC3339)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.hooks) >= MaxHooks {
+		return types.Webhook{}, ErrTooManyHooks
+	}
+	ctx, stop := context.WithCancel(context.Background())
+	sub := &subscriber{hook: hook, queue: make(chan types.Event, queueSize), stop: stop}
+	m.hooks[hook.ID] = sub
+	go m.work(ctx, sub)
+	return redact(hook), nil
+}
+
+// Remove unregisters a webhook, reporting whether it existed. Events
+// still queued for it are dropped.
+func (m *Manager) Remove(id string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	sub, exists := m.hooks[id]
+	if !exists {
+		return false
+	}
+	sub.stop()
+	delete(m.hooks, id)
+	return true
+}
+
+// List returns the registered webhooks, oldest first, without secrets
+func (m *Manager) List() []types.Webhook {
+	m.mu.RLock()
+	hooks := make([]types.Webhook, 0, len(m.hooks))
+	for _, sub := range m.hooks {
+		hooks = append(hooks, redact(sub.hook))
+	}
+	m.mu.RUnlock()
+
+	sort.Slice(hooks, func(i, j int) bool {
+		if hooks[i].CreatedAt != hooks[j].CreatedAt {
+			return hooks[i].CreatedAt < hooks[j].CreatedAt
+		}
+		return hooks[i].ID < hooks[j].ID
+	})
+	return hooks
+}
+
+// Len returns the number of registered webhooks
+func (m *Manager) Len() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.hooks)
+}
+
+// Run queues events from the bus for the webhooks subscribed to them
+// until ctx is cancelled, then stops every worker. An event is dropped
+// for a webhook whose queue is full, so a slow or failing endpoint
+// holds back neither the others nor memory.
+func (m *Manager) Run(ctx context.Context) {
+	sub := m.bus.Subscribe(256)
+	defer sub.Close()
+	defer m.stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-sub.C:
+			if !ok {
+				return
+			}
+			m.enqueue(event)
+		}
+	}
+}
+
+// enqueue queues an event for the webhooks subscribed to its type
+func (m *Manager) enqueue(event types.Event) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, sub := range m.hooks {
+		if !events.Matches(sub.hook.Events, event.Type) {
+			continue
+		}
+		select {
+		case sub.queue <- event:
+		default:
+			log.Printf("⚠️  Webhook %s is falling behind; dropped %s", sub.hook.URL, event.Type)
+		}
+	}
+}
+
+// stop ends the workers of every webhook
+func (m *Manager) stop() {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, sub := range m.hooks {
+		sub.stop()
+	}
+}
+
+// work delivers a webhook's queued events in order until ctx is done
+func (m *Manager) work(ctx context.Context, sub *subscriber) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-sub.queue:
+			m.deliver(ctx, sub.hook, event)
+		}
+	}
+}
+
+// deliver POSTs an event to a webhook, retrying with exponential backoff on
+// network errors, 429 and 5xx responses
+func (m *Manager) deliver(ctx context.Context, hook types.Webhook, event types.Event) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+
+	buf := make([]byte, 8)
+	rand.Read(buf)
+	deliveryID := hex.EncodeToString(buf)
+
+	backoff := initialBackoff
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		retry, err := m.post(ctx, hook, event.Type, deliveryID, attempt, body)
+		if err == nil {
+			return
+		}
+		if !retry || attempt == maxAttempts {
+			log.Printf("⚠️  Webhook %s delivery of %s failed after %d attempt(s): %v", hook.URL, event.Type, attempt, err)
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+// post makes one delivery attempt, reporting whether a failure is worth
+// retrying
+func (m *Manager) post(ctx context.Context, hook types.Webhook, eventType, deliveryID string, attempt int, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("X-Gops-Event", eventType)
+	req.Header.Set("X-Gops-Delivery", deliveryID)
+	req.Header.Set("X-Gops-Attempt", strconv.Itoa(attempt))
+	if hook.Secret != "" {
+		req.Header.Set("X-Gops-Signature", "sha256="+sign(hook.Secret, body))
+	}
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, err
+	}
+	resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return true, fmt.Errorf("unexpected status %s", resp.Status)
+	default:
+		return false, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+}
+
+// sign returns the hex HMAC-SHA256 of body keyed by secret
+func sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// redact hides the signing secret
+func redact(hook types.Webhook) types.Webhook {
+	hook.Secret = ""
+	return hook
+}
+
+// ParseURLs turns a comma-separated list of URLs into webhooks that
+// receive every event
+func ParseURLs(value string) []types.Webhook {
+	var hooks []types.Webhook
+	for _, u := range strings.Split(value, ",") {
+		if u = strings.TrimSpace(u); u != "" {
+			hooks = append(hooks, types.Webhook{URL: u})
+		}
+	}
+	return hooks
+}
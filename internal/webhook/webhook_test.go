@@ -0,0 +1,102 @@
+package webhook
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/borankux/gops/internal/events"
+	"github.com/borankux/gops/pkg/types"
+)
+
+func TestAddLimit(t *testing.T) {
+	m := NewManager(events.NewBus())
+	defer m.stop()
+	for i := 0; i < MaxHooks; i++ {
+		if _, err := m.Add(types.Webhook{URL: "http://127.0.0.1:1/hook"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := m.Add(types.Webhook{URL: "http://127.0.0.1:1/hook"}); !errors.Is(err, ErrTooManyHooks) {
+		t.Fatalf("Add beyond MaxHooks: err = %v, want ErrTooManyHooks", err)
+	}
+}
+
+// TestQueueBounded blocks the endpoint, floods the webhook with events
+// and checks that those beyond the queue are dropped and the rest are
+// delivered in order once it answers
+func TestQueueBounded(t *testing.T) {
+	release := make(chan struct{})
+	var mu sync.Mutex
+	var got []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		var event types.Event
+		json.NewDecoder(r.Body).Decode(&event)
+		mu.Lock()
+		got = append(got, event.Type)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	m := NewManager(events.NewBus())
+	defer m.stop()
+	if _, err := m.Add(types.Webhook{URL: server.URL}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The first event is taken by the worker, queueSize more wait
+	total := queueSize + 10
+	m.enqueue(types.Event{Type: "e0"})
+	time.Sleep(50 * time.Millisecond)
+	for i := 1; i < total; i++ {
+		m.enqueue(types.Event{Type: fmt.Sprintf("e%d", i)})
+	}
+	close(release)
+
+	want := queueSize + 1
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		mu.Lock()
+		n := len(got)
+		mu.Unlock()
+		if n >= want || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(got) != want {
+		t.Fatalf("delivered %d events, want %d", len(got), want)
+	}
+	for i, typ := range got {
+		if wantType := fmt.Sprintf("e%d", i); typ != wantType {
+			t.Fatalf("event %d = %s, want %s", i, typ, wantType)
+		}
+	}
+}
+
+func TestRemoveStopsWorker(t *testing.T) {
+	m := NewManager(events.NewBus())
+	hook, err := m.Add(types.Webhook{URL: "http://127.0.0.1:1/hook"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !m.Remove(hook.ID) {
+		t.Fatal("Remove reported an unknown webhook")
+	}
+	if m.Remove(hook.ID) {
+		t.Fatal("Remove succeeded twice")
+	}
+	if m.Len() != 0 {
+		t.Fatalf("Len = %d after Remove", m.Len())
+	}
+}
@@ -0,0 +1,584 @@
+package window
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/borankux/gops/internal/permission"
+	"github.com/borankux/gops/pkg/types"
+)
+
+// ErrNotFound is returned when no open window matches a query
+var ErrNotFound = errors.New("window not found")
+
+// ErrNoDisplay is returned when a requested display is not connected
+var ErrNoDisplay = errors.New("display not found")
+
+// ErrNoSession is returned by Ready on Linux hosts without a graphical
+// session, where there are no windows to list
+var ErrNoSession = errors.New("no X11 or Wayland display")
+
+// ErrUnsupported is returned for window actions the Linux backend in use
+// can't take, such as any action on windows listed through lswt
+var ErrUnsupported = errors.New("not supported by this window backend")
+
+// Query selects an open window by its ID, or by its owning process and
+// title. Zero fields are ignored.
+type Query struct {
+	ID  uint32
+	PID int32
+	// Title matches window titles containing it, ignoring case
+	Title string
+}
+
+// String describes q for error messages
+func (q Query) String() string {
+	var parts []string
+	if q.ID != 0 {
+		parts = append(parts, fmt.Sprintf("id %d", q.ID))
+	}
+	if q.PID != 0 {
+		parts = append(parts, fmt.Sprintf("PID %d", q.PID))
+	}
+	if q.Title != "" {
+		parts = append(parts, fmt.Sprintf("title %q", q.Title))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Find returns the frontmost open window matching q
+func Find(ctx context.Context, q Query) (types.WindowInfo, error) {
+	windows, err := GetOpenWindows(ctx)
+	if err != nil {
+		return types.WindowInfo{}, err
+	}
+
+	title := strings.ToLower(q.Title)
+	var match *types.WindowInfo
+	for i, w := range windows {
+		if (q.ID != 0 && w.ID != q.ID) || (q.PID != 0 && w.PID != q.PID) ||
+			!strings.Contains(strings.ToLower(w.Title), title) {
+			continue
+		}
+		if match == nil || (w.ZOrder > 0 && (match.ZOrder == 0 || w.ZOrder < match.ZOrder)) {
+			match = &windows[i]
+		}
+	}
+	if match == nil {
+		return types.WindowInfo{}, fmt.Errorf("%w: %s", ErrNotFound, q)
+	}
+	return *match, nil
+}
+
+// Focus brings the window matching q to the foreground, restoring it if
+// it is minimized
+func Focus(ctx context.Context, q Query) (types.WindowInfo, error) {
+	w, err := Find(ctx, q)
+	if err != nil {
+		return types.WindowInfo{}, err
+	}
+
+	switch runtime.GOOS {
+	case "darwin":
+		err = focusMacOSWindow(ctx, w)
+	case "linux":
+		err = focusLinuxWindow(ctx, w)
+	case "windows":
+		err = focusWindowsWindow(ctx, w)
+	default:
+		err = errors.New("focusing windows is not supported on " + runtime.GOOS)
+	}
+	if err != nil {
+		return types.WindowInfo{}, err
+	}
+	return w, nil
+}
+
+// Close asks the window matching q to close, as if its close button had
+// been clicked; the app may still prompt to save changes. With dryRun set
+// the window is only looked up.
+func Close(ctx context.Context, q Query, dryRun bool) (types.WindowInfo, error) {
+	w, err := Find(ctx, q)
+	if err != nil || dryRun {
+		return w, err
+	}
+
+	switch runtime.GOOS {
+	case "darwin":
+		err = closeMacOSWindow(ctx, w)
+	case "linux":
+		err = closeLinuxWindow(ctx, w)
+	case "windows":
+		err = closeWindowsWindow(ctx, w)
+	default:
+		err = errors.New("closing windows is not supported on " + runtime.GOOS)
+	}
+	if err != nil {
+		return types.WindowInfo{}, err
+	}
+	return w, nil
+}
+
+// Minimize minimizes the window matching q to the Dock or taskbar
+func Minimize(ctx context.Context, q Query) (types.WindowInfo, error) {
+	w, err := Find(ctx, q)
+	if err != nil {
+		return types.WindowInfo{}, err
+	}
+
+	switch runtime.GOOS {
+	case "darwin":
+		err = macOSWindowScript(ctx, w, `set value of attribute "AXMinimized" of win to true`)
+	case "linux":
+		err = minimizeLinuxWindow(ctx, w)
+	case "windows":
+		err = showWindowsWindow(ctx, w, swMinimize)
+	default:
+		err = errors.New("minimizing windows is not supported on " + runtime.GOOS)
+	}
+	if err != nil {
+		return types.WindowInfo{}, err
+	}
+	w.Minimized = true
+	return w, nil
+}
+
+// Restore unminimizes the window matching q and, on macOS, unhides its
+// app. On Linux the window is also activated, and on sway brought back
+// from the scratchpad.
+func Restore(ctx context.Context, q Query) (types.WindowInfo, error) {
+	w, err := Find(ctx, q)
+	if err != nil {
+		return types.WindowInfo{}, err
+	}
+
+	switch runtime.GOOS {
+	case "darwin":
+		err = macOSWindowScript(ctx, w, `set visible of proc to true
+			set value of attribute "AXMinimized" of win to false`)
+	case "linux":
+		err = restoreLinuxWindow(ctx, w)
+	case "windows":
+		err = showWindowsWindow(ctx, w, swRestore)
+	default:
+		err = errors.New("restoring windows is not supported on " + runtime.GOOS)
+	}
+	if err != nil {
+		return types.WindowInfo{}, err
+	}
+	w.Minimized, w.Hidden = false, false
+	return w, nil
+}
+
+// Frame presets, laid out on a display
+const (
+	PresetLeftHalf   = "left_half"
+	PresetRightHalf  = "right_half"
+	PresetTopHalf    = "top_half"
+	PresetBottomHalf = "bottom_half"
+	PresetMaximize   = "maximize"
+	PresetCenter     = "center"
+)
+
+// Presets lists the frame presets
+var Presets = []string{
+	PresetLeftHalf, PresetRightHalf, PresetTopHalf, PresetBottomHalf,
+	PresetMaximize, PresetCenter,
+}
+
+// Frame is a requested window position and size: either a preset or
+// explicit values, where nil values keep the window's current ones
+type Frame struct {
+	Preset string
+	// Display is the 1-based display a preset is laid out on, 0 for the
+	// display showing the window
+	Display int
+	X, Y    *int
+	Width   *int
+	Height  *int
+}
+
+// Move sets the position and size of the window matching q, restoring it
+// first if it is minimized or maximized
+func Move(ctx context.Context, q Query, f Frame) (types.WindowInfo, error) {
+	w, err := Find(ctx, q)
+	if err != nil {
+		return types.WindowInfo{}, err
+	}
+	if w.Geometry == nil {
+		return types.WindowInfo{}, fmt.Errorf("the geometry of %q is unknown", w.Title)
+	}
+
+	target := *w.Geometry
+	if f.Preset != "" {
+		if target, w.Display, err = presetFrame(ctx, f, w); err != nil {
+			return types.WindowInfo{}, err
+		}
+	}
+	if f.X != nil {
+		target.X = *f.X
+	}
+	if f.Y != nil {
+		target.Y = *f.Y
+	}
+	if f.Width != nil {
+		target.Width = *f.Width
+	}
+	if f.Height != nil {
+		target.Height = *f.Height
+	}
+	if target.Width <= 0 || target.Height <= 0 {
+		return types.WindowInfo{}, fmt.Errorf("invalid window size %dx%d", target.Width, target.Height)
+	}
+
+	switch runtime.GOOS {
+	case "darwin":
+		// Setting the position again after the size keeps windows that
+		// moved to another display from being clamped to the old one
+		err = macOSWindowScript(ctx, w, fmt.Sprintf(`set value of attribute "AXMinimized" of win to false
+			set position of win to {%[1]d, %[2]d}
+			set size of win to {%[3]d, %[4]d}
+			set position of win to {%[1]d, %[2]d}`, target.X, target.Y, target.Width, target.Height))
+	case "linux":
+		err = moveLinuxWindow(ctx, w, target)
+	case "windows":
+		err = moveWindowsWindow(ctx, w, target)
+	default:
+		err = errors.New("moving windows is not supported on " + runtime.GOOS)
+	}
+	if err != nil {
+		return types.WindowInfo{}, err
+	}
+	w.Geometry = &target
+	w.Minimized = false
+	return w, nil
+}
+
+// presetFrame lays out f.Preset on the chosen display, returning the frame
+// and the display's number
+func presetFrame(ctx context.Context, f Frame, w types.WindowInfo) (types.WindowGeometry, int, error) {
+	displays, err := GetDisplays(ctx)
+	if err != nil {
+		return types.WindowGeometry{}, 0, err
+	}
+	index := f.Display
+	if index == 0 {
+		index = w.Display
+	}
+	if index == 0 {
+		index = 1
+	}
+	if index > len(displays) || displays[index-1].Bounds == nil {
+		return types.WindowGeometry{}, 0, fmt.Errorf("%w: %d", ErrNoDisplay, index)
+	}
+
+	d := *displays[index-1].Bounds
+	switch f.Preset {
+	case PresetLeftHalf:
+		return types.WindowGeometry{X: d.X, Y: d.Y, Width: d.Width / 2, Height: d.Height}, index, nil
+	case PresetRightHalf:
+		return types.WindowGeometry{X: d.X + d.Width/2, Y: d.Y, Width: d.Width - d.Width/2, Height: d.Height}, index, nil
+	case PresetTopHalf:
+		return types.WindowGeometry{X: d.X, Y: d.Y, Width: d.Width, Height: d.Height / 2}, index, nil
+	case PresetBottomHalf:
+		return types.WindowGeometry{X: d.X, Y: d.Y + d.Height/2, Width: d.Width, Height: d.Height - d.Height/2}, index, nil
+	case PresetMaximize:
+		return d, index, nil
+	case PresetCenter:
+		width, height := min(w.Geometry.Width, d.Width), min(w.Geometry.Height, d.Height)
+		return types.WindowGeometry{X: d.X + (d.Width-width)/2, Y: d.Y + (d.Height-height)/2, Width: width, Height: height}, index, nil
+	default:
+		return types.WindowGeometry{}, 0, fmt.Errorf("unknown preset %q", f.Preset)
+	}
+}
+
+// Arrangement layouts
+const (
+	// LayoutSideBySide gives each window an equal column, left to right
+	LayoutSideBySide = "side_by_side"
+	// LayoutStack gives each window an equal row, top to bottom
+	LayoutStack = "stack"
+	// LayoutGrid lays windows out left to right in rows, using as many
+	// columns as rows or one more
+	LayoutGrid = "grid"
+)
+
+// Layouts lists the arrangement layouts
+var Layouts = []string{LayoutSideBySide, LayoutStack, LayoutGrid}
+
+// Arrange lays out the windows with the given IDs on a display, in order:
+// the first window gets the leftmost column, the top row or the top left
+// cell. display is 1-based, 0 for the display showing the first window.
+// Each window is moved like Move, so minimized and maximized windows are
+// restored first. It returns the arranged windows and the display's
+// number.
+func Arrange(ctx context.Context, layout string, ids []uint32, display int) ([]types.WindowInfo, int, error) {
+	if len(ids) == 0 {
+		return nil, 0, errors.New("no windows to arrange")
+	}
+	// Check every window exists before moving any of them
+	for _, id := range ids {
+		w, err := Find(ctx, Query{ID: id})
+		if err != nil {
+			return nil, 0, err
+		}
+		if display == 0 {
+			display = w.Display
+		}
+	}
+	if display == 0 {
+		display = 1
+	}
+	displays, err := GetDisplays(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	if display > len(displays) || displays[display-1].Bounds == nil {
+		return nil, 0, fmt.Errorf("%w: %d", ErrNoDisplay, display)
+	}
+	frames, err := layoutFrames(layout, *displays[display-1].Bounds, len(ids))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	windows := make([]types.WindowInfo, 0, len(ids))
+	for i, id := range ids {
+		g := frames[i]
+		w, err := Move(ctx, Query{ID: id}, Frame{X: &g.X, Y: &g.Y, Width: &g.Width, Height: &g.Height})
+		if err != nil {
+			return windows, display, err
+		}
+		w.Display = display
+		windows = append(windows, w)
+	}
+	return windows, display, nil
+}
+
+// layoutFrames splits the display area d into n frames for layout. The
+// last column and row take up any remainder.
+func layoutFrames(layout string, d types.WindowGeometry, n int) ([]types.WindowGeometry, error) {
+	var columns, rows int
+	switch layout {
+	case LayoutSideBySide:
+		columns, rows = n, 1
+	case LayoutStack:
+		columns, rows = 1, n
+	case LayoutGrid:
+		columns = int(math.Ceil(math.Sqrt(float64(n))))
+		rows = (n + columns - 1) / columns
+	default:
+		return nil, fmt.Errorf("unknown layout %q", layout)
+	}
+
+	frames := make([]types.WindowGeometry, n)
+	for i := range frames {
+		column, row := i%columns, i/columns
+		x, y := d.X+d.Width*column/columns, d.Y+d.Height*row/rows
+		frames[i] = types.WindowGeometry{
+			X:      x,
+			Y:      y,
+			Width:  d.X + d.Width*(column+1)/columns - x,
+			Height: d.Y + d.Height*(row+1)/rows - y,
+		}
+	}
+	return frames, nil
+}
+
+// HideApp hides the app owning pid on macOS, like Command-H. Linux and
+// Windows have no app hiding, so the app's windows are minimized instead.
+// It returns the windows of the app.
+func HideApp(ctx context.Context, pid int32) ([]types.WindowInfo, error) {
+	all, err := GetOpenWindows(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var windows []types.WindowInfo
+	for _, w := range all {
+		if w.PID == pid {
+			windows = append(windows, w)
+		}
+	}
+	if len(windows) == 0 {
+		return nil, fmt.Errorf("%w: no windows for PID %d", ErrNotFound, pid)
+	}
+
+	switch runtime.GOOS {
+	case "darwin":
+		script := `on run argv
+			tell application "System Events"
+				set visible of (first process whose unix id is ((item 1 of argv) as integer)) to false
+			end tell
+		end run`
+		cmd := exec.CommandContext(ctx, "osascript", "-e", script, strconv.Itoa(int(pid)))
+		if output, err := cmd.CombinedOutput(); err != nil {
+			return nil, osascriptError(output)
+		}
+		for i := range windows {
+			windows[i].Hidden = true
+		}
+	default:
+		for i, w := range windows {
+			if w.Minimized {
+				continue
+			}
+			if windows[i], err = Minimize(ctx, Query{ID: w.ID}); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return windows, nil
+}
+
+// focusMacOSWindow activates the app owning w with System Events and
+// raises the window with the matching title. The PID and title are
+// passed as arguments so they need no quoting.
+func focusMacOSWindow(ctx context.Context, w types.WindowInfo) error {
+	script := `on run argv
+		set targetPID to (item 1 of argv) as integer
+		set targetTitle to item 2 of argv
+		tell application "System Events"
+			set proc to first process whose unix id is targetPID
+			set frontmost of proc to true
+			try
+				set win to first window of proc whose name is targetTitle
+				try
+					set value of attribute "AXMinimized" of win to false
+				end try
+				perform action "AXRaise" of win
+			end try
+		end tell
+	end run`
+
+	cmd := exec.CommandContext(ctx, "osascript", "-e", script, strconv.Itoa(int(w.PID)), w.Title)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return osascriptError(output)
+	}
+	return nil
+}
+
+// focusWindowsWindow restores w if it is minimized and makes it the
+// foreground window
+func focusWindowsWindow(ctx context.Context, w types.WindowInfo) error {
+	psScript := `
+		Add-Type @"
+using System;
+using System.Runtime.InteropServices;
+public class Focus {
+	[DllImport("user32.dll")] public static extern bool SetForegroundWindow(IntPtr hWnd);
+	[DllImport("user32.dll")] public static extern bool ShowWindow(IntPtr hWnd, int cmd);
+	[DllImport("user32.dll")] public static extern bool IsIconic(IntPtr hWnd);
+}
+"@
+		$h = [IntPtr]` + strconv.FormatUint(uint64(w.ID), 10) + `
+		if ([Focus]::IsIconic($h)) { [void][Focus]::ShowWindow($h, 9) }
+		if (-not [Focus]::SetForegroundWindow($h)) { exit 1 }
+	`
+
+	if err := exec.CommandContext(ctx, "powershell", "-Command", psScript).Run(); err != nil {
+		return errors.New("windows refused to bring the window to the foreground")
+	}
+	return nil
+}
+
+// closeMacOSWindow presses the close button of w through the
+// accessibility API
+func closeMacOSWindow(ctx context.Context, w types.WindowInfo) error {
+	return macOSWindowScript(ctx, w, `click (first button of win whose subrole is "AXCloseButton")`)
+}
+
+// macOSWindowScript runs AppleScript commands within System Events with
+// proc set to the process owning w and win to the window itself
+func macOSWindowScript(ctx context.Context, w types.WindowInfo, commands string) error {
+	script := `on run argv
+		set targetPID to (item 1 of argv) as integer
+		set targetTitle to item 2 of argv
+		tell application "System Events"
+			set proc to first process whose unix id is targetPID
+			set win to first window of proc whose name is targetTitle
+			` + commands + `
+		end tell
+	end run`
+
+	cmd := exec.CommandContext(ctx, "osascript", "-e", script, strconv.Itoa(int(w.PID)), w.Title)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return osascriptError(output)
+	}
+	return nil
+}
+
+// ShowWindow commands
+const (
+	swMinimize = 6
+	swRestore  = 9
+)
+
+// showWindowsWindow calls ShowWindow on w with the given command
+func showWindowsWindow(ctx context.Context, w types.WindowInfo, command int) error {
+	psScript := `
+		Add-Type @"
+using System;
+using System.Runtime.InteropServices;
+public class Show {
+	[DllImport("user32.dll")] public static extern bool ShowWindow(IntPtr hWnd, int cmd);
+}
+"@
+		[void][Show]::ShowWindow([IntPtr]` + strconv.FormatUint(uint64(w.ID), 10) + `, ` + strconv.Itoa(command) + `)
+	`
+	return exec.CommandContext(ctx, "powershell", "-Command", psScript).Run()
+}
+
+// moveWindowsWindow restores w and moves it to frame with MoveWindow
+func moveWindowsWindow(ctx context.Context, w types.WindowInfo, frame types.WindowGeometry) error {
+	hwnd := strconv.FormatUint(uint64(w.ID), 10)
+	psScript := fmt.Sprintf(`
+		Add-Type @"
+using System;
+using System.Runtime.InteropServices;
+public class Move {
+	[DllImport("user32.dll")] public static extern bool ShowWindow(IntPtr hWnd, int cmd);
+	[DllImport("user32.dll")] public static extern bool MoveWindow(IntPtr hWnd, int x, int y, int width, int height, bool repaint);
+}
+"@
+		[void][Move]::ShowWindow([IntPtr]%s, %d)
+		if (-not [Move]::MoveWindow([IntPtr]%s, %d, %d, %d, %d, $true)) { exit 1 }
+	`, hwnd, swRestore, hwnd, frame.X, frame.Y, frame.Width, frame.Height)
+
+	if err := exec.CommandContext(ctx, "powershell", "-Command", psScript).Run(); err != nil {
+		return errors.New("failed to move the window")
+	}
+	return nil
+}
+
+// closeWindowsWindow posts WM_CLOSE to w
+func closeWindowsWindow(ctx context.Context, w types.WindowInfo) error {
+	psScript := `
+		Add-Type @"
+using System;
+using System.Runtime.InteropServices;
+public class Close {
+	[DllImport("user32.dll")] public static extern bool PostMessage(IntPtr hWnd, uint msg, IntPtr wParam, IntPtr lParam);
+}
+"@
+		if (-not [Close]::PostMessage([IntPtr]` + strconv.FormatUint(uint64(w.ID), 10) + `, 0x0010, [IntPtr]::Zero, [IntPtr]::Zero)) { exit 1 }
+	`
+
+	if err := exec.CommandContext(ctx, "powershell", "-Command", psScript).Run(); err != nil {
+		return errors.New("failed to send WM_CLOSE to the window")
+	}
+	return nil
+}
+
+// osascriptError describes a failed osascript run from its combined
+// output, as a *permission.Error when a missing permission caused it
+func osascriptError(output []byte) error {
+	if err := permission.FromAppleScript(string(output)); err != nil {
+		return err
+	}
+	return fmt.Errorf("osascript: %s", strings.TrimSpace(string(output)))
+}
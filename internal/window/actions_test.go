@@ -0,0 +1,52 @@
+package window
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/borankux/gops/pkg/types"
+)
+
+func TestLayoutFrames(t *testing.T) {
+	display := types.WindowGeometry{X: 100, Y: 25, Width: 1000, Height: 601}
+	geometry := func(x, y, width, height int) types.WindowGeometry {
+		return types.WindowGeometry{X: x, Y: y, Width: width, Height: height}
+	}
+	tests := []struct {
+		name   string
+		layout string
+		n      int
+		want   []types.WindowGeometry
+	}{
+		{"single window fills the display", LayoutGrid, 1, []types.WindowGeometry{geometry(100, 25, 1000, 601)}},
+		{"side by side", LayoutSideBySide, 3, []types.WindowGeometry{
+			geometry(100, 25, 333, 601), geometry(433, 25, 333, 601), geometry(766, 25, 334, 601),
+		}},
+		{"stack", LayoutStack, 2, []types.WindowGeometry{
+			geometry(100, 25, 1000, 300), geometry(100, 325, 1000, 301),
+		}},
+		{"square grid", LayoutGrid, 4, []types.WindowGeometry{
+			geometry(100, 25, 500, 300), geometry(600, 25, 500, 300),
+			geometry(100, 325, 500, 301), geometry(600, 325, 500, 301),
+		}},
+		{"partial last row", LayoutGrid, 5, []types.WindowGeometry{
+			geometry(100, 25, 333, 300), geometry(433, 25, 333, 300), geometry(766, 25, 334, 300),
+			geometry(100, 325, 333, 301), geometry(433, 325, 333, 301),
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := layoutFrames(tt.layout, display, tt.n)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("layoutFrames(%s, %d) =\n%v\nwant\n%v", tt.layout, tt.n, got, tt.want)
+			}
+		})
+	}
+
+	if _, err := layoutFrames("spiral", display, 2); err == nil {
+		t.Error("unknown layout accepted")
+	}
+}
@@ -0,0 +1,155 @@
+package window
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strconv"
+
+	"github.com/borankux/gops/pkg/types"
+)
+
+// Linux window listing backends
+const (
+	BackendWmctrl   = "wmctrl"
+	BackendXprop    = "xprop"
+	BackendXdotool  = "xdotool"
+	BackendSway     = "sway"
+	BackendHyprland = "hyprland"
+	BackendWlr      = "wlr-foreign-toplevel"
+)
+
+// backendCommands are the commands the backends run
+var backendCommands = map[string]string{
+	BackendWmctrl:   "wmctrl",
+	BackendXprop:    "xprop",
+	BackendXdotool:  "xdotool",
+	BackendSway:     "swaymsg",
+	BackendHyprland: "hyprctl",
+	BackendWlr:      "lswt",
+}
+
+// Backend returns the backend listing windows on Linux, and "" on other
+// platforms
+func Backend() string {
+	if runtime.GOOS != "linux" {
+		return ""
+	}
+	return linuxBackend()
+}
+
+// linuxBackend picks the backend listing windows: the Wayland
+// compositor's where supported, and otherwise wmctrl, falling back to
+// reading EWMH properties with xprop or to xdotool when wmctrl isn't
+// installed
+func linuxBackend() string {
+	if backend := waylandBackend(); backend != "" {
+		return backend
+	}
+	for _, backend := range []string{BackendWmctrl, BackendXprop, BackendXdotool} {
+		if _, err := exec.LookPath(backendCommands[backend]); err == nil {
+			return backend
+		}
+	}
+	// Fail naming the command to install
+	return BackendWmctrl
+}
+
+// focusLinuxWindow activates w, switching to its workspace
+func focusLinuxWindow(ctx context.Context, w types.WindowInfo) error {
+	switch backend := linuxBackend(); backend {
+	case BackendSway:
+		return swayCommand(ctx, w, "focus")
+	case BackendHyprland:
+		return hyprlandDispatch(ctx, w, "focuswindow address:%s")
+	case BackendWlr:
+		return unsupported("focusing", backend)
+	}
+	return x11Command(ctx, w, []string{"-a"}, []string{"windowactivate"})
+}
+
+// closeLinuxWindow asks w to close
+func closeLinuxWindow(ctx context.Context, w types.WindowInfo) error {
+	switch backend := linuxBackend(); backend {
+	case BackendSway:
+		return swayCommand(ctx, w, "kill")
+	case BackendHyprland:
+		return hyprlandDispatch(ctx, w, "closewindow address:%s")
+	case BackendWlr:
+		return unsupported("closing", backend)
+	}
+	return x11Command(ctx, w, []string{"-c"}, []string{"windowclose"})
+}
+
+// minimizeLinuxWindow minimizes w on X11, where wmctrl can't, so it needs
+// xdotool. sway has no minimizing, so windows go to the scratchpad.
+func minimizeLinuxWindow(ctx context.Context, w types.WindowInfo) error {
+	switch backend := linuxBackend(); backend {
+	case BackendSway:
+		return swayCommand(ctx, w, "move scratchpad")
+	case BackendHyprland, BackendWlr:
+		return unsupported("minimizing", backend)
+	}
+	return exec.CommandContext(ctx, "xdotool", "windowminimize", strconv.FormatUint(uint64(w.ID), 10)).Run()
+}
+
+// restoreLinuxWindow unminimizes and activates w, bringing it back from
+// the scratchpad on sway
+func restoreLinuxWindow(ctx context.Context, w types.WindowInfo) error {
+	switch backend := linuxBackend(); backend {
+	case BackendSway:
+		if w.Hidden {
+			return swayCommand(ctx, w, "scratchpad show")
+		}
+		return swayCommand(ctx, w, "focus")
+	case BackendHyprland:
+		if w.Hidden {
+			return unsupported("restoring hidden", backend)
+		}
+		return hyprlandDispatch(ctx, w, "focuswindow address:%s")
+	case BackendWlr:
+		return unsupported("restoring", backend)
+	}
+	return x11Command(ctx, w, []string{"-a"}, []string{"windowactivate"})
+}
+
+// moveLinuxWindow sets the frame of w. Tiling compositors only place
+// floating windows, so sway and Hyprland float w first.
+func moveLinuxWindow(ctx context.Context, w types.WindowInfo, target types.WindowGeometry) error {
+	switch backend := linuxBackend(); backend {
+	case BackendSway:
+		return swayCommand(ctx, w, fmt.Sprintf("floating enable, resize set %d px %d px, move absolute position %d px %d px",
+			target.Width, target.Height, target.X, target.Y))
+	case BackendHyprland:
+		return hyprlandDispatch(ctx, w, "setfloating address:%[1]s",
+			fmt.Sprintf("resizewindowpixel exact %d %d,address:%%[1]s", target.Width, target.Height),
+			fmt.Sprintf("movewindowpixel exact %d %d,address:%%[1]s", target.X, target.Y))
+	case BackendWlr:
+		return unsupported("moving", backend)
+	}
+	return moveX11Window(ctx, w, target)
+}
+
+// captureLinuxWindow takes a PNG screenshot of w: of the window itself
+// with ImageMagick's import on X11, and of its area of the screen with
+// grim on sway and Hyprland
+func captureLinuxWindow(ctx context.Context, w types.WindowInfo) ([]byte, error) {
+	switch backend := linuxBackend(); backend {
+	case BackendSway, BackendHyprland:
+		g := w.Geometry
+		if g == nil || g.Width <= 0 || g.Height <= 0 {
+			return nil, fmt.Errorf("the geometry of %q is unknown", w.Title)
+		}
+		region := fmt.Sprintf("%d,%d %dx%d", g.X, g.Y, g.Width, g.Height)
+		return exec.CommandContext(ctx, "grim", "-t", "png", "-g", region, "-").Output()
+	case BackendWlr:
+		return nil, unsupported("capturing", backend)
+	}
+	return exec.CommandContext(ctx, "import", "-window", fmt.Sprintf("0x%08x", w.ID), "png:-").Output()
+}
+
+// unsupported returns ErrUnsupported for an action the backend can't take
+func unsupported(action, backend string) error {
+	return fmt.Errorf("%s windows with %s: %w", action, backend, ErrUnsupported)
+}
@@ -0,0 +1,180 @@
+package window
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image/png"
+	"math"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/borankux/gops/internal/permission"
+	"github.com/borankux/gops/pkg/types"
+)
+
+// Capture takes a PNG screenshot of the window matching q. macOS captures
+// the window itself with screencapture, which needs Screen Recording
+// access; Linux uses ImageMagick's import on X11 and grim on sway and
+// Hyprland, and Windows copies the window's area of the screen, so
+// overlapping windows show up there.
+func Capture(ctx context.Context, q Query) (types.WindowInfo, []byte, error) {
+	w, err := Find(ctx, q)
+	if err != nil {
+		return types.WindowInfo{}, nil, err
+	}
+	if w.Minimized || w.Hidden {
+		return types.WindowInfo{}, nil, fmt.Errorf("%q is not shown on screen", w.Title)
+	}
+
+	var data []byte
+	switch runtime.GOOS {
+	case "darwin":
+		data, err = captureMacOSWindow(ctx, w)
+	case "linux":
+		data, err = captureLinuxWindow(ctx, w)
+	case "windows":
+		data, err = captureWindowsWindow(ctx, w)
+	default:
+		err = errors.New("capturing windows is not supported on " + runtime.GOOS)
+	}
+	if err != nil {
+		return types.WindowInfo{}, nil, err
+	}
+	return w, data, nil
+}
+
+// captureMacOSWindow captures w without its shadow. screencapture writes
+// to a file only, and captures the desktop instead of failing when gops
+// lacks Screen Recording access, so that is checked first.
+func captureMacOSWindow(ctx context.Context, w types.WindowInfo) ([]byte, error) {
+	if w.ID == 0 {
+		return nil, errors.New("capturing needs the window ID, which the AppleScript fallback doesn't report")
+	}
+	if permission.Denied(ctx, permission.ScreenRecording) {
+		return nil, &permission.Error{Permission: permission.ScreenRecording}
+	}
+
+	f, err := os.CreateTemp("", "gops-capture-*.png")
+	if err != nil {
+		return nil, err
+	}
+	f.Close()
+	defer os.Remove(f.Name())
+
+	cmd := exec.CommandContext(ctx, "screencapture", "-x", "-o", "-t", "png", "-l", strconv.FormatUint(uint64(w.ID), 10), f.Name())
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("screencapture: %s", strings.TrimSpace(string(output)))
+	}
+	return os.ReadFile(f.Name())
+}
+
+// captureWindowsWindow copies the screen area of w into a PNG
+func captureWindowsWindow(ctx context.Context, w types.WindowInfo) ([]byte, error) {
+	g := w.Geometry
+	if g == nil || g.Width <= 0 || g.Height <= 0 {
+		return nil, fmt.Errorf("the geometry of %q is unknown", w.Title)
+	}
+	psScript := fmt.Sprintf(`
+		Add-Type -AssemblyName System.Drawing
+		$bmp = New-Object System.Drawing.Bitmap %[3]d, %[4]d
+		$gfx = [System.Drawing.Graphics]::FromImage($bmp)
+		$gfx.CopyFromScreen(%[1]d, %[2]d, 0, 0, $bmp.Size)
+		$ms = New-Object System.IO.MemoryStream
+		$bmp.Save($ms, [System.Drawing.Imaging.ImageFormat]::Png)
+		[Convert]::ToBase64String($ms.ToArray())
+	`, g.X, g.Y, g.Width, g.Height)
+
+	output, err := exec.CommandContext(ctx, "powershell", "-Command", psScript).Output()
+	if err != nil {
+		return nil, errors.New("failed to capture the window")
+	}
+	return base64.StdEncoding.DecodeString(strings.TrimSpace(string(output)))
+}
+
+// RecognizeText finds the text in a PNG image with OCR, using the Vision
+// framework on macOS. Bounding boxes are in image pixels from the top
+// left.
+func RecognizeText(ctx context.Context, image []byte) ([]types.RecognizedText, error) {
+	if runtime.GOOS != "darwin" {
+		return nil, errors.New("text recognition is not supported on " + runtime.GOOS)
+	}
+	config, err := png.DecodeConfig(bytes.NewReader(image))
+	if err != nil {
+		return nil, fmt.Errorf("decoding image: %w", err)
+	}
+
+	f, err := os.CreateTemp("", "gops-ocr-*.png")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(f.Name())
+	_, err = f.Write(image)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Vision reports boxes normalized to the image size, from the bottom
+	// left
+	script := `ObjC.import('Vision')
+		function run(argv) {
+			const url = $.NSURL.fileURLWithPath(argv[0])
+			const handler = $.VNImageRequestHandler.alloc.initWithURLOptions(url, $.NSDictionary.dictionary)
+			const request = $.VNRecognizeTextRequest.alloc.init
+			request.recognitionLevel = $.VNRequestTextRecognitionLevelAccurate
+			request.usesLanguageCorrection = true
+			if (!handler.performRequestsError($.NSArray.arrayWithObject(request), null)) {
+				throw new Error('text recognition failed')
+			}
+			const results = request.results
+			const found = []
+			for (let i = 0; i < results.count; i++) {
+				const candidate = results.objectAtIndex(i).topCandidates(1).objectAtIndex(0)
+				const box = results.objectAtIndex(i).boundingBox
+				found.push({text: candidate.string.js, confidence: candidate.confidence,
+					x: box.origin.x, y: box.origin.y, width: box.size.width, height: box.size.height})
+			}
+			return JSON.stringify(found)
+		}`
+	output, err := exec.CommandContext(ctx, "osascript", "-l", "JavaScript", "-e", script, f.Name()).Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return nil, fmt.Errorf("osascript: %s", strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, err
+	}
+
+	var found []struct {
+		Text                string
+		Confidence          float64
+		X, Y, Width, Height float64
+	}
+	if err := json.Unmarshal(output, &found); err != nil {
+		return nil, fmt.Errorf("parsing Vision output: %w", err)
+	}
+	width, height := float64(config.Width), float64(config.Height)
+	texts := make([]types.RecognizedText, 0, len(found))
+	for _, t := range found {
+		texts = append(texts, types.RecognizedText{
+			Text:       t.Text,
+			Confidence: math.Round(t.Confidence*100) / 100,
+			Bounds: types.WindowGeometry{
+				X:      int(math.Round(t.X * width)),
+				Y:      int(math.Round((1 - t.Y - t.Height) * height)),
+				Width:  int(math.Round(t.Width * width)),
+				Height: int(math.Round(t.Height * height)),
+			},
+		})
+	}
+	return texts, nil
+}
@@ -0,0 +1,250 @@
+package window
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/borankux/gops/pkg/types"
+)
+
+// GetDisplays returns the connected displays, the primary one first, each
+// with the number of open windows it shows. Displays are numbered like
+// WindowInfo.Display.
+func GetDisplays(ctx context.Context) ([]types.DisplayInfo, error) {
+	var displays []types.DisplayInfo
+	var err error
+	switch runtime.GOOS {
+	case "darwin":
+		displays, err = getMacOSDisplays(ctx)
+	case "linux":
+		displays, err = getLinuxDisplays(ctx)
+	case "windows":
+		displays, err = getWindowsDisplays(ctx)
+	}
+	if err != nil || len(displays) == 0 {
+		return displays, err
+	}
+
+	// Window counts are best effort; displays are still worth listing
+	// when windows can't be
+	if windows, err := GetOpenWindows(ctx); err == nil {
+		for _, w := range windows {
+			if w.Display > 0 && w.Display <= len(displays) {
+				displays[w.Display-1].Windows++
+			}
+		}
+	}
+	return displays, nil
+}
+
+// getMacOSDisplays reads displays from CoreGraphics, falling back to
+// system_profiler when gops is built without cgo
+func getMacOSDisplays(ctx context.Context) ([]types.DisplayInfo, error) {
+	if displays, err := nativeDisplays(); err == nil {
+		return displays, nil
+	}
+
+	output, err := exec.CommandContext(ctx, "system_profiler", "SPDisplaysDataType", "-json").Output()
+	if err != nil {
+		return nil, err
+	}
+	var report struct {
+		Graphics []struct {
+			Displays []struct {
+				Name       string `json:"_name"`
+				Pixels     string `json:"_spdisplays_pixels"`
+				Resolution string `json:"_spdisplays_resolution"`
+				Main       string `json:"spdisplays_main"`
+			} `json:"spdisplays_ndrvs"`
+		} `json:"SPDisplaysDataType"`
+	}
+	if err := json.Unmarshal(output, &report); err != nil {
+		return nil, fmt.Errorf("system_profiler: %w", err)
+	}
+
+	var displays []types.DisplayInfo
+	for _, gpu := range report.Graphics {
+		for _, d := range gpu.Displays {
+			display := types.DisplayInfo{
+				Name:    d.Name,
+				Primary: d.Main == "spdisplays_yes",
+			}
+			// e.g. "3024 x 1964" and "1512 x 982 @ 120.00Hz"
+			display.PixelWidth, display.PixelHeight = parseResolution(d.Pixels)
+			width, height := parseResolution(d.Resolution)
+			if width > 0 {
+				display.Bounds = &types.WindowGeometry{Width: width, Height: height}
+				display.Scale = float64(display.PixelWidth) / float64(width)
+			}
+			if _, rate, found := strings.Cut(d.Resolution, "@"); found {
+				display.RefreshRate, _ = strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(rate), "Hz"), 64)
+			}
+			if display.Primary {
+				displays = append([]types.DisplayInfo{display}, displays...)
+			} else {
+				displays = append(displays, display)
+			}
+		}
+	}
+	numberDisplays(displays)
+	return displays, nil
+}
+
+// parseResolution parses the leading "width x height" of s
+func parseResolution(s string) (width, height int) {
+	fields := strings.Fields(s)
+	if len(fields) >= 3 && fields[1] == "x" {
+		width, _ = strconv.Atoi(fields[0])
+		height, _ = strconv.Atoi(fields[2])
+	}
+	return width, height
+}
+
+// xrandrOutput matches a connected output of xrandr --query, e.g.
+// "eDP-1 connected primary 1920x1080+0+0 (normal left inverted ...)"
+var xrandrOutput = regexp.MustCompile(`^(\S+) connected`)
+
+// getLinuxDisplays reads monitors with xrandr. X11 has no scale factor,
+// so bounds are in pixels.
+func getLinuxDisplays(ctx context.Context) ([]types.DisplayInfo, error) {
+	output, err := exec.CommandContext(ctx, "xrandr", "--listmonitors").Output()
+	if err != nil {
+		return nil, err
+	}
+	rates := linuxRefreshRates(ctx)
+
+	var displays []types.DisplayInfo
+	for _, line := range strings.Split(string(output), "\n") {
+		m := xrandrMonitor.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		width, _ := strconv.Atoi(m[2])
+		height, _ := strconv.Atoi(m[3])
+		x, _ := strconv.Atoi(m[4])
+		y, _ := strconv.Atoi(m[5])
+		fields := strings.Fields(line)
+		name := fields[len(fields)-1]
+		display := types.DisplayInfo{
+			Name:        name,
+			Primary:     m[1] == "*",
+			Bounds:      &types.WindowGeometry{X: x, Y: y, Width: width, Height: height},
+			PixelWidth:  width,
+			PixelHeight: height,
+			Scale:       1,
+			RefreshRate: rates[name],
+		}
+		// Same order as linuxMonitors, so window display numbers match
+		if display.Primary {
+			displays = append([]types.DisplayInfo{display}, displays...)
+		} else {
+			displays = append(displays, display)
+		}
+	}
+	numberDisplays(displays)
+	return displays, nil
+}
+
+// linuxRefreshRates returns the refresh rate of each connected output,
+// taken from the mode xrandr marks as current with an asterisk
+func linuxRefreshRates(ctx context.Context) map[string]float64 {
+	output, err := exec.CommandContext(ctx, "xrandr", "--query").Output()
+	if err != nil {
+		return nil
+	}
+	rates := make(map[string]float64)
+	name := ""
+	for _, line := range strings.Split(string(output), "\n") {
+		if m := xrandrOutput.FindStringSubmatch(line); m != nil {
+			name = m[1]
+			continue
+		}
+		fields := strings.Fields(line)
+		if !strings.HasPrefix(line, " ") || len(fields) < 2 {
+			name = ""
+			continue
+		}
+		// Mode lines: "   1920x1080     60.01*+  59.97    59.96"
+		for _, field := range fields[1:] {
+			if name != "" && strings.Contains(field, "*") {
+				rates[name], _ = strconv.ParseFloat(strings.TrimRight(field, "*+"), 64)
+			}
+		}
+	}
+	return rates
+}
+
+// getWindowsDisplays reads screens with Windows Forms and their current
+// mode with EnumDisplaySettings, which reports physical pixels
+func getWindowsDisplays(ctx context.Context) ([]types.DisplayInfo, error) {
+	psScript := `
+		Add-Type -AssemblyName System.Windows.Forms
+		Add-Type @"
+using System;
+using System.Runtime.InteropServices;
+public class Display {
+	[StructLayout(LayoutKind.Sequential, CharSet = CharSet.Unicode)]
+	public struct DEVMODE {
+		[MarshalAs(UnmanagedType.ByValTStr, SizeConst = 32)] public string dmDeviceName;
+		public short dmSpecVersion, dmDriverVersion, dmSize, dmDriverExtra;
+		public int dmFields, dmPositionX, dmPositionY, dmDisplayOrientation, dmDisplayFixedOutput;
+		public short dmColor, dmDuplex, dmYResolution, dmTTOption, dmCollate;
+		[MarshalAs(UnmanagedType.ByValTStr, SizeConst = 32)] public string dmFormName;
+		public short dmLogPixels;
+		public int dmBitsPerPel, dmPelsWidth, dmPelsHeight, dmDisplayFlags, dmDisplayFrequency;
+		public int dmICMMethod, dmICMIntent, dmMediaType, dmDitherType, dmReserved1, dmReserved2, dmPanningWidth, dmPanningHeight;
+	}
+	[DllImport("user32.dll", CharSet = CharSet.Unicode)] public static extern bool EnumDisplaySettings(string device, int mode, ref DEVMODE devMode);
+}
+"@
+		[System.Windows.Forms.Screen]::AllScreens | Sort-Object { -not $_.Primary } | ForEach-Object {
+			$mode = New-Object Display+DEVMODE
+			$mode.dmSize = [System.Runtime.InteropServices.Marshal]::SizeOf($mode)
+			[void][Display]::EnumDisplaySettings($_.DeviceName, -1, [ref]$mode)
+			$b = $_.Bounds
+			$_.DeviceName + "|" + [int]$_.Primary + "|" + $b.X + "|" + $b.Y + "|" + $b.Width + "|" + $b.Height + "|" +
+				$mode.dmPelsWidth + "|" + $mode.dmPelsHeight + "|" + $mode.dmDisplayFrequency
+		}
+	`
+
+	output, err := exec.CommandContext(ctx, "powershell", "-Command", psScript).Output()
+	if err != nil {
+		return nil, err
+	}
+
+	var displays []types.DisplayInfo
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		// Format: name|primary|x|y|width|height|pixelWidth|pixelHeight|refresh
+		parts := strings.Split(strings.TrimSpace(line), "|")
+		if len(parts) != 9 {
+			continue
+		}
+		display := types.DisplayInfo{
+			Name:    strings.TrimPrefix(parts[0], `\\.\`),
+			Primary: parts[1] == "1",
+			Bounds:  parseGeometry(parts[2], parts[3], parts[4], parts[5]),
+		}
+		display.PixelWidth, _ = strconv.Atoi(parts[6])
+		display.PixelHeight, _ = strconv.Atoi(parts[7])
+		display.RefreshRate, _ = strconv.ParseFloat(parts[8], 64)
+		if display.Bounds != nil && display.Bounds.Width > 0 {
+			display.Scale = float64(display.PixelWidth) / float64(display.Bounds.Width)
+		}
+		displays = append(displays, display)
+	}
+	numberDisplays(displays)
+	return displays, nil
+}
+
+// numberDisplays sets the 1-based index of each display
+func numberDisplays(displays []types.DisplayInfo) {
+	for i := range displays {
+		displays[i].Index = i + 1
+	}
+}
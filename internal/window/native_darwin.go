@@ -0,0 +1,345 @@
+//go:build darwin && cgo
+
+package window
+
+/*
+#cgo LDFLAGS: -framework CoreGraphics -framework CoreFoundation
+#include <stdlib.h>
+#include <CoreFoundation/CoreFoundation.h>
+#include <CoreGraphics/CoreGraphics.h>
+
+typedef struct {
+	unsigned int id;
+	int pid;
+	int layer;
+	int onscreen;
+	int display;
+	int space;
+	int current_space;
+	int fullscreen;
+	double x, y, width, height;
+	char *owner;
+	char *title;
+} gops_window;
+
+static char *gops_copy_string(CFDictionaryRef dict, CFStringRef key) {
+	CFStringRef str = CFDictionaryGetValue(dict, key);
+	if (str == NULL || CFGetTypeID(str) != CFStringGetTypeID()) {
+		return NULL;
+	}
+	CFIndex size = CFStringGetMaximumSizeForEncoding(CFStringGetLength(str), kCFStringEncodingUTF8) + 1;
+	char *buf = malloc(size);
+	if (buf != NULL && !CFStringGetCString(str, buf, size, kCFStringEncodingUTF8)) {
+		free(buf);
+		return NULL;
+	}
+	return buf;
+}
+
+static long long gops_number(CFDictionaryRef dict, CFStringRef key) {
+	long long value = 0;
+	CFNumberRef num = CFDictionaryGetValue(dict, key);
+	if (num != NULL && CFGetTypeID(num) == CFNumberGetTypeID()) {
+		CFNumberGetValue(num, kCFNumberLongLongType, &value);
+	}
+	return value;
+}
+
+// gops_display returns the 1-based index of the active display containing
+// point, 0 if none does. The main display comes first.
+static int gops_display(CGPoint point) {
+	CGDirectDisplayID displays[16];
+	uint32_t count = 0;
+	if (CGGetActiveDisplayList(16, displays, &count) != kCGErrorSuccess) {
+		return 0;
+	}
+	for (uint32_t i = 0; i < count; i++) {
+		if (CGRectContainsPoint(CGDisplayBounds(displays[i]), point)) {
+			return (int)i + 1;
+		}
+	}
+	return 0;
+}
+
+// Private CoreGraphics (SkyLight) calls behind Mission Control; there is
+// no public API for Spaces
+typedef int CGSConnectionID;
+extern CGSConnectionID CGSMainConnectionID(void);
+extern CFArrayRef CGSCopyManagedDisplaySpaces(CGSConnectionID cid);
+extern CFArrayRef CGSCopySpacesForWindows(CGSConnectionID cid, int mask, CFArrayRef windows);
+
+// kCGSAllSpacesMask selects the Spaces of every type a window is on
+#define GOPS_ALL_SPACES_MASK 7
+#define GOPS_MAX_SPACES 64
+
+// Full-screen windows get a Space of their own of this type
+#define GOPS_FULLSCREEN_SPACE 4
+
+typedef struct {
+	int count;
+	long long ids[GOPS_MAX_SPACES];
+	int current[GOPS_MAX_SPACES];
+	int fullscreen[GOPS_MAX_SPACES];
+} gops_spaces;
+
+// gops_load_spaces lists the Spaces of every display in Mission Control
+// order, marking the one each display currently shows
+static void gops_load_spaces(CGSConnectionID cid, gops_spaces *spaces) {
+	spaces->count = 0;
+	CFArrayRef displays = CGSCopyManagedDisplaySpaces(cid);
+	if (displays == NULL) {
+		return;
+	}
+	for (CFIndex i = 0; i < CFArrayGetCount(displays); i++) {
+		CFDictionaryRef display = CFArrayGetValueAtIndex(displays, i);
+		long long current = 0;
+		CFDictionaryRef currentSpace = CFDictionaryGetValue(display, CFSTR("Current Space"));
+		if (currentSpace != NULL && CFGetTypeID(currentSpace) == CFDictionaryGetTypeID()) {
+			current = gops_number(currentSpace, CFSTR("ManagedSpaceID"));
+		}
+		CFArrayRef list = CFDictionaryGetValue(display, CFSTR("Spaces"));
+		if (list == NULL || CFGetTypeID(list) != CFArrayGetTypeID()) {
+			continue;
+		}
+		for (CFIndex j = 0; j < CFArrayGetCount(list) && spaces->count < GOPS_MAX_SPACES; j++) {
+			CFDictionaryRef space = CFArrayGetValueAtIndex(list, j);
+			long long id = gops_number(space, CFSTR("ManagedSpaceID"));
+			spaces->ids[spaces->count] = id;
+			spaces->current[spaces->count] = id == current;
+			spaces->fullscreen[spaces->count] = gops_number(space, CFSTR("type")) == GOPS_FULLSCREEN_SPACE;
+			spaces->count++;
+		}
+	}
+	CFRelease(displays);
+}
+
+// gops_window_space sets the 1-based Space of a window, -1 if it is on
+// every Space and 0 if unknown, whether that Space is showing and whether
+// it is a full-screen Space
+static void gops_window_space(CGSConnectionID cid, const gops_spaces *spaces, gops_window *w) {
+	long long number = w->id;
+	CFNumberRef id = CFNumberCreate(NULL, kCFNumberLongLongType, &number);
+	CFArrayRef ids = CFArrayCreate(NULL, (const void **)&id, 1, &kCFTypeArrayCallBacks);
+	CFRelease(id);
+	CFArrayRef list = CGSCopySpacesForWindows(cid, GOPS_ALL_SPACES_MASK, ids);
+	CFRelease(ids);
+	if (list == NULL) {
+		return;
+	}
+	CFIndex count = CFArrayGetCount(list);
+	if (count > 1) {
+		w->space = -1;
+		w->current_space = 1;
+	} else if (count == 1) {
+		long long space = 0;
+		CFNumberGetValue(CFArrayGetValueAtIndex(list, 0), kCFNumberLongLongType, &space);
+		for (int i = 0; i < spaces->count; i++) {
+			if (spaces->ids[i] == space) {
+				w->space = i + 1;
+				w->current_space = spaces->current[i];
+				w->fullscreen = spaces->fullscreen[i];
+				break;
+			}
+		}
+	}
+	CFRelease(list);
+}
+
+// gops_list_windows copies every window except desktop elements into a
+// newly allocated array, front to back, returning its length or -1 on
+// failure
+static int gops_list_windows(gops_window **out) {
+	CFArrayRef list = CGWindowListCopyWindowInfo(kCGWindowListOptionAll | kCGWindowListExcludeDesktopElements, kCGNullWindowID);
+	if (list == NULL) {
+		return -1;
+	}
+	CFIndex count = CFArrayGetCount(list);
+	gops_window *windows = calloc(count > 0 ? count : 1, sizeof(gops_window));
+	if (windows == NULL) {
+		CFRelease(list);
+		return -1;
+	}
+	CGSConnectionID cid = CGSMainConnectionID();
+	gops_spaces spaces;
+	gops_load_spaces(cid, &spaces);
+	for (CFIndex i = 0; i < count; i++) {
+		CFDictionaryRef dict = CFArrayGetValueAtIndex(list, i);
+		windows[i].id = (unsigned int)gops_number(dict, kCGWindowNumber);
+		windows[i].pid = (int)gops_number(dict, kCGWindowOwnerPID);
+		windows[i].layer = (int)gops_number(dict, kCGWindowLayer);
+		CFBooleanRef onscreen = CFDictionaryGetValue(dict, kCGWindowIsOnscreen);
+		windows[i].onscreen = onscreen != NULL && CFBooleanGetValue(onscreen);
+		CFDictionaryRef bounds = CFDictionaryGetValue(dict, kCGWindowBounds);
+		CGRect rect;
+		if (bounds != NULL && CGRectMakeWithDictionaryRepresentation(bounds, &rect)) {
+			windows[i].x = rect.origin.x;
+			windows[i].y = rect.origin.y;
+			windows[i].width = rect.size.width;
+			windows[i].height = rect.size.height;
+			windows[i].display = gops_display(CGPointMake(CGRectGetMidX(rect), CGRectGetMidY(rect)));
+		}
+		windows[i].owner = gops_copy_string(dict, kCGWindowOwnerName);
+		windows[i].title = gops_copy_string(dict, kCGWindowName);
+		if (windows[i].title != NULL && windows[i].layer >= 0 && windows[i].layer < 20) {
+			gops_window_space(cid, &spaces, &windows[i]);
+		}
+	}
+	CFRelease(list);
+	*out = windows;
+	return (int)count;
+}
+
+typedef struct {
+	int main;
+	int builtin;
+	double x, y, width, height;
+	long pixel_width, pixel_height;
+	double refresh;
+} gops_display_info;
+
+// gops_list_displays fills out with up to max active displays, the main
+// display first, returning their number or -1 on failure
+static int gops_list_displays(gops_display_info *out, int max) {
+	CGDirectDisplayID displays[16];
+	uint32_t count = 0;
+	if (CGGetActiveDisplayList(16, displays, &count) != kCGErrorSuccess) {
+		return -1;
+	}
+	if ((int)count > max) {
+		count = max;
+	}
+	for (uint32_t i = 0; i < count; i++) {
+		CGRect bounds = CGDisplayBounds(displays[i]);
+		out[i].main = CGDisplayIsMain(displays[i]);
+		out[i].builtin = CGDisplayIsBuiltin(displays[i]);
+		out[i].x = bounds.origin.x;
+		out[i].y = bounds.origin.y;
+		out[i].width = bounds.size.width;
+		out[i].height = bounds.size.height;
+		CGDisplayModeRef mode = CGDisplayCopyDisplayMode(displays[i]);
+		if (mode != NULL) {
+			out[i].pixel_width = CGDisplayModeGetPixelWidth(mode);
+			out[i].pixel_height = CGDisplayModeGetPixelHeight(mode);
+			out[i].refresh = CGDisplayModeGetRefreshRate(mode);
+			CGDisplayModeRelease(mode);
+		}
+	}
+	return (int)count;
+}
+
+static void gops_free_windows(gops_window *windows, int count) {
+	for (int i = 0; i < count; i++) {
+		free(windows[i].owner);
+		free(windows[i].title);
+	}
+	free(windows);
+}
+*/
+import "C"
+
+import (
+	"errors"
+	"fmt"
+	"unsafe"
+
+	"github.com/borankux/gops/pkg/types"
+)
+
+// nativeWindowList reports whether windows are listed with CoreGraphics
+// rather than AppleScript
+const nativeWindowList = true
+
+// errNoTitles means CoreGraphics withheld window titles, which it does
+// until the Screen Recording permission is granted
+var errNoTitles = errors.New("window titles unavailable without Screen Recording permission")
+
+// dockWindowLevel is kCGDockWindowLevel; windows at or above it belong to
+// the Dock, menu bar and other system UI
+const dockWindowLevel = 20
+
+// nativeWindows lists application windows, from normal windows up to
+// floating panels, with CGWindowListCopyWindowInfo in a single call.
+// Windows without a title, which are mostly invisible helper windows,
+// are skipped. offscreen reports whether any window is off screen, as
+// only then can windows be minimized or hidden.
+func nativeWindows() (windows []types.WindowInfo, offscreen bool, err error) {
+	var list *C.gops_window
+	count := int(C.gops_list_windows(&list))
+	if count < 0 {
+		return nil, false, errors.New("CGWindowListCopyWindowInfo failed")
+	}
+	defer C.gops_free_windows(list, C.int(count))
+
+	for _, w := range unsafe.Slice(list, count) {
+		if w.layer < 0 || w.layer >= dockWindowLevel || w.title == nil || w.owner == nil {
+			continue
+		}
+		title := C.GoString(w.title)
+		if title == "" {
+			continue
+		}
+		owner := C.GoString(w.owner)
+		windows = append(windows, types.WindowInfo{
+			ID:      uint32(w.id),
+			Title:   title,
+			PID:     int32(w.pid),
+			Process: owner,
+			AppName: owner,
+			Geometry: &types.WindowGeometry{
+				X:      int(w.x),
+				Y:      int(w.y),
+				Width:  int(w.width),
+				Height: int(w.height),
+			},
+			Display:      int(w.display),
+			Space:        int(w.space),
+			CurrentSpace: w.current_space != 0,
+			Layer:        int(w.layer),
+			ZOrder:       len(windows) + 1,
+			OnScreen:     w.onscreen != 0,
+			Fullscreen:   w.fullscreen != 0,
+		})
+		offscreen = offscreen || w.onscreen == 0
+	}
+	if len(windows) == 0 {
+		return nil, false, errNoTitles
+	}
+	return windows, offscreen, nil
+}
+
+// nativeDisplays lists the active displays with CoreGraphics. It has no
+// display names, so displays are named built-in or by number.
+func nativeDisplays() ([]types.DisplayInfo, error) {
+	var list [16]C.gops_display_info
+	count := int(C.gops_list_displays(&list[0], C.int(len(list))))
+	if count < 0 {
+		return nil, errors.New("CGGetActiveDisplayList failed")
+	}
+
+	displays := make([]types.DisplayInfo, 0, count)
+	for i, d := range list[:count] {
+		display := types.DisplayInfo{
+			Index:   i + 1,
+			Name:    fmt.Sprintf("Display %d", i+1),
+			Primary: d.main != 0,
+			Bounds: &types.WindowGeometry{
+				X:      int(d.x),
+				Y:      int(d.y),
+				Width:  int(d.width),
+				Height: int(d.height),
+			},
+			PixelWidth:  int(d.pixel_width),
+			PixelHeight: int(d.pixel_height),
+			// Built-in panels report no fixed refresh rate
+			RefreshRate: float64(d.refresh),
+		}
+		if d.builtin != 0 {
+			display.Name = "Built-in Display"
+		}
+		if d.width > 0 {
+			display.Scale = float64(d.pixel_width) / float64(d.width)
+		}
+		displays = append(displays, display)
+	}
+	return displays, nil
+}
@@ -0,0 +1,23 @@
+//go:build !darwin || !cgo
+
+package window
+
+import (
+	"errors"
+
+	"github.com/borankux/gops/pkg/types"
+)
+
+// nativeWindowList reports whether windows are listed with CoreGraphics
+// rather than AppleScript
+const nativeWindowList = false
+
+// nativeDisplays needs cgo on macOS
+func nativeDisplays() ([]types.DisplayInfo, error) {
+	return nil, errors.New("native display listing requires cgo on macOS")
+}
+
+// nativeWindows needs cgo on macOS
+func nativeWindows() ([]types.WindowInfo, bool, error) {
+	return nil, false, errors.New("native window listing requires cgo on macOS")
+}
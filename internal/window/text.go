@@ -0,0 +1,150 @@
+package window
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/borankux/gops/pkg/types"
+)
+
+// maxTextElements bounds how many text elements Text returns, so a
+// window with a huge table or document doesn't produce megabytes
+const maxTextElements = 2000
+
+// Text reads the visible text of the window matching q from its
+// accessibility tree: labels, text fields, buttons, links and the like, in
+// tree order. macOS reads it through System Events, which needs
+// Accessibility access, and Windows through UI Automation.
+func Text(ctx context.Context, q Query) (types.WindowInfo, []types.TextElement, error) {
+	w, err := Find(ctx, q)
+	if err != nil {
+		return types.WindowInfo{}, nil, err
+	}
+
+	var elements []types.TextElement
+	switch runtime.GOOS {
+	case "darwin":
+		elements, err = macOSWindowText(ctx, w)
+	case "windows":
+		elements, err = windowsWindowText(ctx, w)
+	default:
+		err = errors.New("reading window text is not supported on " + runtime.GOOS)
+	}
+	if err != nil {
+		return types.WindowInfo{}, nil, err
+	}
+
+	// Containers often repeat the text of their only child
+	var text []types.TextElement
+	for _, e := range elements {
+		e.Text = strings.TrimSpace(e.Text)
+		if e.Text == "" || (len(text) > 0 && text[len(text)-1].Text == e.Text) {
+			continue
+		}
+		text = append(text, e)
+		if len(text) == maxTextElements {
+			break
+		}
+	}
+	return w, text, nil
+}
+
+// macOSWindowText walks the accessibility tree of w with System Events,
+// reading the value of text elements and the title or description of
+// controls. Elements are separated by ASCII record separators and roles
+// from text by unit separators, since text may span lines.
+func macOSWindowText(ctx context.Context, w types.WindowInfo) ([]types.TextElement, error) {
+	script := `on run argv
+		set targetPID to (item 1 of argv) as integer
+		set targetTitle to item 2 of argv
+		set textRoles to {"AXStaticText", "AXTextField", "AXTextArea", "AXHeading", "AXLink", "AXButton", "AXCheckBox", "AXRadioButton", "AXPopUpButton", "AXMenuButton", "AXTab", "AXCell"}
+		set output to {}
+		tell application "System Events"
+			set proc to first process whose unix id is targetPID
+			set win to first window of proc whose name is targetTitle
+			repeat with el in (entire contents of win)
+				try
+					set elRole to role of el
+					if textRoles contains elRole then
+						set elText to missing value
+						try
+							set elText to value of el
+						end try
+						if elText is missing value or elText is "" or class of elText is not text then
+							set elText to title of el
+						end if
+						if elText is missing value or elText is "" then
+							set elText to description of el
+						end if
+						if elText is not missing value and elText is not "" then
+							set end of output to elRole & (ASCII character 31) & (elText as text)
+						end if
+					end if
+				end try
+			end repeat
+		end tell
+		set AppleScript's text item delimiters to (ASCII character 30)
+		return output as text
+	end run`
+
+	cmd := exec.CommandContext(ctx, "osascript", "-e", script, strconv.Itoa(int(w.PID)), w.Title)
+	output, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return nil, osascriptError(exitErr.Stderr)
+		}
+		return nil, err
+	}
+
+	var elements []types.TextElement
+	for _, record := range strings.Split(strings.TrimSuffix(string(output), "\n"), "\x1e") {
+		if role, text, found := strings.Cut(record, "\x1f"); found {
+			elements = append(elements, types.TextElement{Role: role, Text: text})
+		}
+	}
+	return elements, nil
+}
+
+// windowsWindowText walks the UI Automation tree of w, reading the value
+// of elements supporting the Value pattern and the name of the others
+func windowsWindowText(ctx context.Context, w types.WindowInfo) ([]types.TextElement, error) {
+	psScript := fmt.Sprintf(`
+		Add-Type -AssemblyName UIAutomationClient, UIAutomationTypes
+		$roles = @('Text', 'Edit', 'Document', 'Hyperlink', 'Button', 'CheckBox', 'RadioButton', 'TabItem', 'ListItem', 'DataItem', 'HeaderItem')
+		$root = [System.Windows.Automation.AutomationElement]::FromHandle([IntPtr]%d)
+		$all = $root.FindAll([System.Windows.Automation.TreeScope]::Descendants, [System.Windows.Automation.Condition]::TrueCondition)
+		@(foreach ($e in $all) {
+			$role = $e.Current.ControlType.ProgrammaticName -replace '^ControlType\.', ''
+			if ($roles -notcontains $role) { continue }
+			$text = $null
+			try { $text = $e.GetCurrentPattern([System.Windows.Automation.ValuePattern]::Pattern).Current.Value } catch {}
+			if (-not $text) { $text = $e.Current.Name }
+			if ($text) { [pscustomobject]@{ role = $role; text = $text } }
+		}) | ConvertTo-Json -Compress
+	`, w.ID)
+
+	output, err := exec.CommandContext(ctx, "powershell", "-Command", psScript).Output()
+	if err != nil {
+		return nil, errors.New("failed to read the window through UI Automation")
+	}
+	output = []byte(strings.TrimSpace(string(output)))
+	if len(output) == 0 {
+		return nil, nil
+	}
+	// ConvertTo-Json writes a lone element as an object
+	if output[0] == '{' {
+		output = append(append([]byte{'['}, output...), ']')
+	}
+	var elements []types.TextElement
+	if err := json.Unmarshal(output, &elements); err != nil {
+		return nil, fmt.Errorf("parsing UI Automation output: %w", err)
+	}
+	return elements, nil
+}
@@ -0,0 +1,344 @@
+package window
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/borankux/gops/pkg/types"
+)
+
+// waylandBackend picks the backend for the running Wayland compositor:
+// its own IPC for sway and Hyprland, and the wlr-foreign-toplevel
+// protocol through lswt for other wlroots compositors. It returns ""
+// outside Wayland sessions, and for compositors it can't list windows of,
+// where wmctrl still sees XWayland windows.
+func waylandBackend() string {
+	if os.Getenv("WAYLAND_DISPLAY") == "" {
+		return ""
+	}
+	switch {
+	case os.Getenv("SWAYSOCK") != "":
+		return BackendSway
+	case os.Getenv("HYPRLAND_INSTANCE_SIGNATURE") != "":
+		return BackendHyprland
+	}
+	if _, err := exec.LookPath("lswt"); err == nil {
+		return BackendWlr
+	}
+	return ""
+}
+
+// getWaylandWindows lists windows with a Wayland backend
+func getWaylandWindows(ctx context.Context, backend string) ([]types.WindowInfo, error) {
+	switch backend {
+	case BackendSway:
+		return getSwayWindows(ctx)
+	case BackendHyprland:
+		return getHyprlandWindows(ctx)
+	case BackendWlr:
+		return getWlrWindows(ctx)
+	}
+	return nil, fmt.Errorf("unknown window backend %q", backend)
+}
+
+// swayNode is a node of the sway layout tree: an output, workspace or
+// container, windows being the containers with an app
+type swayNode struct {
+	ID             int64                `json:"id"`
+	Type           string               `json:"type"`
+	Name           string               `json:"name"`
+	Num            int                  `json:"num"`
+	PID            int32                `json:"pid"`
+	AppID          string               `json:"app_id"`
+	Rect           types.WindowGeometry `json:"rect"`
+	Visible        bool                 `json:"visible"`
+	FullscreenMode int                  `json:"fullscreen_mode"`
+	WindowProps    struct {
+		Class string `json:"class"`
+	} `json:"window_properties"`
+	Nodes         []swayNode `json:"nodes"`
+	FloatingNodes []swayNode `json:"floating_nodes"`
+}
+
+// getSwayWindows reads windows from the sway layout tree. Workspaces
+// stand in for Spaces, and windows in the scratchpad are hidden.
+func getSwayWindows(ctx context.Context) ([]types.WindowInfo, error) {
+	output, err := exec.CommandContext(ctx, "swaymsg", "-r", "-t", "get_tree").Output()
+	if err != nil {
+		return nil, err
+	}
+	var root swayNode
+	if err := json.Unmarshal(output, &root); err != nil {
+		return nil, fmt.Errorf("swaymsg: %w", err)
+	}
+	visible := swayVisibleWorkspaces(ctx)
+	monitors := linuxMonitors(ctx)
+
+	var windows []types.WindowInfo
+	var walk func(node swayNode, workspace *swayNode)
+	walk = func(node swayNode, workspace *swayNode) {
+		if node.Type == "workspace" {
+			workspace = &node
+		}
+		if node.PID > 0 && node.Name != "" && len(node.Nodes) == 0 {
+			procName := getProcessName(ctx, node.PID)
+			appName := node.AppID
+			if appName == "" {
+				// XWayland windows have a class instead
+				appName = node.WindowProps.Class
+			}
+			if appName == "" {
+				appName = procName
+			}
+			geometry := node.Rect
+			w := types.WindowInfo{
+				ID:         uint32(node.ID),
+				Title:      node.Name,
+				PID:        node.PID,
+				Process:    procName,
+				AppName:    appName,
+				Geometry:   &geometry,
+				Display:    displayOf(&geometry, monitors),
+				Fullscreen: node.FullscreenMode > 0,
+				OnScreen:   node.Visible,
+			}
+			if workspace != nil {
+				if workspace.Name == "__i3_scratch" {
+					w.Hidden = true
+				} else {
+					// Named workspaces have no number
+					if workspace.Num > 0 {
+						w.Space = workspace.Num
+					}
+					w.CurrentSpace = visible[workspace.Name]
+				}
+			}
+			windows = append(windows, w)
+		}
+		for _, child := range node.Nodes {
+			walk(child, workspace)
+		}
+		for _, child := range node.FloatingNodes {
+			walk(child, workspace)
+		}
+	}
+	walk(root, nil)
+	return windows, nil
+}
+
+// swayVisibleWorkspaces returns the names of the workspaces shown on an
+// output
+func swayVisibleWorkspaces(ctx context.Context) map[string]bool {
+	output, err := exec.CommandContext(ctx, "swaymsg", "-r", "-t", "get_workspaces").Output()
+	if err != nil {
+		return nil
+	}
+	var workspaces []struct {
+		Name    string `json:"name"`
+		Visible bool   `json:"visible"`
+	}
+	if err := json.Unmarshal(output, &workspaces); err != nil {
+		return nil
+	}
+	visible := make(map[string]bool, len(workspaces))
+	for _, ws := range workspaces {
+		visible[ws.Name] = ws.Visible
+	}
+	return visible
+}
+
+// getHyprlandWindows reads windows with hyprctl. Workspaces stand in for
+// Spaces, windows on special (scratchpad) workspaces are hidden, and the
+// focus history orders windows in place of a stacking order.
+func getHyprlandWindows(ctx context.Context) ([]types.WindowInfo, error) {
+	output, err := exec.CommandContext(ctx, "hyprctl", "clients", "-j").Output()
+	if err != nil {
+		return nil, err
+	}
+	var clients []struct {
+		Mapped    bool   `json:"mapped"`
+		Hidden    bool   `json:"hidden"`
+		At        [2]int `json:"at"`
+		Size      [2]int `json:"size"`
+		Workspace struct {
+			ID int `json:"id"`
+		} `json:"workspace"`
+		Class string `json:"class"`
+		Title string `json:"title"`
+		PID   int32  `json:"pid"`
+		// A boolean in older releases, the full-screen mode in newer ones
+		Fullscreen     interface{} `json:"fullscreen"`
+		FocusHistoryID int         `json:"focusHistoryID"`
+	}
+	if err := json.Unmarshal(output, &clients); err != nil {
+		return nil, fmt.Errorf("hyprctl: %w", err)
+	}
+	active := hyprlandActiveWorkspaces(ctx)
+	monitors := linuxMonitors(ctx)
+
+	var windows []types.WindowInfo
+	var positions []int
+	for _, c := range clients {
+		if !c.Mapped || c.Title == "" {
+			continue
+		}
+		procName := getProcessName(ctx, c.PID)
+		appName := c.Class
+		if appName == "" {
+			appName = procName
+		}
+		geometry := &types.WindowGeometry{X: c.At[0], Y: c.At[1], Width: c.Size[0], Height: c.Size[1]}
+		w := types.WindowInfo{
+			Title:    c.Title,
+			PID:      c.PID,
+			Process:  procName,
+			AppName:  appName,
+			Geometry: geometry,
+			Display:  displayOf(geometry, monitors),
+			// Grouped windows behind the active tab are hidden too
+			Hidden: c.Hidden || c.Workspace.ID < 0,
+		}
+		switch fullscreen := c.Fullscreen.(type) {
+		case bool:
+			w.Fullscreen = fullscreen
+		case float64:
+			w.Fullscreen = fullscreen > 0
+		}
+		if c.Workspace.ID > 0 {
+			w.Space = c.Workspace.ID
+			w.CurrentSpace = active[c.Workspace.ID]
+		}
+		windows = append(windows, w)
+		positions = append(positions, c.FocusHistoryID)
+	}
+	setZOrder(windows, positions)
+	setOnScreen(windows)
+	return windows, nil
+}
+
+// hyprlandActiveWorkspaces returns the IDs of the workspaces shown on a
+// monitor
+func hyprlandActiveWorkspaces(ctx context.Context) map[int]bool {
+	output, err := exec.CommandContext(ctx, "hyprctl", "monitors", "-j").Output()
+	if err != nil {
+		return nil
+	}
+	var monitors []struct {
+		ActiveWorkspace struct {
+			ID int `json:"id"`
+		} `json:"activeWorkspace"`
+	}
+	if err := json.Unmarshal(output, &monitors); err != nil {
+		return nil
+	}
+	active := make(map[int]bool, len(monitors))
+	for _, m := range monitors {
+		active[m.ActiveWorkspace.ID] = true
+	}
+	return active
+}
+
+// lswtToplevel is a toplevel as listed by lswt -j
+type lswtToplevel struct {
+	Title      string `json:"title"`
+	AppID      string `json:"app-id"`
+	Minimized  bool   `json:"minimized"`
+	Fullscreen bool   `json:"fullscreen"`
+}
+
+// getWlrWindows lists toplevels with lswt, which speaks the
+// wlr-foreign-toplevel-management protocol. The protocol has no process
+// IDs, geometry or workspaces, so windows only carry their title, app ID
+// and state.
+func getWlrWindows(ctx context.Context) ([]types.WindowInfo, error) {
+	output, err := exec.CommandContext(ctx, "lswt", "-j").Output()
+	if err != nil {
+		return nil, err
+	}
+	var list struct {
+		Toplevels []lswtToplevel `json:"toplevels"`
+	}
+	if err := json.Unmarshal(output, &list); err != nil {
+		return nil, fmt.Errorf("lswt: %w", err)
+	}
+
+	var windows []types.WindowInfo
+	for _, t := range list.Toplevels {
+		if t.Title == "" {
+			continue
+		}
+		windows = append(windows, types.WindowInfo{
+			Title:      t.Title,
+			Process:    t.AppID,
+			AppName:    t.AppID,
+			Minimized:  t.Minimized,
+			Fullscreen: t.Fullscreen,
+		})
+	}
+	setOnScreen(windows)
+	return windows, nil
+}
+
+// swayCommand runs a sway command on the window w, selected by its
+// container ID
+func swayCommand(ctx context.Context, w types.WindowInfo, command string) error {
+	output, err := exec.CommandContext(ctx, "swaymsg", fmt.Sprintf("[con_id=%d] %s", w.ID, command)).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("swaymsg: %s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// hyprlandDispatch runs Hyprland dispatchers on the window w in one
+// batch, formatting its address into each
+func hyprlandDispatch(ctx context.Context, w types.WindowInfo, dispatchers ...string) error {
+	address, err := hyprlandAddress(ctx, w)
+	if err != nil {
+		return err
+	}
+	batch := make([]string, len(dispatchers))
+	for i, d := range dispatchers {
+		batch[i] = "dispatch " + fmt.Sprintf(d, address)
+	}
+	output, err := exec.CommandContext(ctx, "hyprctl", "--batch", strings.Join(batch, " ; ")).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("hyprctl: %s", strings.TrimSpace(string(output)))
+	}
+	// hyprctl exits 0 when a dispatcher fails, printing its error in
+	// place of "ok"
+	for _, line := range strings.Split(string(output), "\n") {
+		if line = strings.TrimSpace(line); line != "" && line != "ok" {
+			return fmt.Errorf("hyprctl: %s", line)
+		}
+	}
+	return nil
+}
+
+// hyprlandAddress looks up the address Hyprland dispatchers select w by.
+// Listed windows carry no ID, so clients are matched by process and
+// title.
+func hyprlandAddress(ctx context.Context, w types.WindowInfo) (string, error) {
+	output, err := exec.CommandContext(ctx, "hyprctl", "clients", "-j").Output()
+	if err != nil {
+		return "", err
+	}
+	var clients []struct {
+		Address string `json:"address"`
+		Mapped  bool   `json:"mapped"`
+		Title   string `json:"title"`
+		PID     int32  `json:"pid"`
+	}
+	if err := json.Unmarshal(output, &clients); err != nil {
+		return "", fmt.Errorf("hyprctl: %w", err)
+	}
+	for _, c := range clients {
+		if c.Mapped && c.PID == w.PID && c.Title == w.Title && c.Address != "" {
+			return c.Address, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %q closed", ErrNotFound, w.Title)
+}
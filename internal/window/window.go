@@ -2,11 +2,17 @@ package window
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"os"
 	"os/exec"
+	"regexp"
 	"runtime"
+	"sort"
 	"strconv"
 	"strings"
 
+	"github.com/borankux/gops/internal/permission"
 	"github.com/borankux/gops/pkg/types"
 )
 
@@ -24,8 +30,201 @@ func GetOpenWindows(ctx context.Context) ([]types.WindowInfo, error) {
 	}
 }
 
-// getMacOSWindows gets windows on macOS using osascript
+// GetWindowsForPID returns the open windows of a process
+func GetWindowsForPID(ctx context.Context, pid int32) ([]types.WindowInfo, error) {
+	windows, err := GetOpenWindows(ctx)
+	if err != nil {
+		return nil, err
+	}
+	kept := make([]types.WindowInfo, 0, len(windows))
+	for _, w := range windows {
+		if w.PID == pid {
+			kept = append(kept, w)
+		}
+	}
+	return kept, nil
+}
+
+// FilterApp keeps the windows whose process or app name contains app,
+// ignoring case
+func FilterApp(windows []types.WindowInfo, app string) []types.WindowInfo {
+	if app == "" {
+		return windows
+	}
+	app = strings.ToLower(app)
+	kept := make([]types.WindowInfo, 0, len(windows))
+	for _, w := range windows {
+		if strings.Contains(strings.ToLower(w.Process), app) || strings.Contains(strings.ToLower(w.AppName), app) {
+			kept = append(kept, w)
+		}
+	}
+	return kept
+}
+
+// FilterSpace keeps the windows on a Space or virtual desktop, given as
+// "current" or its 1-based number; an empty space keeps every window.
+// Windows shown on every Space are always kept, as are windows whose
+// Space is unknown.
+func FilterSpace(windows []types.WindowInfo, space string) ([]types.WindowInfo, error) {
+	if space == "" {
+		return windows, nil
+	}
+	current := space == "current"
+	n, err := strconv.Atoi(space)
+	if !current && (err != nil || n < 1) {
+		return nil, fmt.Errorf("invalid space %q: use current or a number from 1", space)
+	}
+
+	kept := make([]types.WindowInfo, 0, len(windows))
+	for _, w := range windows {
+		if w.Space <= 0 || (current && w.CurrentSpace) || (!current && w.Space == n) {
+			kept = append(kept, w)
+		}
+	}
+	return kept, nil
+}
+
+// getMacOSWindows gets windows on macOS from CoreGraphics, falling back
+// to AppleScript when gops is built without cgo or window titles are
+// withheld
 func getMacOSWindows(ctx context.Context) ([]types.WindowInfo, error) {
+	windows, offscreen, err := nativeWindows()
+	if err != nil {
+		windows, err := getMacOSScriptWindows(ctx)
+		if err == nil && len(windows) == 0 && permission.Denied(ctx, permission.Accessibility) {
+			// System Events lists no windows, rather than failing,
+			// without Accessibility access. Screen Recording access is
+			// the better fix where native listing is available.
+			if nativeWindowList {
+				return nil, &permission.Error{Permission: permission.ScreenRecording}
+			}
+			return nil, &permission.Error{Permission: permission.Accessibility}
+		}
+		setOnScreen(windows)
+		return windows, err
+	}
+	if offscreen {
+		// CoreGraphics can't tell minimized windows and hidden apps
+		// apart from windows on other Spaces
+		setMacOSWindowStates(ctx, windows)
+	}
+	for i, w := range windows {
+		// Windows off screen on a showing Space that aren't minimized
+		// have been ordered out, e.g. closed but kept by their app
+		if !w.OnScreen && !w.Minimized && (w.Space == 0 || w.CurrentSpace) {
+			windows[i].Hidden = true
+		}
+	}
+	return windows, nil
+}
+
+// setOnScreen marks the windows that are showing, for platforms that
+// don't report it: those neither minimized nor hidden on a showing Space
+// or desktop
+func setOnScreen(windows []types.WindowInfo) {
+	for i, w := range windows {
+		windows[i].OnScreen = !w.Minimized && !w.Hidden && (w.Space == 0 || w.CurrentSpace)
+	}
+}
+
+// FilterFullscreen keeps the full-screen windows
+func FilterFullscreen(windows []types.WindowInfo) []types.WindowInfo {
+	kept := make([]types.WindowInfo, 0, len(windows))
+	for _, w := range windows {
+		if w.Fullscreen {
+			kept = append(kept, w)
+		}
+	}
+	return kept
+}
+
+// FilterHidden drops minimized and hidden windows, returning the windows
+// kept and how many were dropped
+func FilterHidden(windows []types.WindowInfo) ([]types.WindowInfo, int) {
+	kept := make([]types.WindowInfo, 0, len(windows))
+	for _, w := range windows {
+		if !w.Minimized && !w.Hidden {
+			kept = append(kept, w)
+		}
+	}
+	return kept, len(windows) - len(kept)
+}
+
+// scriptError converts an osascript failure into a *permission.Error
+// when a missing permission caused it
+func scriptError(err error) error {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		if permErr := permission.FromAppleScript(string(exitErr.Stderr)); permErr != nil {
+			return permErr
+		}
+	}
+	return err
+}
+
+// windowKey identifies a window by its process and title, for sources
+// that expose no window IDs
+type windowKey struct {
+	pid   int32
+	title string
+}
+
+// setMacOSWindowStates marks the windows of hidden apps and minimized
+// windows, asking System Events for both in one call. Windows are left
+// unmarked if it fails, e.g. without Accessibility access.
+func setMacOSWindowStates(ctx context.Context, windows []types.WindowInfo) {
+	script := `tell application "System Events"
+		set states to {}
+		repeat with proc in (every process whose background only is false)
+			try
+				set procPID to unix id of proc
+				if visible of proc is false then
+					set end of states to "hidden|" & procPID
+				else
+					repeat with win in windows of proc
+						try
+							if value of attribute "AXMinimized" of win is true then
+								set end of states to "minimized|" & procPID & "|" & (name of win)
+							end if
+						end try
+					end repeat
+				end if
+			end try
+		end repeat
+	end tell
+	set AppleScript's text item delimiters to linefeed
+	return states as text`
+
+	output, err := exec.CommandContext(ctx, "osascript", "-e", script).Output()
+	if err != nil {
+		return
+	}
+	hidden := make(map[int32]bool)
+	minimized := make(map[windowKey]bool)
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		parts := strings.SplitN(strings.TrimSpace(line), "|", 3)
+		if len(parts) < 2 {
+			continue
+		}
+		pid, err := strconv.ParseInt(parts[1], 10, 32)
+		if err != nil {
+			continue
+		}
+		switch {
+		case parts[0] == "hidden":
+			hidden[int32(pid)] = true
+		case parts[0] == "minimized" && len(parts) == 3:
+			minimized[windowKey{int32(pid), parts[2]}] = true
+		}
+	}
+	for i := range windows {
+		windows[i].Hidden = hidden[windows[i].PID]
+		windows[i].Minimized = minimized[windowKey{windows[i].PID, windows[i].Title}]
+	}
+}
+
+// getMacOSScriptWindows gets windows on macOS using osascript
+func getMacOSScriptWindows(ctx context.Context) ([]types.WindowInfo, error) {
 	script := `
 		tell application "System Events"
 			set windowList to {}
@@ -53,7 +252,7 @@ func getMacOSWindows(ctx context.Context) ([]types.WindowInfo, error) {
 	cmd := exec.CommandContext(ctx, "osascript", "-e", script)
 	output, err := cmd.Output()
 	if err != nil {
-		return nil, err
+		return nil, scriptError(err)
 	}
 
 	lines := strings.Split(strings.TrimSpace(string(output)), ", ")
@@ -125,7 +324,17 @@ func getMacOSWindowsAlt(ctx context.Context) ([]types.WindowInfo, error) {
 						try
 							set winTitle to title of win
 							if winTitle is not "" then
-								set end of windowList to procName & "|" & winTitle & "|" & procPID
+								set {x, y} to position of win
+								set {w, h} to size of win
+								set isMinimized to false
+								try
+									set isMinimized to value of attribute "AXMinimized" of win
+								end try
+								set isFullScreen to false
+								try
+									set isFullScreen to value of attribute "AXFullScreen" of win
+								end try
+								set end of windowList to procName & "|" & winTitle & "|" & procPID & "|" & x & "|" & y & "|" & w & "|" & h & "|" & isMinimized & "|" & isFullScreen & "|" & (visible of proc)
 							end if
 						end try
 					end repeat
@@ -133,12 +342,13 @@ func getMacOSWindowsAlt(ctx context.Context) ([]types.WindowInfo, error) {
 			end if
 		end repeat
 	end tell
-	return windowList`
+	set AppleScript's text item delimiters to linefeed
+	return windowList as text`
 
 	cmd := exec.CommandContext(ctx, "osascript", "-e", script)
 	output, err := cmd.Output()
 	if err != nil {
-		return nil, err
+		return nil, scriptError(err)
 	}
 
 	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
@@ -150,11 +360,14 @@ func getMacOSWindowsAlt(ctx context.Context) ([]types.WindowInfo, error) {
 			continue
 		}
 
+		// Format: app|title|pid|x|y|width|height|minimized|fullscreen|visible;
+		// the title may itself contain "|"
 		parts := strings.Split(line, "|")
-		if len(parts) >= 3 {
+		if len(parts) >= 10 {
+			n := len(parts)
 			appName := strings.TrimSpace(parts[0])
-			title := strings.TrimSpace(parts[1])
-			pidStr := strings.TrimSpace(parts[2])
+			title := strings.TrimSpace(strings.Join(parts[1:n-8], "|"))
+			pidStr := strings.TrimSpace(parts[n-8])
 
 			pid, err := strconv.ParseInt(pidStr, 10, 32)
 			if err != nil {
@@ -163,10 +376,14 @@ func getMacOSWindowsAlt(ctx context.Context) ([]types.WindowInfo, error) {
 
 			if appName != "" && title != "" {
 				windows = append(windows, types.WindowInfo{
-					Title:   title,
-					PID:     int32(pid),
-					Process: appName,
-					AppName: appName,
+					Title:      title,
+					PID:        int32(pid),
+					Process:    appName,
+					AppName:    appName,
+					Geometry:   parseGeometry(parts[n-7], parts[n-6], parts[n-5], parts[n-4]),
+					Minimized:  parts[n-3] == "true",
+					Fullscreen: parts[n-2] == "true",
+					Hidden:     parts[n-1] == "false",
 				})
 			}
 		}
@@ -175,9 +392,25 @@ func getMacOSWindowsAlt(ctx context.Context) ([]types.WindowInfo, error) {
 	return windows, nil
 }
 
-// getLinuxWindows gets windows on Linux using wmctrl
+// getLinuxWindows gets windows on Linux with the backend linuxBackend
+// picks
 func getLinuxWindows(ctx context.Context) ([]types.WindowInfo, error) {
-	cmd := exec.CommandContext(ctx, "wmctrl", "-lp")
+	switch backend := linuxBackend(); backend {
+	case BackendWmctrl:
+		return getWmctrlWindows(ctx)
+	case BackendXprop:
+		return getXpropWindows(ctx)
+	case BackendXdotool:
+		return getXdotoolWindows(ctx)
+	default:
+		return getWaylandWindows(ctx, backend)
+	}
+}
+
+// getWmctrlWindows gets X11 windows using wmctrl, with the stacking
+// order from xprop and displays from xrandr when they are installed
+func getWmctrlWindows(ctx context.Context) ([]types.WindowInfo, error) {
+	cmd := exec.CommandContext(ctx, "wmctrl", "-lpG")
 	output, err := cmd.Output()
 	if err != nil {
 		return nil, err
@@ -185,34 +418,267 @@ func getLinuxWindows(ctx context.Context) ([]types.WindowInfo, error) {
 
 	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
 	var windows []types.WindowInfo
+	var positions []int
+	stacking := linuxStacking(ctx)
+	monitors := linuxMonitors(ctx)
+	desktop := linuxCurrentDesktop(ctx)
 
 	for _, line := range lines {
+		// Format: id desktop pid x y width height host title...
 		parts := strings.Fields(line)
-		if len(parts) >= 5 {
+		if len(parts) >= 9 {
+			id, _ := strconv.ParseUint(strings.TrimPrefix(parts[0], "0x"), 16, 32)
 			pidStr := parts[2]
 			pid, _ := strconv.ParseInt(pidStr, 10, 32)
-			title := strings.Join(parts[4:], " ")
+			title := strings.Join(parts[8:], " ")
 
 			// Get process name
 			procName := getProcessName(ctx, int32(pid))
 
-			windows = append(windows, types.WindowInfo{
-				Title:   title,
-				PID:     int32(pid),
-				Process: procName,
-				AppName: procName,
-			})
+			geometry := parseGeometry(parts[3], parts[4], parts[5], parts[6])
+			w := types.WindowInfo{
+				ID:       uint32(id),
+				Title:    title,
+				PID:      int32(pid),
+				Process:  procName,
+				AppName:  procName,
+				Geometry: geometry,
+				Display:  displayOf(geometry, monitors),
+			}
+			w.Minimized, w.Fullscreen = linuxWindowState(ctx, uint32(id))
+			if n, ok := parseDesktop(parts[1]); ok {
+				setDesktop(&w, n, desktop)
+			}
+			windows = append(windows, w)
+			position, ok := stacking[uint32(id)]
+			if !ok {
+				position = -1
+			}
+			positions = append(positions, position)
 		}
 	}
+	setZOrder(windows, positions)
+	setOnScreen(windows)
 
 	return windows, nil
 }
 
-// getWindowsWindows gets windows on Windows using PowerShell
+// linuxStacking returns the position of each client window in the
+// stacking order, 0 being the topmost, from _NET_CLIENT_LIST_STACKING
+func linuxStacking(ctx context.Context) map[uint32]int {
+	// The property lists windows bottom to top
+	ids := xpropWindowList(ctx, "_NET_CLIENT_LIST_STACKING")
+	stacking := make(map[uint32]int, len(ids))
+	for i, id := range ids {
+		stacking[id] = len(ids) - 1 - i
+	}
+	return stacking
+}
+
+// linuxCurrentDesktop returns the 0-based number of the current desktop,
+// which wmctrl -d marks with an asterisk, or -1 if unknown
+func linuxCurrentDesktop(ctx context.Context) int {
+	output, err := exec.CommandContext(ctx, "wmctrl", "-d").Output()
+	if err != nil {
+		return -1
+	}
+	for _, line := range strings.Split(string(output), "\n") {
+		// Format: number current geometry... e.g. "0  * DG: 1920x1080 ..."
+		parts := strings.Fields(line)
+		if len(parts) >= 2 && parts[1] == "*" {
+			if n, err := strconv.Atoi(parts[0]); err == nil {
+				return n
+			}
+		}
+	}
+	return -1
+}
+
+// parseDesktop parses a 0-based desktop number. Sticky windows are on
+// desktop -1, which EWMH properties hold as the cardinal 0xFFFFFFFF.
+func parseDesktop(s string) (int, bool) {
+	n, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return int(int32(uint32(n))), true
+}
+
+// setDesktop sets the Space of a window from its 0-based desktop number,
+// given the current one
+func setDesktop(w *types.WindowInfo, n, current int) {
+	if n < 0 {
+		w.Space = -1
+		w.CurrentSpace = true
+		return
+	}
+	w.Space = n + 1
+	w.CurrentSpace = n == current
+}
+
+// linuxWindowState reports whether a window is iconified and whether it
+// is full screen, which EWMH window managers flag in _NET_WM_STATE with
+// _NET_WM_STATE_HIDDEN and _NET_WM_STATE_FULLSCREEN
+func linuxWindowState(ctx context.Context, id uint32) (minimized, fullscreen bool) {
+	output, err := exec.CommandContext(ctx, "xprop", "-id", fmt.Sprintf("0x%x", id), "_NET_WM_STATE").Output()
+	if err != nil {
+		return false, false
+	}
+	state := string(output)
+	return strings.Contains(state, "_NET_WM_STATE_HIDDEN"), strings.Contains(state, "_NET_WM_STATE_FULLSCREEN")
+}
+
+// xrandrMonitor matches a monitor of xrandr --listmonitors, e.g.
+// " 0: +*eDP-1 1920/344x1080/193+0+0  eDP-1"
+var xrandrMonitor = regexp.MustCompile(`^\s*\d+:\s+\+?(\*?)\S+\s+(\d+)/\d+x(\d+)/\d+\+(-?\d+)\+(-?\d+)`)
+
+// linuxMonitors returns the monitor rectangles from xrandr, the primary
+// monitor first
+func linuxMonitors(ctx context.Context) []types.WindowGeometry {
+	output, err := exec.CommandContext(ctx, "xrandr", "--listmonitors").Output()
+	if err != nil {
+		return nil
+	}
+	return parseXrandrMonitors(string(output))
+}
+
+// parseXrandrMonitors reads the monitor rectangles of xrandr
+// --listmonitors output, the primary monitor first
+func parseXrandrMonitors(output string) []types.WindowGeometry {
+	var monitors []types.WindowGeometry
+	for _, line := range strings.Split(output, "\n") {
+		m := xrandrMonitor.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		width, _ := strconv.Atoi(m[2])
+		height, _ := strconv.Atoi(m[3])
+		x, _ := strconv.Atoi(m[4])
+		y, _ := strconv.Atoi(m[5])
+		monitor := types.WindowGeometry{X: x, Y: y, Width: width, Height: height}
+		if m[1] == "*" {
+			monitors = append([]types.WindowGeometry{monitor}, monitors...)
+		} else {
+			monitors = append(monitors, monitor)
+		}
+	}
+	return monitors
+}
+
+// parseGeometry builds a window geometry from its textual coordinates,
+// returning nil if any of them is not a number
+func parseGeometry(x, y, width, height string) *types.WindowGeometry {
+	values := make([]int, 4)
+	for i, s := range []string{x, y, width, height} {
+		f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+		if err != nil {
+			return nil
+		}
+		values[i] = int(f)
+	}
+	return &types.WindowGeometry{X: values[0], Y: values[1], Width: values[2], Height: values[3]}
+}
+
+// displayOf returns the 1-based index of the display containing the
+// centre of a window, 0 if it is unknown
+func displayOf(geometry *types.WindowGeometry, displays []types.WindowGeometry) int {
+	if geometry == nil {
+		return 0
+	}
+	cx, cy := geometry.X+geometry.Width/2, geometry.Y+geometry.Height/2
+	for i, d := range displays {
+		if cx >= d.X && cx < d.X+d.Width && cy >= d.Y && cy < d.Y+d.Height {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// setZOrder ranks windows by their position in the platform's stacking
+// order, lowest first; windows with a negative position are left unranked
+func setZOrder(windows []types.WindowInfo, positions []int) {
+	ranked := make([]int, 0, len(windows))
+	for i, position := range positions {
+		if position >= 0 {
+			ranked = append(ranked, i)
+		}
+	}
+	sort.SliceStable(ranked, func(a, b int) bool { return positions[ranked[a]] < positions[ranked[b]] })
+	for rank, i := range ranked {
+		windows[i].ZOrder = rank + 1
+	}
+}
+
+// getWindowsWindows gets the main window of each process on Windows
+// using PowerShell, with its rectangle, display, stacking position and
+// whether it is topmost or minimized
 func getWindowsWindows(ctx context.Context) ([]types.WindowInfo, error) {
 	psScript := `
+		Add-Type -AssemblyName System.Windows.Forms
+		Add-Type @"
+using System;
+using System.Runtime.InteropServices;
+public class Win {
+	[StructLayout(LayoutKind.Sequential)] public struct RECT { public int Left, Top, Right, Bottom; }
+	[DllImport("user32.dll")] public static extern bool GetWindowRect(IntPtr hWnd, out RECT rect);
+	[DllImport("user32.dll")] public static extern int GetWindowLong(IntPtr hWnd, int index);
+	[DllImport("user32.dll")] public static extern IntPtr GetTopWindow(IntPtr hWnd);
+	[DllImport("user32.dll")] public static extern IntPtr GetWindow(IntPtr hWnd, uint cmd);
+	[DllImport("user32.dll")] public static extern bool IsIconic(IntPtr hWnd);
+	[DllImport("user32.dll")] public static extern bool IsWindowVisible(IntPtr hWnd);
+}
+[ComImport, InterfaceType(ComInterfaceType.InterfaceIsIUnknown), Guid("a5cd92ff-29be-454c-8d04-d82879fb3f1b")]
+public interface IVirtualDesktopManager {
+	[PreserveSig] int IsWindowOnCurrentVirtualDesktop(IntPtr hWnd, out int onCurrent);
+	[PreserveSig] int GetWindowDesktopId(IntPtr hWnd, out Guid desktopId);
+}
+[ComImport, Guid("aa509086-5ca9-4c25-8f95-589d3c07b48a")] public class VirtualDesktopManager {}
+"@
+		$stack = @{}
+		$h = [Win]::GetTopWindow([IntPtr]::Zero)
+		$i = 0
+		while ($h -ne [IntPtr]::Zero) {
+			$stack[$h.ToInt64()] = $i
+			$i++
+			$h = [Win]::GetWindow($h, 2)
+		}
+		$screens = @([System.Windows.Forms.Screen]::AllScreens | Sort-Object { -not $_.Primary })
+		$vdm = $null
+		try { $vdm = [IVirtualDesktopManager](New-Object VirtualDesktopManager) } catch {}
+		# Explorer keeps the desktop GUIDs in order, 16 bytes each
+		$desktops = @()
+		try {
+			$ids = (Get-ItemProperty 'HKCU:\Software\Microsoft\Windows\CurrentVersion\Explorer\VirtualDesktops' -ErrorAction Stop).VirtualDesktopIDs
+			for ($j = 0; $j + 16 -le $ids.Length; $j += 16) { $desktops += [Guid]::new([byte[]]$ids[$j..($j + 15)]) }
+		} catch {}
 		Get-Process | Where-Object {$_.MainWindowTitle -ne ""} | ForEach-Object {
-			$_.Id.ToString() + "|" + $_.ProcessName + "|" + $_.MainWindowTitle
+			$hwnd = $_.MainWindowHandle
+			$r = New-Object Win+RECT
+			[void][Win]::GetWindowRect($hwnd, [ref]$r)
+			$topmost = [int](([Win]::GetWindowLong($hwnd, -20) -band 8) -ne 0)
+			$screen = [System.Windows.Forms.Screen]::FromHandle($hwnd)
+			$display = [array]::IndexOf($screens, $screen) + 1
+			# Full-screen windows cover their whole screen without a caption
+			$b = $screen.Bounds
+			$fullscreen = [int]($r.Left -eq $b.Left -and $r.Top -eq $b.Top -and $r.Right -eq $b.Right -and
+				$r.Bottom -eq $b.Bottom -and ([Win]::GetWindowLong($hwnd, -16) -band 0xC00000) -ne 0xC00000)
+			$position = $stack[$hwnd.ToInt64()]
+			if ($position -eq $null) { $position = -1 }
+			$space = 0
+			$current = 0
+			if ($vdm -ne $null) {
+				[void]$vdm.IsWindowOnCurrentVirtualDesktop($hwnd, [ref]$current)
+				$desktop = [Guid]::Empty
+				if ($vdm.GetWindowDesktopId($hwnd, [ref]$desktop) -eq 0) {
+					$space = [array]::IndexOf($desktops, $desktop) + 1
+				}
+				if ($desktops.Count -eq 0 -and $current -ne 0) { $space = 1 }
+			}
+			$_.Id.ToString() + "|" + $_.ProcessName + "|" + $hwnd.ToInt64() + "|" +
+				$r.Left + "|" + $r.Top + "|" + ($r.Right - $r.Left) + "|" + ($r.Bottom - $r.Top) + "|" +
+				$display + "|" + $topmost + "|" + [int][Win]::IsIconic($hwnd) + "|" +
+				[int](-not [Win]::IsWindowVisible($hwnd)) + "|" + $position + "|" +
+				$space + "|" + [int]($current -ne 0) + "|" + $fullscreen + "|" + $_.MainWindowTitle
 		}
 	`
 
@@ -223,6 +689,7 @@ func getWindowsWindows(ctx context.Context) ([]types.WindowInfo, error) {
 	}
 
 	var windows []types.WindowInfo
+	var positions []int
 	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
 
 	for _, line := range lines {
@@ -231,29 +698,188 @@ func getWindowsWindows(ctx context.Context) ([]types.WindowInfo, error) {
 			continue
 		}
 
-		parts := strings.Split(line, "|")
-		if len(parts) >= 3 {
+		// Format: pid|name|hwnd|x|y|width|height|display|topmost|minimized|hidden|position|space|current|fullscreen|title
+		parts := strings.SplitN(line, "|", 16)
+		if len(parts) == 16 {
 			pidStr := strings.TrimSpace(parts[0])
 			processName := strings.TrimSpace(parts[1])
-			title := strings.TrimSpace(parts[2])
+			title := strings.TrimSpace(parts[15])
 
 			pid, err := strconv.ParseInt(pidStr, 10, 32)
 			if err != nil {
 				continue
 			}
+			hwnd, _ := strconv.ParseUint(parts[2], 10, 32)
+			display, _ := strconv.Atoi(parts[7])
+			layer, _ := strconv.Atoi(parts[8])
+			position, err := strconv.Atoi(parts[11])
+			if err != nil {
+				position = -1
+			}
+			space, _ := strconv.Atoi(parts[12])
 
 			windows = append(windows, types.WindowInfo{
-				Title:   title,
-				PID:     int32(pid),
-				Process: processName,
-				AppName: processName,
+				ID:           uint32(hwnd),
+				Title:        title,
+				PID:          int32(pid),
+				Process:      processName,
+				AppName:      processName,
+				Geometry:     parseGeometry(parts[3], parts[4], parts[5], parts[6]),
+				Display:      display,
+				Space:        space,
+				CurrentSpace: parts[13] == "1",
+				Layer:        layer,
+				Minimized:    parts[9] == "1",
+				Hidden:       parts[10] == "1",
+				Fullscreen:   parts[14] == "1",
 			})
+			positions = append(positions, position)
 		}
 	}
+	setZOrder(windows, positions)
+	setOnScreen(windows)
 
 	return windows, nil
 }
 
+// GetFocusedWindow returns the window that currently has keyboard focus
+func GetFocusedWindow(ctx context.Context) (*types.WindowInfo, error) {
+	switch runtime.GOOS {
+	case "darwin":
+		return getMacOSFocusedWindow(ctx)
+	case "linux":
+		return getLinuxFocusedWindow(ctx)
+	case "windows":
+		return getWindowsFocusedWindow(ctx)
+	default:
+		return nil, nil
+	}
+}
+
+// GetFrontmost returns the frontmost app and its focused window, with the
+// window's ID, geometry and state filled in from the window list. Window
+// is nil when the app has no titled window.
+func GetFrontmost(ctx context.Context) (types.FocusedWindowResponse, error) {
+	focused, err := GetFocusedWindow(ctx)
+	if err != nil {
+		return types.FocusedWindowResponse{}, err
+	}
+	if focused == nil || focused.PID == 0 {
+		return types.FocusedWindowResponse{}, fmt.Errorf("%w: no focused window", ErrNotFound)
+	}
+
+	resp := types.FocusedWindowResponse{
+		App: types.FrontmostApp{PID: focused.PID, Name: focused.AppName},
+	}
+	if focused.Title == "" {
+		return resp, nil
+	}
+	if w, err := Find(ctx, Query{PID: focused.PID, Title: focused.Title}); err == nil {
+		resp.Window = &w
+	} else {
+		resp.Window = focused
+	}
+	return resp, nil
+}
+
+// getMacOSFocusedWindow gets the frontmost app and its front window via osascript
+func getMacOSFocusedWindow(ctx context.Context) (*types.WindowInfo, error) {
+	script := `tell application "System Events"
+		set frontProc to first application process whose frontmost is true
+		set procName to name of frontProc
+		set procPID to unix id of frontProc
+		set winTitle to ""
+		try
+			set winTitle to name of front window of frontProc
+		end try
+	end tell
+	return procName & "|" & procPID & "|" & winTitle`
+
+	cmd := exec.CommandContext(ctx, "osascript", "-e", script)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, scriptError(err)
+	}
+
+	parts := strings.SplitN(strings.TrimSpace(string(output)), "|", 3)
+	if len(parts) < 3 {
+		return nil, nil
+	}
+
+	pid, _ := strconv.ParseInt(strings.TrimSpace(parts[1]), 10, 32)
+	appName := strings.TrimSpace(parts[0])
+	return &types.WindowInfo{
+		Title:   strings.TrimSpace(parts[2]),
+		PID:     int32(pid),
+		Process: appName,
+		AppName: appName,
+	}, nil
+}
+
+// getLinuxFocusedWindow gets the active window on X11 using xdotool
+func getLinuxFocusedWindow(ctx context.Context) (*types.WindowInfo, error) {
+	cmd := exec.CommandContext(ctx, "xdotool", "getactivewindow", "getwindowname", "getwindowpid")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	if len(lines) < 2 {
+		return nil, nil
+	}
+
+	pid, _ := strconv.ParseInt(strings.TrimSpace(lines[1]), 10, 32)
+	procName := getProcessName(ctx, int32(pid))
+	return &types.WindowInfo{
+		Title:   strings.TrimSpace(lines[0]),
+		PID:     int32(pid),
+		Process: procName,
+		AppName: procName,
+	}, nil
+}
+
+// getWindowsFocusedWindow gets the foreground window on Windows using PowerShell
+func getWindowsFocusedWindow(ctx context.Context) (*types.WindowInfo, error) {
+	psScript := `
+		Add-Type @"
+using System;
+using System.Runtime.InteropServices;
+public class Fg {
+	[DllImport("user32.dll")] public static extern IntPtr GetForegroundWindow();
+	[DllImport("user32.dll")] public static extern uint GetWindowThreadProcessId(IntPtr hWnd, out uint pid);
+}
+"@
+		$procId = 0
+		[void][Fg]::GetWindowThreadProcessId([Fg]::GetForegroundWindow(), [ref]$procId)
+		$p = Get-Process -Id $procId
+		$p.Id.ToString() + "|" + $p.ProcessName + "|" + $p.MainWindowTitle
+	`
+
+	cmd := exec.CommandContext(ctx, "powershell", "-Command", psScript)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	parts := strings.SplitN(strings.TrimSpace(string(output)), "|", 3)
+	if len(parts) < 3 {
+		return nil, nil
+	}
+
+	pid, err := strconv.ParseInt(strings.TrimSpace(parts[0]), 10, 32)
+	if err != nil {
+		return nil, err
+	}
+	processName := strings.TrimSpace(parts[1])
+	return &types.WindowInfo{
+		Title:   strings.TrimSpace(parts[2]),
+		PID:     int32(pid),
+		Process: processName,
+		AppName: processName,
+	}, nil
+}
+
 func getPIDForApp(ctx context.Context, appName string) int32 {
 	cmd := exec.CommandContext(ctx, "pgrep", "-f", appName)
 	output, err := cmd.Output()
@@ -276,3 +902,26 @@ func getProcessName(ctx context.Context, pid int32) string {
 	}
 	return ""
 }
+
+// Ready verifies that the window listing tool for this platform works. On
+// Linux hosts without an X11 or Wayland display it returns ErrNoSession.
+func Ready(ctx context.Context) error {
+	switch runtime.GOOS {
+	case "darwin":
+		if nativeWindowList {
+			return nil
+		}
+		return exec.CommandContext(ctx, "osascript", "-e", "return 1").Run()
+	case "linux":
+		if os.Getenv("DISPLAY") == "" && os.Getenv("WAYLAND_DISPLAY") == "" {
+			return ErrNoSession
+		}
+		_, err := exec.LookPath(backendCommands[linuxBackend()])
+		return err
+	case "windows":
+		_, err := exec.LookPath("powershell")
+		return err
+	default:
+		return nil
+	}
+}
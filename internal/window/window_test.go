@@ -0,0 +1,49 @@
+package window
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/borankux/gops/pkg/types"
+)
+
+func TestParseXrandrMonitors(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   []types.WindowGeometry
+	}{
+		{
+			"primary first",
+			"Monitors: 2\n" +
+				" 0: +HDMI-1 2560/597x1440/336+1920+0  HDMI-1\n" +
+				" 1: +*eDP-1 1920/344x1080/193+0+360  eDP-1\n",
+			[]types.WindowGeometry{
+				{X: 0, Y: 360, Width: 1920, Height: 1080},
+				{X: 1920, Y: 0, Width: 2560, Height: 1440},
+			},
+		},
+		{
+			"negative offset",
+			"Monitors: 2\n" +
+				" 0: +*DP-1 1920/527x1080/296+0+0  DP-1\n" +
+				" 1: +DP-2 1280/338x1024/270+-1280+0  DP-2\n",
+			[]types.WindowGeometry{
+				{X: 0, Y: 0, Width: 1920, Height: 1080},
+				{X: -1280, Y: 0, Width: 1280, Height: 1024},
+			},
+		},
+		{
+			"virtual monitor without +",
+			"Monitors: 1\n 0: VIRTUAL-1 1024/271x768/203+0+0  VIRTUAL-1\n",
+			[]types.WindowGeometry{{X: 0, Y: 0, Width: 1024, Height: 768}},
+		},
+		{"no monitors", "Monitors: 0\n", nil},
+		{"not xrandr output", "Can't open display\n", nil},
+	}
+	for _, tt := range tests {
+		if got := parseXrandrMonitors(tt.output); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: parseXrandrMonitors = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,238 @@
+package window
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/borankux/gops/pkg/types"
+)
+
+// getXpropWindows gets X11 windows without wmctrl, reading the EWMH
+// properties wmctrl reports with xprop, and geometry with xwininfo when
+// it is installed
+func getXpropWindows(ctx context.Context) ([]types.WindowInfo, error) {
+	output, err := exec.CommandContext(ctx, "xprop", "-root", "_NET_CLIENT_LIST", "_NET_CURRENT_DESKTOP").Output()
+	if err != nil {
+		return nil, err
+	}
+	root := parseXprop(string(output))
+	desktop, ok := parseDesktop(root["_NET_CURRENT_DESKTOP"])
+	if !ok {
+		desktop = -1
+	}
+	stacking := linuxStacking(ctx)
+	monitors := linuxMonitors(ctx)
+
+	var windows []types.WindowInfo
+	var positions []int
+	for _, id := range parseWindowList(root["_NET_CLIENT_LIST"]) {
+		output, err := exec.CommandContext(ctx, "xprop", "-id", fmt.Sprintf("0x%x", id),
+			"_NET_WM_NAME", "WM_NAME", "_NET_WM_PID", "_NET_WM_DESKTOP", "_NET_WM_STATE").Output()
+		if err != nil {
+			// Closed since the client list was read
+			continue
+		}
+		props := parseXprop(string(output))
+		title := xpropString(props["_NET_WM_NAME"])
+		if title == "" {
+			title = xpropString(props["WM_NAME"])
+		}
+		if title == "" {
+			continue
+		}
+		pid, _ := strconv.ParseInt(props["_NET_WM_PID"], 10, 32)
+		procName := getProcessName(ctx, int32(pid))
+		geometry := xwininfoGeometry(ctx, id)
+		state := props["_NET_WM_STATE"]
+		w := types.WindowInfo{
+			ID:         id,
+			Title:      title,
+			PID:        int32(pid),
+			Process:    procName,
+			AppName:    procName,
+			Geometry:   geometry,
+			Display:    displayOf(geometry, monitors),
+			Minimized:  strings.Contains(state, "_NET_WM_STATE_HIDDEN"),
+			Fullscreen: strings.Contains(state, "_NET_WM_STATE_FULLSCREEN"),
+		}
+		if n, ok := parseDesktop(props["_NET_WM_DESKTOP"]); ok {
+			setDesktop(&w, n, desktop)
+		}
+		windows = append(windows, w)
+		position, ok := stacking[id]
+		if !ok {
+			position = -1
+		}
+		positions = append(positions, position)
+	}
+	setZOrder(windows, positions)
+	setOnScreen(windows)
+	return windows, nil
+}
+
+// getXdotoolWindows gets X11 windows with xdotool when neither wmctrl nor
+// xprop is installed. xdotool only finds mapped windows, so minimized
+// windows aren't listed, and it can't report the stacking order or
+// full-screen state.
+func getXdotoolWindows(ctx context.Context) ([]types.WindowInfo, error) {
+	output, err := exec.CommandContext(ctx, "xdotool", "search", "--onlyvisible", "--name", ".").Output()
+	if err != nil {
+		// xdotool exits with 1 when nothing matches
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return nil, nil
+		}
+		return nil, err
+	}
+	desktop := -1
+	if out, err := exec.CommandContext(ctx, "xdotool", "get_desktop").Output(); err == nil {
+		if n, ok := parseDesktop(string(out)); ok {
+			desktop = n
+		}
+	}
+	monitors := linuxMonitors(ctx)
+
+	var windows []types.WindowInfo
+	for _, field := range strings.Fields(string(output)) {
+		id, err := strconv.ParseUint(field, 10, 32)
+		if err != nil {
+			continue
+		}
+		title, err := exec.CommandContext(ctx, "xdotool", "getwindowname", field).Output()
+		if err != nil || strings.TrimSpace(string(title)) == "" {
+			continue
+		}
+		w := types.WindowInfo{
+			ID:    uint32(id),
+			Title: strings.TrimSpace(string(title)),
+		}
+		// Windows of clients not setting _NET_WM_PID have no PID
+		if out, err := exec.CommandContext(ctx, "xdotool", "getwindowpid", field).Output(); err == nil {
+			pid, _ := strconv.ParseInt(strings.TrimSpace(string(out)), 10, 32)
+			w.PID = int32(pid)
+			w.Process = getProcessName(ctx, w.PID)
+			w.AppName = w.Process
+		}
+		// Format: WINDOW=... X=... Y=... WIDTH=... HEIGHT=... SCREEN=..., one per line
+		if out, err := exec.CommandContext(ctx, "xdotool", "getwindowgeometry", "--shell", field).Output(); err == nil {
+			values := make(map[string]string)
+			for _, line := range strings.Fields(string(out)) {
+				if key, value, found := strings.Cut(line, "="); found {
+					values[key] = value
+				}
+			}
+			w.Geometry = parseGeometry(values["X"], values["Y"], values["WIDTH"], values["HEIGHT"])
+			w.Display = displayOf(w.Geometry, monitors)
+		}
+		if out, err := exec.CommandContext(ctx, "xdotool", "get_desktop_for_window", field).Output(); err == nil {
+			if n, ok := parseDesktop(string(out)); ok {
+				setDesktop(&w, n, desktop)
+			}
+		}
+		windows = append(windows, w)
+	}
+	setOnScreen(windows)
+	return windows, nil
+}
+
+// parseXprop parses xprop output into property values by name, e.g.
+// `_NET_WM_PID(CARDINAL) = 4242`. Properties a window lacks are left out.
+func parseXprop(output string) map[string]string {
+	props := make(map[string]string)
+	for _, line := range strings.Split(output, "\n") {
+		name, value, found := strings.Cut(line, " = ")
+		if !found {
+			// Window lists read "_NET_CLIENT_LIST(WINDOW): window id # 0x..."
+			name, value, found = strings.Cut(line, ": window id # ")
+		}
+		if !found {
+			continue
+		}
+		if i := strings.IndexByte(name, '('); i >= 0 {
+			name = name[:i]
+		}
+		props[name] = strings.TrimSpace(value)
+	}
+	return props
+}
+
+// xpropString unquotes a string property value
+func xpropString(value string) string {
+	if s, err := strconv.Unquote(value); err == nil {
+		return s
+	}
+	return strings.Trim(value, `"`)
+}
+
+// xpropWindowList reads a window list property of the root window, such
+// as _NET_CLIENT_LIST
+func xpropWindowList(ctx context.Context, property string) []uint32 {
+	output, err := exec.CommandContext(ctx, "xprop", "-root", property).Output()
+	if err != nil {
+		return nil
+	}
+	return parseWindowList(parseXprop(string(output))[property])
+}
+
+// parseWindowList parses a comma-separated list of window IDs
+func parseWindowList(list string) []uint32 {
+	var ids []uint32
+	for _, s := range strings.Split(list, ",") {
+		if id, err := strconv.ParseUint(strings.TrimPrefix(strings.TrimSpace(s), "0x"), 16, 32); err == nil {
+			ids = append(ids, uint32(id))
+		}
+	}
+	return ids
+}
+
+// xwininfoGeometry reads the position and size of a window with
+// xwininfo, returning nil when it isn't installed
+func xwininfoGeometry(ctx context.Context, id uint32) *types.WindowGeometry {
+	output, err := exec.CommandContext(ctx, "xwininfo", "-id", fmt.Sprintf("0x%x", id)).Output()
+	if err != nil {
+		return nil
+	}
+	// Lines like "  Absolute upper-left X:  10" and "  Width: 800"
+	values := make(map[string]string)
+	for _, line := range strings.Split(string(output), "\n") {
+		if key, value, found := strings.Cut(line, ":"); found {
+			values[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+	return parseGeometry(values["Absolute upper-left X"], values["Absolute upper-left Y"], values["Width"], values["Height"])
+}
+
+// x11Command runs wmctrl -i with wmctrlArgs on the X11 window w, or
+// xdotool with xdotoolArgs where wmctrl isn't installed
+func x11Command(ctx context.Context, w types.WindowInfo, wmctrlArgs, xdotoolArgs []string) error {
+	if _, err := exec.LookPath("wmctrl"); err != nil {
+		if _, err := exec.LookPath("xdotool"); err == nil {
+			return exec.CommandContext(ctx, "xdotool", append(xdotoolArgs, strconv.FormatUint(uint64(w.ID), 10))...).Run()
+		}
+	}
+	args := append([]string{"-i"}, wmctrlArgs...)
+	return exec.CommandContext(ctx, "wmctrl", append(args, fmt.Sprintf("0x%08x", w.ID))...).Run()
+}
+
+// moveX11Window sets the frame of w with wmctrl, unmaximizing it first,
+// or with xdotool where wmctrl isn't installed
+func moveX11Window(ctx context.Context, w types.WindowInfo, target types.WindowGeometry) error {
+	if _, err := exec.LookPath("wmctrl"); err != nil {
+		if _, err := exec.LookPath("xdotool"); err == nil {
+			id := strconv.FormatUint(uint64(w.ID), 10)
+			return exec.CommandContext(ctx, "xdotool",
+				"windowsize", id, strconv.Itoa(target.Width), strconv.Itoa(target.Height),
+				"windowmove", id, strconv.Itoa(target.X), strconv.Itoa(target.Y)).Run()
+		}
+	}
+	id := fmt.Sprintf("0x%08x", w.ID)
+	if err := exec.CommandContext(ctx, "wmctrl", "-i", "-r", id, "-b", "remove,maximized_vert,maximized_horz").Run(); err != nil {
+		return err
+	}
+	geometry := fmt.Sprintf("0,%d,%d,%d,%d", target.X, target.Y, target.Width, target.Height)
+	return exec.CommandContext(ctx, "wmctrl", "-i", "-r", id, "-e", geometry).Run()
+}
@@ -3,31 +3,417 @@ package types
 // ProcessInfo represents information about a running process
 type ProcessInfo struct {
 	PID       int32  `json:"pid"`
+	PPID      int32  `json:"ppid,omitempty"`
 	Name      string `json:"name"`
 	Path      string `json:"path,omitempty"`
 	Status    string `json:"status,omitempty"`
 	User      string `json:"user,omitempty"`
-	StartTime string `json:"start_time,omitempty"`
+	StartTime string `json:"start_time,omitempty"` // RFC 3339
+	Uptime    string `json:"uptime,omitempty"`     // Time since start, e.g. "3h 12m"
+
+	// macOS app bundle the executable belongs to; helpers resolve to the
+	// app that contains them
+	BundleID   string `json:"bundle_id,omitempty"`
+	AppName    string `json:"app_name,omitempty"`
+	AppVersion string `json:"app_version,omitempty"`
+
+	// CPU architecture the process runs as (arm64, x86_64); Translated is
+	// set for x86_64 processes emulated by Rosetta 2 on Apple Silicon
+	Arch       string `json:"arch,omitempty"`
+	Translated bool   `json:"translated,omitempty"`
+
+	// Cmdline is the argument vector, populated only on request as it can
+	// be large
+	Cmdline []string `json:"cmdline,omitempty"`
+
+	// Usage metrics, populated when sorting by cpu or memory
+	CPUPercent float64 `json:"cpu_percent,omitempty"`
+	MemoryRSS  uint64  `json:"memory_rss,omitempty"`
+}
+
+// ProcessRef identifies a related process
+type ProcessRef struct {
+	PID  int32  `json:"pid"`
+	Name string `json:"name"`
+}
+
+// ProcessDetail is the full view of a single process
+type ProcessDetail struct {
+	ProcessInfo
+	ParentName string       `json:"parent_name,omitempty"`
+	Children   []ProcessRef `json:"children"`
+	Cwd        string       `json:"cwd,omitempty"` // Working directory, when readable
+
+	Security *ProcessSecurity `json:"security,omitempty"` // macOS code signature, when readable
+}
+
+// ProcessSecurity describes the code signature of a process executable
+type ProcessSecurity struct {
+	Signed          bool                   `json:"signed"`
+	TeamID          string                 `json:"team_id,omitempty"`
+	Sandboxed       bool                   `json:"sandboxed"`
+	HardenedRuntime bool                   `json:"hardened_runtime"`
+	Entitlements    map[string]interface{} `json:"entitlements,omitempty"`
+}
+
+// ProcessIconResponse is the application icon of a process. Data is the
+// PNG image, base64-encoded in JSON.
+type ProcessIconResponse struct {
+	PID      int32  `json:"pid"`
+	Name     string `json:"name"`
+	Source   string `json:"source"` // File the icon was read from
+	MimeType string `json:"mime_type"`
+	Width    int    `json:"width,omitempty"`
+	Height   int    `json:"height,omitempty"`
+	Data     []byte `json:"data"`
+}
+
+// ProcessNode is a process with the processes it spawned
+type ProcessNode struct {
+	PID      int32         `json:"pid"`
+	PPID     int32         `json:"ppid"`
+	Name     string        `json:"name"`
+	User     string        `json:"user,omitempty"`
+	Path     string        `json:"path,omitempty"`
+	Children []ProcessNode `json:"children,omitempty"`
 }
 
 // WindowInfo represents information about an open window
 type WindowInfo struct {
-	Title    string `json:"title"`
-	PID      int32  `json:"pid"`
-	Process  string `json:"process"`
-	AppName  string `json:"app_name,omitempty"`
-	Geometry string `json:"geometry,omitempty"`
+	ID       uint32          `json:"id,omitempty"` // Window server ID, where the platform exposes one
+	Title    string          `json:"title"`
+	PID      int32           `json:"pid"`
+	Process  string          `json:"process"`
+	AppName  string          `json:"app_name,omitempty"`
+	Geometry *WindowGeometry `json:"geometry,omitempty"`
+	// Display is the 1-based index of the display showing the window's
+	// centre, 1 being the main display; 0 if unknown
+	Display int `json:"display,omitempty"`
+	// Layer is the window level: 0 for normal windows, higher for floating
+	// panels and always-on-top windows
+	Layer int `json:"layer,omitempty"`
+	// ZOrder is the 1-based position in the stacking order, 1 being the
+	// frontmost window; 0 if unknown
+	ZOrder int `json:"z_order,omitempty"`
+	// Space is the 1-based macOS Space or virtual desktop showing the
+	// window, -1 if it is shown on all of them and 0 if unknown
+	Space int `json:"space,omitempty"`
+	// CurrentSpace is set for windows on the active Space or desktop
+	CurrentSpace bool `json:"current_space,omitempty"`
+	// Minimized is set for windows minimized to the Dock or taskbar
+	Minimized bool `json:"minimized"`
+	// Hidden is set for windows that aren't shown although not minimized:
+	// those of a hidden app or ordered out by their app on macOS, and
+	// invisible windows on Windows
+	Hidden bool `json:"hidden"`
+	// Fullscreen is set for windows in full-screen mode, which on macOS
+	// take a Space of their own
+	Fullscreen bool `json:"is_fullscreen"`
+	// OnScreen is set for windows currently showing: neither minimized
+	// nor hidden, and on the active Space or desktop
+	OnScreen bool `json:"onscreen"`
+}
+
+// WindowTitleChange is the data of a window.title_changed event: the
+// window with its new title, and the title it had before
+type WindowTitleChange struct {
+	WindowInfo
+	PreviousTitle string `json:"previous_title"`
+}
+
+// WindowTitleHistory is the titles a window has had while gops watched
+// it, oldest first
+type WindowTitleHistory struct {
+	ID      uint32 `json:"id,omitempty"`
+	PID     int32  `json:"pid"`
+	Process string `json:"process"`
+	// Title is the latest title
+	Title  string        `json:"title"`
+	Closed bool          `json:"closed"`
+	Titles []TitleChange `json:"titles"`
+}
+
+// PortSpan is a listening port as recorded in the port history: the
+// socket, the process holding it, and when it was open
+type PortSpan struct {
+	Port          uint32 `json:"port"`
+	Protocol      string `json:"protocol"`
+	Family        string `json:"family"`
+	LocalIP       string `json:"local_ip,omitempty"`
+	Exposure      string `json:"exposure,omitempty"`
+	PID           int32  `json:"pid"`
+	Name          string `json:"name"`
+	Path          string `json:"path,omitempty"`
+	ContainerName string `json:"container_name,omitempty"`
+	// Opened is when the listener was first seen, Closed when it was
+	// first seen gone, empty while it is open, and LastSeen when it was
+	// last seen open; all RFC 3339 times in UTC
+	Opened   string `json:"opened"`
+	Closed   string `json:"closed,omitempty"`
+	LastSeen string `json:"last_seen"`
+}
+
+// TitleChange is a title a window took on and when it was first seen
+type TitleChange struct {
+	Title string `json:"title"`
+	Time  string `json:"time"`
+}
+
+// WindowGeometry is the position and size of a window in screen
+// coordinates, with the origin at the top left of the main display
+type WindowGeometry struct {
+	X      int `json:"x"`
+	Y      int `json:"y"`
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+// DisplayInfo describes a connected display
+type DisplayInfo struct {
+	// Index is the 1-based display number used by WindowInfo.Display, the
+	// primary display being 1
+	Index   int    `json:"index"`
+	Name    string `json:"name"`
+	Primary bool   `json:"primary"`
+	// Bounds is the display area in screen coordinates, which are points
+	// rather than pixels on scaled (e.g. Retina) displays
+	Bounds      *WindowGeometry `json:"bounds,omitempty"`
+	PixelWidth  int             `json:"pixel_width,omitempty"`
+	PixelHeight int             `json:"pixel_height,omitempty"`
+	// Scale is the number of pixels per point, e.g. 2 on Retina displays
+	Scale float64 `json:"scale,omitempty"`
+	// RefreshRate is in Hz, 0 if unknown
+	RefreshRate float64 `json:"refresh_rate,omitempty"`
+	// Windows is the number of open windows on the display
+	Windows int `json:"windows"`
 }
 
 // PortInfo represents information about an open port
 type PortInfo struct {
 	Port     uint32 `json:"port"`
 	Protocol string `json:"protocol"`
+	// Family is the address family of the socket: ipv4 or ipv6
+	Family  string `json:"family"`
+	PID     int32  `json:"pid"`
+	Name    string `json:"name"`
+	Path    string `json:"path,omitempty"`
+	State   string `json:"state,omitempty"`
+	LocalIP string `json:"local_ip,omitempty"`
+	// Exposure is who can reach the port, judged by its bind address:
+	// loopback (this machine only), lan (one interface's networks) or all
+	// (every interface)
+	Exposure string `json:"exposure"`
+	// RemoteIP and RemotePort are the peer of a connected socket, listed
+	// when ports are selected by a state other than LISTEN
+	RemoteIP   string `json:"remote_ip,omitempty"`
+	RemotePort uint32 `json:"remote_port,omitempty"`
+	// Service is the well-known service registered for the port and
+	// protocol in the system services database, e.g. postgresql
+	Service string `json:"service,omitempty"`
+	// Hostname is the reverse DNS name of LocalIP, looked up on request
+	Hostname string `json:"hostname,omitempty"`
+	// Firewall is whether the host firewall lets other machines connect:
+	// allowed or blocked. It is set only when the firewall is checked and
+	// the port isn't bound to loopback, and left empty when the firewall
+	// has no rule for the port's application.
+	Firewall string `json:"firewall,omitempty"`
+	// ContainerID, ContainerName and ContainerImage identify the Docker
+	// container publishing the port, when a Docker proxy such as
+	// docker-proxy or com.docker.backend listens on it
+	ContainerID    string `json:"container_id,omitempty"`
+	ContainerName  string `json:"container_name,omitempty"`
+	ContainerImage string `json:"container_image,omitempty"`
+	// DualStack is set when the same process serves the port over both
+	// IPv4 and IPv6, with one socket for each
+	DualStack bool `json:"dual_stack,omitempty"`
+	// Connections is the number of established connections a TCP
+	// listener has accepted, and TopPeers the remote addresses with the
+	// most of them, busiest first
+	Connections int         `json:"connections,omitempty"`
+	TopPeers    []PeerCount `json:"top_peers,omitempty"`
+}
+
+// PeerCount is a remote address and its number of connections
+type PeerCount struct {
+	IP          string `json:"ip"`
+	Connections int    `json:"connections"`
+}
+
+// ScanResult is a local TCP port found by a scan, answering or bound
+type ScanResult struct {
+	Port uint32 `json:"port"`
+	// Status is answering, when the port accepted a connection on
+	// loopback, or bound, when a process listens on it but no connection
+	// was accepted
+	Status string `json:"status"`
+	// Answered lists the loopback addresses that accepted a connection
+	Answered []string `json:"answered,omitempty"`
+	// LatencyMs is how long the fastest connection took to establish
+	LatencyMs float64 `json:"latency_ms,omitempty"`
+	// BindAddresses are the addresses the listening sockets are bound to
+	BindAddresses []string `json:"bind_addresses,omitempty"`
+	// PID and Name are the listening process, zero and empty when it
+	// can't be seen, e.g. when another user owns it
+	PID            int32  `json:"pid,omitempty"`
+	Name           string `json:"name,omitempty"`
+	Path           string `json:"path,omitempty"`
+	Service        string `json:"service,omitempty"`
+	ContainerName  string `json:"container_name,omitempty"`
+	ContainerImage string `json:"container_image,omitempty"`
+}
+
+// FirewallStatus is the state of the host firewall
+type FirewallStatus struct {
+	// Backend is the firewall read: application-firewall (macOS), ufw or
+	// firewalld
+	Backend string `json:"backend,omitempty"`
+	Enabled bool   `json:"enabled"`
+	// DefaultIncoming is what happens to incoming connections no rule
+	// covers: allow or deny
+	DefaultIncoming string `json:"default_incoming,omitempty"`
+	// BlockAll is set when the macOS Application Firewall blocks every
+	// incoming connection
+	BlockAll bool `json:"block_all,omitempty"`
+	// PacketFilter is whether macOS's pf is enabled or disabled, empty
+	// when gops doesn't run as root; its rules aren't evaluated
+	PacketFilter string `json:"packet_filter,omitempty"`
+	// Error says why the firewall couldn't be read
+	Error string `json:"error,omitempty"`
+}
+
+// ConnectionInfo is an established connection with its owning process
+type ConnectionInfo struct {
+	Protocol   string `json:"protocol"`
+	Family     string `json:"family"`
+	LocalIP    string `json:"local_ip"`
+	LocalPort  uint32 `json:"local_port"`
+	RemoteIP   string `json:"remote_ip"`
+	RemotePort uint32 `json:"remote_port"`
+	State      string `json:"state"`
+	PID        int32  `json:"pid"`
+	Name       string `json:"name"`
+	Path       string `json:"path,omitempty"`
+	// Country, CountryName, ASN and ASOrg locate the remote address,
+	// when GeoIP lookups are asked for; see GeoInfo
+	Country     string `json:"country,omitempty"`
+	CountryName string `json:"country_name,omitempty"`
+	ASN         uint   `json:"asn,omitempty"`
+	ASOrg       string `json:"as_org,omitempty"`
+}
+
+// GeoInfo is where an IP address is registered, from local MaxMind
+// databases; empty for private addresses and those not found
+type GeoInfo struct {
+	// Country is the ISO 3166 code of the country, e.g. DE
+	Country     string `json:"country,omitempty"`
+	CountryName string `json:"country_name,omitempty"`
+	// ASN and ASOrg identify the autonomous system announcing the
+	// address, e.g. 15169 Google LLC
+	ASN   uint   `json:"asn,omitempty"`
+	ASOrg string `json:"as_org,omitempty"`
+}
+
+// UnixSocketInfo is a Unix domain socket bound to a path, with its owning
+// process
+type UnixSocketInfo struct {
+	// Path is the socket's file path, or its name prefixed with @ in
+	// Linux's abstract namespace
+	Path string `json:"path"`
+	// Type is stream, dgram or seqpacket; macOS doesn't report it
+	Type     string `json:"type,omitempty"`
+	Abstract bool   `json:"abstract,omitempty"`
 	PID      int32  `json:"pid"`
 	Name     string `json:"name"`
-	Path     string `json:"path,omitempty"`
-	State    string `json:"state,omitempty"`
-	LocalIP  string `json:"local_ip,omitempty"`
+	// Executable is the path of the owning process's executable
+	Executable string `json:"executable,omitempty"`
+	// Sockets counts the process's sockets on the path: its listening
+	// socket and the connections it accepted
+	Sockets int `json:"sockets"`
+}
+
+// InterfaceInfo is a network interface with its addresses and traffic
+// counters since boot
+type InterfaceInfo struct {
+	Name  string `json:"name"`
+	Index int    `json:"index"`
+	MAC   string `json:"mac,omitempty"`
+	MTU   int    `json:"mtu"`
+	// State is up when the interface is enabled and its link is running
+	State string   `json:"state"`
+	Flags []string `json:"flags"`
+	// Addresses are the interface's IPv4 and IPv6 addresses in CIDR
+	// notation
+	Addresses       []string `json:"addresses"`
+	BytesSent       uint64   `json:"bytes_sent"`
+	BytesReceived   uint64   `json:"bytes_received"`
+	PacketsSent     uint64   `json:"packets_sent"`
+	PacketsReceived uint64   `json:"packets_received"`
+	ErrorsIn        uint64   `json:"errors_in"`
+	ErrorsOut       uint64   `json:"errors_out"`
+	DropsIn         uint64   `json:"drops_in"`
+	DropsOut        uint64   `json:"drops_out"`
+}
+
+// RouteInfo is an entry in the routing table
+type RouteInfo struct {
+	// Destination is a network in CIDR notation, an address, or default
+	Destination string `json:"destination"`
+	// Gateway is the next hop, empty or a link for on-link routes
+	Gateway   string `json:"gateway,omitempty"`
+	Interface string `json:"interface"`
+	Family    string `json:"family"`
+	Metric    int    `json:"metric,omitempty"`
+	// Flags are the route's flags as the platform shows them, e.g. UGScg
+	// on macOS
+	Flags string `json:"flags,omitempty"`
+	// Protocol is what installed the route on Linux and Windows, e.g.
+	// dhcp or kernel
+	Protocol string `json:"protocol,omitempty"`
+	// Default is set for routes covering every address
+	Default bool `json:"default,omitempty"`
+	// VPN is set for routes through a VPN tunnel, judged by the
+	// interface's name
+	VPN bool `json:"vpn,omitempty"`
+}
+
+// NeighborInfo is an entry in the ARP (IPv4) or NDP (IPv6) neighbor
+// table: a host on a local network and its link-layer address
+type NeighborInfo struct {
+	IP string `json:"ip"`
+	// MAC is empty while the address is being resolved
+	MAC       string `json:"mac,omitempty"`
+	Interface string `json:"interface"`
+	Family    string `json:"family"`
+	// State is the entry's state as the platform reports it, e.g.
+	// reachable, stale, incomplete or permanent; macOS reports none for
+	// resolved ARP entries
+	State string `json:"state,omitempty"`
+}
+
+// DNSResolver is a resolver the system sends DNS queries to
+type DNSResolver struct {
+	Nameservers   []string `json:"nameservers"`
+	SearchDomains []string `json:"search_domains,omitempty"`
+	// Domain is the domain the resolver answers for on macOS, e.g. local,
+	// or the local domain name elsewhere
+	Domain string `json:"domain,omitempty"`
+	// Interface is the interface the resolver is bound to
+	Interface string   `json:"interface,omitempty"`
+	Options   []string `json:"options,omitempty"`
+	// Scoped is set for resolvers used only for queries through their
+	// interface
+	Scoped bool `json:"scoped,omitempty"`
+}
+
+// DNSCacheEntry is a record in the system's DNS cache
+type DNSCacheEntry struct {
+	Name string `json:"name"`
+	// Type is the record type, e.g. A, AAAA or CNAME
+	Type string `json:"type"`
+	Data string `json:"data"`
+	// TTL is the seconds left before the record expires, where the
+	// platform reports it
+	TTL int `json:"ttl,omitempty"`
 }
 
 // ResourceUsage represents CPU and memory usage
@@ -42,12 +428,42 @@ type ResourceUsage struct {
 	CPUHuman      string  `json:"cpu_human"`    // Human readable CPU
 	Threads       int32   `json:"threads,omitempty"`
 	OpenFiles     int32   `json:"open_files,omitempty"`
+	// Network is the throughput since the previous sample, reported by
+	// the resource stream where per-process traffic can be measured
+	Network *NetworkRate `json:"network,omitempty"`
+}
+
+// NetworkRate is the network throughput of a process in bytes per second
+type NetworkRate struct {
+	SentPerSec     uint64 `json:"sent_per_sec"`
+	ReceivedPerSec uint64 `json:"received_per_sec"`
+}
+
+// NetworkUsage is the network throughput of a process over a sampling
+// interval
+type NetworkUsage struct {
+	PID  int32  `json:"pid"`
+	Name string `json:"name"`
+	NetworkRate
+	SentHuman     string `json:"sent_human"`
+	ReceivedHuman string `json:"received_human"`
 }
 
 // ServiceInfo represents a system service
 type ServiceInfo struct {
-	Name          string  `json:"name"`
-	Status        string  `json:"status"`
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	// Domain is the launchd domain (system, user/<uid> or gui/<uid>) or,
+	// for systemd, system or user
+	Domain string `json:"domain,omitempty"`
+	// Scope is daemon for system-wide services and agent for those
+	// running on behalf of a user
+	Scope string `json:"scope,omitempty"`
+	// Enablement tells whether the service starts at boot or login:
+	// enabled or disabled, another systemd unit file state such as
+	// static or masked, or the Windows start type: automatic, manual or
+	// disabled
+	Enablement    string  `json:"enablement,omitempty"`
 	PID           int32   `json:"pid,omitempty"`
 	CPUPercent    float64 `json:"cpu_percent,omitempty"`
 	MemoryPercent float32 `json:"memory_percent,omitempty"`
@@ -55,31 +471,769 @@ type ServiceInfo struct {
 	CPUHuman      string  `json:"cpu_human,omitempty"`
 }
 
+// SystemInfo represents host identity and uptime
+type SystemInfo struct {
+	Hostname        string `json:"hostname"`
+	OS              string `json:"os"`
+	Platform        string `json:"platform,omitempty"`
+	PlatformVersion string `json:"platform_version,omitempty"`
+	KernelVersion   string `json:"kernel_version,omitempty"`
+	Arch            string `json:"arch,omitempty"`
+	CPUs            int    `json:"cpus"`
+	Processes       uint64 `json:"processes"`
+	BootTime        string `json:"boot_time,omitempty"`
+	Uptime          uint64 `json:"uptime"`       // Seconds since boot
+	UptimeHuman     string `json:"uptime_human"` // Human readable uptime
+}
+
+// SystemMetricsResponse reports how the machine is doing overall
+type SystemMetricsResponse struct {
+	SchemaVersion int     `json:"schema_version,omitempty"`
+	Hostname      string  `json:"hostname"`
+	CPUs          int     `json:"cpus"`
+	CPUPercent    float64 `json:"cpu_percent"` // Across all CPUs, over half a second
+	// Load holds the load averages, absent on Windows
+	Load        *LoadAverage `json:"load,omitempty"`
+	Memory      MemoryStats  `json:"memory"`
+	Swap        SwapStats    `json:"swap"`
+	Disks       []DiskUsage  `json:"disks"`
+	BootTime    string       `json:"boot_time,omitempty"`
+	Uptime      uint64       `json:"uptime"`       // Seconds since boot
+	UptimeHuman string       `json:"uptime_human"` // Human readable uptime
+}
+
+// LoadAverage is the number of runnable processes averaged over 1, 5 and
+// 15 minutes
+type LoadAverage struct {
+	Load1  float64 `json:"load1"`
+	Load5  float64 `json:"load5"`
+	Load15 float64 `json:"load15"`
+}
+
+// MemoryStats is the physical memory in bytes. Available is what can be
+// given to programs without swapping, counting reclaimable caches.
+type MemoryStats struct {
+	Total       uint64  `json:"total"`
+	Used        uint64  `json:"used"`
+	Free        uint64  `json:"free"`
+	Available   uint64  `json:"available"`
+	Cached      uint64  `json:"cached"` // Page cache; 0 where the OS doesn't report it
+	UsedPercent float64 `json:"used_percent"`
+}
+
+// SwapStats is the swap space in bytes
+type SwapStats struct {
+	Total       uint64  `json:"total"`
+	Used        uint64  `json:"used"`
+	Free        uint64  `json:"free"`
+	UsedPercent float64 `json:"used_percent"`
+}
+
+// DiskUsage is the space used on a mounted file system, in bytes
+type DiskUsage struct {
+	Mount       string  `json:"mount"`
+	Device      string  `json:"device"`
+	FSType      string  `json:"fs_type"`
+	Total       uint64  `json:"total"`
+	Used        uint64  `json:"used"`
+	Free        uint64  `json:"free"`
+	UsedPercent float64 `json:"used_percent"`
+}
+
+// CollectorStatus reports the health of a data collector
+type CollectorStatus struct {
+	Status        string `json:"status"` // ok, failing or unknown
+	LastSuccess   string `json:"last_success,omitempty"`
+	LastError     string `json:"last_error,omitempty"`
+	LastErrorTime string `json:"last_error_time,omitempty"`
+}
+
+// PermissionStatus reports whether a required OS permission is granted
+type PermissionStatus struct {
+	Name   string `json:"name"`
+	Status string `json:"status"` // granted, denied, not_required or unknown
+	Detail string `json:"detail,omitempty"`
+	// Settings is where a denied permission is granted
+	Settings string `json:"settings,omitempty"`
+}
+
+// Event represents a system change streamed to clients
+type Event struct {
+	Type string      `json:"type"`
+	Time string      `json:"time"`
+	Data interface{} `json:"data,omitempty"`
+}
+
+// WatchdogEvent is the data of the watchdog events, published when a
+// watched service or process is found down and as it is restarted
+type WatchdogEvent struct {
+	Kind string `json:"kind"` // service or process
+	Name string `json:"name"`
+	User bool   `json:"user,omitempty"` // A systemd user unit
+	PID  int32  `json:"pid,omitempty"`  // The new process after a restart
+	// Restarts is how many restarts were attempted within the restart
+	// window, counting this one
+	Restarts    int    `json:"restarts"`
+	MaxRestarts int    `json:"max_restarts"`
+	NextAttempt string `json:"next_attempt,omitempty"` // When a failed restart is retried
+	Error       string `json:"error,omitempty"`
+}
+
+// SchemaVersion is the version of the response types below. Bump it when
+// a response changes incompatibly; clients read it from schema_version.
+const SchemaVersion = 2
+
+// Webhook is a URL that receives system events as JSON POSTs
+type Webhook struct {
+	ID  string `json:"id"`
+	URL string `json:"url"`
+	// Events filters deliveries by event type or category, e.g. "port" or
+	// "process.cpu_high"; empty receives every event
+	Events []string `json:"events,omitempty"`
+	// Secret signs deliveries with HMAC-SHA256; it is never returned
+	Secret    string `json:"secret,omitempty"`
+	CreatedAt string `json:"created_at,omitempty"`
+}
+
 // Response types for MCP
 type ProcessesResponse struct {
-	Processes []ProcessInfo `json:"processes"`
-	Count     int           `json:"count"`
+	SchemaVersion int           `json:"schema_version,omitempty"`
+	Processes     []ProcessInfo `json:"processes"`
+	Count         int           `json:"count"`
+	Total         int           `json:"total"`
+	NextCursor    string        `json:"next_cursor,omitempty"`
+}
+
+type ProcessResponse struct {
+	SchemaVersion int           `json:"schema_version,omitempty"`
+	Process       ProcessDetail `json:"process"`
+}
+
+// StrayProcess is a zombie or orphaned process with the parent that
+// failed to reap it or has gone away
+type StrayProcess struct {
+	PID        int32  `json:"pid"`
+	Name       string `json:"name"`
+	User       string `json:"user,omitempty"`
+	Status     string `json:"status,omitempty"`
+	PPID       int32  `json:"ppid"`
+	ParentName string `json:"parent_name,omitempty"` // Empty when the parent no longer exists
+}
+
+type StrayProcessesResponse struct {
+	SchemaVersion int            `json:"schema_version,omitempty"`
+	Zombies       []StrayProcess `json:"zombies"`
+	Orphans       []StrayProcess `json:"orphans"`
+	Count         int            `json:"count"`
+}
+
+type ProcessSnapshotResponse struct {
+	SchemaVersion int    `json:"schema_version,omitempty"`
+	ID            string `json:"id"`
+	Time          string `json:"time"` // RFC 3339
+	Count         int    `json:"count"`
+}
+
+// ProcessChange is how a process's resource usage moved between two
+// snapshots
+type ProcessChange struct {
+	PID         int32   `json:"pid"`
+	Name        string  `json:"name"`
+	CPUSeconds  float64 `json:"cpu_seconds"` // CPU time used in between
+	CPUPercent  float64 `json:"cpu_percent"` // Average over the interval
+	MemoryRSS   uint64  `json:"memory_rss"`
+	MemoryDelta int64   `json:"memory_delta"`
+}
+
+type ProcessDiffResponse struct {
+	SchemaVersion int    `json:"schema_version,omitempty"`
+	Since         string `json:"since"`
+	SinceTime     string `json:"since_time"`
+	// Snapshot is the snapshot taken for this diff, usable as the next since
+	Snapshot string          `json:"snapshot"`
+	Time     string          `json:"time"`
+	Elapsed  string          `json:"elapsed"`
+	Started  []ProcessInfo   `json:"started"`
+	Stopped  []ProcessInfo   `json:"stopped"`
+	Changed  []ProcessChange `json:"changed"`
+}
+
+type ProcessTreeResponse struct {
+	SchemaVersion int           `json:"schema_version,omitempty"`
+	Roots         []ProcessNode `json:"roots"`
+	Count         int           `json:"count"` // Processes in the tree
+}
+
+type SignalResponse struct {
+	SchemaVersion int    `json:"schema_version,omitempty"`
+	PID           int32  `json:"pid"`
+	Name          string `json:"name"`
+	Signal        string `json:"signal"`
+}
+
+type LaunchResponse struct {
+	SchemaVersion int    `json:"schema_version,omitempty"`
+	PID           int32  `json:"pid"` // 0 if the process could not be identified
+	Name          string `json:"name"`
+	Path          string `json:"path,omitempty"`
+	BundleID      string `json:"bundle_id,omitempty"`
+	// AlreadyRunning is set when the app was running and was only
+	// brought to the front
+	AlreadyRunning bool `json:"already_running,omitempty"`
+}
+
+type FocusWindowResponse struct {
+	SchemaVersion int    `json:"schema_version,omitempty"`
+	ID            uint32 `json:"id,omitempty"`
+	PID           int32  `json:"pid"`
+	Process       string `json:"process"`
+	Title         string `json:"title"`
+}
+
+type CloseWindowResponse struct {
+	SchemaVersion int    `json:"schema_version,omitempty"`
+	ID            uint32 `json:"id,omitempty"`
+	PID           int32  `json:"pid"`
+	Process       string `json:"process"`
+	Title         string `json:"title"`
+	// DryRun is set when the window was only looked up, not closed
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+type FocusedWindowResponse struct {
+	SchemaVersion int          `json:"schema_version,omitempty"`
+	App           FrontmostApp `json:"app"`
+	// Window is the focused window of App, nil when it has none
+	Window *WindowInfo `json:"window"`
+}
+
+// FrontmostApp is the application receiving keyboard input
+type FrontmostApp struct {
+	PID  int32  `json:"pid"`
+	Name string `json:"name"`
+}
+
+type WindowStateResponse struct {
+	SchemaVersion int    `json:"schema_version,omitempty"`
+	ID            uint32 `json:"id,omitempty"`
+	PID           int32  `json:"pid"`
+	Process       string `json:"process"`
+	Title         string `json:"title"`
+	Minimized     bool   `json:"minimized"`
+	Hidden        bool   `json:"hidden"`
+}
+
+type MoveWindowResponse struct {
+	SchemaVersion int            `json:"schema_version,omitempty"`
+	ID            uint32         `json:"id,omitempty"`
+	PID           int32          `json:"pid"`
+	Process       string         `json:"process"`
+	Title         string         `json:"title"`
+	Geometry      WindowGeometry `json:"geometry"`
+	Display       int            `json:"display,omitempty"`
+}
+
+// TextElement is a piece of text read from a window's accessibility
+// tree, with the platform role of the element holding it, e.g.
+// AXStaticText on macOS or Text on Windows
+type TextElement struct {
+	Role string `json:"role"`
+	Text string `json:"text"`
+}
+
+type WindowTextResponse struct {
+	SchemaVersion int    `json:"schema_version,omitempty"`
+	ID            uint32 `json:"id,omitempty"`
+	PID           int32  `json:"pid"`
+	Process       string `json:"process"`
+	Title         string `json:"title"`
+	// Text joins the text of every element, one per line
+	Text     string        `json:"text"`
+	Elements []TextElement `json:"elements"`
+}
+
+// RecognizedText is a line of text found in a window screenshot by OCR
+type RecognizedText struct {
+	Text string `json:"text"`
+	// Confidence is between 0 and 1
+	Confidence float64 `json:"confidence"`
+	// Bounds is in screenshot pixels from its top left corner
+	Bounds WindowGeometry `json:"bounds"`
+}
+
+type WindowCaptureResponse struct {
+	SchemaVersion int    `json:"schema_version,omitempty"`
+	ID            uint32 `json:"id,omitempty"`
+	PID           int32  `json:"pid"`
+	Process       string `json:"process"`
+	Title         string `json:"title"`
+	MimeType      string `json:"mime_type"`
+	Width         int    `json:"width"`
+	Height        int    `json:"height"`
+	Data          []byte `json:"data"`
+	// Text and TextRegions hold the text OCR found, when asked for; Text
+	// joins the regions one per line
+	Text        string           `json:"text,omitempty"`
+	TextRegions []RecognizedText `json:"text_regions,omitempty"`
+}
+
+type ArrangeWindowsResponse struct {
+	SchemaVersion int    `json:"schema_version,omitempty"`
+	Layout        string `json:"layout"`
+	Display       int    `json:"display"`
+	// Windows are the arranged windows with their new geometry, in the
+	// order they were given
+	Windows []MoveWindowResponse `json:"windows"`
+}
+
+type HideAppResponse struct {
+	SchemaVersion int    `json:"schema_version,omitempty"`
+	PID           int32  `json:"pid"`
+	Process       string `json:"process"`
+	// Action is "hidden" on macOS, or "minimized" where apps can't be
+	// hidden and their windows were minimized instead
+	Action  string `json:"action"`
+	Windows int    `json:"windows"` // Windows affected
+}
+
+type EnvironmentResponse struct {
+	SchemaVersion int               `json:"schema_version,omitempty"`
+	PID           int32             `json:"pid"`
+	Name          string            `json:"name"`
+	Env           map[string]string `json:"env"`
+	Redacted      []string          `json:"redacted,omitempty"` // Keys whose values were hidden
+	Count         int               `json:"count"`
+}
+
+// MemoryRegion is a range of a process's virtual address space
+type MemoryRegion struct {
+	Start       string `json:"start"` // Hex address
+	End         string `json:"end"`
+	Size        uint64 `json:"size"`               // Virtual size in bytes
+	Resident    uint64 `json:"resident,omitempty"` // Bytes in physical memory
+	Permissions string `json:"permissions"`        // e.g. r-x
+	// Type is executable, library, heap, stack, anonymous, file, system
+	// or other
+	Type string `json:"type"`
+	// Detail is the platform's own region label, e.g. "Stack: thread 0"
+	// on macOS
+	Detail string `json:"detail,omitempty"`
+	Path   string `json:"path,omitempty"`
+}
+
+// MemoryRegionSummary totals the regions of one type
+type MemoryRegionSummary struct {
+	Type     string `json:"type"`
+	Regions  int    `json:"regions"`
+	Size     uint64 `json:"size"`
+	Resident uint64 `json:"resident"`
+}
+
+type MemoryMapResponse struct {
+	SchemaVersion int                   `json:"schema_version,omitempty"`
+	PID           int32                 `json:"pid"`
+	Name          string                `json:"name"`
+	Regions       []MemoryRegion        `json:"regions"`
+	Summary       []MemoryRegionSummary `json:"summary"` // Largest resident first
+	Count         int                   `json:"count"`
+}
+
+// ThreadInfo is a thread of a process
+type ThreadInfo struct {
+	// ID is the kernel thread ID on Linux; macOS doesn't expose thread
+	// IDs, so there it is the thread's position in the process
+	ID         int32   `json:"id"`
+	Name       string  `json:"name,omitempty"` // Linux only
+	State      string  `json:"state"`
+	CPUPercent float64 `json:"cpu_percent"`
+	UserTime   float64 `json:"user_time"`   // CPU seconds in user mode
+	SystemTime float64 `json:"system_time"` // CPU seconds in the kernel
+}
+
+type ThreadsResponse struct {
+	SchemaVersion int          `json:"schema_version,omitempty"`
+	PID           int32        `json:"pid"`
+	Name          string       `json:"name"`
+	Threads       []ThreadInfo `json:"threads"` // Busiest first
+	Count         int          `json:"count"`
+}
+
+// Sources of resource limits
+const (
+	// LimitSourceProcess limits are those in force for the process
+	LimitSourceProcess = "process"
+	// LimitSourceLaunchd limits are the macOS defaults launchd gives the
+	// processes it starts; a shell's ulimit may have changed them for
+	// its children
+	LimitSourceLaunchd = "launchd"
+)
+
+// Units of resource limits; limits without a unit, such as nice, are
+// plain values
+const (
+	LimitUnitBytes        = "bytes"
+	LimitUnitCount        = "count"
+	LimitUnitSeconds      = "seconds"
+	LimitUnitMicroseconds = "microseconds"
+)
+
+// ResourceLimit is a soft and hard resource limit (rlimit). Soft and Hard
+// are null when unlimited.
+type ResourceLimit struct {
+	Resource string  `json:"resource"` // e.g. open_files
+	Unit     string  `json:"unit,omitempty"`
+	Soft     *uint64 `json:"soft"`
+	Hard     *uint64 `json:"hard"`
+	Used     *uint64 `json:"used,omitempty"` // Current usage, when known
+}
+
+type ResourceLimitsResponse struct {
+	SchemaVersion int             `json:"schema_version,omitempty"`
+	PID           int32           `json:"pid"`
+	Name          string          `json:"name"`
+	Source        string          `json:"source"`
+	Limits        []ResourceLimit `json:"limits"`
+	Count         int             `json:"count"`
+}
+
+// OpenFile is a file descriptor held open by a process
+type OpenFile struct {
+	FD   uint64 `json:"fd"`
+	Path string `json:"path"`
+	Type string `json:"type"`           // file, dir, socket, pipe, device or other
+	Mode string `json:"mode,omitempty"` // r, w or rw when known
+}
+
+type OpenFilesResponse struct {
+	SchemaVersion int        `json:"schema_version,omitempty"`
+	PID           int32      `json:"pid"`
+	Name          string     `json:"name"`
+	Files         []OpenFile `json:"files"`
+	Count         int        `json:"count"`
+}
+
+type PriorityResponse struct {
+	SchemaVersion int    `json:"schema_version,omitempty"`
+	PID           int32  `json:"pid"`
+	Name          string `json:"name"`
+	OldPriority   string `json:"old_priority"` // Nice value, or priority class on Windows
+	NewPriority   string `json:"new_priority"`
 }
 
 type WindowsResponse struct {
-	Windows []WindowInfo `json:"windows"`
-	Count   int          `json:"count"`
+	SchemaVersion int          `json:"schema_version,omitempty"`
+	Windows       []WindowInfo `json:"windows"`
+	Count         int          `json:"count"`
+	// Omitted counts minimized and hidden windows left out of the list
+	Omitted int `json:"omitted,omitempty"`
+	// Backend names the Linux backend that listed the windows, e.g.
+	// wmctrl, xprop or sway
+	Backend string `json:"backend,omitempty"`
+}
+
+type WindowTitleHistoryResponse struct {
+	SchemaVersion int                  `json:"schema_version,omitempty"`
+	Windows       []WindowTitleHistory `json:"windows"`
+	Count         int                  `json:"count"`
+	// Since is when title recording began, empty until the watcher's
+	// first poll
+	Since string `json:"since,omitempty"`
+}
+
+type PortHistoryResponse struct {
+	SchemaVersion int        `json:"schema_version,omitempty"`
+	Ports         []PortSpan `json:"ports"`
+	Count         int        `json:"count"`
+	// Since is when this run of gops began recording, empty until the
+	// watcher's first poll; listeners saved by earlier runs may be older
+	Since string `json:"since,omitempty"`
+	// Retention is how long closed listeners are kept, e.g. "168h0m0s"
+	Retention string `json:"retention"`
+}
+
+type DisplaysResponse struct {
+	SchemaVersion int           `json:"schema_version,omitempty"`
+	Displays      []DisplayInfo `json:"displays"`
+	Count         int           `json:"count"`
 }
 
 type PortsResponse struct {
-	Ports []PortInfo `json:"ports"`
-	Count int        `json:"count"`
+	SchemaVersion int        `json:"schema_version,omitempty"`
+	Ports         []PortInfo `json:"ports"`
+	Count         int        `json:"count"`
+	Total         int        `json:"total"`
+	NextCursor    string     `json:"next_cursor,omitempty"`
+	// Firewall is set when the firewall was asked for
+	Firewall *FirewallStatus `json:"firewall,omitempty"`
+}
+
+type ScanResponse struct {
+	SchemaVersion int          `json:"schema_version,omitempty"`
+	Ports         []ScanResult `json:"ports"`
+	Count         int          `json:"count"`
+	// Answering and Bound count the ports with each status
+	Answering int `json:"answering"`
+	Bound     int `json:"bound"`
+	// From and To are the range scanned
+	From uint32 `json:"from"`
+	To   uint32 `json:"to"`
+}
+
+type ConnectionsResponse struct {
+	SchemaVersion int              `json:"schema_version,omitempty"`
+	Connections   []ConnectionInfo `json:"connections"`
+	Count         int              `json:"count"`
+	Total         int              `json:"total"`
+	NextCursor    string           `json:"next_cursor,omitempty"`
+}
+
+type UnixSocketsResponse struct {
+	SchemaVersion int              `json:"schema_version,omitempty"`
+	Sockets       []UnixSocketInfo `json:"sockets"`
+	Count         int              `json:"count"`
+	Total         int              `json:"total"`
+	NextCursor    string           `json:"next_cursor,omitempty"`
+}
+
+type InterfacesResponse struct {
+	SchemaVersion int             `json:"schema_version,omitempty"`
+	Interfaces    []InterfaceInfo `json:"interfaces"`
+	Count         int             `json:"count"`
+}
+
+type RoutesResponse struct {
+	SchemaVersion int         `json:"schema_version,omitempty"`
+	Routes        []RouteInfo `json:"routes"`
+	Count         int         `json:"count"`
+}
+
+type NeighborsResponse struct {
+	SchemaVersion int            `json:"schema_version,omitempty"`
+	Neighbors     []NeighborInfo `json:"neighbors"`
+	Count         int            `json:"count"`
+}
+
+type DNSResponse struct {
+	SchemaVersion int `json:"schema_version,omitempty"`
+	// Source is what the resolvers were read from, e.g. scutil or
+	// resolv.conf
+	Source    string        `json:"source"`
+	Resolvers []DNSResolver `json:"resolvers"`
+	// Cache is set when the cache was asked for and could be read
+	Cache []DNSCacheEntry `json:"cache,omitempty"`
+	// CacheError says why the cache, when asked for, couldn't be read
+	CacheError string `json:"cache_error,omitempty"`
+}
+
+type NetworkTopResponse struct {
+	SchemaVersion int            `json:"schema_version,omitempty"`
+	Processes     []NetworkUsage `json:"processes"`
+	Count         int            `json:"count"`
+	// Interval is how long traffic was measured, e.g. "1s"
+	Interval string `json:"interval"`
 }
 
 type ResourceResponse struct {
-	Usage ResourceUsage `json:"usage"`
+	SchemaVersion int           `json:"schema_version,omitempty"`
+	Usage         ResourceUsage `json:"usage"`
 }
 
 type ServicesResponse struct {
-	Services []ServiceInfo `json:"services"`
-	Count    int           `json:"count"`
+	SchemaVersion int           `json:"schema_version,omitempty"`
+	Services      []ServiceInfo `json:"services"`
+	Count         int           `json:"count"`
+}
+
+// ServiceDetailResponse describes how a service is configured and how it
+// last ran, to tell why it keeps restarting
+type ServiceDetailResponse struct {
+	SchemaVersion int    `json:"schema_version,omitempty"`
+	Name          string `json:"name"`
+	Manager       string `json:"manager"`          // launchd, systemd or windows
+	Domain        string `json:"domain,omitempty"` // launchd domain: system or gui/<uid>
+	User          bool   `json:"user,omitempty"`   // A systemd user unit
+	State         string `json:"state"`
+	PID           int32  `json:"pid,omitempty"`
+	// File is the launchd property list or systemd unit file, or the
+	// command line of a Windows service
+	File    string   `json:"file,omitempty"`
+	Program []string `json:"program,omitempty"` // ProgramArguments or ExecStart
+	// LogFiles are where a launchd job's standard output and error go
+	// (StandardOutPath, StandardErrorPath)
+	LogFiles []string `json:"log_files,omitempty"`
+	// RunAtLoad and KeepAlive come from the launchd property list;
+	// KeepAlive is true or the conditions under which the job is kept
+	// running
+	RunAtLoad bool        `json:"run_at_load,omitempty"`
+	KeepAlive interface{} `json:"keep_alive,omitempty"`
+	Restart   string      `json:"restart,omitempty"` // systemd Restart= policy
+	// LastExitStatus is unset if the service never exited or was killed
+	// by a signal, which LastSignal names
+	LastExitStatus *int   `json:"last_exit_status,omitempty"`
+	LastSignal     string `json:"last_signal,omitempty"`
+	Runs           int    `json:"runs,omitempty"`     // How often launchd started the job
+	Restarts       int    `json:"restarts,omitempty"` // How often systemd restarted the unit
+	// ThrottleInterval is the seconds launchd waits between starts of
+	// the job, or systemd between restarts (RestartSec=)
+	ThrottleInterval float64 `json:"throttle_interval,omitempty"`
+	// Throttled is set while launchd holds back a job that exited too
+	// soon, or after systemd hit the unit's start limit
+	Throttled bool `json:"throttled,omitempty"`
+	// Enablement is enabled or disabled, another systemd unit file
+	// state such as static, or the Windows start type
+	Enablement string `json:"enablement,omitempty"`
+	// Properties holds the raw systemd unit properties, or more
+	// Win32_Service fields on Windows
+	Properties map[string]string `json:"properties,omitempty"`
+}
+
+// ServiceLogEntry is a log line of a service
+type ServiceLogEntry struct {
+	Time    string `json:"time,omitempty"` // RFC 3339; unset for lines of a log file
+	Process string `json:"process,omitempty"`
+	PID     int32  `json:"pid,omitempty"`
+	Level   string `json:"level,omitempty"` // syslog priority, or the macOS message type
+	Message string `json:"message"`
+	// Source is the file a line was read from, unset for the system log
+	Source string `json:"source,omitempty"`
+}
+
+// ServiceLogsResponse holds the most recent log lines of a service,
+// oldest first
+type ServiceLogsResponse struct {
+	SchemaVersion int               `json:"schema_version,omitempty"`
+	Name          string            `json:"name"`
+	Entries       []ServiceLogEntry `json:"entries"`
+	Count         int               `json:"count"`
+}
+
+// FailedService is a service whose last run ended with a non-zero exit
+// status or a signal, for triage in one call
+type FailedService struct {
+	Name   string `json:"name"`
+	Domain string `json:"domain,omitempty"`
+	Scope  string `json:"scope,omitempty"`
+	State  string `json:"state"`
+	// Running is set when the service was started again since, e.g. by
+	// KeepAlive or a Restart= policy
+	Running        bool   `json:"running"`
+	PID            int32  `json:"pid,omitempty"`
+	LastExitStatus *int   `json:"last_exit_status,omitempty"`
+	LastSignal     string `json:"last_signal,omitempty"`
+	Runs           int    `json:"runs,omitempty"`     // How often launchd started the job since it was loaded
+	Restarts       int    `json:"restarts,omitempty"` // How often systemd restarted the unit since it was last started
+	Restart        string `json:"restart,omitempty"`  // systemd Restart= policy
+	Throttled      bool   `json:"throttled,omitempty"`
+	// Logs holds the last log lines of the service, oldest first
+	Logs []ServiceLogEntry `json:"logs,omitempty"`
+	// Error tells why the details or logs couldn't be read, e.g. without
+	// root
+	Error string `json:"error,omitempty"`
+}
+
+// FailedServicesResponse lists the failed services, those restarted most
+// often first
+type FailedServicesResponse struct {
+	SchemaVersion int             `json:"schema_version,omitempty"`
+	Services      []FailedService `json:"services"`
+	Count         int             `json:"count"`
+}
+
+// ScheduledJob is something the system runs on a schedule: a cron entry,
+// a launchd job with StartCalendarInterval or StartInterval, or a systemd
+// timer
+type ScheduledJob struct {
+	Source string `json:"source"` // cron, launchd or systemd
+	// Name is the launchd label, the timer unit without .timer, or the
+	// crontab's file name
+	Name string `json:"name"`
+	// Schedule is the cron expression, the launchd calendar interval as
+	// one, "every <duration>", or the timer's OnCalendar= and On*Sec=
+	// settings
+	Schedule string `json:"schedule"`
+	// Command is what runs: a command line, or the unit a timer starts
+	Command string `json:"command,omitempty"`
+	User    string `json:"user,omitempty"`
+	File    string `json:"file,omitempty"`
+	// NextRun and LastRun are RFC 3339 times, empty when unknown, such as
+	// for @reboot entries and launchd StartInterval jobs
+	NextRun string `json:"next_run,omitempty"`
+	LastRun string `json:"last_run,omitempty"`
+}
+
+// ScheduledJobsResponse lists the scheduled jobs, the next to run first
+type ScheduledJobsResponse struct {
+	SchemaVersion int            `json:"schema_version,omitempty"`
+	Jobs          []ScheduledJob `json:"jobs"`
+	Count         int            `json:"count"`
+}
+
+// ServiceActionResponse reports a service acted on, whether it runs
+// afterwards and whether it is enabled
+type ServiceActionResponse struct {
+	SchemaVersion int    `json:"schema_version,omitempty"`
+	Name          string `json:"name"`
+	Action        string `json:"action"`
+	User          bool   `json:"user,omitempty"` // A systemd user unit
+	Running       bool   `json:"running"`
+	PID           int32  `json:"pid,omitempty"`
+	Enablement    string `json:"enablement,omitempty"`
+}
+
+type HealthResponse struct {
+	Status      string                     `json:"status"` // healthy or degraded
+	StartedAt   string                     `json:"started_at"`
+	Uptime      uint64                     `json:"uptime"` // Seconds since the server started
+	UptimeHuman string                     `json:"uptime_human"`
+	Collectors  map[string]CollectorStatus `json:"collectors"`
+	Permissions []PermissionStatus         `json:"permissions"`
+}
+
+type WebhooksResponse struct {
+	SchemaVersion int       `json:"schema_version,omitempty"`
+	Webhooks      []Webhook `json:"webhooks"`
+	Count         int       `json:"count"`
+}
+
+type ReadinessResponse struct {
+	Status string            `json:"status"` // ready, degraded or not_ready
+	Checks map[string]string `json:"checks"` // "ok", "unavailable: <reason>" or the failure reason
+}
+
+// BatchCall is one sub-request of a batch query
+type BatchCall struct {
+	ID        string                 `json:"id,omitempty"`
+	Tool      string                 `json:"tool"`
+	Arguments map[string]interface{} `json:"arguments,omitempty"`
+}
+
+type BatchRequest struct {
+	Requests []BatchCall `json:"requests"`
+}
+
+// BatchResult is the outcome of one BatchCall; Status mirrors the HTTP
+// status the call would have returned on its own endpoint
+type BatchResult struct {
+	ID     string      `json:"id,omitempty"`
+	Tool   string      `json:"tool"`
+	Status int         `json:"status"`
+	Result interface{} `json:"result,omitempty"`
+	Error  string      `json:"error,omitempty"`
+	// PermissionRequired names the macOS permission (e.g. accessibility)
+	// whose absence made the call fail
+	PermissionRequired string `json:"permission_required,omitempty"`
+}
+
+type BatchResponse struct {
+	SchemaVersion int           `json:"schema_version,omitempty"`
+	Results       []BatchResult `json:"results"`
+	Count         int           `json:"count"`
 }
 
 type ErrorResponse struct {
-	Error string `json:"error"`
+	SchemaVersion int    `json:"schema_version,omitempty"`
+	Error         string `json:"error"`
+	// PermissionRequired names the macOS permission (e.g. accessibility)
+	// whose absence made the request fail; see the health endpoint
+	PermissionRequired string `json:"permission_required,omitempty"`
 }